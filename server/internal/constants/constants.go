@@ -23,13 +23,63 @@ var (
 	TableNameMap     = map[TableType]string{}
 	DefaultConfigDir = "/tmp/olake-config"
 
-	DefaultLogRetentionPeriod   = 30
-	DefaultCancelSyncWaitTime   = 30 * time.Second
-	DefaultListWorkflowPageSize = 500
+	DefaultLogRetentionPeriod     = 30
+	DefaultShortLivedLogRetention = 1
+	DefaultLogRetentionKeepRuns   = 10
+	DefaultLogJanitorInterval     = 60
+	DefaultCancelSyncWaitTime     = 30 * time.Second
+	DefaultListWorkflowPageSize   = 500
+	DefaultStorageBackend         = "local"
+	DefaultStateHistoryLimit      = 50
+	DefaultEncryptionFormat       = "legacy"
+	DefaultSecretRefEnvPrefix     = "OLAKE_REF_"
+	DefaultSecretRefFileDir       = "/run/secrets"
+	DefaultPasswordMinLength      = 8
+	DefaultLoginMaxFailedAttempts = 5
+	DefaultLoginLockoutMinutes    = 15
+	DefaultLoginRateLimit         = 10
+	DefaultSignupEnabled          = true
+	DefaultSessionIdleTimeout     = 60    // minutes, 0 disables it
+	DefaultSessionAbsoluteTimeout = 43200 // minutes, 30 days
+	DefaultInviteExpiryHours      = 72
+	DefaultEmailVerifyExpiryHours = 24
+	DefaultAppURL                 = "http://localhost:8000"
+	DefaultSMTPPort               = 587
+	DefaultSMTPTLS                = "starttls"
+	DefaultStreamRediscovery      = 360 // minutes, 0 disables it
+	DefaultSchemaDriftCheck       = 360 // minutes, 0 disables it
+	DefaultCatalogCacheTTL        = 0   // minutes, 0 disables it
+	DefaultDryRunRowLimit         = 100
+	DefaultDryRunSampleSize       = 10
+	DefaultStreamPreviewLimit     = 50
+	MaxStreamPreviewLimit         = 500
+	DefaultStreamPreviewCacheTTL  = 60   // seconds, 0 disables it
+	DefaultMaintenanceCheck       = 60   // seconds, 0 disables it
+	DefaultMaintenanceDrain       = 1800 // seconds
+	DefaultMaintenanceEventLimit  = 100
+	MaxMaintenanceEventLimit      = 1000
+	MaxLabels                     = 32 // labels per source, destination or job
+	MaxLabelValueLength           = 255
+	DefaultSyncQueueCheck         = 60  // seconds, 0 disables it
+	DefaultSyncAdmissionRetry     = 15  // seconds a queued sync waits before asking again
+	DefaultSyncQueueStale         = 300 // seconds after which a queued sync that stopped asking is dropped
 
-	// versions
-	DefaultSpecVersion             = "v0.2.0"
-	DefaultClearDestinationVersion = "v0.3.0"
+	// SecretMask replaces secret config values in API responses, sending it back keeps the stored value
+	SecretMask = "********"
+	// PIIMask replaces the values of columns redacted by a masking rule
+	PIIMask = "****"
+
+	// connector features that need a minimum connector version
+	FeatureSpec             = "spec"
+	FeatureClearDestination = "clear_destination"
+	// DefaultConnectorFeatures seeds the minimum version of each feature for new registry connectors
+	DefaultConnectorFeatures = map[string]string{
+		FeatureSpec:             "v0.2.0",
+		FeatureClearDestination: "v0.3.0",
+	}
+	DefaultConnectorImagePrefix     = "olakego/source-"
+	DefaultConnectorRegistryRefresh = 720 // minutes, 0 disables it
+	DefaultConnectorRegistryOffline = false
 
 	// logging
 	EnvLogLevel          = "LOG_LEVEL"
@@ -40,12 +90,89 @@ var (
 	TemporalTaskQueue = "OLAKE_DOCKER_TASK_QUEUE"
 
 	// conf keys
-	ConfEncryptionKey         = "encryptionkey"
+	ConfEncryptionKey = "encryptionkey"
+	// comma separated keys (local secrets or KMS ARNs) still accepted for decryption after a rotation
+	ConfPreviousEncryptionKeys = "previousencryptionkeys"
+	// format new ciphertexts are stored in, "v1" (key ID envelope) or "legacy" for workers without envelope support
+	ConfEncryptionFormat      = "OLAKE_ENCRYPTION_FORMAT"
 	ConfTemporalAddress       = "TEMPORAL_ADDRESS"
 	ConfDeploymentMode        = "DEPLOYMENT_MODE"
 	ConfRunMode               = "runmode"
 	ConfContainerRegistryBase = "CONTAINER_REGISTRY_BASE"
+	// registry credentials, the auth file holds credentials per registry in the docker config.json
+	// format, username and password apply to the CONTAINER_REGISTRY_BASE registry. Insecure lists
+	// registries reached over plain HTTP.
+	ConfContainerRegistryAuthFile = "CONTAINER_REGISTRY_AUTH_FILE"
+	ConfContainerRegistryUsername = "CONTAINER_REGISTRY_USERNAME"
+	ConfContainerRegistryPassword = "CONTAINER_REGISTRY_PASSWORD"
+	ConfContainerRegistryInsecure = "CONTAINER_REGISTRY_INSECURE"
+	// comma separated worker features beyond the base contract, see temporal.WorkerSupports
+	ConfWorkerFeatures = "WORKER_FEATURES"
+	// log retention keys, retention periods are in days and the janitor interval in minutes
+	ConfLogRetentionPeriod     = "LOG_RETENTION_PERIOD"
+	ConfLogRetentionDiscover   = "LOG_RETENTION_DISCOVER"
+	ConfLogRetentionCheck      = "LOG_RETENTION_CHECK"
+	ConfLogRetentionSpec       = "LOG_RETENTION_SPEC"
+	ConfLogRetentionDifference = "LOG_RETENTION_DIFFERENCE"
+	ConfLogRetentionDryRun     = "LOG_RETENTION_DRY_RUN"
+	ConfLogRetentionKeepRuns   = "LOG_RETENTION_KEEP_RUNS"
+	ConfLogJanitorInterval     = "LOG_JANITOR_INTERVAL"
+	// number of state versions kept per job
+	ConfStateHistoryLimit = "STATE_HISTORY_LIMIT"
+	// storage keys, the worker must be configured with the same backend
+	ConfStorageBackend     = "STORAGE_BACKEND"
+	ConfStorageS3Bucket    = "STORAGE_S3_BUCKET"
+	ConfStorageS3Prefix    = "STORAGE_S3_PREFIX"
+	ConfStorageS3Endpoint  = "STORAGE_S3_ENDPOINT"
+	ConfStorageS3Region    = "STORAGE_S3_REGION"
+	ConfStorageS3AccessKey = "STORAGE_S3_ACCESS_KEY"
+	ConfStorageS3SecretKey = "STORAGE_S3_SECRET_KEY"
+	ConfStorageS3PathStyle = "STORAGE_S3_PATH_STYLE"
+	// secret reference keys, env references must start with the prefix and file references live under the dir
+	ConfSecretRefEnvPrefix = "SECRET_REF_ENV_PREFIX"
+	ConfSecretRefFileDir   = "SECRET_REF_FILE_DIR"
+	ConfVaultAddr          = "VAULT_ADDR"
+	ConfVaultToken         = "VAULT_TOKEN"
+	ConfVaultNamespace     = "VAULT_NAMESPACE"
 	// database keys
+	ConfPasswordMinLength        = "PASSWORD_MIN_LENGTH"
+	ConfPasswordRequireUpper     = "PASSWORD_REQUIRE_UPPERCASE"
+	ConfPasswordRequireLower     = "PASSWORD_REQUIRE_LOWERCASE"
+	ConfPasswordRequireDigit     = "PASSWORD_REQUIRE_DIGIT"
+	ConfPasswordRequireSpecial   = "PASSWORD_REQUIRE_SPECIAL"
+	ConfLoginMaxFailedAttempts   = "LOGIN_MAX_FAILED_ATTEMPTS"
+	ConfLoginLockoutMinutes      = "LOGIN_LOCKOUT_MINUTES"
+	ConfLoginRateLimit           = "LOGIN_RATE_LIMIT"
+	ConfLoginRateLimitTrustProxy = "LOGIN_RATE_LIMIT_TRUST_PROXY"
+	ConfSignupEnabled            = "SIGNUP_ENABLED"
+	ConfSessionIdleTimeout       = "SESSION_IDLE_TIMEOUT"
+	ConfSessionAbsoluteTimeout   = "SESSION_ABSOLUTE_TIMEOUT"
+	ConfAuthTokenSecret          = "AUTH_TOKEN_SECRET"
+	ConfInviteExpiryHours        = "INVITE_EXPIRY_HOURS"
+	ConfEmailVerifyExpiryHours   = "EMAIL_VERIFY_EXPIRY_HOURS"
+	ConfAppURL                   = "APP_URL"
+	ConfSMTPHost                 = "SMTP_HOST"
+	ConfSMTPPort                 = "SMTP_PORT"
+	ConfSMTPUsername             = "SMTP_USERNAME"
+	ConfSMTPPassword             = "SMTP_PASSWORD"
+	ConfSMTPFrom                 = "SMTP_FROM"
+	ConfSMTPTLS                  = "SMTP_TLS"
+	// interval in minutes of the re-discovery applying the stream selection rules of jobs
+	ConfStreamRediscoveryInterval = "STREAM_REDISCOVERY_INTERVAL"
+	// interval in minutes of the schema checks of jobs with a drift policy
+	ConfSchemaDriftCheckInterval = "SCHEMA_DRIFT_CHECK_INTERVAL"
+	// minutes a discovered catalog is served from cache, 0 turns the catalog cache off
+	ConfCatalogCacheTTL = "CATALOG_CACHE_TTL"
+	// seconds a stream preview is served from cache, 0 turns the preview cache off
+	ConfStreamPreviewCacheTTL = "STREAM_PREVIEW_CACHE_TTL"
+	// interval in seconds of the checks pausing and resuming jobs for maintenance windows
+	ConfMaintenanceCheckInterval = "MAINTENANCE_CHECK_INTERVAL"
+	// interval in seconds of the checks releasing the sync slots of runs that ended without a callback
+	ConfSyncQueueCheckInterval = "SYNC_QUEUE_CHECK_INTERVAL"
+	// interval in minutes of the connector registry refresh, offline registries only read local images
+	ConfConnectorRegistryRefresh = "CONNECTOR_REGISTRY_REFRESH_INTERVAL"
+	ConfConnectorRegistryOffline = "CONNECTOR_REGISTRY_OFFLINE"
+
 	ConfPostgresDB            = "postgresdb"
 	ConfOLakePostgresUser     = "OLAKE_POSTGRES_USER"
 	ConfOLakePostgresPassword = "OLAKE_POSTGRES_PASSWORD"
@@ -66,6 +193,19 @@ var (
 
 	// DefaultLogsDirection is the fallback pagination direction ("older" or "newer").
 	DefaultLogsDirection = "older"
+
+	// SyncLogFileName is the connector log written inside every logs/sync_* attempt folder.
+	SyncLogFileName = "olake.log"
+
+	// AttemptFolderTimeLayout is the layout of the start time in logs/sync_* attempt folder names.
+	AttemptFolderTimeLayout = "2006-01-02_15-04-05"
+
+	// ActivityLogArchiveName is the file the activity history of a run is written to in log archives.
+	ActivityLogArchiveName = "activity.log"
+
+	// LogTypeSync selects the connector log and LogTypeActivity the activity history of the run.
+	LogTypeSync     = "sync"
+	LogTypeActivity = "activity"
 )
 
 // Supported database/source types
@@ -110,13 +250,29 @@ func Init() {
 
 	// init table names
 	TableNameMap = map[TableType]string{
-		UserTable:            "olake-$$-user",
-		SourceTable:          "olake-$$-source",
-		DestinationTable:     "olake-$$-destination",
-		JobTable:             "olake-$$-job",
-		CatalogTable:         "olake-$$-catalog",
-		SessionTable:         "session",
-		ProjectSettingsTable: "olake-$$-project-settings",
+		UserTable:                 "olake-$$-user",
+		SourceTable:               "olake-$$-source",
+		DestinationTable:          "olake-$$-destination",
+		JobTable:                  "olake-$$-job",
+		CatalogTable:              "olake-$$-catalog",
+		SessionTable:              "session",
+		ProjectSettingsTable:      "olake-$$-project-settings",
+		JobStateVersionTable:      "olake-$$-job-state-version",
+		SystemSettingTable:        "olake-$$-system-setting",
+		UserSessionTable:          "olake-$$-user-session",
+		UserInviteTable:           "olake-$$-user-invite",
+		JobTemplateTable:          "olake-$$-job-template",
+		JobStreamRulesTable:       "olake-$$-job-stream-rules",
+		StreamReviewTable:         "olake-$$-stream-review",
+		SchemaDriftTable:          "olake-$$-schema-drift",
+		ConnectorTable:            "olake-$$-connector",
+		ConnectorVersionTable:     "olake-$$-connector-version",
+		ConnectorUpgradeTable:     "olake-$$-connector-upgrade",
+		ConnectorUpgradeItemTable: "olake-$$-connector-upgrade-item",
+		MaskingRuleTable:          "olake-$$-masking-rule",
+		MaintenanceWindowTable:    "olake-$$-maintenance-window",
+		MaintenanceEventTable:     "olake-$$-maintenance-event",
+		SyncSlotTable:             "olake-$$-sync-slot",
 	}
 
 	// replace $$ with the environment
@@ -6,12 +6,82 @@ import "errors"
 var (
 	// User related errors
 	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidUserAction  = errors.New("action not allowed on this user")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrPasswordProcessing = errors.New("failed to process password")
+	ErrWeakPassword       = errors.New("password does not meet the password policy")
+	ErrSignupDisabled     = errors.New("signup is disabled")
+	ErrSessionExpired     = errors.New("session expired or revoked")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrUserDisabled       = errors.New("user is disabled")
+	ErrUserOwnsResources  = errors.New("user still owns sources, destinations or jobs")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrInviteNotFound     = errors.New("invite not found")
+	ErrEmailNotConfigured = errors.New("email delivery is not configured")
+
+	// Job related errors
+	ErrJobNotFound = errors.New("job not found")
+
+	// Job template related errors
+	ErrJobTemplateNotFound  = errors.New("job template not found")
+	ErrInvalidJobTemplate   = errors.New("invalid job template")
+	ErrJobTemplateNameTaken = errors.New("job template name is not unique")
+
+	// Stream selection related errors
+	ErrStreamRulesNotFound  = errors.New("job has no stream selection rules")
+	ErrInvalidStreamRules   = errors.New("invalid stream selection rules")
+	ErrStreamReviewNotFound = errors.New("stream review not found")
+	ErrStreamReviewStale    = errors.New("job streams changed since the review was created")
+
+	// Schema drift related errors
+	ErrSchemaDriftNotFound = errors.New("schema drift not found")
+	ErrSchemaDriftStale    = errors.New("job streams changed since the drift was detected")
+	ErrNoDriftPolicy       = errors.New("job has no schema drift policy")
+
+	// Dry run related errors
+	ErrInvalidDryRun = errors.New("invalid dry run")
+
+	// Manual sync related errors
+	ErrInvalidManualSync = errors.New("invalid manual sync")
+	ErrSyncRunning       = errors.New("a sync of the job is already running")
+
+	// Label related errors
+	ErrInvalidLabels = errors.New("invalid labels")
+
+	// Worker related errors
+	ErrWorkerFeatureUnsupported = errors.New("the worker does not support this feature")
+
+	// Sync concurrency related errors
+	ErrInvalidResourceLimits = errors.New("invalid resource limits")
+
+	// Bulk job related errors
+	ErrInvalidBulkJobRequest = errors.New("invalid bulk job request")
 
 	// Source related errors
-	ErrSourceNotFound = errors.New("source not found")
+	ErrSourceNotFound       = errors.New("source not found")
+	ErrDestinationNotFound  = errors.New("destination not found")
+	ErrInvalidStreamPreview = errors.New("invalid stream preview")
+
+	// Masking rule related errors
+	ErrMaskingRuleNotFound = errors.New("masking rule not found")
+	ErrInvalidMaskingRule  = errors.New("invalid masking rule")
+
+	// Maintenance window related errors
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+	ErrInvalidMaintenanceWindow  = errors.New("invalid maintenance window")
+	ErrJobInMaintenance          = errors.New("job is paused for a maintenance window")
+
+	// connector registry errors
+	ErrConnectorNotFound          = errors.New("connector not found")
+	ErrConnectorVersionNotFound   = errors.New("connector version not found")
+	ErrConnectorVersionNotAllowed = errors.New("connector version is not allowed")
+	ErrInvalidConnector           = errors.New("invalid connector settings")
+
+	// connector upgrade errors
+	ErrConnectorUpgradeNotFound = errors.New("connector upgrade not found")
+	ErrConnectorUpgradeRunning  = errors.New("a connector upgrade is already running")
+	ErrInvalidConnectorUpgrade  = errors.New("invalid connector upgrade")
 )
 
 // Validation messages
@@ -5,4 +5,6 @@ const (
 	SessionUserName  = "username"
 	SessionUserEmail = "user_email"
 	SessionUserRole  = "user_role"
+	// SessionMustChangePassword is set while the user has to change a password reset by an admin
+	SessionMustChangePassword = "must_change_password"
 )
@@ -11,4 +11,20 @@ const (
 	CatalogTable
 	SessionTable
 	ProjectSettingsTable
+	JobStateVersionTable
+	SystemSettingTable
+	UserSessionTable
+	UserInviteTable
+	JobTemplateTable
+	JobStreamRulesTable
+	StreamReviewTable
+	SchemaDriftTable
+	ConnectorTable
+	ConnectorVersionTable
+	ConnectorUpgradeTable
+	ConnectorUpgradeItemTable
+	MaskingRuleTable
+	MaintenanceWindowTable
+	MaintenanceEventTable
+	SyncSlotTable
 )
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+// Catalog cache kinds
+const (
+	CatalogKindSourceSpec      = "source_spec"
+	CatalogKindDestinationSpec = "destination_spec"
+	CatalogKindDiscover        = "discover"
+)
+
+// GetCachedCatalog returns the cache entry of a kind, connector type, name and version
+func (db *Database) GetCachedCatalog(kind, connectorType, name, version string) (*models.Catalog, error) {
+	entry := &models.Catalog{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.CatalogTable]).
+		Filter("kind", kind).
+		Filter("type", connectorType).
+		Filter("name", name).
+		Filter("version", version).
+		One(entry)
+	return entry, err
+}
+
+// SaveCachedCatalog creates or replaces a cache entry
+func (db *Database) SaveCachedCatalog(entry *models.Catalog) error {
+	existing, err := db.GetCachedCatalog(entry.Kind, entry.Type, entry.Name, entry.Version)
+	switch {
+	case err == nil:
+		entry.ID = existing.ID
+		entry.CreatedAt = existing.CreatedAt
+		_, err = db.ormer.Update(entry)
+	case errors.Is(err, orm.ErrNoRows):
+		_, err = db.ormer.Insert(entry)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save cache entry kind[%s] type[%s] version[%s]: %s", entry.Kind, entry.Type, entry.Version, err)
+	}
+	return nil
+}
+
+// DeleteCachedSpecs removes cached specs, an empty connector type or version matches any
+func (db *Database) DeleteCachedSpecs(connectorType, version string) (int64, error) {
+	query := db.ormer.QueryTable(constants.TableNameMap[constants.CatalogTable]).
+		Filter("kind__in", CatalogKindSourceSpec, CatalogKindDestinationSpec)
+	if connectorType != "" {
+		query = query.Filter("type", connectorType)
+	}
+	if version != "" {
+		query = query.Filter("version", version)
+	}
+	removed, err := query.Delete()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached specs: %s", err)
+	}
+	return removed, nil
+}
+
+// DeleteCachedSourceCatalogs removes the discovered catalogs cached for a source
+func (db *Database) DeleteCachedSourceCatalogs(sourceID int) (int64, error) {
+	removed, err := db.ormer.QueryTable(constants.TableNameMap[constants.CatalogTable]).
+		Filter("kind", CatalogKindDiscover).
+		Filter("source_id", sourceID).
+		Delete()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached catalogs source_id[%d]: %s", sourceID, err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Connector version origins
+const (
+	ConnectorVersionRegistry = "registry"
+	ConnectorVersionLocal    = "local"
+	ConnectorVersionManual   = "manual"
+)
+
+// SeedConnectors adds the connectors whose type is not in the registry yet, existing
+// connectors keep the settings they were changed to
+func (db *Database) SeedConnectors(connectors []*models.Connector) error {
+	for _, connector := range connectors {
+		exists := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorTable]).
+			Filter("type", connector.Type).
+			Exist()
+		if exists {
+			continue
+		}
+		if _, err := db.ormer.Insert(connector); err != nil {
+			return fmt.Errorf("failed to seed connector type[%s]: %s", connector.Type, err)
+		}
+	}
+	return nil
+}
+
+// ListConnectors returns the registry connectors in driver order
+func (db *Database) ListConnectors() ([]*models.Connector, error) {
+	var connectors []*models.Connector
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorTable]).
+		OrderBy("position", "id").
+		All(&connectors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connectors: %s", err)
+	}
+	return connectors, nil
+}
+
+// GetConnector returns the registry connector of a type
+func (db *Database) GetConnector(connectorType string) (*models.Connector, error) {
+	connector := &models.Connector{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorTable]).
+		Filter("type", connectorType).
+		One(connector)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, fmt.Errorf("%w: type[%s]", constants.ErrConnectorNotFound, connectorType)
+		}
+		return nil, fmt.Errorf("failed to get connector type[%s]: %s", connectorType, err)
+	}
+	return connector, nil
+}
+
+// UpdateConnector saves the settings of a registry connector
+func (db *Database) UpdateConnector(connector *models.Connector) error {
+	connector.UpdatedAt = time.Now()
+	_, err := db.ormer.Update(connector, "Image", "Position", "Deprecated", "DeprecationNote", "AutoAllow", "Features", "UpdatedAt")
+	if err != nil {
+		return fmt.Errorf("failed to update connector type[%s]: %s", connector.Type, err)
+	}
+	return nil
+}
+
+// ListConnectorVersions returns the versions recorded for a connector
+func (db *Database) ListConnectorVersions(connectorID int) ([]*models.ConnectorVersion, error) {
+	var versions []*models.ConnectorVersion
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorVersionTable]).
+		Filter("connector_id", connectorID).
+		All(&versions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of connector id[%d]: %s", connectorID, err)
+	}
+	return versions, nil
+}
+
+// GetConnectorVersion returns a recorded version of a connector
+func (db *Database) GetConnectorVersion(connectorID int, version string) (*models.ConnectorVersion, error) {
+	entry := &models.ConnectorVersion{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorVersionTable]).
+		Filter("connector_id", connectorID).
+		Filter("version", version).
+		One(entry)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, fmt.Errorf("%w: connector_id[%d] version[%s]", constants.ErrConnectorVersionNotFound, connectorID, version)
+		}
+		return nil, fmt.Errorf("failed to get version[%s] of connector id[%d]: %s", version, connectorID, err)
+	}
+	return entry, nil
+}
+
+// SaveConnectorVersion creates or updates a version of a connector
+func (db *Database) SaveConnectorVersion(entry *models.ConnectorVersion) error {
+	existing, err := db.GetConnectorVersion(entry.Connector.ID, entry.Version)
+	switch {
+	case err == nil:
+		entry.ID = existing.ID
+		entry.Origin = existing.Origin
+		entry.UpdatedAt = time.Now()
+		_, err = db.ormer.Update(entry, "Allowed", "Deprecated", "UpdatedAt")
+	case errors.Is(err, constants.ErrConnectorVersionNotFound):
+		_, err = db.ormer.Insert(entry)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save version[%s] of connector id[%d]: %s", entry.Version, entry.Connector.ID, err)
+	}
+	return nil
+}
+
+// DeleteConnectorVersion removes a version of a connector
+func (db *Database) DeleteConnectorVersion(connectorID int, version string) error {
+	removed, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorVersionTable]).
+		Filter("connector_id", connectorID).
+		Filter("version", version).
+		Delete()
+	if err != nil {
+		return fmt.Errorf("failed to delete version[%s] of connector id[%d]: %s", version, connectorID, err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("%w: connector_id[%d] version[%s]", constants.ErrConnectorVersionNotFound, connectorID, version)
+	}
+	return nil
+}
+
+// RecordConnectorRefresh adds the versions found by a refresh that are not known yet, allowed
+// when the connector auto allows them. Known versions keep their flags, and versions missing
+// from the refresh are kept so images pulled earlier stay usable.
+func (db *Database) RecordConnectorRefresh(connector *models.Connector, versions []string, origin string, refreshErr error) error {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback refresh of connector type[%s]: %s", connector.Type, err)
+		}
+	}()
+
+	for _, version := range versions {
+		exists := tx.QueryTable(constants.TableNameMap[constants.ConnectorVersionTable]).
+			Filter("connector_id", connector.ID).
+			Filter("version", version).
+			Exist()
+		if exists {
+			continue
+		}
+		if _, err := tx.Insert(&models.ConnectorVersion{
+			Connector: connector,
+			Version:   version,
+			Allowed:   connector.AutoAllow,
+			Origin:    origin,
+		}); err != nil {
+			return fmt.Errorf("failed to record version[%s] of connector type[%s]: %s", version, connector.Type, err)
+		}
+	}
+
+	now := time.Now()
+	connector.RefreshedAt = &now
+	connector.RefreshError = ""
+	if refreshErr != nil {
+		connector.RefreshError = refreshErr.Error()
+	}
+	if _, err := tx.Update(connector, "RefreshedAt", "RefreshError"); err != nil {
+		return fmt.Errorf("failed to record refresh of connector type[%s]: %s", connector.Type, err)
+	}
+	return tx.Commit()
+}
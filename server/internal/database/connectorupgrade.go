@@ -0,0 +1,163 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Connector upgrade statuses
+const (
+	ConnectorUpgradeRunning     = "running"
+	ConnectorUpgradeCompleted   = "completed"
+	ConnectorUpgradeStopped     = "stopped"
+	ConnectorUpgradeInterrupted = "interrupted"
+)
+
+// Connector upgrade item statuses
+const (
+	UpgradeItemPending    = "pending"
+	UpgradeItemUpgraded   = "upgraded"
+	UpgradeItemFailed     = "failed"
+	UpgradeItemSkipped    = "skipped"
+	UpgradeItemRolledBack = "rolled_back"
+)
+
+// CreateConnectorUpgrade stores an upgrade with its items, unless an upgrade of the same
+// connector type is still running in the project
+func (db *Database) CreateConnectorUpgrade(upgrade *models.ConnectorUpgrade, items []*models.ConnectorUpgradeItem) error {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback connector upgrade type[%s]: %s", upgrade.SourceType, err)
+		}
+	}()
+
+	running := tx.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeTable]).
+		Filter("project_id", upgrade.ProjectID).
+		Filter("source_type", upgrade.SourceType).
+		Filter("status", ConnectorUpgradeRunning).
+		Exist()
+	if running {
+		return fmt.Errorf("%w: type[%s]", constants.ErrConnectorUpgradeRunning, upgrade.SourceType)
+	}
+
+	if _, err := tx.Insert(upgrade); err != nil {
+		return fmt.Errorf("failed to insert connector upgrade type[%s]: %s", upgrade.SourceType, err)
+	}
+	for _, item := range items {
+		item.Upgrade = upgrade
+		if _, err := tx.Insert(item); err != nil {
+			return fmt.Errorf("failed to insert connector upgrade item source_id[%d]: %s", item.SourceID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListConnectorUpgrades returns the connector upgrades of a project, newest first
+func (db *Database) ListConnectorUpgrades(projectID string) ([]*models.ConnectorUpgrade, error) {
+	var upgrades []*models.ConnectorUpgrade
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeTable]).
+		Filter("project_id", projectID).
+		RelatedSel("CreatedBy").
+		OrderBy("-id").
+		All(&upgrades)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connector upgrades project_id[%s]: %s", projectID, err)
+	}
+	return upgrades, nil
+}
+
+// GetConnectorUpgrade returns a connector upgrade of a project
+func (db *Database) GetConnectorUpgrade(projectID string, id int) (*models.ConnectorUpgrade, error) {
+	upgrade := &models.ConnectorUpgrade{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeTable]).
+		Filter("id", id).
+		Filter("project_id", projectID).
+		RelatedSel("CreatedBy").
+		One(upgrade)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id[%d]", constants.ErrConnectorUpgradeNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get connector upgrade id[%d]: %s", id, err)
+	}
+	return upgrade, nil
+}
+
+// ListConnectorUpgradeItems returns the items of upgrades in rollout order
+func (db *Database) ListConnectorUpgradeItems(upgradeIDs []int) ([]*models.ConnectorUpgradeItem, error) {
+	var items []*models.ConnectorUpgradeItem
+	if len(upgradeIDs) == 0 {
+		return items, nil
+	}
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeItemTable]).
+		Filter("upgrade_id__in", upgradeIDs).
+		OrderBy("id").
+		All(&items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connector upgrade items: %s", err)
+	}
+	return items, nil
+}
+
+// UpdateConnectorUpgrade saves the status of an upgrade
+func (db *Database) UpdateConnectorUpgrade(upgrade *models.ConnectorUpgrade) error {
+	upgrade.UpdatedAt = time.Now()
+	if _, err := db.ormer.Update(upgrade, "Status", "FinishedAt", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to update connector upgrade id[%d]: %s", upgrade.ID, err)
+	}
+	return nil
+}
+
+// UpdateConnectorUpgradeItem saves the outcome of an upgrade item
+func (db *Database) UpdateConnectorUpgradeItem(item *models.ConnectorUpgradeItem) error {
+	item.UpdatedAt = time.Now()
+	if _, err := db.ormer.Update(item, "Status", "CheckResult", "Error", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to update connector upgrade item id[%d]: %s", item.ID, err)
+	}
+	return nil
+}
+
+// InterruptConnectorUpgrades marks the upgrades left running by a previous process as
+// interrupted and skips their pending items, returning the number of upgrades interrupted
+func (db *Database) InterruptConnectorUpgrades() (int64, error) {
+	var upgrades []*models.ConnectorUpgrade
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeTable]).
+		Filter("status", ConnectorUpgradeRunning).
+		All(&upgrades, "ID")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list running connector upgrades: %s", err)
+	}
+	if len(upgrades) == 0 {
+		return 0, nil
+	}
+	ids := make([]int, 0, len(upgrades))
+	for _, upgrade := range upgrades {
+		ids = append(ids, upgrade.ID)
+	}
+
+	now := time.Now().UTC()
+	if _, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeItemTable]).
+		Filter("upgrade_id__in", ids).
+		Filter("status", UpgradeItemPending).
+		Update(orm.Params{"status": UpgradeItemSkipped, "error": "upgrade was interrupted", "updated_at": now}); err != nil {
+		return 0, fmt.Errorf("failed to skip items of interrupted connector upgrades: %s", err)
+	}
+	interrupted, err := db.ormer.QueryTable(constants.TableNameMap[constants.ConnectorUpgradeTable]).
+		Filter("id__in", ids).
+		Update(orm.Params{"status": ConnectorUpgradeInterrupted, "finished_at": now, "updated_at": now})
+	if err != nil {
+		return 0, fmt.Errorf("failed to interrupt connector upgrades: %s", err)
+	}
+	return interrupted, nil
+}
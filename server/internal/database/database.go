@@ -42,7 +42,14 @@ func Init() (*Database, error) {
 		web.BConfig.WebConfig.Session.SessionName = "olake-session"
 		web.BConfig.WebConfig.Session.SessionProvider = "postgresql"
 		web.BConfig.WebConfig.Session.SessionProviderConfig = uri
-		web.BConfig.WebConfig.Session.SessionCookieLifeTime = 30 * 24 * 60 * 60 // 30 days
+		// the cookie lives as long as a session may, idle sessions are garbage collected
+		absoluteTimeout := web.AppConfig.DefaultInt(constants.ConfSessionAbsoluteTimeout, constants.DefaultSessionAbsoluteTimeout) * 60
+		idleTimeout := web.AppConfig.DefaultInt(constants.ConfSessionIdleTimeout, constants.DefaultSessionIdleTimeout) * 60
+		if idleTimeout <= 0 || idleTimeout > absoluteTimeout {
+			idleTimeout = absoluteTimeout
+		}
+		web.BConfig.WebConfig.Session.SessionCookieLifeTime = absoluteTimeout
+		web.BConfig.WebConfig.Session.SessionGCMaxLifetime = int64(idleTimeout)
 	}
 
 	// register session user
@@ -55,6 +62,22 @@ func Init() (*Database, error) {
 		new(models.Job),
 		new(models.User),
 		new(models.Catalog),
+		new(models.JobStateVersion),
+		new(models.SystemSetting),
+		new(models.UserSession),
+		new(models.UserInvite),
+		new(models.JobTemplate),
+		new(models.JobStreamRules),
+		new(models.StreamReview),
+		new(models.SchemaDrift),
+		new(models.Connector),
+		new(models.ConnectorVersion),
+		new(models.ConnectorUpgrade),
+		new(models.ConnectorUpgradeItem),
+		new(models.MaskingRule),
+		new(models.MaintenanceWindow),
+		new(models.MaintenanceEvent),
+		new(models.SyncSlot),
 	)
 
 	// Create tables if they do not exist
@@ -96,6 +96,17 @@ func (db *Database) UpdateDestination(destination *models.Destination) error {
 	return err
 }
 
+// UpdateDestinationLabels sets the labels of a destination, a JSON object or nil to remove them
+func (db *Database) UpdateDestinationLabels(id int, labels interface{}, userID int) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.DestinationTable]).
+		Filter("id", id).
+		Update(orm.Params{"labels": labels, "updated_by_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to update labels of destination id[%d]: %s", id, err)
+	}
+	return nil
+}
+
 func (db *Database) DeleteDestination(id int) error {
 	destination := &models.Destination{ID: id}
 	// Use ORM's Delete method which will automatically handle the soft delete
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// EncryptedConfig is the config of a source or destination as it is stored, without decryption
+type EncryptedConfig struct {
+	ID     int
+	Config string
+}
+
+// ListEncryptedConfigs returns the stored configs of every source (constants.SourceTable)
+// or destination (constants.DestinationTable) ordered by id
+func (db *Database) ListEncryptedConfigs(table constants.TableType) ([]EncryptedConfig, error) {
+	var rows []orm.ParamsList
+	_, err := db.ormer.QueryTable(constants.TableNameMap[table]).
+		OrderBy("id").
+		ValuesList(&rows, "id", "config")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configs of %s: %s", constants.TableNameMap[table], err)
+	}
+
+	configs := make([]EncryptedConfig, 0, len(rows))
+	for _, row := range rows {
+		id, ok := row[0].(int64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected id type %T in %s", row[0], constants.TableNameMap[table])
+		}
+		config, _ := row[1].(string)
+		configs = append(configs, EncryptedConfig{ID: int(id), Config: config})
+	}
+	return configs, nil
+}
+
+// ReplaceEncryptedConfig swaps the stored config of a row for config, only if it still holds
+// previous, so edits made while re-encrypting are not overwritten. It reports whether the row changed.
+func (db *Database) ReplaceEncryptedConfig(table constants.TableType, id int, previous, config string) (bool, error) {
+	updated, err := db.ormer.QueryTable(constants.TableNameMap[table]).
+		Filter("id", id).
+		Filter("config", previous).
+		Update(orm.Params{"config": config})
+	if err != nil {
+		return false, fmt.Errorf("failed to update config of %s id[%d]: %s", constants.TableNameMap[table], id, err)
+	}
+	return updated > 0, nil
+}
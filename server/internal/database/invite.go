@@ -0,0 +1,101 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// CreateInvite stores an invite, revoking earlier pending invites for the same email
+func (db *Database) CreateInvite(invite *models.UserInvite) error {
+	now := time.Now().UTC()
+	if _, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserInviteTable]).
+		Filter("email__iexact", invite.Email).
+		Filter("accepted_at__isnull", true).
+		Filter("revoked_at__isnull", true).
+		Update(orm.Params{"revoked_at": now}); err != nil {
+		return fmt.Errorf("failed to revoke earlier invites email[%s]: %s", invite.Email, err)
+	}
+
+	if _, err := db.ormer.Insert(invite); err != nil {
+		return fmt.Errorf("failed to insert invite email[%s]: %s", invite.Email, err)
+	}
+	return nil
+}
+
+// GetInviteByID returns an invite
+func (db *Database) GetInviteByID(id int) (*models.UserInvite, error) {
+	invite := &models.UserInvite{ID: id}
+	err := db.ormer.Read(invite)
+	return invite, err
+}
+
+// ListInvites returns all invites, newest first
+func (db *Database) ListInvites() ([]*models.UserInvite, error) {
+	var invites []*models.UserInvite
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserInviteTable]).
+		RelatedSel().
+		OrderBy("-id").
+		All(&invites)
+	return invites, err
+}
+
+// RevokeInvite revokes an invite that was not accepted yet
+func (db *Database) RevokeInvite(id int) (bool, error) {
+	updated, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserInviteTable]).
+		Filter("id", id).
+		Filter("accepted_at__isnull", true).
+		Filter("revoked_at__isnull", true).
+		Update(orm.Params{"revoked_at": time.Now().UTC()})
+	return updated > 0, err
+}
+
+// AcceptInvite creates the user of a pending invite and marks it accepted, all or nothing
+func (db *Database) AcceptInvite(inviteID int, user *models.User) error {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback invite acceptance invite[%d]: %s", inviteID, err)
+		}
+	}()
+
+	invite := &models.UserInvite{}
+	if err := tx.QueryTable(constants.TableNameMap[constants.UserInviteTable]).
+		Filter("id", inviteID).
+		ForUpdate().
+		One(invite); err != nil {
+		return fmt.Errorf("failed to lock invite id[%d]: %s", inviteID, err)
+	}
+	if invite.AcceptedAt != nil || invite.RevokedAt != nil || !time.Now().Before(invite.ExpiresAt) {
+		return constants.ErrInvalidToken
+	}
+
+	if tx.QueryTable(constants.TableNameMap[constants.UserTable]).Filter("username", user.Username).Exist() {
+		return fmt.Errorf("%w: username already exists", constants.ErrUserAlreadyExists)
+	}
+	if tx.QueryTable(constants.TableNameMap[constants.UserTable]).Filter("email__iexact", user.Email).Exist() {
+		return fmt.Errorf("%w: email already exists", constants.ErrUserAlreadyExists)
+	}
+	if _, err := tx.Insert(user); err != nil {
+		return fmt.Errorf("failed to create user: %s", err)
+	}
+
+	now := time.Now().UTC()
+	invite.AcceptedAt = &now
+	if _, err := tx.Update(invite, "AcceptedAt", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to mark invite id[%d] accepted: %s", inviteID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit invite acceptance: %s", err)
+	}
+	return nil
+}
@@ -23,6 +23,9 @@ var JobListFields = []string{
 	"DestID",
 	"CreatedBy",
 	"UpdatedBy",
+	"Labels",
+	"CPULimit",
+	"MemoryLimit",
 }
 
 // decryptJobConfig decrypts Config fields in related Source and Destination
@@ -84,7 +87,8 @@ func (db *Database) ListJobs() ([]*models.Job, error) {
 // GetAllJobsByProjectID retrieves all jobs belonging to a specific project,
 // including related Source and Destination, sorted by latest update time.
 // Only fetches columns needed for JobResponse: id, name, frequency, active,
-// created_at, updated_at, source_id, dest_id, created_by, updated_by.
+// created_at, updated_at, labels, cpu_limit, memory_limit, source_id, dest_id,
+// created_by, updated_by.
 // Excludes: streams_config, state (not needed for JobResponse).
 func (db *Database) ListJobsByProjectID(projectID string) ([]*models.Job, error) {
 	var jobs []*models.Job
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func (db *Database) CreateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	_, err := db.ormer.Insert(window)
+	return err
+}
+
+// ListMaintenanceWindows returns the maintenance windows of a project in the order they were
+// created
+func (db *Database) ListMaintenanceWindows(projectID string) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.MaintenanceWindowTable]).
+		RelatedSel().
+		Filter("project_id", projectID).
+		OrderBy("id").
+		All(&windows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows project_id[%s]: %s", projectID, err)
+	}
+	return windows, nil
+}
+
+// ListAllMaintenanceWindows returns the maintenance windows of every project
+func (db *Database) ListAllMaintenanceWindows() ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.MaintenanceWindowTable]).
+		OrderBy("id").
+		All(&windows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %s", err)
+	}
+	return windows, nil
+}
+
+// GetMaintenanceWindowByID returns a maintenance window of a project
+func (db *Database) GetMaintenanceWindowByID(projectID string, id int) (*models.MaintenanceWindow, error) {
+	window := &models.MaintenanceWindow{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.MaintenanceWindowTable]).
+		RelatedSel().
+		Filter("id", id).
+		Filter("project_id", projectID).
+		One(window)
+	return window, err
+}
+
+func (db *Database) UpdateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	_, err := db.ormer.Update(window)
+	return err
+}
+
+// UpdateMaintenanceWindowState saves which jobs a window paused and since when it is active
+func (db *Database) UpdateMaintenanceWindowState(window *models.MaintenanceWindow) error {
+	window.UpdatedAt = time.Now()
+	if _, err := db.ormer.Update(window, "ActiveSince", "PausedJobs", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to update maintenance window id[%d]: %s", window.ID, err)
+	}
+	return nil
+}
+
+func (db *Database) DeleteMaintenanceWindow(id int) error {
+	_, err := db.ormer.Delete(&models.MaintenanceWindow{ID: id})
+	return err
+}
+
+func (db *Database) CreateMaintenanceEvent(event *models.MaintenanceEvent) error {
+	_, err := db.ormer.Insert(event)
+	return err
+}
+
+// ListMaintenanceEvents returns the latest limit maintenance events of a project, newest
+// first. windowID and jobID 0 match any.
+func (db *Database) ListMaintenanceEvents(projectID string, windowID, jobID, limit int) ([]*models.MaintenanceEvent, error) {
+	var events []*models.MaintenanceEvent
+	query := db.ormer.QueryTable(constants.TableNameMap[constants.MaintenanceEventTable]).
+		Filter("project_id", projectID)
+	if windowID != 0 {
+		query = query.Filter("window_id", windowID)
+	}
+	if jobID != 0 {
+		query = query.Filter("job_id", jobID)
+	}
+	_, err := query.RelatedSel("User").OrderBy("-id").Limit(limit).All(&events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance events project_id[%s]: %s", projectID, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func (db *Database) CreateMaskingRule(rule *models.MaskingRule) error {
+	_, err := db.ormer.Insert(rule)
+	return err
+}
+
+// ListMaskingRules returns the masking rules of a project in the order they were created
+func (db *Database) ListMaskingRules(projectID string) ([]*models.MaskingRule, error) {
+	var rules []*models.MaskingRule
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.MaskingRuleTable]).
+		RelatedSel().
+		Filter("project_id", projectID).
+		OrderBy("id").
+		All(&rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list masking rules project_id[%s]: %s", projectID, err)
+	}
+	return rules, nil
+}
+
+// ListSourceMaskingRules returns the masking rules of a project that apply to a source, the
+// rules of the source and those of every source (source_id 0)
+func (db *Database) ListSourceMaskingRules(projectID string, sourceID int) ([]*models.MaskingRule, error) {
+	var rules []*models.MaskingRule
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.MaskingRuleTable]).
+		Filter("project_id", projectID).
+		Filter("source_id__in", 0, sourceID).
+		OrderBy("id").
+		All(&rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list masking rules project_id[%s] source_id[%d]: %s", projectID, sourceID, err)
+	}
+	return rules, nil
+}
+
+// GetMaskingRuleByID returns a masking rule of a project
+func (db *Database) GetMaskingRuleByID(projectID string, id int) (*models.MaskingRule, error) {
+	rule := &models.MaskingRule{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.MaskingRuleTable]).
+		RelatedSel().
+		Filter("id", id).
+		Filter("project_id", projectID).
+		One(rule)
+	return rule, err
+}
+
+func (db *Database) UpdateMaskingRule(rule *models.MaskingRule) error {
+	_, err := db.ormer.Update(rule)
+	return err
+}
+
+func (db *Database) DeleteMaskingRule(id int) error {
+	_, err := db.ormer.Delete(&models.MaskingRule{ID: id})
+	return err
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Schema drift statuses
+const (
+	SchemaDriftPending    = "pending"
+	SchemaDriftAccepted   = "accepted"
+	SchemaDriftRejected   = "rejected"
+	SchemaDriftSuperseded = "superseded"
+)
+
+// ListJobsWithDriftPolicy returns the active jobs that have a schema drift policy
+func (db *Database) ListJobsWithDriftPolicy() ([]*models.Job, error) {
+	var jobs []*models.Job
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobTable]).
+		Filter("drift_policy__isnull", false).
+		Exclude("drift_policy", "").
+		Filter("active", true).
+		OrderBy("id").
+		All(&jobs, "ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs with a drift policy: %s", err)
+	}
+	return jobs, nil
+}
+
+// CreateSchemaDrift stores a drift computed from baseStreamsConfig, superseding the pending
+// drifts of the job. A drift created as accepted also moves the job to its streams config.
+func (db *Database) CreateSchemaDrift(drift *models.SchemaDrift, baseStreamsConfig string) error {
+	drift.BaseHash = streamsConfigHash(baseStreamsConfig)
+
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback schema drift job_id[%d]: %s", drift.Job.ID, err)
+		}
+	}()
+
+	job, err := lockJob(tx, drift.Job.ID)
+	if err != nil {
+		return err
+	}
+	if streamsConfigHash(job.StreamsConfig) != drift.BaseHash {
+		return constants.ErrSchemaDriftStale
+	}
+
+	if _, err := tx.QueryTable(constants.TableNameMap[constants.SchemaDriftTable]).
+		Filter("job_id", drift.Job.ID).
+		Filter("status", SchemaDriftPending).
+		Update(orm.Params{"status": SchemaDriftSuperseded, "resolved_at": time.Now().UTC()}); err != nil {
+		return fmt.Errorf("failed to supersede schema drifts job_id[%d]: %s", drift.Job.ID, err)
+	}
+
+	if drift.Status == SchemaDriftAccepted {
+		if err := updateJobStreamsConfig(tx, drift.Job.ID, drift.StreamsConfig); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Insert(drift); err != nil {
+		return fmt.Errorf("failed to insert schema drift job_id[%d]: %s", drift.Job.ID, err)
+	}
+	return tx.Commit()
+}
+
+// ListSchemaDrifts returns the schema drifts of a project, newest first. jobID 0 and an empty
+// status match any.
+func (db *Database) ListSchemaDrifts(projectID string, jobID int, status string) ([]*models.SchemaDrift, error) {
+	query := db.ormer.QueryTable(constants.TableNameMap[constants.SchemaDriftTable]).
+		Filter("job__project_id", projectID)
+	if jobID > 0 {
+		query = query.Filter("job_id", jobID)
+	}
+	if status != "" {
+		query = query.Filter("status", status)
+	}
+
+	var drifts []*models.SchemaDrift
+	if _, err := query.RelatedSel("Job", "ResolvedBy").OrderBy("-id").All(&drifts); err != nil {
+		return nil, fmt.Errorf("failed to list schema drifts project_id[%s]: %s", projectID, err)
+	}
+	return drifts, nil
+}
+
+// GetSchemaDrift returns a schema drift of a project
+func (db *Database) GetSchemaDrift(projectID string, id int) (*models.SchemaDrift, error) {
+	drift := &models.SchemaDrift{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.SchemaDriftTable]).
+		Filter("id", id).
+		Filter("job__project_id", projectID).
+		RelatedSel("Job", "ResolvedBy").
+		One(drift)
+	return drift, err
+}
+
+// ResolveSchemaDrift accepts or rejects a pending drift. Accepting moves the job to the streams
+// config of the drift, unless the job changed since, then the drift is superseded and
+// ErrSchemaDriftStale returned.
+func (db *Database) ResolveSchemaDrift(id int, accept bool, userID int) error {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback schema drift id[%d]: %s", id, err)
+		}
+	}()
+
+	drift := &models.SchemaDrift{}
+	if err := tx.QueryTable(constants.TableNameMap[constants.SchemaDriftTable]).
+		Filter("id", id).
+		Filter("status", SchemaDriftPending).
+		ForUpdate().
+		One(drift); err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return constants.ErrSchemaDriftNotFound
+		}
+		return fmt.Errorf("failed to lock schema drift id[%d]: %s", id, err)
+	}
+
+	status := SchemaDriftRejected
+	var stale bool
+	if accept {
+		job, err := lockJob(tx, drift.Job.ID)
+		if err != nil {
+			return err
+		}
+		if streamsConfigHash(job.StreamsConfig) != drift.BaseHash {
+			status, stale = SchemaDriftSuperseded, true
+		} else {
+			if err := updateJobStreamsConfig(tx, job.ID, drift.StreamsConfig); err != nil {
+				return err
+			}
+			status = SchemaDriftAccepted
+		}
+	}
+
+	now := time.Now().UTC()
+	drift.Status = status
+	drift.ResolvedAt = &now
+	drift.ResolvedBy = &models.User{ID: userID}
+	if _, err := tx.Update(drift, "Status", "ResolvedAt", "ResolvedBy", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to update schema drift id[%d]: %s", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schema drift id[%d]: %s", id, err)
+	}
+	if stale {
+		return constants.ErrSchemaDriftStale
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// CreateUserSession starts tracking a login session
+func (db *Database) CreateUserSession(session *models.UserSession) error {
+	_, err := db.ormer.Insert(session)
+	return err
+}
+
+// GetUserSessionByKey returns the tracked session of a session key
+func (db *Database) GetUserSessionByKey(sessionKey string) (*models.UserSession, error) {
+	session := &models.UserSession{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.UserSessionTable]).
+		Filter("session_key", sessionKey).
+		One(session)
+	return session, err
+}
+
+// ListUserSessions returns the sessions of a user that have not expired, most recently used first
+func (db *Database) ListUserSessions(userID int, now time.Time) ([]*models.UserSession, error) {
+	var sessions []*models.UserSession
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserSessionTable]).
+		Filter("user_id", userID).
+		Filter("expires_at__gt", now).
+		OrderBy("-last_seen_at").
+		All(&sessions)
+	return sessions, err
+}
+
+// TouchUserSession records activity on a session
+func (db *Database) TouchUserSession(id int, lastSeenAt time.Time) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserSessionTable]).
+		Filter("id", id).
+		Update(orm.Params{"last_seen_at": lastSeenAt})
+	return err
+}
+
+// DeleteUserSessions ends sessions, removing both the tracked session and the session data so
+// the session cookie stops working right away
+func (db *Database) DeleteUserSessions(sessions []*models.UserSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback session revoke transaction: %s", err)
+		}
+	}()
+
+	sessionTable := constants.TableNameMap[constants.SessionTable]
+	for _, session := range sessions {
+		if _, err := tx.Raw(fmt.Sprintf(`DELETE FROM %q WHERE session_key = ?`, sessionTable), session.SessionKey).Exec(); err != nil {
+			return fmt.Errorf("failed to delete session data id[%d]: %s", session.ID, err)
+		}
+		if _, err := tx.Delete(&models.UserSession{ID: session.ID}); err != nil {
+			return fmt.Errorf("failed to delete session id[%d]: %s", session.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session revoke: %s", err)
+	}
+	return nil
+}
+
+// ListAllUserSessions returns every tracked session of a user, expired ones included
+func (db *Database) ListAllUserSessions(userID int) ([]*models.UserSession, error) {
+	var sessions []*models.UserSession
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserSessionTable]).
+		Filter("user_id", userID).
+		All(&sessions)
+	return sessions, err
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+// GetSystemSetting returns the value of a system setting, found is false if it was never set
+func (db *Database) GetSystemSetting(key string) (value string, found bool, err error) {
+	setting := &models.SystemSetting{}
+	err = db.ormer.QueryTable(constants.TableNameMap[constants.SystemSettingTable]).
+		Filter("key", key).
+		One(setting)
+	if err == orm.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get system setting key[%s]: %s", key, err)
+	}
+	return setting.Value, true, nil
+}
+
+// SetSystemSetting inserts or updates a system setting
+func (db *Database) SetSystemSetting(key, value string) error {
+	existing := &models.SystemSetting{Key: key}
+	err := db.ormer.Read(existing)
+	if err == orm.ErrNoRows {
+		if _, err := db.ormer.Insert(&models.SystemSetting{Key: key, Value: value}); err != nil {
+			return fmt.Errorf("failed to insert system setting key[%s]: %s", key, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lookup system setting key[%s]: %s", key, err)
+	}
+
+	existing.Value = value
+	if _, err := db.ormer.Update(existing, "Value", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to update system setting key[%s]: %s", key, err)
+	}
+	return nil
+}
@@ -96,6 +96,17 @@ func (db *Database) UpdateSource(source *models.Source) error {
 	return err
 }
 
+// UpdateSourceLabels sets the labels of a source, a JSON object or nil to remove them
+func (db *Database) UpdateSourceLabels(id int, labels interface{}, userID int) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.SourceTable]).
+		Filter("id", id).
+		Update(orm.Params{"labels": labels, "updated_by_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to update labels of source id[%d]: %s", id, err)
+	}
+	return nil
+}
+
 func (db *Database) DeleteSource(id int) error {
 	source := &models.Source{ID: id}
 	_, err := db.ormer.Delete(source)
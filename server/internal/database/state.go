@@ -0,0 +1,196 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// JobStateActionInitial tags the state a job had before its first versioned change
+const JobStateActionInitial = "initial"
+
+// JobStateVersionListFields defines the subset of JobStateVersion fields fetched for list views
+var JobStateVersionListFields = []string{
+	"ID",
+	"Version",
+	"Action",
+	"Streams",
+	"WorkflowID",
+	"RunStatus",
+	"RestoredFrom",
+	"CreatedBy",
+	"CreatedAt",
+}
+
+// AddJobStateVersion stores version as the next state version of the job and moves the
+// job to its state in a single transaction. If the job has no history yet, its current
+// state is kept first as an "initial" version so the first change can be undone.
+// Only the latest keep versions are retained, keep <= 0 keeps the whole history.
+// A version of the same run and action as the latest one replaces it instead, so a run that
+// pushes its state repeatedly keeps a single snapshot and does not prune older versions.
+func (db *Database) AddJobStateVersion(jobID int, version *models.JobStateVersion, keep int) error {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback state version transaction for job[%d]: %s", jobID, err)
+		}
+	}()
+
+	// lock the job row so concurrent changes get consecutive version numbers
+	job := &models.Job{}
+	if err := tx.QueryTable(constants.TableNameMap[constants.JobTable]).
+		Filter("id", jobID).
+		ForUpdate().
+		One(job, "ID", "State"); err != nil {
+		return fmt.Errorf("failed to lock job id[%d]: %s", jobID, err)
+	}
+
+	latest := &models.JobStateVersion{}
+	err = tx.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("job_id", jobID).
+		OrderBy("-version").
+		One(latest, "ID", "Version", "Action", "WorkflowID", "RunStatus")
+	if err != nil && err != orm.ErrNoRows {
+		return fmt.Errorf("failed to get latest state version job_id[%d]: %s", jobID, err)
+	}
+	if err == nil && version.WorkflowID != "" && latest.WorkflowID == version.WorkflowID && latest.Action == version.Action {
+		return db.replaceLatestJobStateVersion(tx, jobID, latest, version)
+	}
+	if err == orm.ErrNoRows {
+		initial := &models.JobStateVersion{
+			Job:     job,
+			Version: 1,
+			Action:  JobStateActionInitial,
+			State:   job.State,
+		}
+		if initial.State == "" {
+			initial.State = "{}"
+		}
+		if _, err := tx.Insert(initial); err != nil {
+			return fmt.Errorf("failed to insert initial state version job_id[%d]: %s", jobID, err)
+		}
+		latest.Version = initial.Version
+	}
+
+	version.Job = job
+	version.Version = latest.Version + 1
+	if _, err := tx.Insert(version); err != nil {
+		return fmt.Errorf("failed to insert state version job_id[%d]: %s", jobID, err)
+	}
+
+	if err := db.UpdateJobWithTx(tx, jobID, orm.Params{"state": version.State}); err != nil {
+		return fmt.Errorf("failed to update job state job_id[%d]: %s", jobID, err)
+	}
+
+	if keep > 0 && version.Version > keep {
+		if _, err := tx.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+			Filter("job_id", jobID).
+			Filter("version__lte", version.Version-keep).
+			Delete(); err != nil {
+			return fmt.Errorf("failed to prune state versions job_id[%d]: %s", jobID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit state version job_id[%d]: %s", jobID, err)
+	}
+	return nil
+}
+
+// replaceLatestJobStateVersion moves the latest state version and the job to the state of version
+// and commits tx
+func (db *Database) replaceLatestJobStateVersion(tx orm.TxOrmer, jobID int, latest, version *models.JobStateVersion) error {
+	if version.RunStatus == "" {
+		version.RunStatus = latest.RunStatus
+	}
+	if _, err := tx.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("id", latest.ID).
+		Update(orm.Params{"state": version.State, "run_status": version.RunStatus, "updated_at": time.Now()}); err != nil {
+		return fmt.Errorf("failed to replace state version job_id[%d] version[%d]: %s", jobID, latest.Version, err)
+	}
+
+	if err := db.UpdateJobWithTx(tx, jobID, orm.Params{"state": version.State}); err != nil {
+		return fmt.Errorf("failed to update job state job_id[%d]: %s", jobID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit state version job_id[%d]: %s", jobID, err)
+	}
+	version.ID, version.Version = latest.ID, latest.Version
+	return nil
+}
+
+// ListJobStateVersions returns the state history of a job without the state bodies, newest first
+func (db *Database) ListJobStateVersions(jobID int) ([]*models.JobStateVersion, error) {
+	var versions []*models.JobStateVersion
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("job_id", jobID).
+		RelatedSel("CreatedBy").
+		OrderBy("-version").
+		All(&versions, JobStateVersionListFields...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state versions job_id[%d]: %s", jobID, err)
+	}
+	return versions, nil
+}
+
+// GetJobStateVersion returns one state version of a job
+func (db *Database) GetJobStateVersion(jobID, version int) (*models.JobStateVersion, error) {
+	stateVersion := &models.JobStateVersion{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("job_id", jobID).
+		Filter("version", version).
+		RelatedSel("CreatedBy").
+		One(stateVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state version job_id[%d] version[%d]: %s", jobID, version, err)
+	}
+	return stateVersion, nil
+}
+
+// GetJobStateVersionByWorkflowID returns the latest state version written by the given workflow run
+func (db *Database) GetJobStateVersionByWorkflowID(jobID int, workflowID string) (*models.JobStateVersion, error) {
+	stateVersion := &models.JobStateVersion{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("job_id", jobID).
+		Filter("workflow_id", workflowID).
+		OrderBy("-version").
+		RelatedSel("CreatedBy").
+		One(stateVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state version job_id[%d] workflow_id[%s]: %s", jobID, workflowID, err)
+	}
+	return stateVersion, nil
+}
+
+// UpdateJobStateVersionRunStatus records the outcome of the run that wrote a state version
+func (db *Database) UpdateJobStateVersionRunStatus(id int, runStatus string) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("id", id).
+		Update(orm.Params{"run_status": runStatus})
+	return err
+}
+
+// GetLatestJobStateVersionNumber returns the latest state version of a job, 0 if it has no history
+func (db *Database) GetLatestJobStateVersionNumber(jobID int) (int, error) {
+	latest := &models.JobStateVersion{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStateVersionTable]).
+		Filter("job_id", jobID).
+		OrderBy("-version").
+		One(latest, "ID", "Version")
+	if err == orm.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest state version job_id[%d]: %s", jobID, err)
+	}
+	return latest.Version, nil
+}
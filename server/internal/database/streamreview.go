@@ -0,0 +1,243 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Stream review statuses
+const (
+	StreamReviewPending    = "pending"
+	StreamReviewApplied    = "applied"
+	StreamReviewRejected   = "rejected"
+	StreamReviewSuperseded = "superseded"
+)
+
+func streamsConfigHash(streamsConfig string) string {
+	sum := sha256.Sum256([]byte(streamsConfig))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetJobStreamRules returns the stream selection rules of a job
+func (db *Database) GetJobStreamRules(jobID int) (*models.JobStreamRules, error) {
+	rules := &models.JobStreamRules{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStreamRulesTable]).
+		Filter("job_id", jobID).
+		RelatedSel("UpdatedBy").
+		One(rules)
+	return rules, err
+}
+
+// ListJobStreamRules returns the stream selection rules of every job
+func (db *Database) ListJobStreamRules() ([]*models.JobStreamRules, error) {
+	var rules []*models.JobStreamRules
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStreamRulesTable]).
+		OrderBy("id").
+		All(&rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job stream rules: %s", err)
+	}
+	return rules, nil
+}
+
+// SaveJobStreamRules creates or replaces the stream selection rules of a job
+func (db *Database) SaveJobStreamRules(rules *models.JobStreamRules) error {
+	existing, err := db.GetJobStreamRules(rules.Job.ID)
+	switch {
+	case err == nil:
+		rules.ID = existing.ID
+		rules.CreatedAt = existing.CreatedAt
+		_, err = db.ormer.Update(rules)
+	case errors.Is(err, orm.ErrNoRows):
+		_, err = db.ormer.Insert(rules)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save stream rules job_id[%d]: %s", rules.Job.ID, err)
+	}
+	return nil
+}
+
+// DeleteJobStreamRules removes the stream selection rules of a job and its pending reviews
+func (db *Database) DeleteJobStreamRules(jobID int) (bool, error) {
+	deleted, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStreamRulesTable]).
+		Filter("job_id", jobID).
+		Delete()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete stream rules job_id[%d]: %s", jobID, err)
+	}
+	if _, err := db.ormer.QueryTable(constants.TableNameMap[constants.StreamReviewTable]).
+		Filter("job_id", jobID).
+		Filter("status", StreamReviewPending).
+		Update(orm.Params{"status": StreamReviewSuperseded, "resolved_at": time.Now().UTC()}); err != nil {
+		return false, fmt.Errorf("failed to supersede stream reviews job_id[%d]: %s", jobID, err)
+	}
+	return deleted > 0, nil
+}
+
+// RecordStreamRulesCheck stores the outcome of the latest re-discovery of a job
+func (db *Database) RecordStreamRulesCheck(id int, checkedAt time.Time, checkErr string) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobStreamRulesTable]).
+		Filter("id", id).
+		Update(orm.Params{"last_checked_at": checkedAt, "last_error": checkErr})
+	return err
+}
+
+// CreateStreamReview stores a review computed from baseStreamsConfig, superseding the pending
+// reviews of the job. A review created as applied also moves the job to its streams config.
+func (db *Database) CreateStreamReview(review *models.StreamReview, baseStreamsConfig string) error {
+	review.BaseHash = streamsConfigHash(baseStreamsConfig)
+
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback stream review job_id[%d]: %s", review.Job.ID, err)
+		}
+	}()
+
+	job, err := lockJob(tx, review.Job.ID)
+	if err != nil {
+		return err
+	}
+	if streamsConfigHash(job.StreamsConfig) != review.BaseHash {
+		return constants.ErrStreamReviewStale
+	}
+
+	if _, err := tx.QueryTable(constants.TableNameMap[constants.StreamReviewTable]).
+		Filter("job_id", review.Job.ID).
+		Filter("status", StreamReviewPending).
+		Update(orm.Params{"status": StreamReviewSuperseded, "resolved_at": time.Now().UTC()}); err != nil {
+		return fmt.Errorf("failed to supersede stream reviews job_id[%d]: %s", review.Job.ID, err)
+	}
+
+	if review.Status == StreamReviewApplied {
+		if err := updateJobStreamsConfig(tx, review.Job.ID, review.StreamsConfig); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Insert(review); err != nil {
+		return fmt.Errorf("failed to insert stream review job_id[%d]: %s", review.Job.ID, err)
+	}
+	return tx.Commit()
+}
+
+// ListStreamReviews returns the stream reviews of a project, newest first. jobID 0 and an
+// empty status match any.
+func (db *Database) ListStreamReviews(projectID string, jobID int, status string) ([]*models.StreamReview, error) {
+	query := db.ormer.QueryTable(constants.TableNameMap[constants.StreamReviewTable]).
+		Filter("job__project_id", projectID)
+	if jobID > 0 {
+		query = query.Filter("job_id", jobID)
+	}
+	if status != "" {
+		query = query.Filter("status", status)
+	}
+
+	var reviews []*models.StreamReview
+	if _, err := query.RelatedSel("Job", "ResolvedBy").OrderBy("-id").All(&reviews); err != nil {
+		return nil, fmt.Errorf("failed to list stream reviews project_id[%s]: %s", projectID, err)
+	}
+	return reviews, nil
+}
+
+// GetStreamReview returns a stream review of a project
+func (db *Database) GetStreamReview(projectID string, id int) (*models.StreamReview, error) {
+	review := &models.StreamReview{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.StreamReviewTable]).
+		Filter("id", id).
+		Filter("job__project_id", projectID).
+		RelatedSel("Job", "ResolvedBy").
+		One(review)
+	return review, err
+}
+
+// ResolveStreamReview approves or rejects a pending review. Approving moves the job to the
+// streams config of the review, unless the job changed since, then the review is superseded
+// and ErrStreamReviewStale returned.
+func (db *Database) ResolveStreamReview(id int, approve bool, userID int) error {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback stream review id[%d]: %s", id, err)
+		}
+	}()
+
+	review := &models.StreamReview{}
+	if err := tx.QueryTable(constants.TableNameMap[constants.StreamReviewTable]).
+		Filter("id", id).
+		Filter("status", StreamReviewPending).
+		ForUpdate().
+		One(review); err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return constants.ErrStreamReviewNotFound
+		}
+		return fmt.Errorf("failed to lock stream review id[%d]: %s", id, err)
+	}
+
+	status := StreamReviewRejected
+	var stale bool
+	if approve {
+		job, err := lockJob(tx, review.Job.ID)
+		if err != nil {
+			return err
+		}
+		if streamsConfigHash(job.StreamsConfig) != review.BaseHash {
+			status, stale = StreamReviewSuperseded, true
+		} else {
+			if err := updateJobStreamsConfig(tx, job.ID, review.StreamsConfig); err != nil {
+				return err
+			}
+			status = StreamReviewApplied
+		}
+	}
+
+	now := time.Now().UTC()
+	review.Status = status
+	review.ResolvedAt = &now
+	review.ResolvedBy = &models.User{ID: userID}
+	if _, err := tx.Update(review, "Status", "ResolvedAt", "ResolvedBy", "UpdatedAt"); err != nil {
+		return fmt.Errorf("failed to update stream review id[%d]: %s", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stream review id[%d]: %s", id, err)
+	}
+	if stale {
+		return constants.ErrStreamReviewStale
+	}
+	return nil
+}
+
+func lockJob(tx orm.TxOrmer, jobID int) (*models.Job, error) {
+	job := &models.Job{}
+	if err := tx.QueryTable(constants.TableNameMap[constants.JobTable]).
+		Filter("id", jobID).
+		ForUpdate().
+		One(job, "ID", "StreamsConfig"); err != nil {
+		return nil, fmt.Errorf("failed to lock job id[%d]: %s", jobID, err)
+	}
+	return job, nil
+}
+
+func updateJobStreamsConfig(tx orm.TxOrmer, jobID int, streamsConfig string) error {
+	if _, err := tx.QueryTable(constants.TableNameMap[constants.JobTable]).
+		Filter("id", jobID).
+		Update(orm.Params{"streams_config": streamsConfig, "updated_at": time.Now().UTC()}); err != nil {
+		return fmt.Errorf("failed to update streams config job_id[%d]: %s", jobID, err)
+	}
+	return nil
+}
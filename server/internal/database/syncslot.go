@@ -0,0 +1,66 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func (db *Database) CreateSyncSlot(slot *models.SyncSlot) error {
+	_, err := db.ormer.Insert(slot)
+	return err
+}
+
+// GetSyncSlotByWorkflowID returns the sync slot of a run
+func (db *Database) GetSyncSlotByWorkflowID(workflowID string) (*models.SyncSlot, error) {
+	slot := &models.SyncSlot{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.SyncSlotTable]).
+		Filter("workflow_id", workflowID).
+		One(slot)
+	return slot, err
+}
+
+// ListSyncSlots returns the sync slots of a project in the order they were queued
+func (db *Database) ListSyncSlots(projectID string) ([]*models.SyncSlot, error) {
+	var slots []*models.SyncSlot
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.SyncSlotTable]).
+		Filter("project_id", projectID).
+		OrderBy("enqueued_at", "id").
+		All(&slots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync slots project_id[%s]: %s", projectID, err)
+	}
+	return slots, nil
+}
+
+// ListAllSyncSlots returns the sync slots of every project
+func (db *Database) ListAllSyncSlots() ([]*models.SyncSlot, error) {
+	var slots []*models.SyncSlot
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.SyncSlotTable]).
+		OrderBy("id").
+		All(&slots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync slots: %s", err)
+	}
+	return slots, nil
+}
+
+// UpdateSyncSlot saves the given columns of a sync slot
+func (db *Database) UpdateSyncSlot(slot *models.SyncSlot, cols ...string) error {
+	if _, err := db.ormer.Update(slot, cols...); err != nil {
+		return fmt.Errorf("failed to update sync slot workflow_id[%s]: %s", slot.WorkflowID, err)
+	}
+	return nil
+}
+
+// DeleteSyncSlot removes the sync slot of a run, it tells whether there was one
+func (db *Database) DeleteSyncSlot(workflowID string) (bool, error) {
+	deleted, err := db.ormer.QueryTable(constants.TableNameMap[constants.SyncSlotTable]).
+		Filter("workflow_id", workflowID).
+		Delete()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete sync slot workflow_id[%s]: %s", workflowID, err)
+	}
+	return deleted > 0, nil
+}
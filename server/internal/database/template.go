@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func (db *Database) CreateJobTemplate(template *models.JobTemplate) error {
+	_, err := db.ormer.Insert(template)
+	return err
+}
+
+// ListJobTemplatesByProjectID returns the job templates of a project, most recently updated first
+func (db *Database) ListJobTemplatesByProjectID(projectID string) ([]*models.JobTemplate, error) {
+	var templates []*models.JobTemplate
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.JobTemplateTable]).
+		RelatedSel().
+		Filter("project_id", projectID).
+		OrderBy(constants.OrderByUpdatedAtDesc).
+		All(&templates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job templates project_id[%s]: %s", projectID, err)
+	}
+	return templates, nil
+}
+
+// GetJobTemplateByID returns a job template of a project
+func (db *Database) GetJobTemplateByID(projectID string, id int) (*models.JobTemplate, error) {
+	template := &models.JobTemplate{}
+	err := db.ormer.QueryTable(constants.TableNameMap[constants.JobTemplateTable]).
+		RelatedSel().
+		Filter("id", id).
+		Filter("project_id", projectID).
+		One(template)
+	return template, err
+}
+
+func (db *Database) UpdateJobTemplate(template *models.JobTemplate) error {
+	_, err := db.ormer.Update(template)
+	return err
+}
+
+func (db *Database) DeleteJobTemplate(id int) error {
+	_, err := db.ormer.Delete(&models.JobTemplate{ID: id})
+	return err
+}
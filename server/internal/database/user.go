@@ -2,11 +2,14 @@ package database
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/beego/beego/v2/client/orm"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
 )
 
 func (db *Database) GetUserByUsername(username string) (*models.User, error) {
@@ -51,3 +54,164 @@ func (db *Database) DeleteUser(id int) error {
 	_, err := db.ormer.Delete(user)
 	return err
 }
+
+// CountUsers returns the number of users
+func (db *Database) CountUsers() (int64, error) {
+	return db.ormer.QueryTable(constants.TableNameMap[constants.UserTable]).Count()
+}
+
+// RecordFailedLogin counts a failed login of a user. Once maxAttempts consecutive logins failed
+// the account is locked for lockout and the counter starts over. It returns the lock expiry when
+// this attempt locked the account.
+func (db *Database) RecordFailedLogin(id, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback failed login transaction for user[%d]: %s", id, err)
+		}
+	}()
+
+	user := &models.User{}
+	if err := tx.QueryTable(constants.TableNameMap[constants.UserTable]).
+		Filter("id", id).
+		ForUpdate().
+		One(user, "ID", "FailedLoginAttempts"); err != nil {
+		return nil, fmt.Errorf("failed to lock user id[%d]: %s", id, err)
+	}
+
+	attempts := user.FailedLoginAttempts + 1
+	params := orm.Params{"failed_login_attempts": attempts}
+	var lockedUntil *time.Time
+	if maxAttempts > 0 && attempts >= maxAttempts {
+		until := time.Now().UTC().Add(lockout)
+		lockedUntil = &until
+		params["failed_login_attempts"] = 0
+		params["locked_until"] = until
+	}
+
+	if _, err := tx.QueryTable(constants.TableNameMap[constants.UserTable]).Filter("id", id).Update(params); err != nil {
+		return nil, fmt.Errorf("failed to record failed login for user id[%d]: %s", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit failed login for user id[%d]: %s", id, err)
+	}
+	return lockedUntil, nil
+}
+
+// ResetFailedLogins clears the failed login counter and any lock of a user
+func (db *Database) ResetFailedLogins(id int) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserTable]).
+		Filter("id", id).
+		Update(orm.Params{"failed_login_attempts": 0, "locked_until": nil})
+	return err
+}
+
+// UpdateUserPassword stores a new password hash, clears any lock and sets whether the user has
+// to change the password on the next login
+func (db *Database) UpdateUserPassword(id int, hashedPassword string, mustChange bool) error {
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserTable]).
+		Filter("id", id).
+		Update(orm.Params{
+			"password":              hashedPassword,
+			"must_change_password":  mustChange,
+			"password_changed_at":   time.Now().UTC(),
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		})
+	return err
+}
+
+// SetUserDisabled disables or re-enables a user
+func (db *Database) SetUserDisabled(id int, disabled bool) error {
+	params := orm.Params{"disabled": disabled, "disabled_at": nil}
+	if disabled {
+		params["disabled_at"] = time.Now().UTC()
+	}
+	_, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserTable]).Filter("id", id).Update(params)
+	return err
+}
+
+// SetUserEmailVerified marks the email of a user as verified, if it is still email
+func (db *Database) SetUserEmailVerified(id int, email string) (bool, error) {
+	updated, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserTable]).
+		Filter("id", id).
+		Filter("email", email).
+		Update(orm.Params{"email_verified": true, "email_verified_at": time.Now().UTC()})
+	return updated > 0, err
+}
+
+// userReferences are the columns referencing a user as owner of a resource
+var userReferences = []struct {
+	table  constants.TableType
+	column string
+}{
+	{constants.SourceTable, "created_by_id"},
+	{constants.SourceTable, "updated_by_id"},
+	{constants.DestinationTable, "created_by_id"},
+	{constants.DestinationTable, "updated_by_id"},
+	{constants.JobTable, "created_by_id"},
+	{constants.JobTable, "updated_by_id"},
+	{constants.JobStateVersionTable, "created_by_id"},
+	{constants.JobTemplateTable, "created_by_id"},
+	{constants.JobTemplateTable, "updated_by_id"},
+	{constants.MaskingRuleTable, "created_by_id"},
+	{constants.MaskingRuleTable, "updated_by_id"},
+	{constants.MaintenanceWindowTable, "created_by_id"},
+	{constants.MaintenanceWindowTable, "updated_by_id"},
+}
+
+// CountUserReferences returns how many sources, destinations, jobs, state versions and other owned resources reference a user
+func (db *Database) CountUserReferences(id int) (int64, error) {
+	var total int64
+	for _, ref := range userReferences {
+		var count int64
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %q WHERE %s = ?`, constants.TableNameMap[ref.table], ref.column)
+		if err := db.ormer.Raw(query, id).QueryRow(&count); err != nil {
+			return 0, fmt.Errorf("failed to count references of user id[%d] in %s: %s", id, constants.TableNameMap[ref.table], err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// TransferUserOwnership moves every created_by and updated_by reference from one user to another.
+// It returns the number of rows changed per table.
+func (db *Database) TransferUserOwnership(fromID, toID int) (map[constants.TableType]int64, error) {
+	tx, err := db.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.RollbackUnlessCommit(); err != nil {
+			logger.Errorf("failed to rollback ownership transfer from user[%d] to user[%d]: %s", fromID, toID, err)
+		}
+	}()
+
+	changed := map[constants.TableType]int64{}
+	for _, ref := range userReferences {
+		query := fmt.Sprintf(`UPDATE %q SET %s = ? WHERE %s = ?`, constants.TableNameMap[ref.table], ref.column, ref.column)
+		result, err := tx.Raw(query, toID, fromID).Exec()
+		if err != nil {
+			return nil, fmt.Errorf("failed to transfer %s of %s: %s", ref.column, constants.TableNameMap[ref.table], err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to transfer %s of %s: %s", ref.column, constants.TableNameMap[ref.table], err)
+		}
+		changed[ref.table] += rows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit ownership transfer: %s", err)
+	}
+	return changed, nil
+}
+
+// UserEmailExists reports whether a user has the email, ignoring case
+func (db *Database) UserEmailExists(email string) (bool, error) {
+	count, err := db.ormer.QueryTable(constants.TableNameMap[constants.UserTable]).Filter("email__iexact", email).Count()
+	return count > 0, err
+}
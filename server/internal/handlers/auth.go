@@ -7,6 +7,7 @@ import (
 
 	"github.com/beego/beego/v2/server/web"
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/handlers/middleware"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils"
@@ -31,6 +32,8 @@ func (h *Handler) Login() {
 			utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, fmt.Sprintf("user not found, sign up first: %s", err), err)
 		case errors.Is(err, constants.ErrInvalidCredentials):
 			utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, fmt.Sprintf("Invalid credentials: %s", err), err)
+		case errors.Is(err, constants.ErrUserDisabled):
+			utils.ErrorResponse(&h.Controller, http.StatusForbidden, "User is disabled, contact an admin", err)
 		default:
 			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("Login failed: %s", err), err)
 		}
@@ -39,11 +42,29 @@ func (h *Handler) Login() {
 
 	// check if session is enabled
 	if web.BConfig.WebConfig.Session.SessionOn {
+		// a fresh session id on every login, the previous session of this browser ends
+		if previousKey := GetSessionKey(&h.Controller); previousKey != "" {
+			if err := h.etl.EndUserSession(h.Ctx.Request.Context(), previousKey); err != nil {
+				logger.Warnf("failed to end previous session user_id[%d]: %s", user.ID, err)
+			}
+		}
+		if err := h.SessionRegenerateID(); err != nil {
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("Login failed: %s", err), err)
+			return
+		}
 		_ = h.SetSession(constants.SessionUserID, user.ID)
+		_ = h.SetSession(constants.SessionMustChangePassword, user.MustChangePassword)
+
+		if err := h.etl.StartUserSession(h.Ctx.Request.Context(), GetSessionKey(&h.Controller), user.ID, middleware.ClientIP(h.Ctx), h.Ctx.Input.UserAgent()); err != nil {
+			_ = h.DestroySession()
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("Login failed: %s", err), err)
+			return
+		}
 	}
 
 	utils.SuccessResponse(&h.Controller, "login successful", map[string]interface{}{
-		"username": user.Username,
+		"username":             user.Username,
+		"must_change_password": user.MustChangePassword,
 	})
 }
 
@@ -63,6 +84,11 @@ func (h *Handler) CheckAuth() {
 			utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, fmt.Sprintf("Invalid session: %s", err), err)
 			return
 		}
+		if err := h.etl.ValidateSession(h.Ctx.Request.Context(), GetSessionKey(&h.Controller), userIDInt); err != nil {
+			_ = h.DestroySession()
+			utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, fmt.Sprintf("Invalid session: %s", err), err)
+			return
+		}
 	}
 
 	utils.SuccessResponse(&h.Controller, "authenticated successfully", nil)
@@ -70,9 +96,20 @@ func (h *Handler) CheckAuth() {
 
 // @router /logout [post]
 func (h *Handler) Logout() {
+	// without sessions there is nothing to log out of
+	if !web.BConfig.WebConfig.Session.SessionOn {
+		utils.SuccessResponse(&h.Controller, "logout successful", nil)
+		return
+	}
+
 	userID := h.GetSession(constants.SessionUserID)
 	logger.Debugf("Logout initiated user_id[%v]", userID)
 
+	if err := h.etl.EndUserSession(h.Ctx.Request.Context(), GetSessionKey(&h.Controller)); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to end session: %s", err), err)
+		return
+	}
+
 	err := h.DestroySession()
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to destroy session: %s", err), err)
@@ -92,6 +129,10 @@ func (h *Handler) Signup() {
 
 	if err := h.etl.Signup(h.Ctx.Request.Context(), &req); err != nil {
 		switch {
+		case errors.Is(err, constants.ErrSignupDisabled):
+			utils.ErrorResponse(&h.Controller, http.StatusForbidden, "Signup is disabled, ask an admin to create an account", err)
+		case errors.Is(err, constants.ErrWeakPassword):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, err.Error(), err)
 		case errors.Is(err, constants.ErrUserAlreadyExists):
 			utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("Username already exists: %s", err), err)
 		case errors.Is(err, constants.ErrPasswordProcessing):
@@ -108,6 +149,82 @@ func (h *Handler) Signup() {
 	})
 }
 
+// @router /auth/password [put]
+func (h *Handler) ChangePassword() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, constants.ValidationInvalidRequestFormat, err)
+		return
+	}
+
+	logger.Infof("Change password initiated user_id[%d]", *userID)
+
+	if err := h.etl.ChangePassword(h.Ctx.Request.Context(), *userID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, constants.ErrInvalidCredentials), errors.Is(err, constants.ErrWeakPassword):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, err.Error(), err)
+		default:
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to change password: %s", err), err)
+		}
+		return
+	}
+
+	if web.BConfig.WebConfig.Session.SessionOn {
+		_ = h.SetSession(constants.SessionMustChangePassword, false)
+		// other sessions may have been opened with the old password
+		if err := h.etl.RevokeUserSessions(h.Ctx.Request.Context(), *userID, GetSessionKey(&h.Controller)); err != nil {
+			logger.Errorf("failed to revoke other sessions after password change user_id[%d]: %s", *userID, err)
+		}
+	}
+
+	utils.SuccessResponse(&h.Controller, "password changed successfully", nil)
+}
+
+// @router /auth/settings [get]
+func (h *Handler) GetAuthSettings() {
+	signupEnabled, err := h.etl.SignupEnabled()
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get auth settings: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "auth settings fetched successfully", dto.AuthSettingsResponse{
+		SignupEnabled: signupEnabled,
+	})
+}
+
+// @router /auth/settings [put]
+func (h *Handler) UpdateAuthSettings() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	var req dto.UpdateAuthSettingsRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, constants.ValidationInvalidRequestFormat, err)
+		return
+	}
+
+	logger.Infof("Update auth settings initiated user_id[%d] signup_enabled[%t]", *userID, *req.SignupEnabled)
+
+	if err := h.etl.SetSignupEnabled(h.Ctx.Request.Context(), *req.SignupEnabled); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update auth settings: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "auth settings updated successfully", dto.AuthSettingsResponse{
+		SignupEnabled: *req.SignupEnabled,
+	})
+}
+
 // @router /telemetry-id [get]
 func (h *Handler) GetTelemetryID() {
 	logger.Info("Get telemetry ID initiated")
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /connector-cache/specs [delete]
+func (h *Handler) InvalidateSpecCache() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	connectorType := h.GetString("type")
+	version := h.GetString("version")
+	logger.Infof("Spec cache invalidation initiated user_id[%d] type[%s] version[%s]", *userID, connectorType, version)
+
+	removed, err := h.etl.InvalidateSpecCache(connectorType, version)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to invalidate spec cache: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "spec cache invalidated successfully", dto.CacheInvalidationResponse{Removed: removed})
+}
+
+// @router /project/:projectid/sources/:id/catalog-cache [delete]
+func (h *Handler) InvalidateSourceCatalogCache() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Debugf("Catalog cache invalidation initiated project_id[%s] source_id[%d]", projectID, id)
+
+	removed, err := h.etl.InvalidateSourceCatalogCache(projectID, id)
+	if err != nil {
+		if errors.Is(err, constants.ErrSourceNotFound) {
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("source not found: %s", err), err)
+		} else {
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to invalidate catalog cache: %s", err), err)
+		}
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("catalog cache of source %d invalidated successfully", id), dto.CacheInvalidationResponse{Removed: removed})
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /connectors [get]
+func (h *Handler) ListConnectors() {
+	connectors, err := h.etl.ListConnectors()
+	if err != nil {
+		respondConnectorError(h, "failed to list connectors", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connectors listed successfully", connectors)
+}
+
+// @router /connectors/:type [get]
+func (h *Handler) GetConnector() {
+	connectorType := h.Ctx.Input.Param(":type")
+
+	connector, err := h.etl.GetConnector(connectorType)
+	if err != nil {
+		respondConnectorError(h, "failed to get connector", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("connector %s retrieved successfully", connectorType), connector)
+}
+
+// @router /connectors/:type [put]
+func (h *Handler) UpdateConnector() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+	connectorType := h.Ctx.Input.Param(":type")
+
+	var req dto.UpdateConnectorRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Update connector initiated type[%s] user_id[%d]", connectorType, *userID)
+
+	connector, err := h.etl.UpdateConnector(connectorType, &req)
+	if err != nil {
+		respondConnectorError(h, "failed to update connector", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("connector %s updated successfully", connectorType), connector)
+}
+
+// @router /connectors/:type/versions [put]
+func (h *Handler) SetConnectorVersion() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+	connectorType := h.Ctx.Input.Param(":type")
+
+	var req dto.ConnectorVersionRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Set connector version initiated type[%s] version[%s] allowed[%t] deprecated[%t] user_id[%d]",
+		connectorType, req.Version, *req.Allowed, req.Deprecated, *userID)
+
+	connector, err := h.etl.SetConnectorVersion(connectorType, &req)
+	if err != nil {
+		respondConnectorError(h, "failed to set connector version", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("connector %s version %s updated successfully", connectorType, req.Version), connector)
+}
+
+// @router /connectors/:type/versions [delete]
+func (h *Handler) DeleteConnectorVersion() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+	connectorType := h.Ctx.Input.Param(":type")
+	version := h.GetString("version")
+	if version == "" {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, "version is required", errors.New("version is required"))
+		return
+	}
+
+	logger.Infof("Delete connector version initiated type[%s] version[%s] user_id[%d]", connectorType, version, *userID)
+
+	if err := h.etl.DeleteConnectorVersion(connectorType, version); err != nil {
+		respondConnectorError(h, "failed to delete connector version", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("connector %s version %s deleted successfully", connectorType, version), nil)
+}
+
+// @router /connectors/refresh [post]
+func (h *Handler) RefreshConnectors() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	logger.Infof("Connector registry refresh initiated user_id[%d]", *userID)
+
+	connectors, err := h.etl.RefreshConnectors(h.Ctx.Request.Context())
+	if err != nil {
+		respondConnectorError(h, "failed to refresh connectors", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connector registry refreshed", connectors)
+}
+
+func respondConnectorError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrConnectorNotFound),
+		errors.Is(err, constants.ErrConnectorVersionNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidConnector):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/connector-upgrades/check [post]
+func (h *Handler) CheckConnectorUpgrade() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ConnectorUpgradeRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Connector upgrade check initiated project_id[%s] type[%s] from[%s] to[%s]", projectID, req.SourceType, req.FromVersion, req.ToVersion)
+
+	result, err := h.etl.CheckConnectorUpgrade(h.Ctx.Request.Context(), projectID, &req)
+	if err != nil {
+		respondConnectorUpgradeError(h, "failed to check connector upgrade", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connector upgrade checked successfully", result)
+}
+
+// @router /project/:projectid/connector-upgrades [post]
+func (h *Handler) StartConnectorUpgrade() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ConnectorUpgradeRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Connector upgrade initiated project_id[%s] type[%s] from[%s] to[%s] force[%t] user_id[%d]",
+		projectID, req.SourceType, req.FromVersion, req.ToVersion, req.Force, *userID)
+
+	upgrade, err := h.etl.StartConnectorUpgrade(h.Ctx.Request.Context(), projectID, &req, userID)
+	if err != nil {
+		respondConnectorUpgradeError(h, "failed to start connector upgrade", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connector upgrade started", upgrade)
+}
+
+// @router /project/:projectid/connector-upgrades [get]
+func (h *Handler) ListConnectorUpgrades() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	upgrades, err := h.etl.ListConnectorUpgrades(h.Ctx.Request.Context(), projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list connector upgrades: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connector upgrades listed successfully", upgrades)
+}
+
+// @router /project/:projectid/connector-upgrades/:id [get]
+func (h *Handler) GetConnectorUpgrade() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	upgrade, err := h.etl.GetConnectorUpgrade(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondConnectorUpgradeError(h, "failed to get connector upgrade", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connector upgrade retrieved successfully", upgrade)
+}
+
+// @router /project/:projectid/connector-upgrades/:id/rollback [post]
+func (h *Handler) RollbackConnectorUpgrade() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ConnectorUpgradeRollbackRequest
+	if len(h.Ctx.Input.RequestBody) > 0 {
+		if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+			return
+		}
+	}
+
+	logger.Infof("Connector upgrade rollback initiated project_id[%s] upgrade_id[%d] user_id[%d]", projectID, id, *userID)
+
+	upgrade, err := h.etl.RollbackConnectorUpgrade(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		respondConnectorUpgradeError(h, "failed to roll back connector upgrade", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "connector upgrade rolled back", upgrade)
+}
+
+// respondConnectorUpgradeError maps connector upgrade errors to their status codes
+func respondConnectorUpgradeError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrConnectorUpgradeNotFound),
+		errors.Is(err, constants.ErrJobNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrConnectorUpgradeRunning):
+		utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidConnectorUpgrade),
+		errors.Is(err, constants.ErrConnectorVersionNotAllowed):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
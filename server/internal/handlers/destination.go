@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
@@ -18,7 +19,13 @@ func (h *Handler) ListDestinations() {
 		return
 	}
 
-	items, err := h.etl.ListDestinations(h.Ctx.Request.Context(), projectID)
+	selector, err := GetLabelSelectorFromQuery(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	items, err := h.etl.ListDestinations(h.Ctx.Request.Context(), projectID, selector)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get destinations: %s", err), err)
 		return
@@ -79,10 +86,19 @@ func (h *Handler) CreateDestination() {
 		projectID, req.Type, req.Name, userID)
 
 	if err := h.etl.CreateDestination(h.Ctx.Request.Context(), &req, projectID, userID); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create destination: %s", err), err)
+		if errors.Is(err, constants.ErrConnectorVersionNotAllowed) || errors.Is(err, constants.ErrInvalidLabels) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to create destination: %s", err), err)
+		} else {
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create destination: %s", err), err)
+		}
 		return
 	}
 
+	// secrets are not echoed back to the client
+	if req.Config, err = h.etl.MaskDestinationConfig(h.Ctx.Request.Context(), req.Type, req.Version, req.Config); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to mask destination config: %s", err), err)
+		return
+	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("destination %s created successfully", req.Name), req)
 }
 
@@ -121,7 +137,16 @@ func (h *Handler) UpdateDestination() {
 		projectID, id, req.Type, userID)
 
 	if err := h.etl.UpdateDestination(h.Ctx.Request.Context(), id, projectID, &req, userID); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update destination: %s", err), err)
+		if errors.Is(err, constants.ErrConnectorVersionNotAllowed) || errors.Is(err, constants.ErrInvalidLabels) || errors.Is(err, constants.ErrWorkerFeatureUnsupported) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to update destination: %s", err), err)
+		} else {
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update destination: %s", err), err)
+		}
+		return
+	}
+	// secrets are not echoed back to the client
+	if req.Config, err = h.etl.MaskDestinationConfig(h.Ctx.Request.Context(), req.Type, req.Version, req.Config); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to mask destination config: %s", err), err)
 		return
 	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("destination %s updated successfully", req.Name), req)
@@ -225,6 +250,10 @@ func (h *Handler) GetDestinationSpec() {
 		return
 	}
 
+	if refresh, _ := h.GetBool("refresh", false); refresh {
+		req.Refresh = true
+	}
+
 	logger.Debugf("Get destination spec initiated project_id[%s] destination_type[%s] destination_version[%s]",
 		projectID, req.Type, req.Version)
 
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /encryption/re-encrypt [post]
+func (h *Handler) StartConfigReEncryption() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	logger.Infof("Config re-encryption initiated user_id[%v]", *userID)
+
+	status, err := h.etl.StartConfigReEncryption()
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("failed to start re-encryption: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "config re-encryption started", status)
+}
+
+// @router /encryption/re-encrypt [get]
+func (h *Handler) GetConfigReEncryptionStatus() {
+	utils.SuccessResponse(&h.Controller, "config re-encryption status retrieved successfully", h.etl.GetConfigReEncryptionStatus())
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/handlers/middleware"
 	services "github.com/datazip-inc/olake-ui/server/internal/services/etl"
 )
 
@@ -23,3 +24,8 @@ func NewHandler(s *services.ETLService) *Handler {
 func (h *Handler) Prepare() {
 	h.etl = etl
 }
+
+// SessionValidator exposes the session checks of the AppService to the auth middleware
+func (h *Handler) SessionValidator() middleware.SessionValidator {
+	return h.etl
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /invites [post]
+func (h *Handler) CreateInvite() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	var req dto.CreateInviteRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Create invite initiated email[%s] by[%d]", req.Email, *userID)
+
+	invite, err := h.etl.CreateInvite(h.Ctx.Request.Context(), *userID, req.Email, req.ExpiresInHours)
+	if err != nil {
+		if errors.Is(err, constants.ErrUserAlreadyExists) {
+			utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("failed to create invite: %s", err), err)
+			return
+		}
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create invite: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "invite created successfully", invite)
+}
+
+// @router /invites [get]
+func (h *Handler) ListInvites() {
+	invites, err := h.etl.ListInvites(h.Ctx.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list invites: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "invites listed successfully", invites)
+}
+
+// @router /invites/:id [delete]
+func (h *Handler) RevokeInvite() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Revoke invite initiated invite_id[%d]", id)
+
+	if err := h.etl.RevokeInvite(h.Ctx.Request.Context(), id); err != nil {
+		if errors.Is(err, constants.ErrInviteNotFound) {
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to revoke invite: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "invite revoked successfully", nil)
+}
+
+// @router /invites/info [get]
+func (h *Handler) GetInviteInfo() {
+	token := h.GetString("token")
+	if token == "" {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, "token is required", errors.New("token is required"))
+		return
+	}
+
+	info, err := h.etl.GetInviteInfo(h.Ctx.Request.Context(), token)
+	if err != nil {
+		respondTokenError(h, "failed to get invite", err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "invite fetched successfully", info)
+}
+
+// @router /invites/accept [post]
+func (h *Handler) AcceptInvite() {
+	var req dto.AcceptInviteRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, constants.ValidationInvalidRequestFormat, err)
+		return
+	}
+
+	logger.Infof("Accept invite initiated username[%s]", req.Username)
+
+	user, err := h.etl.AcceptInvite(h.Ctx.Request.Context(), req.Token, req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrUserAlreadyExists):
+			utils.ErrorResponse(&h.Controller, http.StatusConflict, err.Error(), err)
+		case errors.Is(err, constants.ErrWeakPassword):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, err.Error(), err)
+		default:
+			respondTokenError(h, "failed to accept invite", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "user created successfully", map[string]interface{}{
+		"email":    user.Email,
+		"username": user.Username,
+	})
+}
+
+// @router /auth/verify-email [post]
+func (h *Handler) SendEmailVerification() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	if err := h.etl.SendEmailVerification(h.Ctx.Request.Context(), *userID); err != nil {
+		switch {
+		case errors.Is(err, constants.ErrEmailNotConfigured):
+			utils.ErrorResponse(&h.Controller, http.StatusServiceUnavailable, err.Error(), err)
+		case errors.Is(err, constants.ErrInvalidUserAction):
+			utils.ErrorResponse(&h.Controller, http.StatusConflict, err.Error(), err)
+		default:
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to send verification email: %s", err), err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "verification email sent", nil)
+}
+
+// @router /verify-email [post]
+func (h *Handler) VerifyEmail() {
+	var req dto.VerifyEmailRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, constants.ValidationInvalidRequestFormat, err)
+		return
+	}
+
+	if err := h.etl.VerifyEmail(h.Ctx.Request.Context(), req.Token); err != nil {
+		respondTokenError(h, "failed to verify email", err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "email verified successfully", nil)
+}
+
+// respondTokenError answers 400 for tokens that are invalid, expired or already used
+func respondTokenError(h *Handler, message string, err error) {
+	if errors.Is(err, constants.ErrInvalidToken) {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+		return
+	}
+	utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+}
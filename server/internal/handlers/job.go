@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -20,7 +21,13 @@ func (h *Handler) ListJobs() {
 
 	logger.Debugf("Get all jobs initiated project_id[%s]", projectID)
 
-	jobs, err := h.etl.ListJobs(h.Ctx.Request.Context(), projectID)
+	selector, err := GetLabelSelectorFromQuery(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	jobs, err := h.etl.ListJobs(h.Ctx.Request.Context(), projectID, selector)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to retrieve jobs by project ID: %s", err), err)
 		return
@@ -98,7 +105,8 @@ func (h *Handler) CreateJob() {
 	logger.Debugf("Create job initiated project_id[%s] job_name[%s] user_id[%v]", projectID, req.Name, userID)
 
 	if err := h.etl.CreateJob(h.Ctx.Request.Context(), &req, projectID, userID); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create job: %s", err), err)
+		status := utils.Ternary(errors.Is(err, constants.ErrInvalidLabels) || errors.Is(err, constants.ErrInvalidResourceLimits) || errors.Is(err, constants.ErrWorkerFeatureUnsupported), http.StatusBadRequest, http.StatusInternalServerError).(int)
+		utils.ErrorResponse(&h.Controller, status, fmt.Sprintf("failed to create job: %s", err), err)
 		return
 	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job '%s' created successfully", req.Name), nil)
@@ -154,7 +162,8 @@ func (h *Handler) UpdateJob() {
 	logger.Debugf("Update job initiated project_id[%s] job_id[%d] job_name[%s] user_id[%v]", projectID, jobID, req.Name, userID)
 
 	if err := h.etl.UpdateJob(h.Ctx.Request.Context(), &req, projectID, jobID, userID); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update job: %s", err), err)
+		status := utils.Ternary(errors.Is(err, constants.ErrInvalidLabels) || errors.Is(err, constants.ErrInvalidResourceLimits) || errors.Is(err, constants.ErrWorkerFeatureUnsupported), http.StatusBadRequest, http.StatusInternalServerError).(int)
+		utils.ErrorResponse(&h.Controller, status, fmt.Sprintf("failed to update job: %s", err), err)
 		return
 	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job '%s' updated successfully", req.Name), nil)
@@ -178,6 +187,96 @@ func (h *Handler) DeleteJob() {
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job '%s' deleted successfully", jobName), nil)
 }
 
+// @router /project/:projectid/jobs/:id/clone [post]
+func (h *Handler) CloneJob() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.CloneJobRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Clone job initiated project_id[%s] job_id[%d] name[%s] user_id[%d]", projectID, id, req.Name, *userID)
+
+	result, err := h.etl.CloneJob(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		if errors.Is(err, constants.ErrJobNotFound) {
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to clone job: %s", err), err)
+			return
+		}
+		if errors.Is(err, constants.ErrWorkerFeatureUnsupported) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to clone job: %s", err), err)
+			return
+		}
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to clone job: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job '%s' cloned successfully", req.Name), result)
+}
+
+// @router /project/:projectid/jobs/:id/dry-run [post]
+func (h *Handler) DryRunJob() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.DryRunRequest
+	if len(h.Ctx.Input.RequestBody) > 0 {
+		if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+			return
+		}
+	}
+
+	logger.Infof("Dry run initiated project_id[%s] job_id[%d] row_limit[%d] user_id[%d]", projectID, id, req.RowLimit, *userID)
+
+	result, err := h.etl.DryRunJob(h.Ctx.Request.Context(), projectID, id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrJobNotFound):
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to dry run job: %s", err), err)
+		case errors.Is(err, constants.ErrInvalidDryRun),
+			errors.Is(err, constants.ErrConnectorVersionNotAllowed):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to dry run job: %s", err), err)
+		case errors.Is(err, constants.ErrWorkerFeatureUnsupported):
+			utils.ErrorResponse(&h.Controller, http.StatusNotImplemented, fmt.Sprintf("failed to dry run job: %s", err), err)
+		default:
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to dry run job: %s", err), err)
+		}
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "dry run completed successfully", result)
+}
+
 // @router /project/:projectid/check-unique [post]
 func (h *Handler) CheckUniqueName() {
 	projectID, err := GetProjectIDFromPath(&h.Controller)
@@ -226,12 +325,59 @@ func (h *Handler) SyncJob() {
 
 	result, err := h.etl.SyncJob(h.Ctx.Request.Context(), projectID, id)
 	if err != nil {
+		if errors.Is(err, constants.ErrJobInMaintenance) {
+			utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("failed to trigger sync: %s", err), err)
+			return
+		}
+		if errors.Is(err, constants.ErrWorkerFeatureUnsupported) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to trigger sync: %s", err), err)
+			return
+		}
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to trigger sync: %s", err), err)
 		return
 	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("sync triggered successfully for job_id[%d]", id), result)
 }
 
+// @router /project/:projectid/jobs/:id/sync/streams [post]
+func (h *Handler) SyncJobStreams() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ManualSyncRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Manual sync initiated for project_id[%s] job_id[%d] streams[%v] full_refresh[%t]", projectID, id, req.Streams, req.FullRefresh)
+
+	result, err := h.etl.SyncJobStreams(h.Ctx.Request.Context(), projectID, id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrJobNotFound):
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to start manual sync: %s", err), err)
+		case errors.Is(err, constants.ErrSyncRunning), errors.Is(err, constants.ErrJobInMaintenance):
+			utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("failed to start manual sync: %s", err), err)
+		case errors.Is(err, constants.ErrInvalidManualSync), errors.Is(err, constants.ErrWorkerFeatureUnsupported):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to start manual sync: %s", err), err)
+		default:
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to start manual sync: %s", err), err)
+		}
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("manual sync started successfully for job_id[%d]", id), result)
+}
+
 // @router /project/:projectid/jobs/:id/activate [put]
 func (h *Handler) ActivateJob() {
 	userID := GetUserIDFromSession(&h.Controller)
@@ -284,6 +430,40 @@ func (h *Handler) CancelJobRun() {
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job workflow cancel requested successfully for job_id[%d]", id), nil)
 }
 
+// @router /project/:projectid/jobs/bulk [post]
+func (h *Handler) BulkJobAction() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.BulkJobRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Bulk job %s initiated project_id[%s] job_ids[%v] user_id[%v]", req.Action, projectID, req.JobIDs, *userID)
+
+	result, err := h.etl.BulkJobAction(h.Ctx.Request.Context(), projectID, &req, userID)
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidBulkJobRequest) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to run bulk job action: %s", err), err)
+			return
+		}
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to run bulk job action: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("bulk %s completed: %d succeeded, %d failed", req.Action, result.Succeeded, result.Failed), result)
+}
+
 // @router /project/:projectid/jobs/:id/clear-destination [post]
 func (h *Handler) ClearDestination() {
 	projectID, err := GetProjectIDFromPath(&h.Controller)
@@ -298,7 +478,8 @@ func (h *Handler) ClearDestination() {
 		return
 	}
 	if err := h.etl.ClearDestination(h.Ctx.Request.Context(), projectID, id, "", 0, true); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to trigger clear destination: %s", err), err)
+		status := utils.Ternary(errors.Is(err, constants.ErrWorkerFeatureUnsupported), http.StatusBadRequest, http.StatusInternalServerError).(int)
+		utils.ErrorResponse(&h.Controller, status, fmt.Sprintf("failed to trigger clear destination: %s", err), err)
 		return
 	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("clear destination triggered successfully for job_id[%d]", id), nil)
@@ -374,9 +555,15 @@ func (h *Handler) GetJobTasks() {
 		return
 	}
 
+	selector, err := GetLabelSelectorFromQuery(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
 	logger.Debugf("Get job tasks initiated project_id[%s] job_id[%d]", projectID, id)
 
-	tasks, err := h.etl.GetJobTasks(h.Ctx.Request.Context(), projectID, id)
+	tasks, err := h.etl.GetJobTasks(h.Ctx.Request.Context(), projectID, id, selector)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get job tasks: %s", err), err)
 		return
@@ -401,10 +588,16 @@ func (h *Handler) GetTaskLogs() {
 	cursor, _ := h.GetInt64("cursor", constants.DefaultLogsCursor)
 	limit, _ := h.GetInt("limit", constants.DefaultLogsLimit)
 	direction := h.GetString("direction", constants.DefaultLogsDirection)
+	attempt := h.GetString("attempt")
+	logType := h.GetString("log_type", constants.LogTypeSync)
+	if logType != constants.LogTypeSync && logType != constants.LogTypeActivity {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("invalid log_type '%s', supported log types are: %s, %s", logType, constants.LogTypeSync, constants.LogTypeActivity), nil)
+		return
+	}
 
-	logger.Debugf("Get task logs initiated job_id[%d] file_path[%s] cursor[%d] limit[%d] direction[%s]", id, req.FilePath, cursor, limit, direction)
+	logger.Debugf("Get task logs initiated job_id[%d] file_path[%s] attempt[%s] log_type[%s] cursor[%d] limit[%d] direction[%s]", id, req.FilePath, attempt, logType, cursor, limit, direction)
 
-	logs, err := h.etl.GetTaskLogs(h.Ctx.Request.Context(), id, req.FilePath, cursor, limit, direction)
+	logs, err := h.etl.GetTaskLogs(h.Ctx.Request.Context(), id, req.FilePath, attempt, logType, cursor, limit, direction)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get task logs: %s", err), err)
 		return
@@ -472,7 +665,7 @@ func (h *Handler) DownloadTaskLogs() {
 
 	logger.Debugf("Download task logs initiated job_id[%d] file_path[%s]", id, filePath)
 
-	filename, err := utils.GetLogArchiveFilename(id, filePath)
+	filename, err := utils.GetLogArchiveFilename(h.Ctx.Request.Context(), id, filePath)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to prepare log archive: %s", err), err)
 		return
@@ -485,7 +678,7 @@ func (h *Handler) DownloadTaskLogs() {
 	// Expose Content-Disposition header so browser JS can access filename for download
 	h.Ctx.Output.Header("Access-Control-Expose-Headers", "Content-Disposition")
 
-	if err := h.etl.StreamLogArchive(id, filePath, h.Ctx.ResponseWriter); err != nil {
+	if err := h.etl.StreamLogArchive(h.Ctx.Request.Context(), id, filePath, h.Ctx.ResponseWriter); err != nil {
 		logger.Errorf("failed to stream log archive job_id[%d]: %s", id, err)
 		return
 	}
@@ -495,6 +688,12 @@ func (h *Handler) DownloadTaskLogs() {
 
 // @router /internal/project/:projectid/jobs/:id/statefile [put]
 func (h *Handler) UpdateStateFile() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
 	jobID, err := GetIDFromPath(&h.Controller)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
@@ -507,7 +706,7 @@ func (h *Handler) UpdateStateFile() {
 		return
 	}
 
-	if err := h.etl.UpdateStateFile(jobID, req.StateFile); err != nil {
+	if err := h.etl.UpdateStateFile(h.Ctx.Request.Context(), projectID, jobID, &req); err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update state file: %s", err), err)
 		return
 	}
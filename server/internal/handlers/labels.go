@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/sources/:id/labels [put]
+func (h *Handler) SetSourceLabels() {
+	userID, projectID, id, req, ok := h.parseLabelsRequest()
+	if !ok {
+		return
+	}
+
+	logger.Infof("Set source labels initiated project_id[%s] source_id[%d] user_id[%d]", projectID, id, *userID)
+
+	if err := h.etl.SetSourceLabels(projectID, id, req.Labels, userID); err != nil {
+		respondLabelsError(h, "failed to set source labels", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("labels of source %d updated successfully", id), req)
+}
+
+// @router /project/:projectid/destinations/:id/labels [put]
+func (h *Handler) SetDestinationLabels() {
+	userID, projectID, id, req, ok := h.parseLabelsRequest()
+	if !ok {
+		return
+	}
+
+	logger.Infof("Set destination labels initiated project_id[%s] destination_id[%d] user_id[%d]", projectID, id, *userID)
+
+	if err := h.etl.SetDestinationLabels(projectID, id, req.Labels, userID); err != nil {
+		respondLabelsError(h, "failed to set destination labels", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("labels of destination %d updated successfully", id), req)
+}
+
+// @router /project/:projectid/jobs/:id/labels [put]
+func (h *Handler) SetJobLabels() {
+	userID, projectID, id, req, ok := h.parseLabelsRequest()
+	if !ok {
+		return
+	}
+
+	logger.Infof("Set job labels initiated project_id[%s] job_id[%d] user_id[%d]", projectID, id, *userID)
+
+	if err := h.etl.SetJobLabels(h.Ctx.Request.Context(), projectID, id, req.Labels, userID); err != nil {
+		respondLabelsError(h, "failed to set job labels", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("labels of job %d updated successfully", id), req)
+}
+
+// parseLabelsRequest reads the user, project, id and body of a labels request, responding with
+// the error when one is missing or invalid
+func (h *Handler) parseLabelsRequest() (*int, string, int, *dto.LabelsRequest, bool) {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return nil, "", 0, nil, false
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return nil, "", 0, nil, false
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return nil, "", 0, nil, false
+	}
+
+	var req dto.LabelsRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return nil, "", 0, nil, false
+	}
+	return userID, projectID, id, &req, true
+}
+
+// respondLabelsError maps label errors to their status codes
+func respondLabelsError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrSourceNotFound),
+		errors.Is(err, constants.ErrDestinationNotFound),
+		errors.Is(err, constants.ErrJobNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidLabels):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/logs/usage [get]
+func (h *Handler) GetLogDiskUsage() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Debugf("Get log disk usage initiated project_id[%s]", projectID)
+
+	usage, err := h.etl.GetLogDiskUsage(h.Ctx.Request.Context(), projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get log disk usage: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "log disk usage retrieved successfully", usage)
+}
+
+// @router /project/:projectid/logs/cleanup [post]
+func (h *Handler) CleanupLogs() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Log cleanup initiated project_id[%s]", projectID)
+
+	report, err := h.etl.CleanupLogs(h.Ctx.Request.Context(), projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to cleanup logs: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("log cleanup removed %d directories", len(report.Deleted)), report)
+}
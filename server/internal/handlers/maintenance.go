@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/maintenance-windows [get]
+func (h *Handler) ListMaintenanceWindows() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	windows, err := h.etl.ListMaintenanceWindows(projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list maintenance windows: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "maintenance windows listed successfully", windows)
+}
+
+// @router /project/:projectid/maintenance-windows/:id [get]
+func (h *Handler) GetMaintenanceWindow() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	window, err := h.etl.GetMaintenanceWindow(projectID, id)
+	if err != nil {
+		respondMaintenanceWindowError(h, "failed to get maintenance window", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "maintenance window retrieved successfully", window)
+}
+
+// @router /project/:projectid/maintenance-windows [post]
+func (h *Handler) CreateMaintenanceWindow() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.MaintenanceWindowRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Create maintenance window initiated project_id[%s] name[%s] target[%s %d] user_id[%d]", projectID, req.Name, req.TargetType, req.TargetID, *userID)
+
+	window, err := h.etl.CreateMaintenanceWindow(projectID, &req, userID)
+	if err != nil {
+		respondMaintenanceWindowError(h, "failed to create maintenance window", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "maintenance window created successfully", window)
+}
+
+// @router /project/:projectid/maintenance-windows/:id [put]
+func (h *Handler) UpdateMaintenanceWindow() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.MaintenanceWindowRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Update maintenance window initiated project_id[%s] window_id[%d] user_id[%d]", projectID, id, *userID)
+
+	window, err := h.etl.UpdateMaintenanceWindow(projectID, id, &req, userID)
+	if err != nil {
+		respondMaintenanceWindowError(h, "failed to update maintenance window", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "maintenance window updated successfully", window)
+}
+
+// @router /project/:projectid/maintenance-windows/:id [delete]
+func (h *Handler) DeleteMaintenanceWindow() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Delete maintenance window initiated project_id[%s] window_id[%d] user_id[%d]", projectID, id, *userID)
+
+	if err := h.etl.DeleteMaintenanceWindow(h.Ctx.Request.Context(), projectID, id, userID); err != nil {
+		respondMaintenanceWindowError(h, "failed to delete maintenance window", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "maintenance window deleted successfully", nil)
+}
+
+// @router /project/:projectid/maintenance-windows/events [get]
+func (h *Handler) ListMaintenanceEvents() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	windowID, err := h.GetInt("window_id", 0)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: invalid window_id: %s", err), err)
+		return
+	}
+	jobID, err := h.GetInt("job_id", 0)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: invalid job_id: %s", err), err)
+		return
+	}
+	limit, err := h.GetInt("limit", constants.DefaultMaintenanceEventLimit)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: invalid limit: %s", err), err)
+		return
+	}
+
+	events, err := h.etl.ListMaintenanceEvents(projectID, windowID, jobID, limit)
+	if err != nil {
+		respondMaintenanceWindowError(h, "failed to list maintenance events", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "maintenance events listed successfully", events)
+}
+
+// respondMaintenanceWindowError maps maintenance window errors to their status codes
+func respondMaintenanceWindowError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrMaintenanceWindowNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidMaintenanceWindow):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/masking-rules [get]
+func (h *Handler) ListMaskingRules() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	rules, err := h.etl.ListMaskingRules(projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list masking rules: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "masking rules listed successfully", rules)
+}
+
+// @router /project/:projectid/masking-rules/:id [get]
+func (h *Handler) GetMaskingRule() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	rule, err := h.etl.GetMaskingRule(projectID, id)
+	if err != nil {
+		respondMaskingRuleError(h, "failed to get masking rule", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "masking rule retrieved successfully", rule)
+}
+
+// @router /project/:projectid/masking-rules [post]
+func (h *Handler) CreateMaskingRule() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.MaskingRuleRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Create masking rule initiated project_id[%s] column[%s] strategy[%s] user_id[%d]", projectID, req.Column, req.Strategy, *userID)
+
+	rule, err := h.etl.CreateMaskingRule(projectID, &req, userID)
+	if err != nil {
+		respondMaskingRuleError(h, "failed to create masking rule", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "masking rule created successfully", rule)
+}
+
+// @router /project/:projectid/masking-rules/:id [put]
+func (h *Handler) UpdateMaskingRule() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.MaskingRuleRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Update masking rule initiated project_id[%s] rule_id[%d] user_id[%d]", projectID, id, *userID)
+
+	rule, err := h.etl.UpdateMaskingRule(projectID, id, &req, userID)
+	if err != nil {
+		respondMaskingRuleError(h, "failed to update masking rule", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "masking rule updated successfully", rule)
+}
+
+// @router /project/:projectid/masking-rules/:id [delete]
+func (h *Handler) DeleteMaskingRule() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Delete masking rule initiated project_id[%s] rule_id[%d]", projectID, id)
+
+	if err := h.etl.DeleteMaskingRule(projectID, id); err != nil {
+		respondMaskingRuleError(h, "failed to delete masking rule", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "masking rule deleted successfully", nil)
+}
+
+// respondMaskingRuleError maps masking rule errors to their status codes
+func respondMaskingRuleError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrMaskingRuleNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidMaskingRule):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -1,23 +1,65 @@
 package middleware
 
 import (
+	gocontext "context"
+	"errors"
+
 	"github.com/beego/beego/v2/server/web"
 	"github.com/beego/beego/v2/server/web/context"
 
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
 )
 
-// middleware only works if session is enabled
-func AuthMiddleware(ctx *context.Context) {
-	if web.BConfig.WebConfig.Session.SessionOn {
-		if userID := ctx.Input.Session(constants.SessionUserID); userID == nil {
+// changePasswordPath stays reachable while a user has to change their password
+const changePasswordPath = "/api/v1/auth/password"
+
+// SessionValidator checks that a session of a user is still active, i.e. not revoked or expired
+type SessionValidator interface {
+	ValidateSession(ctx gocontext.Context, sessionKey string, userID int) error
+}
+
+// AuthMiddleware only works if session is enabled
+func AuthMiddleware(sessions SessionValidator) web.FilterFunc {
+	return func(ctx *context.Context) {
+		if !web.BConfig.WebConfig.Session.SessionOn {
+			return
+		}
+
+		userID, ok := ctx.Input.Session(constants.SessionUserID).(int)
+		if !ok {
 			// Send unauthorized response
-			ctx.Output.SetStatus(401)
+			unauthorized(ctx)
+			return
+		}
+
+		sessionKey := ctx.Input.CruSession.SessionID(ctx.Request.Context())
+		if err := sessions.ValidateSession(ctx.Request.Context(), sessionKey, userID); err != nil {
+			if !errors.Is(err, constants.ErrSessionExpired) {
+				logger.Errorf("failed to validate session user_id[%d]: %s", userID, err)
+			}
+			// forget the user so the stale cookie stops working
+			_ = ctx.Input.CruSession.Flush(ctx.Request.Context())
+			unauthorized(ctx)
+			return
+		}
+
+		// a password reset by an admin has to be changed before anything else
+		if mustChange, _ := ctx.Input.Session(constants.SessionMustChangePassword).(bool); mustChange && ctx.Input.URL() != changePasswordPath {
+			ctx.Output.SetStatus(403)
 			_ = ctx.Output.JSON(dto.JSONResponse{
-				Message: "Unauthorized, try login again",
+				Message: "Password change required, set a new password first",
 				Success: false,
 			}, false, false)
 		}
 	}
 }
+
+func unauthorized(ctx *context.Context) {
+	ctx.Output.SetStatus(401)
+	_ = ctx.Output.JSON(dto.JSONResponse{
+		Message: "Unauthorized, try login again",
+		Success: false,
+	}, false, false)
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+)
+
+// loginRateWindow is the window LOGIN_RATE_LIMIT applies to
+const loginRateWindow = time.Minute
+
+// rateLimiter counts requests per client in fixed windows
+type rateLimiter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	clients map[string]*rateWindow
+	swept   time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+var loginLimiter = &rateLimiter{window: loginRateWindow, clients: map[string]*rateWindow{}}
+
+// allow counts a request of a client and reports whether it is within limit, otherwise it
+// returns how long until the client may retry
+func (l *rateLimiter) allow(client string, limit int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// drop finished windows now and then so the map does not grow with every client seen
+	if now.Sub(l.swept) > l.window {
+		for key, w := range l.clients {
+			if now.Sub(w.start) >= l.window {
+				delete(l.clients, key)
+			}
+		}
+		l.swept = now
+	}
+
+	w, ok := l.clients[client]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.clients[client] = w
+	}
+	if w.count >= limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// LoginRateLimit limits login attempts per client IP to LOGIN_RATE_LIMIT per minute, 0 disables it
+func LoginRateLimit(ctx *context.Context) {
+	if ctx.Input.Method() != http.MethodPost {
+		return
+	}
+	limit := web.AppConfig.DefaultInt(constants.ConfLoginRateLimit, constants.DefaultLoginRateLimit)
+	if limit <= 0 {
+		return
+	}
+
+	allowed, retryAfter := loginLimiter.allow(ClientIP(ctx), limit, time.Now())
+	if allowed {
+		return
+	}
+
+	seconds := int(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	ctx.Output.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.Output.SetStatus(http.StatusTooManyRequests)
+	_ = ctx.Output.JSON(dto.JSONResponse{
+		Message: "Too many login attempts, try again later",
+		Success: false,
+	}, false, false)
+}
+
+// ClientIP returns the IP of the client, X-Forwarded-For is only trusted when the server runs
+// behind a proxy that sets it (LOGIN_RATE_LIMIT_TRUST_PROXY)
+func ClientIP(ctx *context.Context) string {
+	if web.AppConfig.DefaultBool(constants.ConfLoginRateLimitTrustProxy, false) {
+		if forwarded := ctx.Request.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
+	if err != nil {
+		return ctx.Request.RemoteAddr
+	}
+	return host
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
@@ -45,7 +47,8 @@ func (h *Handler) UpsertProjectSettings() {
 	logger.Debugf("Update project settings initiated project_id[%s]", projectID)
 
 	if err := h.etl.UpsertProjectSettings(req); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update project settings: %s", err), err)
+		status := utils.Ternary(errors.Is(err, constants.ErrInvalidLabels) || errors.Is(err, constants.ErrWorkerFeatureUnsupported), http.StatusBadRequest, http.StatusInternalServerError).(int)
+		utils.ErrorResponse(&h.Controller, status, fmt.Sprintf("failed to update project settings: %s", err), err)
 		return
 	}
 
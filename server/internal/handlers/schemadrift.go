@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/jobs/:id/drift-policy [get]
+func (h *Handler) GetJobDriftPolicy() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	policy, err := h.etl.GetJobDriftPolicy(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondSchemaDriftError(h, "failed to get drift policy", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "drift policy retrieved successfully", policy)
+}
+
+// @router /project/:projectid/jobs/:id/drift-policy [put]
+func (h *Handler) SetJobDriftPolicy() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.DriftPolicyRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Set drift policy initiated project_id[%s] job_id[%d] policy[%s] user_id[%d]", projectID, id, req.Policy, *userID)
+
+	policy, err := h.etl.SetJobDriftPolicy(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		respondSchemaDriftError(h, "failed to set drift policy", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "drift policy updated successfully", policy)
+}
+
+// @router /project/:projectid/jobs/:id/schema-check [post]
+func (h *Handler) CheckJobSchema() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Schema check initiated project_id[%s] job_id[%d]", projectID, id)
+
+	result, err := h.etl.CheckJobSchema(h.Ctx.Request.Context(), projectID, id, userID)
+	if err != nil {
+		respondSchemaDriftError(h, "failed to check schema", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "schema checked successfully", result)
+}
+
+// @router /project/:projectid/schema-drifts [get]
+func (h *Handler) ListSchemaDrifts() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	jobID, err := h.GetInt("job_id", 0)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("invalid job_id: %s", err), err)
+		return
+	}
+
+	drifts, err := h.etl.ListSchemaDrifts(h.Ctx.Request.Context(), projectID, jobID, h.GetString("status"))
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list schema drifts: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "schema drifts listed successfully", drifts)
+}
+
+// @router /project/:projectid/schema-drifts/:id [get]
+func (h *Handler) GetSchemaDrift() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	drift, err := h.etl.GetSchemaDrift(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondSchemaDriftError(h, "failed to get schema drift", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "schema drift retrieved successfully", drift)
+}
+
+// @router /project/:projectid/schema-drifts/:id/approve [post]
+func (h *Handler) ApproveSchemaDrift() {
+	h.resolveSchemaDrift(true)
+}
+
+// @router /project/:projectid/schema-drifts/:id/reject [post]
+func (h *Handler) RejectSchemaDrift() {
+	h.resolveSchemaDrift(false)
+}
+
+func (h *Handler) resolveSchemaDrift(accept bool) {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Resolve schema drift initiated project_id[%s] drift_id[%d] accept[%t] user_id[%d]", projectID, id, accept, *userID)
+
+	drift, err := h.etl.ResolveSchemaDrift(h.Ctx.Request.Context(), projectID, id, accept, userID)
+	if err != nil {
+		respondSchemaDriftError(h, "failed to resolve schema drift", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("schema drift %s", drift.Status), drift)
+}
+
+// respondSchemaDriftError maps schema drift errors to their status codes
+func respondSchemaDriftError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrJobNotFound),
+		errors.Is(err, constants.ErrSchemaDriftNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrSchemaDriftStale):
+		utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrNoDriftPolicy):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
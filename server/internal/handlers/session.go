@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /auth/sessions [get]
+func (h *Handler) ListSessions() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	sessions, err := h.etl.ListUserSessions(h.Ctx.Request.Context(), *userID, GetSessionKey(&h.Controller))
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list sessions: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "sessions listed successfully", sessions)
+}
+
+// @router /auth/sessions [delete]
+func (h *Handler) RevokeOtherSessions() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	logger.Infof("Revoke other sessions initiated user_id[%d]", *userID)
+
+	if err := h.etl.RevokeUserSessions(h.Ctx.Request.Context(), *userID, GetSessionKey(&h.Controller)); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to revoke sessions: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "other sessions revoked successfully", nil)
+}
+
+// @router /auth/sessions/:id [delete]
+func (h *Handler) RevokeSession() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	sessionID, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Revoke session initiated user_id[%d] session_id[%d]", *userID, sessionID)
+
+	if err := h.etl.RevokeUserSession(h.Ctx.Request.Context(), *userID, sessionID); err != nil {
+		if errors.Is(err, constants.ErrSessionNotFound) {
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to revoke session: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "session revoked successfully", nil)
+}
+
+// @router /users/:id/sessions [delete]
+func (h *Handler) RevokeUserSessions() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Revoke all sessions initiated user_id[%d]", id)
+
+	if err := h.etl.RevokeUserSessions(h.Ctx.Request.Context(), id, ""); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to revoke sessions: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "user sessions revoked successfully", nil)
+}
@@ -21,7 +21,13 @@ func (h *Handler) ListSources() {
 
 	logger.Debugf("Get all sources initiated project_id[%s]", projectID)
 
-	sources, err := h.etl.ListSources(h.Ctx.Request.Context(), projectID)
+	selector, err := GetLabelSelectorFromQuery(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	sources, err := h.etl.ListSources(h.Ctx.Request.Context(), projectID, selector)
 	if err != nil {
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to retrieve sources: %s", err), err)
 		return
@@ -82,10 +88,19 @@ func (h *Handler) CreateSource() {
 		projectID, req.Type, req.Name, userID)
 
 	if err := h.etl.CreateSource(h.Ctx.Request.Context(), &req, projectID, userID); err != nil {
-		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create source: %s", err), err)
+		if errors.Is(err, constants.ErrConnectorVersionNotAllowed) || errors.Is(err, constants.ErrInvalidLabels) || errors.Is(err, constants.ErrWorkerFeatureUnsupported) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to create source: %s", err), err)
+		} else {
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create source: %s", err), err)
+		}
 		return
 	}
 
+	// secrets are not echoed back to the client
+	if req.Config, err = h.etl.MaskSourceConfig(h.Ctx.Request.Context(), req.Type, req.Version, req.Config); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to mask source config: %s", err), err)
+		return
+	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("source %s created successfully", req.Name), req)
 }
 
@@ -125,13 +140,22 @@ func (h *Handler) UpdateSource() {
 
 	if err := h.etl.UpdateSource(h.Ctx.Request.Context(), projectID, id, &req, userID); err != nil {
 		status := http.StatusInternalServerError
-		if errors.Is(err, constants.ErrSourceNotFound) {
+		switch {
+		case errors.Is(err, constants.ErrSourceNotFound):
 			status = http.StatusNotFound
+		case errors.Is(err, constants.ErrConnectorVersionNotAllowed), errors.Is(err, constants.ErrInvalidLabels),
+			errors.Is(err, constants.ErrWorkerFeatureUnsupported):
+			status = http.StatusBadRequest
 		}
 		utils.ErrorResponse(&h.Controller, status, fmt.Sprintf("failed to update source: %s", err), err)
 		return
 	}
 
+	// secrets are not echoed back to the client
+	if req.Config, err = h.etl.MaskSourceConfig(h.Ctx.Request.Context(), req.Type, req.Version, req.Config); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to mask source config: %s", err), err)
+		return
+	}
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("source %s updated successfully", req.Name), req)
 }
 
@@ -197,6 +221,10 @@ func (h *Handler) GetSourceCatalog() {
 		return
 	}
 
+	if refresh, _ := h.GetBool("refresh", false); refresh {
+		req.Refresh = true
+	}
+
 	logger.Debugf("Get source catalog initiated source_type[%s] source_version[%s] job_id[%d]",
 		req.Type, req.Version, req.JobID)
 
@@ -208,6 +236,46 @@ func (h *Handler) GetSourceCatalog() {
 	utils.SuccessResponse(&h.Controller, fmt.Sprintf("source %s catalog fetched successfully", req.Type), catalog)
 }
 
+// @router /project/:projectid/sources/:id/streams/preview [get]
+func (h *Handler) PreviewSourceStream() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	stream := h.GetString("stream")
+	limit, err := h.GetInt("limit", constants.DefaultStreamPreviewLimit)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: invalid limit: %s", err), err)
+		return
+	}
+	refresh, _ := h.GetBool("refresh", false)
+
+	logger.Debugf("Stream preview initiated project_id[%s] source_id[%d] stream[%s] limit[%d]", projectID, id, stream, limit)
+
+	preview, err := h.etl.PreviewSourceStream(h.Ctx.Request.Context(), projectID, id, stream, limit, refresh)
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrSourceNotFound):
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("source not found: %s", err), err)
+		case errors.Is(err, constants.ErrInvalidStreamPreview):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to preview stream: %s", err), err)
+		case errors.Is(err, constants.ErrWorkerFeatureUnsupported):
+			utils.ErrorResponse(&h.Controller, http.StatusNotImplemented, fmt.Sprintf("failed to preview stream: %s", err), err)
+		default:
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to preview stream: %s", err), err)
+		}
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("stream %s previewed successfully", stream), preview)
+}
+
 // @router /sources/:id/jobs [get]
 func (h *Handler) GetSourceJobs() {
 	id, err := GetIDFromPath(&h.Controller)
@@ -271,6 +339,10 @@ func (h *Handler) GetSourceSpec() {
 		return
 	}
 
+	if refresh, _ := h.GetBool("refresh", false); refresh {
+		req.Refresh = true
+	}
+
 	logger.Debugf("Get source spec initiated project_id[%s] source_type[%s] source_version[%s]",
 		projectID, req.Type, req.Version)
 
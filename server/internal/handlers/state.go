@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/jobs/:id/state [get]
+func (h *Handler) GetJobState() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Debugf("Get job state initiated job_id[%d]", id)
+
+	state, err := h.etl.GetJobState(h.Ctx.Request.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get job state: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("state of job %d retrieved successfully", id), state)
+}
+
+// @router /project/:projectid/jobs/:id/state [put]
+func (h *Handler) UpdateJobState() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.UpdateJobStateRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Update job state initiated project_id[%s] job_id[%d] user_id[%v]", projectID, id, *userID)
+
+	state, err := h.etl.UpdateJobState(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to update job state: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("state of job %d updated successfully", id), state)
+}
+
+// @router /project/:projectid/jobs/:id/state/reset [post]
+func (h *Handler) ResetJobState() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ResetJobStateRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Reset job state initiated project_id[%s] job_id[%d] streams[%v] user_id[%v]", projectID, id, req.Streams, *userID)
+
+	state, err := h.etl.ResetJobStreamsState(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to reset job state: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("state of %d streams reset successfully for job %d", len(req.Streams), id), state)
+}
+
+// @router /project/:projectid/jobs/:id/state/restore [post]
+func (h *Handler) RestoreJobState() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", fmt.Errorf("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.RestoreJobStateRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Restore job state initiated project_id[%s] job_id[%d] version[%d] workflow_id[%s] user_id[%v]", projectID, id, req.Version, req.WorkflowID, *userID)
+
+	state, err := h.etl.RestoreJobState(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to restore job state: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("state of job %d restored successfully", id), state)
+}
+
+// @router /project/:projectid/jobs/:id/state/versions [get]
+func (h *Handler) ListJobStateVersions() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	versions, err := h.etl.ListJobStateVersions(h.Ctx.Request.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list job state versions: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "job state versions listed successfully", versions)
+}
+
+// @router /project/:projectid/jobs/:id/state/versions/:version [get]
+func (h *Handler) GetJobStateVersion() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	version, err := strconv.Atoi(h.Ctx.Input.Param(":version"))
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("invalid version: %s", err), err)
+		return
+	}
+
+	stateVersion, err := h.etl.GetJobStateVersion(h.Ctx.Request.Context(), id, version)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to get job state version: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("version %d of job %d state retrieved successfully", version, id), stateVersion)
+}
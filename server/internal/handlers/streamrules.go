@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/jobs/:id/stream-rules [get]
+func (h *Handler) GetJobStreamRules() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	rules, err := h.etl.GetJobStreamRules(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondStreamRulesError(h, "failed to get stream rules", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "stream rules retrieved successfully", rules)
+}
+
+// @router /project/:projectid/jobs/:id/stream-rules [put]
+func (h *Handler) SetJobStreamRules() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.JobStreamRulesRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Set stream rules initiated project_id[%s] job_id[%d] user_id[%d]", projectID, id, *userID)
+
+	rules, err := h.etl.SetJobStreamRules(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		respondStreamRulesError(h, "failed to set stream rules", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "stream rules updated successfully", rules)
+}
+
+// @router /project/:projectid/jobs/:id/stream-rules [delete]
+func (h *Handler) DeleteJobStreamRules() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Delete stream rules initiated project_id[%s] job_id[%d]", projectID, id)
+
+	if err := h.etl.DeleteJobStreamRules(h.Ctx.Request.Context(), projectID, id); err != nil {
+		respondStreamRulesError(h, "failed to delete stream rules", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "stream rules deleted successfully", nil)
+}
+
+// @router /project/:projectid/jobs/:id/stream-rules/rediscover [post]
+func (h *Handler) RediscoverJobStreams() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Stream re-discovery initiated project_id[%s] job_id[%d]", projectID, id)
+
+	result, err := h.etl.RediscoverJobStreams(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondStreamRulesError(h, "failed to re-discover streams", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "streams re-discovered successfully", result)
+}
+
+// @router /project/:projectid/stream-reviews [get]
+func (h *Handler) ListStreamReviews() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	jobID, err := h.GetInt("job_id", 0)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("invalid job_id: %s", err), err)
+		return
+	}
+
+	reviews, err := h.etl.ListStreamReviews(h.Ctx.Request.Context(), projectID, jobID, h.GetString("status"))
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list stream reviews: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "stream reviews listed successfully", reviews)
+}
+
+// @router /project/:projectid/stream-reviews/:id [get]
+func (h *Handler) GetStreamReview() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	review, err := h.etl.GetStreamReview(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondStreamRulesError(h, "failed to get stream review", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "stream review retrieved successfully", review)
+}
+
+// @router /project/:projectid/stream-reviews/:id/approve [post]
+func (h *Handler) ApproveStreamReview() {
+	h.resolveStreamReview(true)
+}
+
+// @router /project/:projectid/stream-reviews/:id/reject [post]
+func (h *Handler) RejectStreamReview() {
+	h.resolveStreamReview(false)
+}
+
+func (h *Handler) resolveStreamReview(approve bool) {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Resolve stream review initiated project_id[%s] review_id[%d] approve[%t] user_id[%d]", projectID, id, approve, *userID)
+
+	review, err := h.etl.ResolveStreamReview(h.Ctx.Request.Context(), projectID, id, approve, userID)
+	if err != nil {
+		respondStreamRulesError(h, "failed to resolve stream review", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("stream review %s", review.Status), review)
+}
+
+// respondStreamRulesError maps stream rule and review errors to their status codes
+func respondStreamRulesError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrJobNotFound),
+		errors.Is(err, constants.ErrStreamRulesNotFound),
+		errors.Is(err, constants.ErrStreamReviewNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrStreamReviewStale):
+		utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidStreamRules):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/sync-queue [get]
+func (h *Handler) GetSyncQueue() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	queue, err := h.etl.GetSyncQueue(projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to get sync queue: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "sync queue retrieved successfully", queue)
+}
+
+// @router /internal/worker/callback/sync-admission [post]
+func (h *Handler) AdmitSync() {
+	var req dto.SyncAdmissionRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Debugf("Sync admission requested project_id[%s] job_id[%d] workflow_id[%s]", req.ProjectID, req.JobID, req.WorkflowID)
+
+	admission, err := h.etl.AdmitSync(h.Ctx.Request.Context(), &req)
+	if err != nil {
+		status := utils.Ternary(errors.Is(err, constants.ErrJobNotFound), http.StatusNotFound, http.StatusInternalServerError).(int)
+		utils.ErrorResponse(&h.Controller, status, fmt.Sprintf("failed to admit sync: %s", err), err)
+		return
+	}
+	message := utils.Ternary(admission.Admitted, "sync admitted", "sync queued").(string)
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("%s for workflow_id[%s]", message, req.WorkflowID), admission)
+}
+
+// @router /internal/worker/callback/sync-release [post]
+func (h *Handler) ReleaseSync() {
+	var req dto.SyncReleaseRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	if err := h.etl.ReleaseSync(req.WorkflowID); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to release sync: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("sync released for workflow_id[%s]", req.WorkflowID), nil)
+}
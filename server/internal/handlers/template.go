@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// @router /project/:projectid/job-templates [get]
+func (h *Handler) ListJobTemplates() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	templates, err := h.etl.ListJobTemplates(h.Ctx.Request.Context(), projectID)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to list job templates: %s", err), err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "job templates listed successfully", templates)
+}
+
+// @router /project/:projectid/job-templates/:id [get]
+func (h *Handler) GetJobTemplate() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	template, err := h.etl.GetJobTemplate(h.Ctx.Request.Context(), projectID, id)
+	if err != nil {
+		respondJobTemplateError(h, "failed to get job template", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "job template retrieved successfully", template)
+}
+
+// @router /project/:projectid/job-templates [post]
+func (h *Handler) CreateJobTemplate() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.JobTemplateRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Create job template initiated project_id[%s] name[%s] user_id[%d]", projectID, req.Name, *userID)
+
+	template, err := h.etl.CreateJobTemplate(h.Ctx.Request.Context(), projectID, &req, userID)
+	if err != nil {
+		respondJobTemplateError(h, "failed to create job template", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job template '%s' created successfully", template.Name), template)
+}
+
+// @router /project/:projectid/job-templates/:id [put]
+func (h *Handler) UpdateJobTemplate() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.JobTemplateRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Update job template initiated project_id[%s] template_id[%d] user_id[%d]", projectID, id, *userID)
+
+	template, err := h.etl.UpdateJobTemplate(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		respondJobTemplateError(h, "failed to update job template", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, fmt.Sprintf("job template '%s' updated successfully", template.Name), template)
+}
+
+// @router /project/:projectid/job-templates/:id [delete]
+func (h *Handler) DeleteJobTemplate() {
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Delete job template initiated project_id[%s] template_id[%d]", projectID, id)
+
+	if err := h.etl.DeleteJobTemplate(h.Ctx.Request.Context(), projectID, id); err != nil {
+		respondJobTemplateError(h, "failed to delete job template", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "job template deleted successfully", nil)
+}
+
+// @router /project/:projectid/job-templates/:id/apply [post]
+func (h *Handler) ApplyJobTemplate() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	projectID, err := GetProjectIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ApplyJobTemplateRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Apply job template initiated project_id[%s] template_id[%d] source_id[%d] create[%t]", projectID, id, req.SourceID, req.Create)
+
+	result, err := h.etl.ApplyJobTemplate(h.Ctx.Request.Context(), projectID, id, &req, userID)
+	if err != nil {
+		respondJobTemplateError(h, "failed to apply job template", err)
+		return
+	}
+	utils.SuccessResponse(&h.Controller, "job template applied successfully", result)
+}
+
+// respondJobTemplateError maps job template errors to their status codes
+func respondJobTemplateError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrJobTemplateNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrJobTemplateNameTaken):
+		utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidJobTemplate), errors.Is(err, constants.ErrWorkerFeatureUnsupported):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
 )
@@ -26,10 +28,15 @@ func (h *Handler) CreateUser() {
 	logger.Infof("Create user initiated username[%s] email[%s]", req.Username, req.Email)
 
 	if err := h.etl.CreateUser(h.Ctx.Request.Context(), &req); err != nil {
+		if errors.Is(err, constants.ErrWeakPassword) {
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, err.Error(), err)
+			return
+		}
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to create user: %s", err), err)
 		return
 	}
 
+	req.Password = ""
 	utils.SuccessResponse(&h.Controller, "user created successfully", req)
 }
 
@@ -82,9 +89,145 @@ func (h *Handler) DeleteUser() {
 	logger.Infof("Delete user initiated user_id[%d]", id)
 
 	if err := h.etl.DeleteUser(h.Ctx.Request.Context(), id); err != nil {
+		if errors.Is(err, constants.ErrUserOwnsResources) {
+			utils.ErrorResponse(&h.Controller, http.StatusConflict, fmt.Sprintf("failed to delete user: %s", err), err)
+			return
+		}
 		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to delete user: %s", err), err)
 		return
 	}
 
 	utils.SuccessResponse(&h.Controller, "user deleted successfully", nil)
 }
+
+// @router /users/:id/reset-password [post]
+func (h *Handler) ResetUserPassword() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.ResetPasswordRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Reset user password initiated user_id[%d]", id)
+
+	if err := h.etl.ResetUserPassword(h.Ctx.Request.Context(), id, req.Password); err != nil {
+		switch {
+		case errors.Is(err, constants.ErrUserNotFound):
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to reset password: %s", err), err)
+		case errors.Is(err, constants.ErrWeakPassword):
+			utils.ErrorResponse(&h.Controller, http.StatusBadRequest, err.Error(), err)
+		default:
+			utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to reset password: %s", err), err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "password reset successfully, the user has to change it on the next login", nil)
+}
+
+// @router /users/:id/unlock [post]
+func (h *Handler) UnlockUser() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Unlock user initiated user_id[%d]", id)
+
+	if err := h.etl.UnlockUser(h.Ctx.Request.Context(), id); err != nil {
+		if errors.Is(err, constants.ErrUserNotFound) {
+			utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("failed to unlock user: %s", err), err)
+			return
+		}
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("failed to unlock user: %s", err), err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "user unlocked successfully", nil)
+}
+
+// @router /users/:id/disable [post]
+func (h *Handler) DisableUser() {
+	userID := GetUserIDFromSession(&h.Controller)
+	if userID == nil {
+		utils.ErrorResponse(&h.Controller, http.StatusUnauthorized, "Not authenticated", errors.New("not authenticated"))
+		return
+	}
+
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Disable user initiated user_id[%d] by[%d]", id, *userID)
+
+	if err := h.etl.DisableUser(h.Ctx.Request.Context(), *userID, id); err != nil {
+		respondUserActionError(h, "failed to disable user", err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "user disabled successfully", nil)
+}
+
+// @router /users/:id/enable [post]
+func (h *Handler) EnableUser() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Enable user initiated user_id[%d]", id)
+
+	if err := h.etl.EnableUser(h.Ctx.Request.Context(), id); err != nil {
+		respondUserActionError(h, "failed to enable user", err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "user enabled successfully", nil)
+}
+
+// @router /users/:id/transfer-ownership [post]
+func (h *Handler) TransferOwnership() {
+	id, err := GetIDFromPath(&h.Controller)
+	if err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	var req dto.TransferOwnershipRequest
+	if err := UnmarshalAndValidate(h.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("failed to validate request: %s", err), err)
+		return
+	}
+
+	logger.Infof("Transfer ownership initiated from_user_id[%d] to_user_id[%d]", id, req.ToUserID)
+
+	result, err := h.etl.TransferOwnership(h.Ctx.Request.Context(), id, req.ToUserID)
+	if err != nil {
+		respondUserActionError(h, "failed to transfer ownership", err)
+		return
+	}
+
+	utils.SuccessResponse(&h.Controller, "ownership transferred successfully", result)
+}
+
+// respondUserActionError maps the errors of user lifecycle actions to a status
+func respondUserActionError(h *Handler, message string, err error) {
+	switch {
+	case errors.Is(err, constants.ErrUserNotFound):
+		utils.ErrorResponse(&h.Controller, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err), err)
+	case errors.Is(err, constants.ErrInvalidUserAction):
+		utils.ErrorResponse(&h.Controller, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err), err)
+	default:
+		utils.ErrorResponse(&h.Controller, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err), err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	services "github.com/datazip-inc/olake-ui/server/internal/services/etl"
 )
 
 // get id from path
@@ -21,6 +22,11 @@ func GetIDFromPath(c *web.Controller) (int, error) {
 	return id, nil
 }
 
+// get the labels a listing is filtered by from the repeated "label=key=value" query params
+func GetLabelSelectorFromQuery(c *web.Controller) (map[string]string, error) {
+	return services.ParseLabelSelector(c.GetStrings("label"))
+}
+
 // get id from path
 func GetProjectIDFromPath(c *web.Controller) (string, error) {
 	projectID := c.Ctx.Input.Param(":projectid")
@@ -40,6 +46,14 @@ func GetUserIDFromSession(c *web.Controller) *int {
 	return nil
 }
 
+// Helper to get the session key of the current request, empty if sessions are off
+func GetSessionKey(c *web.Controller) string {
+	if c.CruSession == nil {
+		return ""
+	}
+	return c.CruSession.SessionID(c.Ctx.Request.Context())
+}
+
 // UnmarshalAndValidate unmarshals JSON from request body into the provided struct
 func UnmarshalAndValidate(requestBody []byte, target interface{}) error {
 	if err := json.Unmarshal(requestBody, target); err != nil {
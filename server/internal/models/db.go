@@ -20,6 +20,16 @@ type User struct {
 	Username  string `json:"username" orm:"size(100);unique"`
 	Password  string `json:"password" orm:"size(100)"` // Hidden in JSON
 	Email     string `json:"email" orm:"size(100);unique"`
+	// consecutive failed logins, reset on a successful login and when the account gets locked
+	FailedLoginAttempts int        `json:"-" orm:"column(failed_login_attempts);default(0)"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty" orm:"column(locked_until);null;type(datetime)"`
+	MustChangePassword  bool       `json:"must_change_password" orm:"column(must_change_password);default(false)"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at,omitempty" orm:"column(password_changed_at);null;type(datetime)"`
+	// disabled users cannot log in but keep owning what they created
+	Disabled        bool       `json:"disabled" orm:"column(disabled);default(false)"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty" orm:"column(disabled_at);null;type(datetime)"`
+	EmailVerified   bool       `json:"email_verified" orm:"column(email_verified);default(false)"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" orm:"column(email_verified_at);null;type(datetime)"`
 }
 
 func (u *User) TableName() string {
@@ -32,6 +42,10 @@ type ProjectSettings struct {
 	ID              int    `json:"id" orm:"column(id);pk;auto"`
 	ProjectID       string `json:"project_id" orm:"column(project_id);unique"`
 	WebhookAlertURL string `json:"webhook_alert_url" orm:"column(webhook_alert_url);size(512)"`
+	// AlertRoutes sends the alerts of labelled jobs to other webhooks, a JSON list of dto.AlertRoute
+	AlertRoutes string `json:"alert_routes" orm:"column(alert_routes);type(jsonb);null"`
+	// MaxConcurrentSyncs caps the syncs running at once in the project, 0 for no cap
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs" orm:"column(max_concurrent_syncs);default(0)"`
 }
 
 func (s *ProjectSettings) TableName() string {
@@ -49,6 +63,9 @@ type Source struct {
 	CreatedBy *User  `json:"created_by" orm:"rel(fk)"`
 	UpdatedBy *User  `json:"updated_by" orm:"rel(fk)"`
 	Type      string `json:"type"`
+	Labels    string `json:"labels" orm:"type(jsonb);null"` // key/value labels as a JSON object
+	// MaxConcurrentSyncs caps the syncs of the jobs of the source running at once, 0 for no cap
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs" orm:"column(max_concurrent_syncs);default(0)"`
 }
 
 func (s *Source) TableName() string {
@@ -66,6 +83,7 @@ type Destination struct {
 	Config    string `json:"config" orm:"type(jsonb)"`
 	CreatedBy *User  `json:"created_by" orm:"rel(fk)"`
 	UpdatedBy *User  `json:"updated_by" orm:"rel(fk)"`
+	Labels    string `json:"labels" orm:"type(jsonb);null"` // key/value labels as a JSON object
 }
 
 func (d *Destination) TableName() string {
@@ -86,19 +104,305 @@ type Job struct {
 	CreatedBy     *User        `json:"created_by" orm:"rel(fk)"`
 	UpdatedBy     *User        `json:"updated_by" orm:"rel(fk)"`
 	ProjectID     string       `json:"project_id" orm:"column(project_id)"`
+	DriftPolicy   string       `json:"drift_policy" orm:"column(drift_policy);size(20);null"` // empty disables schema checks
+	Labels        string       `json:"labels" orm:"type(jsonb);null"`                         // key/value labels as a JSON object
+	CPULimit      string       `json:"cpu_limit" orm:"column(cpu_limit);size(32);null"`       // like "2" or "500m", empty for no limit
+	MemoryLimit   string       `json:"memory_limit" orm:"column(memory_limit);size(32);null"` // like "4Gi" or "512Mi", empty for no limit
 }
 
 func (j *Job) TableName() string {
 	return constants.TableNameMap[constants.JobTable]
 }
 
-type Catalog struct {
+// JobTemplate holds reusable job settings, its rules select the streams of a source and set
+// their normalization, partitioning and sync mode
+type JobTemplate struct {
+	BaseModel   `orm:"embedded"`
+	ID          int    `json:"id" orm:"column(id);pk;auto"`
+	ProjectID   string `json:"project_id" orm:"column(project_id)"`
+	Name        string `json:"name" orm:"size(100)"`
+	Description string `json:"description" orm:"type(text);null"`
+	SourceType  string `json:"source_type" orm:"column(source_type);size(100);null"` // empty applies to any source
+	Frequency   string `json:"frequency" orm:"null"`
+	Rules       string `json:"rules" orm:"type(jsonb)"`
+	CreatedBy   *User  `json:"created_by" orm:"rel(fk)"`
+	UpdatedBy   *User  `json:"updated_by" orm:"rel(fk)"`
+}
+
+func (t *JobTemplate) TableName() string {
+	return constants.TableNameMap[constants.JobTemplateTable]
+}
+
+// JobStreamRules are the stream selection rules of a job. Every re-discovery of the source
+// applies them to the job's catalog, see StreamReview.
+type JobStreamRules struct {
+	BaseModel     `orm:"embedded"`
+	ID            int        `json:"id" orm:"column(id);pk;auto"`
+	Job           *Job       `json:"job_id" orm:"column(job_id);rel(one);on_delete(cascade)"`
+	Rules         string     `json:"rules" orm:"type(jsonb)"`
+	AutoInclude   bool       `json:"auto_include" orm:"column(auto_include)"` // add newly matched streams without review
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty" orm:"column(last_checked_at);null;type(datetime)"`
+	LastError     string     `json:"last_error" orm:"column(last_error);type(text);null"`
+	UpdatedBy     *User      `json:"updated_by" orm:"column(updated_by_id);rel(fk);null;on_delete(set_null)"`
+}
+
+func (r *JobStreamRules) TableName() string {
+	return constants.TableNameMap[constants.JobStreamRulesTable]
+}
+
+// StreamReview is a change of the selected streams of a job proposed by its stream selection
+// rules. BaseHash is the sha256 of the streams config the change was computed from, a review
+// only applies while the job still has that config.
+type StreamReview struct {
+	BaseModel     `orm:"embedded"`
+	ID            int        `json:"id" orm:"column(id);pk;auto"`
+	Job           *Job       `json:"job_id" orm:"column(job_id);rel(fk);on_delete(cascade)"`
+	Status        string     `json:"status" orm:"size(20);index"`   // pending, applied, rejected or superseded
+	Added         string     `json:"added" orm:"type(text);null"`   // comma separated namespace.stream
+	Removed       string     `json:"removed" orm:"type(text);null"` // comma separated namespace.stream
+	StreamsConfig string     `json:"streams_config" orm:"type(jsonb)"`
+	BaseHash      string     `json:"base_hash" orm:"column(base_hash);size(64)"`
+	ResolvedBy    *User      `json:"resolved_by" orm:"column(resolved_by_id);rel(fk);null;on_delete(set_null)"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty" orm:"column(resolved_at);null;type(datetime)"`
+}
+
+func (r *StreamReview) TableName() string {
+	return constants.TableNameMap[constants.StreamReviewTable]
+}
+
+// SchemaDrift is a change of the source schema found by a schema check of a job. StreamsConfig
+// is the job's streams config with the change accepted and BaseHash the sha256 of the streams
+// config it was computed from, see StreamReview.
+type SchemaDrift struct {
+	BaseModel     `orm:"embedded"`
+	ID            int        `json:"id" orm:"column(id);pk;auto"`
+	Job           *Job       `json:"job_id" orm:"column(job_id);rel(fk);on_delete(cascade)"`
+	Status        string     `json:"status" orm:"size(20);index"` // pending, accepted, rejected or superseded
+	Action        string     `json:"action" orm:"size(20)"`       // what the job's policy did: notified, accepted or paused
+	Changes       string     `json:"changes" orm:"type(jsonb)"`
+	StreamsConfig string     `json:"streams_config" orm:"type(jsonb)"`
+	BaseHash      string     `json:"base_hash" orm:"column(base_hash);size(64)"`
+	ResolvedBy    *User      `json:"resolved_by" orm:"column(resolved_by_id);rel(fk);null;on_delete(set_null)"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty" orm:"column(resolved_at);null;type(datetime)"`
+}
+
+func (d *SchemaDrift) TableName() string {
+	return constants.TableNameMap[constants.SchemaDriftTable]
+}
+
+// Connector is a connector image known to the connector registry. Features holds the minimum
+// connector version of each feature that needs one, e.g. {"clear_destination": "v0.3.0"}.
+// Position orders the connectors tried as the driver that writes destinations.
+type Connector struct {
+	BaseModel       `orm:"embedded"`
+	ID              int        `json:"id" orm:"column(id);pk;auto"`
+	Type            string     `json:"type" orm:"size(50);unique"`
+	Image           string     `json:"image" orm:"size(255)"`
+	Position        int        `json:"position" orm:"column(position)"`
+	Deprecated      bool       `json:"deprecated" orm:"column(deprecated);default(false)"`
+	DeprecationNote string     `json:"deprecation_note" orm:"column(deprecation_note);size(255);null"`
+	AutoAllow       bool       `json:"auto_allow" orm:"column(auto_allow);default(true)"` // versions found by a refresh are allowed right away
+	Features        string     `json:"features" orm:"type(jsonb);null"`
+	RefreshedAt     *time.Time `json:"refreshed_at,omitempty" orm:"column(refreshed_at);null;type(datetime)"`
+	RefreshError    string     `json:"refresh_error" orm:"column(refresh_error);type(text);null"`
+}
+
+func (c *Connector) TableName() string {
+	return constants.TableNameMap[constants.ConnectorTable]
+}
+
+// ConnectorVersion is a version of a connector image. Origin tells where it was found:
+// registry, local (images on the docker host) or manual.
+type ConnectorVersion struct {
+	BaseModel  `orm:"embedded"`
+	ID         int        `json:"id" orm:"column(id);pk;auto"`
+	Connector  *Connector `json:"-" orm:"column(connector_id);rel(fk);on_delete(cascade)"`
+	Version    string     `json:"version" orm:"size(50)"`
+	Allowed    bool       `json:"allowed" orm:"column(allowed);default(true)"`
+	Deprecated bool       `json:"deprecated" orm:"column(deprecated);default(false)"`
+	Origin     string     `json:"origin" orm:"size(20)"`
+}
+
+func (v *ConnectorVersion) TableName() string {
+	return constants.TableNameMap[constants.ConnectorVersionTable]
+}
+
+func (v *ConnectorVersion) TableUnique() [][]string {
+	return [][]string{{"Connector", "Version"}}
+}
+
+// ConnectorUpgrade moves the sources of a project from one connector version to another. The
+// sources are upgraded one at a time, each with a ConnectorUpgradeItem. SpecChanges holds the
+// differences between the specs of both versions.
+type ConnectorUpgrade struct {
+	BaseModel   `orm:"embedded"`
+	ID          int        `json:"id" orm:"column(id);pk;auto"`
+	ProjectID   string     `json:"project_id" orm:"column(project_id);index"`
+	SourceType  string     `json:"source_type" orm:"column(source_type);size(100)"`
+	FromVersion string     `json:"from_version" orm:"column(from_version);size(50)"`
+	ToVersion   string     `json:"to_version" orm:"column(to_version);size(50)"`
+	Status      string     `json:"status" orm:"size(20);index"` // running, completed, stopped or interrupted
+	Force       bool       `json:"force" orm:"column(force);default(false)"`
+	MaxFailures int        `json:"max_failures" orm:"column(max_failures);default(0)"`
+	SpecChanges string     `json:"spec_changes" orm:"column(spec_changes);type(jsonb)"`
+	CreatedBy   *User      `json:"created_by" orm:"column(created_by_id);rel(fk);null;on_delete(set_null)"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty" orm:"column(finished_at);null;type(datetime)"`
+}
+
+func (u *ConnectorUpgrade) TableName() string {
+	return constants.TableNameMap[constants.ConnectorUpgradeTable]
+}
+
+// ConnectorUpgradeItem is the upgrade of one source and its jobs. CheckResult holds the
+// connection check and the catalog differences found with the new version.
+type ConnectorUpgradeItem struct {
+	BaseModel   `orm:"embedded"`
+	ID          int               `json:"id" orm:"column(id);pk;auto"`
+	Upgrade     *ConnectorUpgrade `json:"-" orm:"column(upgrade_id);rel(fk);on_delete(cascade)"`
+	SourceID    int               `json:"source_id" orm:"column(source_id);index"`
+	SourceName  string            `json:"source_name" orm:"column(source_name);size(255)"`
+	Status      string            `json:"status" orm:"size(20)"` // pending, upgraded, failed, skipped or rolled_back
+	CheckResult string            `json:"check_result" orm:"column(check_result);type(jsonb)"`
+	Error       string            `json:"error" orm:"column(error);type(text);null"`
+}
+
+func (i *ConnectorUpgradeItem) TableName() string {
+	return constants.TableNameMap[constants.ConnectorUpgradeItemTable]
+}
+
+// MaskingRule masks a PII column in the rows the server shows from a source, such as stream
+// previews and dry run samples. Namespace, Stream and Column are full match regular
+// expressions, empty namespace or stream patterns match anything.
+type MaskingRule struct {
 	BaseModel `orm:"embedded"`
 	ID        int    `json:"id" orm:"column(id);pk;auto"`
-	Type      string `json:"type" orm:"size(50)"`
-	Name      string `json:"name" orm:"size(100)"`
-	Specs     string `json:"specs" orm:"type(jsonb)"`
-	Version   string `json:"version"`
+	ProjectID string `json:"project_id" orm:"column(project_id);index"`
+	SourceID  int    `json:"source_id" orm:"column(source_id);default(0);index"` // 0 applies to every source
+	Namespace string `json:"namespace" orm:"size(255);null"`
+	Stream    string `json:"stream" orm:"size(255);null"`
+	Column    string `json:"column" orm:"column(column_pattern);size(255)"`
+	Strategy  string `json:"strategy" orm:"size(20)"` // redact, hash or partial
+	CreatedBy *User  `json:"created_by" orm:"rel(fk)"`
+	UpdatedBy *User  `json:"updated_by" orm:"rel(fk)"`
+}
+
+func (r *MaskingRule) TableName() string {
+	return constants.TableNameMap[constants.MaskingRuleTable]
+}
+
+// MaintenanceWindow pauses the schedules of the jobs of a source, a destination or a whole
+// project while it lasts. Weekly windows recur on Days (comma separated, e.g. "sun,wed") from
+// StartTime to EndTime ("HH:MM" in Timezone), ending the next day when EndTime is not after
+// StartTime. Once windows last from StartsAt to EndsAt. ActiveSince and PausedJobs (a JSON list
+// of job IDs) track the jobs a started window paused, so they are resumed when it ends.
+type MaintenanceWindow struct {
+	BaseModel    `orm:"embedded"`
+	ID           int        `json:"id" orm:"column(id);pk;auto"`
+	ProjectID    string     `json:"project_id" orm:"column(project_id);index"`
+	Name         string     `json:"name" orm:"size(255)"`
+	TargetType   string     `json:"target_type" orm:"column(target_type);size(20)"` // project, source or destination
+	TargetID     int        `json:"target_id" orm:"column(target_id);default(0)"`   // 0 for project windows
+	Recurrence   string     `json:"recurrence" orm:"size(20)"`                      // weekly or once
+	Days         string     `json:"days" orm:"size(50);null"`
+	StartTime    string     `json:"start_time" orm:"column(start_time);size(5);null"`
+	EndTime      string     `json:"end_time" orm:"column(end_time);size(5);null"`
+	Timezone     string     `json:"timezone" orm:"size(64)"`
+	StartsAt     *time.Time `json:"starts_at,omitempty" orm:"column(starts_at);null;type(datetime)"`
+	EndsAt       *time.Time `json:"ends_at,omitempty" orm:"column(ends_at);null;type(datetime)"`
+	Drain        bool       `json:"drain" orm:"column(drain);default(false)"`
+	DrainTimeout int        `json:"drain_timeout" orm:"column(drain_timeout);default(0)"` // seconds
+	Enabled      bool       `json:"enabled" orm:"column(enabled);default(true)"`
+	ActiveSince  *time.Time `json:"active_since,omitempty" orm:"column(active_since);null;type(datetime)"`
+	PausedJobs   string     `json:"paused_jobs" orm:"column(paused_jobs);type(jsonb);null"`
+	CreatedBy    *User      `json:"created_by" orm:"rel(fk)"`
+	UpdatedBy    *User      `json:"updated_by" orm:"rel(fk)"`
+}
+
+func (w *MaintenanceWindow) TableName() string {
+	return constants.TableNameMap[constants.MaintenanceWindowTable]
+}
+
+// MaintenanceEvent is an entry of the audit trail of maintenance windows: changes to a window,
+// its start and end, and what it did to each job. Events outlive their window, so the window
+// is kept by ID and name.
+type MaintenanceEvent struct {
+	ID         int       `json:"id" orm:"column(id);pk;auto"`
+	ProjectID  string    `json:"project_id" orm:"column(project_id);index"`
+	WindowID   int       `json:"window_id" orm:"column(window_id);index"`
+	WindowName string    `json:"window_name" orm:"column(window_name);size(255)"`
+	JobID      int       `json:"job_id" orm:"column(job_id);default(0)"` // 0 for events of the window
+	Action     string    `json:"action" orm:"size(30)"`
+	Message    string    `json:"message" orm:"type(text);null"`
+	User       *User     `json:"user" orm:"column(user_id);rel(fk);null;on_delete(set_null)"`
+	CreatedAt  time.Time `json:"created_at" orm:"column(created_at);auto_now_add;type(datetime)"`
+}
+
+func (e *MaintenanceEvent) TableName() string {
+	return constants.TableNameMap[constants.MaintenanceEventTable]
+}
+
+// SyncSlot is a sync run that asked to start, queued until the concurrency limits of its
+// project and source let it run. Slots are removed when the run ends.
+type SyncSlot struct {
+	ID         int        `json:"id" orm:"column(id);pk;auto"`
+	ProjectID  string     `json:"project_id" orm:"column(project_id);index"`
+	JobID      int        `json:"job_id" orm:"column(job_id)"`
+	SourceID   int        `json:"source_id" orm:"column(source_id)"`
+	WorkflowID string     `json:"workflow_id" orm:"column(workflow_id);size(255);unique"`
+	Status     string     `json:"status" orm:"size(20)"` // queued or running
+	EnqueuedAt time.Time  `json:"enqueued_at" orm:"column(enqueued_at);type(datetime)"`
+	StartedAt  *time.Time `json:"started_at,omitempty" orm:"column(started_at);null;type(datetime)"`
+	LastSeenAt time.Time  `json:"last_seen_at" orm:"column(last_seen_at);type(datetime)"` // last time a queued run asked to start
+}
+
+func (s *SyncSlot) TableName() string {
+	return constants.TableNameMap[constants.SyncSlotTable]
+}
+
+// JobStateVersion keeps every state a job was moved to, the latest version mirrors Job.State.
+// Versions written by syncs are tagged with the workflow ID and outcome of the run.
+type JobStateVersion struct {
+	BaseModel    `orm:"embedded"`
+	ID           int    `json:"id" orm:"column(id);pk;auto"`
+	Job          *Job   `json:"job_id" orm:"column(job_id);rel(fk);on_delete(cascade)"`
+	Version      int    `json:"version"`
+	Action       string `json:"action" orm:"size(50)"`
+	Streams      string `json:"streams" orm:"type(text);null"` // comma separated stream ids touched by the change
+	State        string `json:"state" orm:"type(jsonb)"`
+	WorkflowID   string `json:"workflow_id" orm:"column(workflow_id);size(255);null"`
+	RunStatus    string `json:"run_status" orm:"column(run_status);size(50);null"`
+	RestoredFrom int    `json:"restored_from" orm:"column(restored_from);null"`
+	CreatedBy    *User  `json:"created_by" orm:"rel(fk);null"`
+}
+
+func (v *JobStateVersion) TableName() string {
+	return constants.TableNameMap[constants.JobStateVersionTable]
+}
+
+// SystemSetting is an instance wide setting changed at runtime, e.g. whether open signup is allowed
+type SystemSetting struct {
+	BaseModel `orm:"embedded"`
+	Key       string `json:"key" orm:"column(key);pk;size(100)"`
+	Value     string `json:"value" orm:"column(value);type(text)"`
+}
+
+func (s *SystemSetting) TableName() string {
+	return constants.TableNameMap[constants.SystemSettingTable]
+}
+
+// Catalog caches connector output. Specs are kept per connector type and version, Name holds
+// the driver image for destination specs. Discovered catalogs are kept per source with an
+// expiry, Name holds a fingerprint of the discover input.
+type Catalog struct {
+	BaseModel `orm:"embedded"`
+	ID        int        `json:"id" orm:"column(id);pk;auto"`
+	Kind      string     `json:"kind" orm:"size(20);null;index"` // source_spec, destination_spec or discover
+	Type      string     `json:"type" orm:"size(50)"`
+	Name      string     `json:"name" orm:"size(100)"`
+	Specs     string     `json:"specs" orm:"type(jsonb)"`
+	Version   string     `json:"version"`
+	SourceID  int        `json:"source_id" orm:"column(source_id);null;index"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" orm:"column(expires_at);null;type(datetime)"`
 }
 
 func (c *Catalog) TableName() string {
@@ -114,3 +418,34 @@ type Session struct {
 func (s *Session) TableName() string {
 	return constants.TableNameMap[constants.SessionTable]
 }
+
+// UserInvite is an invitation to create an account, accepted through a signed token sent by email
+type UserInvite struct {
+	BaseModel  `orm:"embedded"`
+	ID         int        `json:"id" orm:"column(id);pk;auto"`
+	Email      string     `json:"email" orm:"column(email);size(100)"`
+	InvitedBy  *User      `json:"invited_by" orm:"column(invited_by_id);rel(fk);null;on_delete(set_null)"`
+	ExpiresAt  time.Time  `json:"expires_at" orm:"column(expires_at);type(datetime)"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" orm:"column(accepted_at);null;type(datetime)"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" orm:"column(revoked_at);null;type(datetime)"`
+}
+
+func (i *UserInvite) TableName() string {
+	return constants.TableNameMap[constants.UserInviteTable]
+}
+
+// UserSession tracks a login session of a user, the session data itself lives in the session table
+type UserSession struct {
+	BaseModel  `orm:"embedded"`
+	ID         int       `json:"id" orm:"column(id);pk;auto"`
+	SessionKey string    `json:"-" orm:"column(session_key);unique;size(64)"`
+	UserID     int       `json:"user_id" orm:"column(user_id);index"`
+	IPAddress  string    `json:"ip_address" orm:"column(ip_address);size(64)"`
+	UserAgent  string    `json:"user_agent" orm:"column(user_agent);size(512)"`
+	LastSeenAt time.Time `json:"last_seen_at" orm:"column(last_seen_at);type(datetime)"`
+	ExpiresAt  time.Time `json:"expires_at" orm:"column(expires_at);type(datetime)"`
+}
+
+func (s *UserSession) TableName() string {
+	return constants.TableNameMap[constants.UserSessionTable]
+}
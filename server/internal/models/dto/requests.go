@@ -15,9 +15,44 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	// Password is a temporary password the user has to change on the next login
+	Password string `json:"password" validate:"required"`
+}
+
+type UpdateAuthSettingsRequest struct {
+	SignupEnabled *bool `json:"signup_enabled" validate:"required"`
+}
+
+type CreateInviteRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	// ExpiresInHours defaults to INVITE_EXPIRY_HOURS
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+}
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type TransferOwnershipRequest struct {
+	ToUserID int `json:"to_user_id" validate:"required"`
+}
+
 type SpecRequest struct {
 	Type    string `json:"type" validate:"required"`
 	Version string `json:"version" validate:"required"`
+	Refresh bool   `json:"refresh,omitempty"` // bypass the spec cache
 }
 
 // check unique job name request
@@ -31,6 +66,8 @@ type SourceTestConnectionRequest struct {
 	Type    string `json:"type" validate:"required"`
 	Version string `json:"version" validate:"required"`
 	Config  string `json:"config" orm:"type(jsonb)" validate:"required"`
+	// SourceID fills in secrets left masked in Config from an existing source
+	SourceID *int `json:"source_id,omitempty"`
 }
 type StreamsRequest struct {
 	Name    string `json:"name" validate:"required"`
@@ -39,6 +76,9 @@ type StreamsRequest struct {
 	Config  string `json:"config" orm:"type(jsonb)" validate:"required"`
 	JobID   int    `json:"job_id" validate:"required"`
 	JobName string `json:"job_name" validate:"required"`
+	// SourceID fills in secrets left masked in Config from an existing source, defaults to the source of JobID
+	SourceID *int `json:"source_id,omitempty"`
+	Refresh  bool `json:"refresh,omitempty"` // bypass the catalog cache
 }
 
 // TODO: frontend needs to send only version no need for source version
@@ -48,13 +88,18 @@ type DestinationTestConnectionRequest struct {
 	Config        string `json:"config" validate:"required"`
 	SourceType    string `json:"source_type"`
 	SourceVersion string `json:"source_version"`
+	// DestinationID fills in secrets left masked in Config from an existing destination
+	DestinationID *int `json:"destination_id,omitempty"`
 }
 
 type CreateSourceRequest struct {
-	Name    string `json:"name" validate:"required"`
-	Type    string `json:"type" validate:"required"`
-	Version string `json:"version" validate:"required"`
-	Config  string `json:"config" orm:"type(jsonb)" validate:"required"`
+	Name    string            `json:"name" validate:"required"`
+	Type    string            `json:"type" validate:"required"`
+	Version string            `json:"version" validate:"required"`
+	Config  string            `json:"config" orm:"type(jsonb)" validate:"required"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	// MaxConcurrentSyncs caps the syncs of the jobs of the source running at once, 0 for no cap
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs,omitempty" validate:"gte=0"`
 }
 
 type UpdateSourceRequest struct {
@@ -62,13 +107,18 @@ type UpdateSourceRequest struct {
 	Type    string `json:"type" validate:"required"`
 	Version string `json:"version" validate:"required"`
 	Config  string `json:"config" orm:"type(jsonb)" validate:"required"`
+	// Labels replace the labels when set, the labels are kept when omitted
+	Labels map[string]string `json:"labels,omitempty"`
+	// MaxConcurrentSyncs replaces the cap when set, the cap is kept when omitted
+	MaxConcurrentSyncs *int `json:"max_concurrent_syncs,omitempty" validate:"omitempty,gte=0"`
 }
 
 type CreateDestinationRequest struct {
-	Name    string `json:"name" validate:"required"`
-	Type    string `json:"type" validate:"required"`
-	Version string `json:"version" validate:"required"`
-	Config  string `json:"config" orm:"type(jsonb)" validate:"required"`
+	Name    string            `json:"name" validate:"required"`
+	Type    string            `json:"type" validate:"required"`
+	Version string            `json:"version" validate:"required"`
+	Config  string            `json:"config" orm:"type(jsonb)" validate:"required"`
+	Labels  map[string]string `json:"labels,omitempty"`
 }
 
 type UpdateDestinationRequest struct {
@@ -76,15 +126,20 @@ type UpdateDestinationRequest struct {
 	Type    string `json:"type" validate:"required"`
 	Version string `json:"version" validate:"required"`
 	Config  string `json:"config" orm:"type(jsonb)" validate:"required"`
+	// Labels replace the labels when set, the labels are kept when omitted
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 type CreateJobRequest struct {
-	Name          string        `json:"name" validate:"required"`
-	Source        *DriverConfig `json:"source" validate:"required"`
-	Destination   *DriverConfig `json:"destination" validate:"required"`
-	Frequency     string        `json:"frequency" validate:"required"`
-	StreamsConfig string        `json:"streams_config" orm:"type(jsonb)" validate:"required"`
-	Activate      bool          `json:"activate,omitempty"`
+	Name          string            `json:"name" validate:"required"`
+	Source        *DriverConfig     `json:"source" validate:"required"`
+	Destination   *DriverConfig     `json:"destination" validate:"required"`
+	Frequency     string            `json:"frequency" validate:"required"`
+	StreamsConfig string            `json:"streams_config" orm:"type(jsonb)" validate:"required"`
+	Activate      bool              `json:"activate,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	CPULimit      string            `json:"cpu_limit,omitempty"`    // like "2" or "500m"
+	MemoryLimit   string            `json:"memory_limit,omitempty"` // like "4Gi" or "512Mi"
 }
 
 type UpdateJobRequest struct {
@@ -95,6 +150,146 @@ type UpdateJobRequest struct {
 	StreamsConfig     string        `json:"streams_config" orm:"type(jsonb)" validate:"required"`
 	DifferenceStreams string        `json:"difference_streams,omitempty"`
 	Activate          bool          `json:"activate,omitempty"`
+	// Labels replace the labels when set, the labels are kept when omitted
+	Labels map[string]string `json:"labels,omitempty"`
+	// CPULimit and MemoryLimit replace the limits when set, empty removes a limit and the
+	// limits are kept when omitted
+	CPULimit    *string `json:"cpu_limit,omitempty"`
+	MemoryLimit *string `json:"memory_limit,omitempty"`
+}
+
+type CloneJobRequest struct {
+	Name string `json:"name" validate:"required"`
+	// optional overrides, the cloned job keeps the value of the original otherwise
+	SourceID      *int   `json:"source_id,omitempty"`
+	DestinationID *int   `json:"destination_id,omitempty"`
+	Frequency     string `json:"frequency,omitempty"`
+}
+
+// DryRunRequest runs a job with at most RowLimit rows per stream and returns SampleSize records
+// of each. Streams limits the run to some selected streams ("namespace.stream"), StreamsConfig
+// tries a streams config that is not saved yet and Version another source connector version.
+type DryRunRequest struct {
+	RowLimit      int      `json:"row_limit" validate:"omitempty,gte=1,lte=10000"`
+	SampleSize    int      `json:"sample_size" validate:"omitempty,gte=1,lte=100"`
+	Streams       []string `json:"streams,omitempty" validate:"omitempty,dive,required"`
+	StreamsConfig string   `json:"streams_config,omitempty"`
+	Version       string   `json:"version,omitempty"`
+}
+
+// MaskingRuleRequest masks the columns matched by Column in the streams matched by Namespace
+// and Stream, all full match regular expressions where empty namespace or stream patterns
+// match anything. Without SourceID the rule applies to every source of the project.
+type MaskingRuleRequest struct {
+	SourceID  int    `json:"source_id,omitempty" validate:"omitempty,gte=1"`
+	Namespace string `json:"namespace,omitempty"`
+	Stream    string `json:"stream,omitempty"`
+	Column    string `json:"column" validate:"required"`
+	Strategy  string `json:"strategy" validate:"required,oneof=redact hash partial"`
+}
+
+// ManualSyncRequest syncs some selected streams of a job ("namespace.stream") right away.
+// FullRefresh reads them from scratch whatever their sync mode.
+type ManualSyncRequest struct {
+	Streams     []string `json:"streams" validate:"required,min=1,dive,required"`
+	FullRefresh bool     `json:"full_refresh,omitempty"`
+}
+
+// MaintenanceWindowRequest creates or updates a maintenance window. Weekly windows need Days,
+// StartTime and EndTime ("HH:MM" in Timezone, UTC when empty), once windows StartsAt and EndsAt
+// (RFC3339). DrainTimeout is in seconds, 0 uses the default.
+type MaintenanceWindowRequest struct {
+	Name         string   `json:"name" validate:"required,max=255"`
+	TargetType   string   `json:"target_type" validate:"required,oneof=project source destination"`
+	TargetID     int      `json:"target_id,omitempty" validate:"omitempty,gte=1"`
+	Recurrence   string   `json:"recurrence" validate:"required,oneof=weekly once"`
+	Days         []string `json:"days,omitempty" validate:"omitempty,dive,oneof=sun mon tue wed thu fri sat"`
+	StartTime    string   `json:"start_time,omitempty"`
+	EndTime      string   `json:"end_time,omitempty"`
+	Timezone     string   `json:"timezone,omitempty"`
+	StartsAt     string   `json:"starts_at,omitempty"`
+	EndsAt       string   `json:"ends_at,omitempty"`
+	Drain        bool     `json:"drain,omitempty"`
+	DrainTimeout int      `json:"drain_timeout,omitempty" validate:"omitempty,gte=1"`
+	Enabled      *bool    `json:"enabled,omitempty"`
+}
+
+// BulkJobRequest runs an action on many jobs of a project, given by JobIDs or by a Filter
+type BulkJobRequest struct {
+	Action string         `json:"action" validate:"required,oneof=pause resume trigger cancel delete"`
+	JobIDs []int          `json:"job_ids,omitempty" validate:"omitempty,dive,gte=1"`
+	Filter *BulkJobFilter `json:"filter,omitempty"`
+}
+
+// BulkJobFilter selects the jobs of a project reading from a source, writing to a destination
+// and carrying all the given labels. Set criteria must all match.
+type BulkJobFilter struct {
+	SourceID      int               `json:"source_id,omitempty" validate:"omitempty,gte=1"`
+	DestinationID int               `json:"destination_id,omitempty" validate:"omitempty,gte=1"`
+	Labels        map[string]string `json:"labels,omitempty"`
+}
+
+// LabelsRequest replaces the labels of a source, destination or job, an empty object removes
+// them all
+type LabelsRequest struct {
+	Labels map[string]string `json:"labels" validate:"required"`
+}
+
+// StreamSettings are the per stream settings a selection rule applies, unset fields keep the
+// value from discovery
+type StreamSettings struct {
+	Normalization  *bool   `json:"normalization,omitempty"`
+	PartitionRegex *string `json:"partition_regex,omitempty"`
+	AppendMode     *bool   `json:"append_mode,omitempty"`
+	SyncMode       string  `json:"sync_mode,omitempty" validate:"omitempty,oneof=full_refresh incremental cdc strict_cdc"`
+}
+
+// StreamSelectionRule matches streams by namespace and stream name, both full match patterns
+// where empty matches anything. PatternType is "regex" (default) or "glob".
+type StreamSelectionRule struct {
+	Namespace      string `json:"namespace,omitempty"`
+	Stream         string `json:"stream,omitempty"`
+	PatternType    string `json:"pattern_type,omitempty"`
+	Exclude        bool   `json:"exclude,omitempty"`
+	StreamSettings `validate:"-"`
+}
+
+// StreamSelectionRules select the streams matched by an include rule and by no exclude rule.
+// A stream gets Defaults overlaid with the settings of the first include rule it matches.
+type StreamSelectionRules struct {
+	Rules    []StreamSelectionRule `json:"rules" validate:"required,min=1"`
+	Defaults StreamSettings        `json:"defaults"`
+}
+
+type JobTemplateRequest struct {
+	Name        string                `json:"name" validate:"required"`
+	Description string                `json:"description,omitempty"`
+	SourceType  string                `json:"source_type,omitempty"`
+	Frequency   string                `json:"frequency,omitempty"`
+	Rules       *StreamSelectionRules `json:"rules" validate:"required"`
+}
+
+type ApplyJobTemplateRequest struct {
+	SourceID int `json:"source_id" validate:"required"`
+	// Create makes the job, otherwise only the resulting streams config is returned
+	Create        bool   `json:"create,omitempty"`
+	Name          string `json:"name,omitempty"`
+	DestinationID *int   `json:"destination_id,omitempty"`
+	Frequency     string `json:"frequency,omitempty"`
+}
+
+// JobStreamRulesRequest sets the stream selection rules of a job. With AutoInclude, streams
+// newly matched on re-discovery are added to the job right away, other changes always wait
+// for review.
+type JobStreamRulesRequest struct {
+	StreamSelectionRules
+	AutoInclude bool `json:"auto_include"`
+}
+
+// DriftPolicyRequest sets what a job does when a schema check finds drift, an empty policy
+// turns schema checks off
+type DriftPolicyRequest struct {
+	Policy string `json:"policy" validate:"omitempty,oneof=notify auto_accept pause"`
 }
 
 type StreamDifferenceRequest struct {
@@ -113,6 +308,17 @@ type UpsertProjectSettingsRequest struct {
 	ID              int    `json:"id"`
 	ProjectID       string `json:"project_id" validate:"required"`
 	WebhookAlertURL string `json:"webhook_alert_url"`
+	// AlertRoutes replace the alert routes when set, the routes are kept when omitted
+	AlertRoutes *[]AlertRoute `json:"alert_routes,omitempty" validate:"omitempty,dive"`
+	// MaxConcurrentSyncs replaces the project cap when set, 0 for no cap, the cap is kept when
+	// omitted
+	MaxConcurrentSyncs *int `json:"max_concurrent_syncs,omitempty" validate:"omitempty,gte=0"`
+}
+
+// AlertRoute sends the alerts of jobs carrying all of Labels to WebhookURL
+type AlertRoute struct {
+	Labels     map[string]string `json:"labels" validate:"required,min=1"`
+	WebhookURL string            `json:"webhook_url" validate:"required,url"`
 }
 
 type UpdateSyncTelemetryRequest struct {
@@ -122,6 +328,76 @@ type UpdateSyncTelemetryRequest struct {
 	Environment string `json:"environment"`
 }
 
+// SyncAdmissionRequest is sent by the worker before a sync starts, the sync waits until it is
+// admitted
+type SyncAdmissionRequest struct {
+	ProjectID  string `json:"project_id" validate:"required"`
+	JobID      int    `json:"job_id" validate:"required,gte=1"`
+	WorkflowID string `json:"workflow_id" validate:"required"`
+}
+
+// SyncReleaseRequest is sent by the worker when an admitted sync ends
+type SyncReleaseRequest struct {
+	WorkflowID string `json:"workflow_id" validate:"required"`
+}
+
 type UpdateStateFileRequest struct {
 	StateFile string `json:"state_file" validate:"required"`
+	// WorkflowID and Status tag the state snapshot with the run that produced it
+	WorkflowID string `json:"workflow_id"`
+	Status     string `json:"status"`
+}
+
+// UpdateJobStateRequest replaces the whole state of a job with an edited one
+type UpdateJobStateRequest struct {
+	State string `json:"state" validate:"required"`
+}
+
+// RestoreJobStateRequest moves a job back to a past state version, or to the state
+// left by a past run when only the workflow ID is given
+type RestoreJobStateRequest struct {
+	Version    int    `json:"version" validate:"required_without=WorkflowID,gte=0"`
+	WorkflowID string `json:"workflow_id"`
+}
+
+// ResetJobStateRequest drops the state of the given streams ("namespace.stream") so they sync from scratch
+type ResetJobStateRequest struct {
+	Streams []string `json:"streams" validate:"required,min=1,dive,required"`
+}
+
+// UpdateConnectorRequest changes the registry settings of a connector, fields left out are kept.
+// Features maps a feature to the minimum connector version that supports it.
+type UpdateConnectorRequest struct {
+	Image           *string           `json:"image,omitempty" validate:"omitempty,min=1"`
+	Position        *int              `json:"position,omitempty"`
+	Deprecated      *bool             `json:"deprecated,omitempty"`
+	DeprecationNote *string           `json:"deprecation_note,omitempty"`
+	AutoAllow       *bool             `json:"auto_allow,omitempty"`
+	Features        map[string]string `json:"features,omitempty"`
+}
+
+// ConnectorVersionRequest allows, disallows or deprecates a connector version
+type ConnectorVersionRequest struct {
+	Version    string `json:"version" validate:"required"`
+	Allowed    *bool  `json:"allowed" validate:"required"`
+	Deprecated bool   `json:"deprecated"`
+}
+
+// ConnectorUpgradeRequest moves the sources of a connector type from one version to another.
+// SourceIDs limits the upgrade to some of those sources. Force also upgrades sources with
+// breaking changes, and the rollout stops once more than MaxFailures sources failed.
+type ConnectorUpgradeRequest struct {
+	SourceType  string `json:"source_type" validate:"required"`
+	FromVersion string `json:"from_version" validate:"required"`
+	ToVersion   string `json:"to_version" validate:"required"`
+	SourceIDs   []int  `json:"source_ids,omitempty"`
+	Force       bool   `json:"force"`
+	MaxFailures int    `json:"max_failures" validate:"gte=0"`
+}
+
+// ConnectorUpgradeRollbackRequest selects the upgraded sources to move back, by source or by
+// the jobs using them. Leaving both empty rolls back every upgraded source.
+type ConnectorUpgradeRollbackRequest struct {
+	SourceIDs []int `json:"source_ids,omitempty"`
+	JobIDs    []int `json:"job_ids,omitempty"`
 }
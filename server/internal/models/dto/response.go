@@ -10,6 +10,11 @@ type SpecResponse struct {
 	Version string      `json:"version"`
 	Type    string      `json:"type"`
 	Spec    interface{} `json:"spec" orm:"type(jsonb)"`
+	Cached  bool        `json:"cached"`
+}
+
+type CacheInvalidationResponse struct {
+	Removed int64 `json:"removed"`
 }
 type SpecOutput struct {
 	Spec map[string]interface{} `json:"spec"`
@@ -48,20 +53,219 @@ type ClearDestinationStatusResponse struct {
 
 // Job response
 type JobResponse struct {
-	ID            int          `json:"id"`
-	Name          string       `json:"name"`
-	Source        DriverConfig `json:"source"`
-	Destination   DriverConfig `json:"destination"`
-	StreamsConfig string       `json:"streams_config,omitempty"`
-	Frequency     string       `json:"frequency"`
-	LastRunTime   string       `json:"last_run_time,omitempty"`
-	LastRunState  string       `json:"last_run_state,omitempty"`
-	LastRunType   string       `json:"last_run_type,omitempty"` // "sync" | "clear-destination"
-	CreatedAt     string       `json:"created_at"`
-	UpdatedAt     string       `json:"updated_at"`
-	Activate      bool         `json:"activate"`
-	CreatedBy     string       `json:"created_by,omitempty"`
-	UpdatedBy     string       `json:"updated_by,omitempty"`
+	ID            int               `json:"id"`
+	Name          string            `json:"name"`
+	Source        DriverConfig      `json:"source"`
+	Destination   DriverConfig      `json:"destination"`
+	StreamsConfig string            `json:"streams_config,omitempty"`
+	Frequency     string            `json:"frequency"`
+	LastRunTime   string            `json:"last_run_time,omitempty"`
+	LastRunState  string            `json:"last_run_state,omitempty"`
+	LastRunType   string            `json:"last_run_type,omitempty"` // "sync" | "clear-destination"
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+	Activate      bool              `json:"activate"`
+	CreatedBy     string            `json:"created_by,omitempty"`
+	UpdatedBy     string            `json:"updated_by,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	CPULimit      string            `json:"cpu_limit,omitempty"`
+	MemoryLimit   string            `json:"memory_limit,omitempty"`
+}
+
+type JobTemplateResponse struct {
+	ID          int                   `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	SourceType  string                `json:"source_type,omitempty"`
+	Frequency   string                `json:"frequency,omitempty"`
+	Rules       *StreamSelectionRules `json:"rules"`
+	CreatedAt   string                `json:"created_at"`
+	UpdatedAt   string                `json:"updated_at"`
+	CreatedBy   string                `json:"created_by,omitempty"`
+	UpdatedBy   string                `json:"updated_by,omitempty"`
+}
+
+type ApplyJobTemplateResponse struct {
+	StreamsConfig   string   `json:"streams_config"`
+	SelectedStreams []string `json:"selected_streams"` // namespace.stream
+	SkippedStreams  int      `json:"skipped_streams"`
+	JobID           int      `json:"job_id,omitempty"` // set when the job was created
+}
+
+type JobStreamRulesResponse struct {
+	JobID         int                   `json:"job_id"`
+	Rules         *StreamSelectionRules `json:"rules"`
+	AutoInclude   bool                  `json:"auto_include"`
+	LastCheckedAt string                `json:"last_checked_at,omitempty"`
+	LastError     string                `json:"last_error,omitempty"`
+	UpdatedAt     string                `json:"updated_at"`
+	UpdatedBy     string                `json:"updated_by,omitempty"`
+}
+
+type StreamReviewResponse struct {
+	ID            int      `json:"id"`
+	JobID         int      `json:"job_id"`
+	JobName       string   `json:"job_name"`
+	Status        string   `json:"status"`
+	Added         []string `json:"added"`   // namespace.stream
+	Removed       []string `json:"removed"` // namespace.stream
+	StreamsConfig string   `json:"streams_config,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	ResolvedAt    string   `json:"resolved_at,omitempty"`
+	ResolvedBy    string   `json:"resolved_by,omitempty"`
+}
+
+// RediscoverStreamsResponse is the outcome of applying the stream selection rules of a job
+type RediscoverStreamsResponse struct {
+	Changed  bool     `json:"changed"`
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Applied  bool     `json:"applied"`             // the change was applied without review
+	ReviewID int      `json:"review_id,omitempty"` // the review holding the change
+}
+
+// SchemaChange is one difference between the schema a job syncs and the source schema
+type SchemaChange struct {
+	Change  string `json:"change"` // table_added, table_dropped, column_added, column_dropped or column_retyped
+	Stream  string `json:"stream"` // namespace.stream
+	Column  string `json:"column,omitempty"`
+	OldType string `json:"old_type,omitempty"`
+	NewType string `json:"new_type,omitempty"`
+}
+
+type SchemaDriftResponse struct {
+	ID            int            `json:"id"`
+	JobID         int            `json:"job_id"`
+	JobName       string         `json:"job_name"`
+	Status        string         `json:"status"`
+	Action        string         `json:"action"`
+	Changes       []SchemaChange `json:"changes"`
+	StreamsConfig string         `json:"streams_config,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	ResolvedAt    string         `json:"resolved_at,omitempty"`
+	ResolvedBy    string         `json:"resolved_by,omitempty"`
+}
+
+type DriftPolicyResponse struct {
+	JobID  int    `json:"job_id"`
+	Policy string `json:"policy"`
+}
+
+// SchemaCheckResponse is the outcome of a schema check of a job
+type SchemaCheckResponse struct {
+	Drifted bool           `json:"drifted"`
+	Changes []SchemaChange `json:"changes"`
+	Action  string         `json:"action,omitempty"`   // what the job's policy did
+	DriftID int            `json:"drift_id,omitempty"` // the drift holding the changes
+}
+
+// DryRunStream is what a dry run read from one stream. Schema holds the column types of the
+// catalog and InferredSchema the JSON types found in the records read.
+type DryRunStream struct {
+	Stream         string                   `json:"stream"`
+	SyncMode       string                   `json:"sync_mode"` // the stream's sync mode, dry runs always read a full refresh
+	Count          int                      `json:"count"`
+	LimitReached   bool                     `json:"limit_reached"`
+	Schema         map[string]string        `json:"schema"`
+	InferredSchema map[string]string        `json:"inferred_schema"`
+	Samples        []map[string]interface{} `json:"samples"`
+	MaskedColumns  []string                 `json:"masked_columns"` // sample columns masked by the project's masking rules
+}
+
+type DryRunResponse struct {
+	JobID    int            `json:"job_id"`
+	Version  string         `json:"version"`
+	RowLimit int            `json:"row_limit"`
+	Streams  []DryRunStream `json:"streams"`
+}
+
+type MaskingRuleResponse struct {
+	ID        int    `json:"id"`
+	SourceID  int    `json:"source_id,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Stream    string `json:"stream,omitempty"`
+	Column    string `json:"column"`
+	Strategy  string `json:"strategy"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	CreatedBy string `json:"created_by,omitempty"`
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+// MaintenanceWindowResponse is a maintenance window. Active tells whether it is in effect now
+// and PausedJobs the jobs it paused while it is.
+type MaintenanceWindowResponse struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	TargetType   string   `json:"target_type"`
+	TargetID     int      `json:"target_id,omitempty"`
+	Recurrence   string   `json:"recurrence"`
+	Days         []string `json:"days,omitempty"`
+	StartTime    string   `json:"start_time,omitempty"`
+	EndTime      string   `json:"end_time,omitempty"`
+	Timezone     string   `json:"timezone"`
+	StartsAt     string   `json:"starts_at,omitempty"`
+	EndsAt       string   `json:"ends_at,omitempty"`
+	Drain        bool     `json:"drain"`
+	DrainTimeout int      `json:"drain_timeout"`
+	Enabled      bool     `json:"enabled"`
+	Active       bool     `json:"active"`
+	ActiveSince  string   `json:"active_since,omitempty"`
+	PausedJobs   []int    `json:"paused_jobs"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+	CreatedBy    string   `json:"created_by,omitempty"`
+	UpdatedBy    string   `json:"updated_by,omitempty"`
+}
+
+type MaintenanceEventResponse struct {
+	ID         int    `json:"id"`
+	WindowID   int    `json:"window_id"`
+	WindowName string `json:"window_name"`
+	JobID      int    `json:"job_id,omitempty"`
+	Action     string `json:"action"`
+	Message    string `json:"message,omitempty"`
+	User       string `json:"user,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// StreamPreviewResponse holds sample rows of a source stream. Columns are the column types of
+// the discovered catalog and MaskedColumns the columns masked by the project's masking rules.
+type StreamPreviewResponse struct {
+	SourceID      int                      `json:"source_id"`
+	Stream        string                   `json:"stream"`
+	Limit         int                      `json:"limit"`
+	Columns       map[string]string        `json:"columns"`
+	MaskedColumns []string                 `json:"masked_columns"`
+	Rows          []map[string]interface{} `json:"rows"`
+	FetchedAt     string                   `json:"fetched_at"`
+	Cached        bool                     `json:"cached"`
+}
+
+type ManualSyncResponse struct {
+	JobID       int      `json:"job_id"`
+	WorkflowID  string   `json:"workflow_id"`
+	Streams     []string `json:"streams"`
+	FullRefresh bool     `json:"full_refresh"`
+}
+
+type BulkJobResponse struct {
+	Action    string          `json:"action"`
+	Results   []BulkJobResult `json:"results"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+}
+
+// BulkJobResult is the outcome of a bulk action on one job, Error is set when it failed
+type BulkJobResult struct {
+	JobID   int    `json:"job_id"`
+	JobName string `json:"job_name,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type CloneJobResponse struct {
+	JobID int `json:"job_id"`
 }
 
 type JobTask struct {
@@ -70,32 +274,38 @@ type JobTask struct {
 	Status    string `json:"status"`
 	FilePath  string `json:"file_path"`
 	JobType   string `json:"job_type"` // "sync" | "clear-destination"
+	// Labels are the labels the job had when the run started
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 type SourceDataItem struct {
-	ID        int           `json:"id"`
-	Name      string        `json:"name"`
-	Type      string        `json:"type"`
-	Version   string        `json:"version"`
-	Config    string        `json:"config"`
-	CreatedAt string        `json:"created_at"`
-	UpdatedAt string        `json:"updated_at"`
-	CreatedBy string        `json:"created_by"`
-	UpdatedBy string        `json:"updated_by"`
-	Jobs      []JobDataItem `json:"jobs"`
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Config    string            `json:"config"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+	CreatedBy string            `json:"created_by"`
+	UpdatedBy string            `json:"updated_by"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Jobs      []JobDataItem     `json:"jobs"`
+	// MaxConcurrentSyncs caps the syncs of the jobs of the source running at once, 0 for no cap
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs"`
 }
 
 type DestinationDataItem struct {
-	ID        int           `json:"id"`
-	Name      string        `json:"name"`
-	Type      string        `json:"type"`
-	Version   string        `json:"version"`
-	Config    string        `json:"config"`
-	CreatedAt string        `json:"created_at"`
-	UpdatedAt string        `json:"updated_at"`
-	CreatedBy string        `json:"created_by"`
-	UpdatedBy string        `json:"updated_by"`
-	Jobs      []JobDataItem `json:"jobs"`
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Config    string            `json:"config"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+	CreatedBy string            `json:"created_by"`
+	UpdatedBy string            `json:"updated_by"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Jobs      []JobDataItem     `json:"jobs"`
 }
 
 type JobDataItem struct {
@@ -116,10 +326,259 @@ type TaskLogsResponse struct {
 	NewerCursor  int64                    `json:"newer_cursor"`
 	HasMoreOlder bool                     `json:"has_more_older"`
 	HasMoreNewer bool                     `json:"has_more_newer"`
+	Attempt      string                   `json:"attempt,omitempty"`  // sync_* folder the logs were read from
+	Attempts     []string                 `json:"attempts,omitempty"` // all sync_* folders of the run, oldest first
+	LogType      string                   `json:"log_type,omitempty"` // "sync" | "activity"
+}
+
+type AuthSettingsResponse struct {
+	SignupEnabled bool `json:"signup_enabled"`
+}
+
+type InviteResponse struct {
+	ID         int    `json:"id"`
+	Email      string `json:"email"`
+	Status     string `json:"status"` // pending | accepted | revoked | expired
+	InvitedBy  string `json:"invited_by,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at"`
+	AcceptedAt string `json:"accepted_at,omitempty"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+	// set only when the invite is created
+	InviteURL  string `json:"invite_url,omitempty"`
+	EmailSent  bool   `json:"email_sent"`
+	EmailError string `json:"email_error,omitempty"`
+}
+
+type InviteInfoResponse struct {
+	Email     string `json:"email"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type OwnershipTransferResponse struct {
+	Sources            int64 `json:"sources"`
+	Destinations       int64 `json:"destinations"`
+	Jobs               int64 `json:"jobs"`
+	StateVersions      int64 `json:"state_versions"`
+	MaskingRules       int64 `json:"masking_rules"`
+	MaintenanceWindows int64 `json:"maintenance_windows"`
+}
+
+type UserSessionResponse struct {
+	ID         int    `json:"id"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"`
 }
 
 type ProjectSettingsResponse struct {
-	ID              int    `json:"id"`
-	ProjectID       string `json:"project_id"`
-	WebhookAlertURL string `json:"webhook_alert_url"`
+	ID              int          `json:"id"`
+	ProjectID       string       `json:"project_id"`
+	WebhookAlertURL string       `json:"webhook_alert_url"`
+	AlertRoutes     []AlertRoute `json:"alert_routes"`
+	// MaxConcurrentSyncs caps the syncs running at once in the project, 0 for no cap
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs"`
+}
+
+// SyncAdmissionResponse tells the worker whether a sync may start. A queued sync asks again
+// after RetryAfter seconds.
+type SyncAdmissionResponse struct {
+	Admitted   bool   `json:"admitted"`
+	Position   int    `json:"position,omitempty"` // 1 for the next sync of the project to start
+	RetryAfter int    `json:"retry_after,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// SyncQueueResponse holds the running and queued syncs of a project, running syncs first and
+// queued syncs in the order they start
+type SyncQueueResponse struct {
+	MaxConcurrentSyncs int            `json:"max_concurrent_syncs"`
+	Running            int            `json:"running"`
+	Queued             int            `json:"queued"`
+	Syncs              []SyncSlotItem `json:"syncs"`
+}
+
+type SyncSlotItem struct {
+	JobID      int    `json:"job_id"`
+	JobName    string `json:"job_name"`
+	SourceID   int    `json:"source_id"`
+	SourceName string `json:"source_name"`
+	WorkflowID string `json:"workflow_id"`
+	Status     string `json:"status"`
+	Position   int    `json:"position,omitempty"`
+	Reason     string `json:"reason,omitempty"` // why a queued sync waits
+	EnqueuedAt string `json:"enqueued_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+}
+
+type LogCleanupResponse struct {
+	Deleted    []string `json:"deleted"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+type LogKindUsage struct {
+	Kind        string `json:"kind"`
+	Directories int    `json:"directories"`
+	Bytes       int64  `json:"bytes"`
+}
+
+type LogJobUsage struct {
+	JobID   int    `json:"job_id"`
+	JobName string `json:"job_name"`
+	Runs    int    `json:"runs"`
+	Bytes   int64  `json:"bytes"`
+}
+
+type LogDiskUsageResponse struct {
+	ConfigDir     string         `json:"config_dir"`
+	TotalBytes    int64          `json:"total_bytes"`
+	KeepRuns      int            `json:"keep_runs"`
+	RetentionDays map[string]int `json:"retention_days"`
+	Kinds         []LogKindUsage `json:"kinds"`
+	Jobs          []LogJobUsage  `json:"jobs"`
+}
+
+// StreamStateResponse is the state of one stream, State is pretty-printed JSON
+type StreamStateResponse struct {
+	Stream    string `json:"stream"`
+	Namespace string `json:"namespace,omitempty"`
+	SyncMode  string `json:"sync_mode,omitempty"`
+	State     string `json:"state,omitempty"`
+	// Global is set for streams tracked by the shared (CDC) global state
+	Global bool `json:"global"`
+}
+
+// GlobalStateResponse is the shared state of CDC streams, State is pretty-printed JSON
+type GlobalStateResponse struct {
+	State   string   `json:"state"`
+	Streams []string `json:"streams"`
+}
+
+type JobStateResponse struct {
+	JobID   int                   `json:"job_id"`
+	Version int                   `json:"version"`
+	Type    string                `json:"type,omitempty"`
+	Global  *GlobalStateResponse  `json:"global,omitempty"`
+	Streams []StreamStateResponse `json:"streams"`
+	State   string                `json:"state"`
+}
+
+type JobStateVersionResponse struct {
+	Version      int      `json:"version"`
+	Action       string   `json:"action"`
+	Streams      []string `json:"streams,omitempty"`
+	WorkflowID   string   `json:"workflow_id,omitempty"`
+	RunStatus    string   `json:"run_status,omitempty"`
+	RestoredFrom int      `json:"restored_from,omitempty"`
+	CreatedBy    string   `json:"created_by,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+	State        string   `json:"state,omitempty"`
+}
+
+// ReEncryptionFailure is a row that could not be re-encrypted
+type ReEncryptionFailure struct {
+	Table string `json:"table"`
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+}
+
+// ReEncryptionStatusResponse is the progress of the latest config re-encryption run
+type ReEncryptionStatusResponse struct {
+	Running     bool                  `json:"running"`
+	KeyID       string                `json:"key_id"`
+	Total       int                   `json:"total"`
+	Processed   int                   `json:"processed"`
+	ReEncrypted int                   `json:"re_encrypted"`
+	Skipped     int                   `json:"skipped"`
+	Failed      []ReEncryptionFailure `json:"failed"`
+	StartedAt   string                `json:"started_at,omitempty"`
+	FinishedAt  string                `json:"finished_at,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+type ConnectorVersionItem struct {
+	Version    string `json:"version"`
+	Allowed    bool   `json:"allowed"`
+	Deprecated bool   `json:"deprecated"`
+	Origin     string `json:"origin"`
+}
+
+type ConnectorResponse struct {
+	Type            string                 `json:"type"`
+	Image           string                 `json:"image"`
+	Position        int                    `json:"position"`
+	Deprecated      bool                   `json:"deprecated"`
+	DeprecationNote string                 `json:"deprecation_note,omitempty"`
+	AutoAllow       bool                   `json:"auto_allow"`
+	Features        map[string]string      `json:"features"`
+	RefreshedAt     string                 `json:"refreshed_at,omitempty"`
+	RefreshError    string                 `json:"refresh_error,omitempty"`
+	Versions        []ConnectorVersionItem `json:"versions"`
+}
+
+// SpecChange is one difference between the specs of two connector versions
+type SpecChange struct {
+	Change   string `json:"change"` // field_added, field_removed, field_retyped or field_required
+	Field    string `json:"field"`  // dot separated path, "[]" for array items
+	OldType  string `json:"old_type,omitempty"`
+	NewType  string `json:"new_type,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+// UpgradeJobCheck is how a job's catalog changes with a new connector version. Changes only
+// holds the differences caused by the version, not the ones the old version finds as well.
+type UpgradeJobCheck struct {
+	JobID                int            `json:"job_id"`
+	JobName              string         `json:"job_name"`
+	Changes              []SchemaChange `json:"changes"`
+	UnsupportedSyncModes []string       `json:"unsupported_sync_modes,omitempty"` // selected streams whose sync mode the new version lacks
+	Breaking             bool           `json:"breaking"`
+	Error                string         `json:"error,omitempty"`
+}
+
+// UpgradeSourceCheck is the outcome of checking a source with a new connector version
+type UpgradeSourceCheck struct {
+	SourceID          int               `json:"source_id"`
+	SourceName        string            `json:"source_name"`
+	ConnectionStatus  string            `json:"connection_status"`
+	ConnectionMessage string            `json:"connection_message,omitempty"`
+	Jobs              []UpgradeJobCheck `json:"jobs"`
+	Breaking          bool              `json:"breaking"`
+	Error             string            `json:"error,omitempty"`
+}
+
+type ConnectorUpgradeCheckResponse struct {
+	SourceType  string               `json:"source_type"`
+	FromVersion string               `json:"from_version"`
+	ToVersion   string               `json:"to_version"`
+	SpecChanges []SpecChange         `json:"spec_changes"`
+	Breaking    bool                 `json:"breaking"`
+	Sources     []UpgradeSourceCheck `json:"sources"`
+}
+
+type ConnectorUpgradeItemResponse struct {
+	SourceID   int                 `json:"source_id"`
+	SourceName string              `json:"source_name"`
+	Status     string              `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	Check      *UpgradeSourceCheck `json:"check,omitempty"`
+	UpdatedAt  string              `json:"updated_at"`
+}
+
+type ConnectorUpgradeResponse struct {
+	ID          int                            `json:"id"`
+	SourceType  string                         `json:"source_type"`
+	FromVersion string                         `json:"from_version"`
+	ToVersion   string                         `json:"to_version"`
+	Status      string                         `json:"status"`
+	Force       bool                           `json:"force"`
+	MaxFailures int                            `json:"max_failures"`
+	SpecChanges []SpecChange                   `json:"spec_changes"`
+	Items       []ConnectorUpgradeItemResponse `json:"items"`
+	CreatedBy   string                         `json:"created_by,omitempty"`
+	CreatedAt   string                         `json:"created_at"`
+	FinishedAt  string                         `json:"finished_at,omitempty"`
 }
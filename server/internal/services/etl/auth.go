@@ -3,26 +3,67 @@ package services
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
 	"github.com/datazip-inc/olake-ui/server/utils/telemetry"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Auth-related methods on AppService
 
+// settingSignupEnabled is the system setting overriding SIGNUP_ENABLED
+const settingSignupEnabled = "signup_enabled"
+
+// bcryptMaxPasswordBytes is the longest password bcrypt can hash
+const bcryptMaxPasswordBytes = 72
+
 func (s *ETLService) Login(ctx context.Context, username, password string) (*models.User, error) {
 	user, err := s.db.GetUserByUsername(username)
 	if err != nil {
 		if strings.Contains(err.Error(), "no row found") {
-			return nil, fmt.Errorf("user not found: %s", err)
+			return nil, fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
 		}
 		return nil, fmt.Errorf("failed to get user: %s", err)
 	}
 
+	// a locked account fails like a wrong password, whatever the password, so the response
+	// reveals neither the lock nor whether a guess during the lock was right
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		_ = s.db.CompareUserPassword(user.Password, password)
+		logger.Warnf("login of locked user rejected user_id[%d] until[%s]", user.ID, user.LockedUntil.UTC().Format(time.RFC3339))
+		return nil, constants.ErrInvalidCredentials
+	}
+
 	if err := s.db.CompareUserPassword(user.Password, password); err != nil {
-		return nil, fmt.Errorf("invalid credentials: %s", err)
+		maxAttempts := web.AppConfig.DefaultInt(constants.ConfLoginMaxFailedAttempts, constants.DefaultLoginMaxFailedAttempts)
+		lockout := time.Duration(web.AppConfig.DefaultInt(constants.ConfLoginLockoutMinutes, constants.DefaultLoginLockoutMinutes)) * time.Minute
+		lockedUntil, recordErr := s.db.RecordFailedLogin(user.ID, maxAttempts, lockout)
+		if recordErr != nil {
+			logger.Errorf("failed to record failed login user_id[%d]: %s", user.ID, recordErr)
+		}
+		if lockedUntil != nil {
+			logger.Warnf("user locked after %d failed logins user_id[%d] until[%s]", maxAttempts, user.ID, lockedUntil.Format(time.RFC3339))
+		}
+		return nil, constants.ErrInvalidCredentials
+	}
+
+	// checked only after the password so the state of an account is not revealed to others
+	if user.Disabled {
+		return nil, constants.ErrUserDisabled
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.db.ResetFailedLogins(user.ID); err != nil {
+			logger.Errorf("failed to reset failed logins user_id[%d]: %s", user.ID, err)
+		}
 	}
 
 	telemetry.TrackUserLogin(ctx, user)
@@ -30,20 +71,137 @@ func (s *ETLService) Login(ctx context.Context, username, password string) (*mod
 	return user, nil
 }
 
-func (s *ETLService) Signup(_ context.Context, user *models.User) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+func (s *ETLService) Signup(ctx context.Context, user *models.User) error {
+	enabled, err := s.signupAllowed()
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %s", err)
+		return err
+	}
+	if !enabled {
+		return constants.ErrSignupDisabled
 	}
-	user.Password = string(hashedPassword)
+
+	hashedPassword, err := hashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	// signup only creates regular accounts, the flags are managed by admins
+	user.MustChangePassword = false
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	user.Disabled = false
+	user.EmailVerified = false
 
 	if err := s.db.CreateUser(user); err != nil {
-		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
-			return fmt.Errorf("user already exists: %s", err)
+		if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
+			return fmt.Errorf("%w: %s", constants.ErrUserAlreadyExists, err)
 		}
 		return fmt.Errorf("failed to create user: %s", err)
 	}
 
+	if utils.EmailEnabled() {
+		// the signup does not wait for the mail server
+		go func(userID int) {
+			if err := s.SendEmailVerification(context.WithoutCancel(ctx), userID); err != nil {
+				logger.Warnf("failed to send verification email user_id[%d]: %s", userID, err)
+			}
+		}(user.ID)
+	}
+
+	return nil
+}
+
+// signupAllowed reports whether open signup is allowed. The first user can always sign up,
+// afterwards the admin setting applies and falls back to SIGNUP_ENABLED.
+func (s *ETLService) signupAllowed() (bool, error) {
+	count, err := s.db.CountUsers()
+	if err != nil {
+		return false, fmt.Errorf("failed to count users: %s", err)
+	}
+	if count == 0 {
+		return true, nil
+	}
+	return s.SignupEnabled()
+}
+
+// SignupEnabled returns the signup setting, ignoring that the first user can always sign up
+func (s *ETLService) SignupEnabled() (bool, error) {
+	value, found, err := s.db.GetSystemSetting(settingSignupEnabled)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return web.AppConfig.DefaultBool(constants.ConfSignupEnabled, constants.DefaultSignupEnabled), nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s setting '%s': %s", settingSignupEnabled, value, err)
+	}
+	return enabled, nil
+}
+
+// SetSignupEnabled turns open signup on or off, overriding SIGNUP_ENABLED
+func (s *ETLService) SetSignupEnabled(_ context.Context, enabled bool) error {
+	if err := s.db.SetSystemSetting(settingSignupEnabled, strconv.FormatBool(enabled)); err != nil {
+		return fmt.Errorf("failed to update signup setting: %s", err)
+	}
+	return nil
+}
+
+// ChangePassword changes the password of a user after checking the current one, it also
+// clears a pending forced password change
+func (s *ETLService) ChangePassword(_ context.Context, userID int, currentPassword, newPassword string) error {
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %s", err)
+	}
+
+	if err := s.db.CompareUserPassword(user.Password, currentPassword); err != nil {
+		return fmt.Errorf("%w: current password is incorrect", constants.ErrInvalidCredentials)
+	}
+	if currentPassword == newPassword {
+		return fmt.Errorf("%w: new password must differ from the current password", constants.ErrWeakPassword)
+	}
+
+	hashedPassword, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.db.UpdateUserPassword(userID, hashedPassword, false); err != nil {
+		return fmt.Errorf("failed to update password: %s", err)
+	}
+	return nil
+}
+
+// ResetUserPassword sets a temporary password for a user, who has to change it on the next login.
+// All sessions of the user end.
+func (s *ETLService) ResetUserPassword(ctx context.Context, userID int, temporaryPassword string) error {
+	if _, err := s.db.GetUserByID(userID); err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+
+	hashedPassword, err := hashPassword(temporaryPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.db.UpdateUserPassword(userID, hashedPassword, true); err != nil {
+		return fmt.Errorf("failed to reset password: %s", err)
+	}
+	// whoever knew the old password is logged out
+	if err := s.RevokeUserSessions(ctx, userID, ""); err != nil {
+		return fmt.Errorf("password reset but %s", err)
+	}
+	return nil
+}
+
+// UnlockUser clears the failed login counter and lock of a user
+func (s *ETLService) UnlockUser(_ context.Context, userID int) error {
+	if _, err := s.db.GetUserByID(userID); err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+	if err := s.db.ResetFailedLogins(userID); err != nil {
+		return fmt.Errorf("failed to unlock user: %s", err)
+	}
 	return nil
 }
 
@@ -56,9 +214,72 @@ func (s *ETLService) GetUserByID(userID int) (*models.User, error) {
 }
 
 func (s *ETLService) ValidateUser(userID int) error {
-	_, err := s.db.GetUserByID(userID)
+	user, err := s.db.GetUserByID(userID)
 	if err != nil {
 		return fmt.Errorf("failed to validate user: %s", err)
 	}
+	if user.Disabled {
+		return constants.ErrUserDisabled
+	}
 	return nil
 }
+
+// validatePassword checks a password against the configured password policy
+func validatePassword(password string) error {
+	var violations []string
+
+	minLength := web.AppConfig.DefaultInt(constants.ConfPasswordMinLength, constants.DefaultPasswordMinLength)
+	if len([]rune(password)) < minLength {
+		violations = append(violations, fmt.Sprintf("be at least %d characters long", minLength))
+	}
+	if len(password) > bcryptMaxPasswordBytes {
+		violations = append(violations, fmt.Sprintf("be at most %d bytes long", bcryptMaxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	rules := []struct {
+		conf    string
+		met     bool
+		message string
+	}{
+		{constants.ConfPasswordRequireUpper, hasUpper, "contain an uppercase letter"},
+		{constants.ConfPasswordRequireLower, hasLower, "contain a lowercase letter"},
+		{constants.ConfPasswordRequireDigit, hasDigit, "contain a digit"},
+		{constants.ConfPasswordRequireSpecial, hasSpecial, "contain a special character"},
+	}
+	for _, rule := range rules {
+		if !rule.met && web.AppConfig.DefaultBool(rule.conf, false) {
+			violations = append(violations, rule.message)
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: password must %s", constants.ErrWeakPassword, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// hashPassword validates a password against the policy and hashes it
+func hashPassword(password string) (string, error) {
+	if err := validatePassword(password); err != nil {
+		return "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", constants.ErrPasswordProcessing, err)
+	}
+	return string(hashedPassword), nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Bulk job methods on AppService
+//
+// A bulk action pauses, resumes, triggers, cancels or deletes many jobs of a project at once,
+// like pausing every job of a database during its maintenance window. Jobs are given by ID or
+// selected by a filter on their source, destination and labels. The action runs on every job even when some fail, and the outcome is
+// reported per job.
+
+// Bulk job actions
+const (
+	BulkPause   = "pause"
+	BulkResume  = "resume"
+	BulkTrigger = "trigger"
+	BulkCancel  = "cancel"
+	BulkDelete  = "delete"
+)
+
+// BulkJobAction runs an action on the jobs of a bulk request
+func (s *ETLService) BulkJobAction(ctx context.Context, projectID string, req *dto.BulkJobRequest, userID *int) (*dto.BulkJobResponse, error) {
+	jobs, results, err := s.bulkJobs(projectID, req)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("bulk job %s started project_id[%s] jobs[%d]", req.Action, projectID, len(jobs))
+
+	failures := map[int]error{}
+	if req.Action == BulkCancel {
+		// a single list query finds the running workflows of all jobs
+		if failures, err = cancelJobWorkflows(ctx, s.temporal, jobs, projectID); err != nil {
+			return nil, fmt.Errorf("failed to cancel job workflows: %s", err)
+		}
+	} else {
+		for _, job := range jobs {
+			if err := s.runBulkJobAction(ctx, projectID, job, req.Action, userID); err != nil {
+				failures[job.ID] = err
+			}
+		}
+	}
+
+	for _, job := range jobs {
+		result := dto.BulkJobResult{JobID: job.ID, JobName: job.Name, Success: true}
+		if err, failed := failures[job.ID]; failed {
+			result.Success = false
+			result.Error = err.Error()
+			logger.Warnf("bulk job %s failed job_id[%d]: %s", req.Action, job.ID, err)
+		}
+		results = append(results, result)
+	}
+
+	response := &dto.BulkJobResponse{Action: req.Action, Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response, nil
+}
+
+// bulkJobs returns the jobs of a bulk request, with failed results for the requested IDs that
+// are not jobs of the project
+func (s *ETLService) bulkJobs(projectID string, req *dto.BulkJobRequest) ([]*models.Job, []dto.BulkJobResult, error) {
+	hasFilter := req.Filter != nil && (req.Filter.SourceID != 0 || req.Filter.DestinationID != 0 || len(req.Filter.Labels) > 0)
+	if len(req.JobIDs) > 0 == hasFilter {
+		return nil, nil, fmt.Errorf("%w: either job_ids or a filter is required", constants.ErrInvalidBulkJobRequest)
+	}
+	if hasFilter {
+		if err := validateLabels(req.Filter.Labels); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", constants.ErrInvalidBulkJobRequest, err)
+		}
+	}
+
+	results := []dto.BulkJobResult{}
+	if !hasFilter {
+		var jobs []*models.Job
+		seen := make(map[int]bool, len(req.JobIDs))
+		for _, jobID := range req.JobIDs {
+			if seen[jobID] {
+				continue
+			}
+			seen[jobID] = true
+			job, err := s.db.GetJobByID(jobID, false)
+			if err != nil || job.ProjectID != projectID {
+				results = append(results, dto.BulkJobResult{JobID: jobID, Error: constants.ErrJobNotFound.Error()})
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+		return jobs, results, nil
+	}
+
+	projectJobs, err := s.db.ListJobsByProjectID(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var jobs []*models.Job
+	for _, job := range projectJobs {
+		if req.Filter.SourceID != 0 && (job.SourceID == nil || job.SourceID.ID != req.Filter.SourceID) ||
+			req.Filter.DestinationID != 0 && (job.DestID == nil || job.DestID.ID != req.Filter.DestinationID) ||
+			!labelsMatch(decodeLabels(job.Labels), req.Filter.Labels) {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, results, nil
+}
+
+// runBulkJobAction runs an action other than cancel on one job
+func (s *ETLService) runBulkJobAction(ctx context.Context, projectID string, job *models.Job, action string, userID *int) error {
+	switch action {
+	case BulkPause, BulkResume:
+		return s.ActivateJob(ctx, job.ID, dto.JobStatusRequest{Activate: action == BulkResume}, userID)
+	case BulkTrigger:
+		_, err := s.SyncJob(ctx, projectID, job.ID)
+		return err
+	case BulkDelete:
+		_, err := s.DeleteJob(ctx, job.ID)
+		return err
+	default:
+		return fmt.Errorf("%w: unsupported action '%s'", constants.ErrInvalidBulkJobRequest, action)
+	}
+}
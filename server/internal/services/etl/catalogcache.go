@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+	"golang.org/x/mod/semver"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/database"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Connector output cache methods on AppService
+//
+// Specs only change with the connector image, so they are kept in the catalog table per
+// connector type and version and never expire. Tags such as "latest" move and are not cached.
+// Discovered catalogs depend on the source's data and are cached per source for
+// CATALOG_CACHE_TTL minutes, keyed by a fingerprint of everything sent to discover.
+
+// specCacheKey returns where the spec of a source (destType empty) or destination connector is
+// cached. The spec command needs a minimum connector version, older versions read the spec of it.
+func (s *ETLService) specCacheKey(destType, sourceType, version string) (kind, connectorType, name, specVersion string) {
+	if minVersion := s.featureVersion(sourceType, constants.FeatureSpec); semver.Compare(version, minVersion) < 0 {
+		version = minVersion
+	}
+	if destType != "" {
+		return database.CatalogKindDestinationSpec, destType, sourceType, version
+	}
+	return database.CatalogKindSourceSpec, sourceType, "", version
+}
+
+// storedDriverSpec returns the cached spec of a source (destType empty) or destination connector
+// without running the spec command, ok is false when the spec is not cached
+func (s *ETLService) storedDriverSpec(destType, sourceType, version string) (spec map[string]interface{}, ok bool) {
+	kind, connectorType, name, version := s.specCacheKey(destType, sourceType, version)
+	if !semver.IsValid(version) {
+		return nil, false
+	}
+
+	entry, err := s.db.GetCachedCatalog(kind, connectorType, name, version)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal([]byte(entry.Specs), &spec); err == nil {
+			return spec, true
+		}
+		logger.Warnf("ignoring unreadable cached spec type[%s] version[%s]", connectorType, version)
+	case !errors.Is(err, orm.ErrNoRows):
+		logger.Warnf("failed to read cached spec type[%s] version[%s]: %s", connectorType, version, err)
+	}
+	return nil, false
+}
+
+// cachedDriverSpec returns the spec of a source (destType empty) or destination connector,
+// from the cache unless refresh is set. cached reports whether the cache served it.
+func (s *ETLService) cachedDriverSpec(ctx context.Context, destType, sourceType, version string, refresh bool) (spec map[string]interface{}, cached bool, err error) {
+	if !refresh {
+		if spec, ok := s.storedDriverSpec(destType, sourceType, version); ok {
+			return spec, true, nil
+		}
+	}
+
+	kind, connectorType, name, version := s.specCacheKey(destType, sourceType, version)
+	cacheable := semver.IsValid(version)
+
+	specOut, err := s.temporal.GetDriverSpecs(ctx, destType, sourceType, version)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cacheable {
+		if encoded, err := json.Marshal(specOut.Spec); err != nil {
+			logger.Warnf("failed to encode spec for cache type[%s] version[%s]: %s", connectorType, version, err)
+		} else if err := s.db.SaveCachedCatalog(&models.Catalog{
+			Kind:    kind,
+			Type:    connectorType,
+			Name:    name,
+			Version: version,
+			Specs:   string(encoded),
+		}); err != nil {
+			logger.Warnf("failed to cache spec type[%s] version[%s]: %s", connectorType, version, err)
+		}
+	}
+	return specOut.Spec, false, nil
+}
+
+// catalogCacheTTL is how long a discovered catalog is served from cache, zero when disabled
+func catalogCacheTTL() time.Duration {
+	return time.Duration(web.AppConfig.DefaultInt(constants.ConfCatalogCacheTTL, constants.DefaultCatalogCacheTTL)) * time.Minute
+}
+
+// discoverFingerprint keys a discovered catalog by its discover input. The config holds
+// secrets, so the key is a keyed hash that cannot be checked against guessed values.
+func discoverFingerprint(sourceType, version, config, oldStreams, jobName string) string {
+	return utils.KeyedHash("catalog-cache", fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", sourceType, version, config, oldStreams, jobName))
+}
+
+// cachedSourceCatalog returns a discovered catalog of a source that has not expired yet
+func (s *ETLService) cachedSourceCatalog(sourceType, version, fingerprint string) (map[string]interface{}, bool) {
+	entry, err := s.db.GetCachedCatalog(database.CatalogKindDiscover, sourceType, fingerprint, version)
+	if err != nil {
+		if !errors.Is(err, orm.ErrNoRows) {
+			logger.Warnf("failed to read cached catalog source_type[%s] version[%s]: %s", sourceType, version, err)
+		}
+		return nil, false
+	}
+	if entry.ExpiresAt == nil || time.Now().After(*entry.ExpiresAt) {
+		return nil, false
+	}
+
+	var catalog map[string]interface{}
+	if err := json.Unmarshal([]byte(entry.Specs), &catalog); err != nil {
+		logger.Warnf("ignoring unreadable cached catalog source_id[%d]: %s", entry.SourceID, err)
+		return nil, false
+	}
+	return catalog, true
+}
+
+// cacheSourceCatalog stores a discovered catalog of a source for ttl
+func (s *ETLService) cacheSourceCatalog(sourceID int, sourceType, version, fingerprint string, catalog map[string]interface{}, ttl time.Duration) {
+	encoded, err := json.Marshal(catalog)
+	if err != nil {
+		logger.Warnf("failed to encode catalog for cache source_id[%d]: %s", sourceID, err)
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := s.db.SaveCachedCatalog(&models.Catalog{
+		Kind:      database.CatalogKindDiscover,
+		Type:      sourceType,
+		Name:      fingerprint,
+		Version:   version,
+		Specs:     string(encoded),
+		SourceID:  sourceID,
+		ExpiresAt: &expiresAt,
+	}); err != nil {
+		logger.Warnf("failed to cache catalog source_id[%d]: %s", sourceID, err)
+	}
+}
+
+// InvalidateSpecCache drops cached specs, an empty connector type or version matches any
+func (s *ETLService) InvalidateSpecCache(connectorType, version string) (int64, error) {
+	removed, err := s.db.DeleteCachedSpecs(connectorType, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate spec cache: %s", err)
+	}
+	secretPathsCache.Range(func(key, _ interface{}) bool {
+		secretPathsCache.Delete(key)
+		return true
+	})
+	return removed, nil
+}
+
+// InvalidateSourceCatalogCache drops the discovered catalogs cached for a source of the project
+func (s *ETLService) InvalidateSourceCatalogCache(projectID string, sourceID int) (int64, error) {
+	source, err := s.db.GetSourceByID(sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find source: %s", err)
+	}
+	if source.ProjectID != projectID {
+		return 0, fmt.Errorf("%w: source_id[%d] is not in project[%s]", constants.ErrSourceNotFound, sourceID, projectID)
+	}
+
+	removed, err := s.db.DeleteCachedSourceCatalogs(sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate catalog cache: %s", err)
+	}
+	return removed, nil
+}
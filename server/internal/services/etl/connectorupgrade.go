@@ -0,0 +1,713 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/database"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Connector upgrade methods on AppService
+//
+// A connector upgrade moves the sources of a type from one version to another. Checking a
+// source runs its connection check with the new version and discovers its catalog with both
+// versions, so catalog changes caused by the upgrade are told apart from schema drift. The
+// rollout upgrades one source at a time: the workflows of its jobs are cancelled, the source
+// moved to the new version and the job schedules updated to run it. A source whose schedules
+// cannot be updated is moved back right away, upgraded sources can be rolled back later by
+// source or by job.
+
+// Spec change kinds
+const (
+	specFieldAdded    = "field_added"
+	specFieldRemoved  = "field_removed"
+	specFieldRetyped  = "field_retyped"
+	specFieldRequired = "field_required"
+)
+
+// connectionSucceeded is the status a passing connection check reports
+const connectionSucceeded = "SUCCEEDED"
+
+// specField is a field of a connector spec
+type specField struct {
+	fieldType  string
+	required   bool
+	hasDefault bool
+}
+
+// specFields flattens the fields of a connector spec by dot separated path. Fields of oneOf,
+// anyOf and allOf variants share the parent path and are never required, as only some
+// variants need them.
+func specFields(spec map[string]interface{}) map[string]specField {
+	schema, ok := spec["jsonschema"].(map[string]interface{})
+	if !ok {
+		schema = spec
+	}
+	fields := map[string]specField{}
+	collectSpecFields(schema, "", true, fields)
+	return fields
+}
+
+func collectSpecFields(schema map[string]interface{}, prefix string, canRequire bool, fields map[string]specField) {
+	if schema == nil {
+		return
+	}
+
+	required := map[string]bool{}
+	if canRequire {
+		list, _ := schema["required"].([]interface{})
+		for _, raw := range list {
+			if name, ok := raw.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, raw := range properties {
+			property, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			path := joinFieldPath(prefix, name)
+			_, hasDefault := property["default"]
+			field := fields[path]
+			field.fieldType = schemaType(property["type"])
+			field.required = field.required || required[name]
+			field.hasDefault = field.hasDefault || hasDefault
+			fields[path] = field
+			collectSpecFields(property, path, canRequire && required[name], fields)
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		collectSpecFields(items, prefix+"[]", canRequire, fields)
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf", "allOf"} {
+		variants, _ := schema[keyword].([]interface{})
+		for _, raw := range variants {
+			if variant, ok := raw.(map[string]interface{}); ok {
+				collectSpecFields(variant, prefix, false, fields)
+			}
+		}
+	}
+}
+
+// schemaType returns a JSON schema type, "null|string" for ["string", "null"]
+func schemaType(value interface{}) string {
+	switch t := value.(type) {
+	case string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		sort.Strings(types)
+		return strings.Join(types, "|")
+	default:
+		return ""
+	}
+}
+
+func joinFieldPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// diffSpecs compares the specs of two connector versions. Removed and retyped fields break
+// configs that set them, and new required fields without a default break every config.
+func diffSpecs(oldSpec, newSpec map[string]interface{}) []dto.SpecChange {
+	oldFields := specFields(oldSpec)
+	newFields := specFields(newSpec)
+
+	changes := []dto.SpecChange{}
+	for path, newField := range newFields {
+		oldField, ok := oldFields[path]
+		switch {
+		case !ok:
+			changes = append(changes, dto.SpecChange{Change: specFieldAdded, Field: path, NewType: newField.fieldType,
+				Breaking: newField.required && !newField.hasDefault})
+		case oldField.fieldType != newField.fieldType:
+			changes = append(changes, dto.SpecChange{Change: specFieldRetyped, Field: path, OldType: oldField.fieldType, NewType: newField.fieldType, Breaking: true})
+		case newField.required && !oldField.required:
+			changes = append(changes, dto.SpecChange{Change: specFieldRequired, Field: path, NewType: newField.fieldType, Breaking: !newField.hasDefault})
+		}
+	}
+	for path, oldField := range oldFields {
+		if _, ok := newFields[path]; !ok {
+			changes = append(changes, dto.SpecChange{Change: specFieldRemoved, Field: path, OldType: oldField.fieldType, Breaking: true})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Field != changes[j].Field {
+			return changes[i].Field < changes[j].Field
+		}
+		return changes[i].Change < changes[j].Change
+	})
+	return changes
+}
+
+func specChangesBreaking(changes []dto.SpecChange) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// connectorSpecChanges compares the specs of two versions of a source connector
+func (s *ETLService) connectorSpecChanges(ctx context.Context, sourceType, fromVersion, toVersion string) ([]dto.SpecChange, error) {
+	oldSpec, _, err := s.cachedDriverSpec(ctx, "", sourceType, fromVersion, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spec of version %s: %s", fromVersion, err)
+	}
+	newSpec, _, err := s.cachedDriverSpec(ctx, "", sourceType, toVersion, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spec of version %s: %s", toVersion, err)
+	}
+	return diffSpecs(oldSpec, newSpec), nil
+}
+
+// upgradeJobCheck compares the catalog a job syncs with the catalogs discovered with the old
+// and the new connector version
+func upgradeJobCheck(job *models.Job, oldCatalog, newCatalog map[string]interface{}) dto.UpgradeJobCheck {
+	check := dto.UpgradeJobCheck{JobID: job.ID, JobName: job.Name, Changes: []dto.SchemaChange{}}
+
+	var current map[string]interface{}
+	if err := json.Unmarshal([]byte(job.StreamsConfig), &current); err != nil {
+		check.Error = fmt.Sprintf("failed to parse streams config: %s", err)
+		return check
+	}
+
+	// changes the old version finds as well are schema drift, not the upgrade
+	existing := map[dto.SchemaChange]bool{}
+	for _, change := range diffSchemas(current, oldCatalog) {
+		existing[change] = true
+	}
+	selected := selectedStreamEntries(current)
+	for _, change := range diffSchemas(current, newCatalog) {
+		if existing[change] {
+			continue
+		}
+		check.Changes = append(check.Changes, change)
+		_, isSelected := selected[change.Stream]
+		switch change.Change {
+		case schemaColumnDropped, schemaColumnRetyped:
+			check.Breaking = true
+		case schemaTableDropped:
+			check.Breaking = check.Breaking || isSelected
+		}
+	}
+
+	currentEntries, _ := catalogEntries(current)
+	oldEntries, _ := catalogEntries(oldCatalog)
+	newEntries, _ := catalogEntries(newCatalog)
+	for id := range selected {
+		currentStream, _ := currentEntries[id]["stream"].(map[string]interface{})
+		mode, _ := currentStream["sync_mode"].(string)
+		oldStream, _ := oldEntries[id]["stream"].(map[string]interface{})
+		newStream, ok := newEntries[id]["stream"].(map[string]interface{})
+		if mode == "" || !ok || supportsSyncMode(newStream, mode) || (oldStream != nil && !supportsSyncMode(oldStream, mode)) {
+			continue
+		}
+		check.UnsupportedSyncModes = append(check.UnsupportedSyncModes, fmt.Sprintf("%s: %s", id, mode))
+		check.Breaking = true
+	}
+	sort.Strings(check.UnsupportedSyncModes)
+	return check
+}
+
+// checkSourceUpgrade runs the connection check of a source with the new version and compares
+// the catalogs of its jobs
+func (s *ETLService) checkSourceUpgrade(ctx context.Context, source *models.Source, jobs []*models.Job, fromVersion, toVersion string) dto.UpgradeSourceCheck {
+	check := dto.UpgradeSourceCheck{SourceID: source.ID, SourceName: source.Name, Jobs: []dto.UpgradeJobCheck{}}
+
+	encryptedConfig, err := utils.EncryptForConnector(source.Config)
+	if err != nil {
+		check.Error = fmt.Sprintf("failed to encrypt config for check: %s", err)
+		return check
+	}
+
+	workflowID := fmt.Sprintf("upgrade-check-%s-%d-%d", source.Type, source.ID, time.Now().Unix())
+	result, err := s.temporal.VerifyDriverCredentials(ctx, workflowID, "config", source.Type, toVersion, encryptedConfig)
+	if err != nil {
+		check.ConnectionStatus = "FAILED"
+		check.ConnectionMessage = err.Error()
+		return check
+	}
+	check.ConnectionStatus, _ = result["status"].(string)
+	check.ConnectionMessage, _ = result["message"].(string)
+	if !strings.EqualFold(check.ConnectionStatus, connectionSucceeded) || len(jobs) == 0 {
+		return check
+	}
+
+	oldCatalog, err := s.temporal.DiscoverStreams(ctx, source.Type, fromVersion, encryptedConfig, "", "")
+	if err != nil {
+		check.Error = fmt.Sprintf("failed to discover with version %s: %s", fromVersion, err)
+		return check
+	}
+	newCatalog, err := s.temporal.DiscoverStreams(ctx, source.Type, toVersion, encryptedConfig, "", "")
+	if err != nil {
+		check.Error = fmt.Sprintf("failed to discover with version %s: %s", toVersion, err)
+		return check
+	}
+
+	for _, job := range jobs {
+		jobCheck := upgradeJobCheck(job, oldCatalog, newCatalog)
+		check.Breaking = check.Breaking || jobCheck.Breaking
+		check.Jobs = append(check.Jobs, jobCheck)
+	}
+	return check
+}
+
+// upgradeCheckPassed reports whether a source can be upgraded, breaking changes aside
+func upgradeCheckPassed(check *dto.UpgradeSourceCheck) bool {
+	if check.Error != "" || !strings.EqualFold(check.ConnectionStatus, connectionSucceeded) {
+		return false
+	}
+	for _, job := range check.Jobs {
+		if job.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// upgradeSources returns the sources of a project an upgrade request applies to
+func (s *ETLService) upgradeSources(projectID string, req *dto.ConnectorUpgradeRequest) ([]*models.Source, error) {
+	if req.FromVersion == req.ToVersion {
+		return nil, fmt.Errorf("%w: from_version and to_version are the same", constants.ErrInvalidConnectorUpgrade)
+	}
+	if err := s.checkConnectorVersion(req.SourceType, req.ToVersion); err != nil {
+		return nil, err
+	}
+
+	sources, err := s.db.ListSourcesByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[int]bool, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		wanted[id] = true
+	}
+
+	var matched []*models.Source
+	for _, source := range sources {
+		if source.Type != req.SourceType || source.Version != req.FromVersion {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[source.ID] {
+			continue
+		}
+		delete(wanted, source.ID)
+		matched = append(matched, source)
+	}
+	for _, id := range req.SourceIDs {
+		if wanted[id] {
+			return nil, fmt.Errorf("%w: source %d is not a %s source at version %s", constants.ErrInvalidConnectorUpgrade, id, req.SourceType, req.FromVersion)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("%w: no %s source at version %s", constants.ErrInvalidConnectorUpgrade, req.SourceType, req.FromVersion)
+	}
+	// oldest first, so the rollout order is stable
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+// CheckConnectorUpgrade reports what upgrading the sources would change without applying it
+func (s *ETLService) CheckConnectorUpgrade(ctx context.Context, projectID string, req *dto.ConnectorUpgradeRequest) (*dto.ConnectorUpgradeCheckResponse, error) {
+	sources, err := s.upgradeSources(projectID, req)
+	if err != nil {
+		return nil, err
+	}
+	specChanges, err := s.connectorSpecChanges(ctx, req.SourceType, req.FromVersion, req.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs := make([]int, 0, len(sources))
+	for _, source := range sources {
+		sourceIDs = append(sourceIDs, source.ID)
+	}
+	jobs, err := s.db.GetJobsBySourceID(sourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs of sources: %s", err)
+	}
+	jobsBySource := map[int][]*models.Job{}
+	for _, job := range jobs {
+		jobsBySource[job.SourceID.ID] = append(jobsBySource[job.SourceID.ID], job)
+	}
+
+	response := &dto.ConnectorUpgradeCheckResponse{
+		SourceType:  req.SourceType,
+		FromVersion: req.FromVersion,
+		ToVersion:   req.ToVersion,
+		SpecChanges: specChanges,
+		Breaking:    specChangesBreaking(specChanges),
+		Sources:     make([]dto.UpgradeSourceCheck, 0, len(sources)),
+	}
+	for _, source := range sources {
+		check := s.checkSourceUpgrade(ctx, source, jobsBySource[source.ID], req.FromVersion, req.ToVersion)
+		response.Breaking = response.Breaking || check.Breaking
+		response.Sources = append(response.Sources, check)
+	}
+	return response, nil
+}
+
+// StartConnectorUpgrade checks the spec differences and starts rolling out the upgrade in the
+// background, the progress is read with GetConnectorUpgrade
+func (s *ETLService) StartConnectorUpgrade(ctx context.Context, projectID string, req *dto.ConnectorUpgradeRequest, userID *int) (*dto.ConnectorUpgradeResponse, error) {
+	sources, err := s.upgradeSources(projectID, req)
+	if err != nil {
+		return nil, err
+	}
+	specChanges, err := s.connectorSpecChanges(ctx, req.SourceType, req.FromVersion, req.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+	encodedChanges, err := json.Marshal(specChanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spec changes: %s", err)
+	}
+
+	upgrade := &models.ConnectorUpgrade{
+		ProjectID:   projectID,
+		SourceType:  req.SourceType,
+		FromVersion: req.FromVersion,
+		ToVersion:   req.ToVersion,
+		Status:      database.ConnectorUpgradeRunning,
+		Force:       req.Force,
+		MaxFailures: req.MaxFailures,
+		SpecChanges: string(encodedChanges),
+		CreatedBy:   &models.User{ID: *userID},
+	}
+	items := make([]*models.ConnectorUpgradeItem, 0, len(sources))
+	for _, source := range sources {
+		items = append(items, &models.ConnectorUpgradeItem{
+			SourceID:    source.ID,
+			SourceName:  source.Name,
+			Status:      database.UpgradeItemPending,
+			CheckResult: "null",
+		})
+	}
+	if err := s.db.CreateConnectorUpgrade(upgrade, items); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("connector upgrade %d started type[%s] %s -> %s sources[%d]", upgrade.ID, upgrade.SourceType, upgrade.FromVersion, upgrade.ToVersion, len(items))
+	go s.runConnectorUpgrade(upgrade, items, specChangesBreaking(specChanges))
+
+	return buildConnectorUpgradeResponse(upgrade, items)
+}
+
+// runConnectorUpgrade upgrades the sources of an upgrade one after the other
+func (s *ETLService) runConnectorUpgrade(upgrade *models.ConnectorUpgrade, items []*models.ConnectorUpgradeItem, specBreaking bool) {
+	ctx := context.Background()
+	counts := map[string]int{}
+
+	for _, item := range items {
+		if counts[database.UpgradeItemFailed] > upgrade.MaxFailures {
+			item.Status = database.UpgradeItemSkipped
+			item.Error = fmt.Sprintf("rollout stopped after %d failed sources", counts[database.UpgradeItemFailed])
+		} else {
+			s.upgradeSource(ctx, upgrade, item, specBreaking)
+		}
+		counts[item.Status]++
+		if err := s.db.UpdateConnectorUpgradeItem(item); err != nil {
+			logger.Errorf("failed to record connector upgrade %d source_id[%d]: %s", upgrade.ID, item.SourceID, err)
+		}
+	}
+
+	now := time.Now().UTC()
+	upgrade.Status = database.ConnectorUpgradeCompleted
+	if counts[database.UpgradeItemFailed] > upgrade.MaxFailures {
+		upgrade.Status = database.ConnectorUpgradeStopped
+	}
+	upgrade.FinishedAt = &now
+	if err := s.db.UpdateConnectorUpgrade(upgrade); err != nil {
+		logger.Errorf("failed to record end of connector upgrade %d: %s", upgrade.ID, err)
+	}
+
+	logger.Infof("connector upgrade %d %s upgraded[%d] failed[%d] skipped[%d]", upgrade.ID, upgrade.Status,
+		counts[database.UpgradeItemUpgraded], counts[database.UpgradeItemFailed], counts[database.UpgradeItemSkipped])
+	s.notifyProject(ctx, upgrade.ProjectID, nil, fmt.Sprintf("Upgrade %d of %s sources from %s to %s %s: %d upgraded, %d failed, %d skipped.",
+		upgrade.ID, upgrade.SourceType, upgrade.FromVersion, upgrade.ToVersion, upgrade.Status,
+		counts[database.UpgradeItemUpgraded], counts[database.UpgradeItemFailed], counts[database.UpgradeItemSkipped]))
+}
+
+// upgradeSource checks and upgrades the source of an item, leaving the outcome on the item
+func (s *ETLService) upgradeSource(ctx context.Context, upgrade *models.ConnectorUpgrade, item *models.ConnectorUpgradeItem, specBreaking bool) {
+	source, err := s.db.GetSourceByID(item.SourceID)
+	if err != nil {
+		item.Status, item.Error = database.UpgradeItemFailed, err.Error()
+		return
+	}
+	if source.Type != upgrade.SourceType || source.Version != upgrade.FromVersion {
+		item.Status = database.UpgradeItemSkipped
+		item.Error = fmt.Sprintf("source moved to %s %s since the upgrade started", source.Type, source.Version)
+		return
+	}
+	jobs, err := s.db.GetJobsBySourceID([]int{source.ID})
+	if err != nil {
+		item.Status, item.Error = database.UpgradeItemFailed, fmt.Sprintf("failed to get jobs of source: %s", err)
+		return
+	}
+
+	check := s.checkSourceUpgrade(ctx, source, jobs, upgrade.FromVersion, upgrade.ToVersion)
+	if encoded, err := json.Marshal(check); err == nil {
+		item.CheckResult = string(encoded)
+	}
+	switch {
+	case !upgradeCheckPassed(&check):
+		item.Status, item.Error = database.UpgradeItemFailed, "check with the new version failed"
+		return
+	case (check.Breaking || specBreaking) && !upgrade.Force:
+		item.Status, item.Error = database.UpgradeItemSkipped, "breaking changes found, force the upgrade to apply it anyway"
+		return
+	}
+
+	// the upgrade is applied on behalf of its creator, or as a system change once they are deleted
+	var upgradedBy *int
+	if upgrade.CreatedBy != nil {
+		upgradedBy = &upgrade.CreatedBy.ID
+	}
+	if err := s.setSourceVersion(ctx, upgrade.ProjectID, source, jobs, upgrade.ToVersion, upgradedBy); err != nil {
+		item.Status, item.Error = database.UpgradeItemFailed, err.Error()
+		return
+	}
+	item.Status, item.Error = database.UpgradeItemUpgraded, ""
+}
+
+// setSourceVersion moves a source and the schedules of its jobs to a connector version. Running
+// workflows of the jobs are cancelled first, as when the source is edited. When a schedule
+// cannot be updated, the source and the schedules updated so far go back to the old version.
+func (s *ETLService) setSourceVersion(ctx context.Context, projectID string, source *models.Source, jobs []*models.Job, version string, userID *int) error {
+	previous := source.Version
+	if err := cancelAllJobWorkflows(ctx, s.temporal, jobs, projectID); err != nil {
+		return fmt.Errorf("failed to cancel workflows of source: %s", err)
+	}
+	if err := s.saveSourceVersion(source, version, userID); err != nil {
+		return err
+	}
+
+	for i, job := range jobs {
+		job.SourceID.Version = version
+		if err := s.temporal.RestoreSyncSchedule(ctx, job); err != nil {
+			scheduleErr := fmt.Errorf("failed to update schedule of job %d: %s", job.ID, err)
+			if err := s.saveSourceVersion(source, previous, userID); err != nil {
+				return fmt.Errorf("%s, and failed to move the source back: %s", scheduleErr, err)
+			}
+			job.SourceID.Version = previous
+			for _, updated := range jobs[:i] {
+				updated.SourceID.Version = previous
+				if err := s.temporal.RestoreSyncSchedule(ctx, updated); err != nil {
+					logger.Errorf("failed to move schedule of job %d back to version %s: %s", updated.ID, previous, err)
+				}
+			}
+			return scheduleErr
+		}
+	}
+	return nil
+}
+
+// saveSourceVersion stores the version of a source, its cached catalogs were discovered with
+// the old version and are dropped. Without a user the source keeps its last editor.
+func (s *ETLService) saveSourceVersion(source *models.Source, version string, userID *int) error {
+	// UpdateSource encrypts the config in place, so a copy is saved
+	updated := *source
+	updated.Version = version
+	if userID != nil {
+		updated.UpdatedBy = &models.User{ID: *userID}
+	}
+	if err := s.db.UpdateSource(&updated); err != nil {
+		return fmt.Errorf("failed to update source version: %s", err)
+	}
+	source.Version = version
+	if _, err := s.db.DeleteCachedSourceCatalogs(source.ID); err != nil {
+		logger.Warnf("failed to invalidate catalog cache for source upgrade: %s", err)
+	}
+	return nil
+}
+
+// RollbackConnectorUpgrade moves upgraded sources back to the version they were upgraded from.
+// Sources changed to another version since the upgrade are left as they are.
+func (s *ETLService) RollbackConnectorUpgrade(ctx context.Context, projectID string, id int, req *dto.ConnectorUpgradeRollbackRequest, userID *int) (*dto.ConnectorUpgradeResponse, error) {
+	upgrade, err := s.db.GetConnectorUpgrade(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upgrade.Status == database.ConnectorUpgradeRunning {
+		return nil, fmt.Errorf("%w: upgrade %d", constants.ErrConnectorUpgradeRunning, id)
+	}
+
+	selected := map[int]bool{}
+	for _, sourceID := range req.SourceIDs {
+		selected[sourceID] = true
+	}
+	for _, jobID := range req.JobIDs {
+		job, err := s.getProjectJob(projectID, jobID)
+		if err != nil {
+			return nil, err
+		}
+		selected[job.SourceID.ID] = true
+	}
+
+	items, err := s.db.ListConnectorUpgradeItems([]int{upgrade.ID})
+	if err != nil {
+		return nil, err
+	}
+	rolledBack := 0
+	for _, item := range items {
+		if item.Status != database.UpgradeItemUpgraded || (len(selected) > 0 && !selected[item.SourceID]) {
+			continue
+		}
+		rolledBack++
+		s.rollbackSource(ctx, upgrade, item, *userID)
+		if err := s.db.UpdateConnectorUpgradeItem(item); err != nil {
+			return nil, err
+		}
+	}
+	if rolledBack == 0 {
+		return nil, fmt.Errorf("%w: no upgraded source of upgrade %d matches", constants.ErrInvalidConnectorUpgrade, id)
+	}
+
+	logger.Infof("connector upgrade %d rolled back sources[%d]", upgrade.ID, rolledBack)
+	return buildConnectorUpgradeResponse(upgrade, items)
+}
+
+// rollbackSource moves the source of an upgraded item back, leaving the outcome on the item
+func (s *ETLService) rollbackSource(ctx context.Context, upgrade *models.ConnectorUpgrade, item *models.ConnectorUpgradeItem, userID int) {
+	source, err := s.db.GetSourceByID(item.SourceID)
+	if err != nil {
+		item.Error = fmt.Sprintf("rollback failed: %s", err)
+		return
+	}
+	if source.Type != upgrade.SourceType || source.Version != upgrade.ToVersion {
+		item.Error = fmt.Sprintf("not rolled back, source moved to %s %s since the upgrade", source.Type, source.Version)
+		return
+	}
+	jobs, err := s.db.GetJobsBySourceID([]int{source.ID})
+	if err != nil {
+		item.Error = fmt.Sprintf("rollback failed: failed to get jobs of source: %s", err)
+		return
+	}
+	if err := s.setSourceVersion(ctx, upgrade.ProjectID, source, jobs, upgrade.FromVersion, &userID); err != nil {
+		item.Error = fmt.Sprintf("rollback failed: %s", err)
+		return
+	}
+	item.Status, item.Error = database.UpgradeItemRolledBack, ""
+}
+
+// ListConnectorUpgrades returns the connector upgrades of a project, newest first
+func (s *ETLService) ListConnectorUpgrades(_ context.Context, projectID string) ([]dto.ConnectorUpgradeResponse, error) {
+	upgrades, err := s.db.ListConnectorUpgrades(projectID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(upgrades))
+	for _, upgrade := range upgrades {
+		ids = append(ids, upgrade.ID)
+	}
+	items, err := s.db.ListConnectorUpgradeItems(ids)
+	if err != nil {
+		return nil, err
+	}
+	itemsByUpgrade := map[int][]*models.ConnectorUpgradeItem{}
+	for _, item := range items {
+		itemsByUpgrade[item.Upgrade.ID] = append(itemsByUpgrade[item.Upgrade.ID], item)
+	}
+
+	responses := make([]dto.ConnectorUpgradeResponse, 0, len(upgrades))
+	for _, upgrade := range upgrades {
+		response, err := buildConnectorUpgradeResponse(upgrade, itemsByUpgrade[upgrade.ID])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetConnectorUpgrade returns a connector upgrade with the outcome per source
+func (s *ETLService) GetConnectorUpgrade(_ context.Context, projectID string, id int) (*dto.ConnectorUpgradeResponse, error) {
+	upgrade, err := s.db.GetConnectorUpgrade(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.db.ListConnectorUpgradeItems([]int{upgrade.ID})
+	if err != nil {
+		return nil, err
+	}
+	return buildConnectorUpgradeResponse(upgrade, items)
+}
+
+// RecoverConnectorUpgrades marks the upgrades a previous run of the server left running as
+// interrupted. Sources already upgraded stay upgraded and can be rolled back.
+func (s *ETLService) RecoverConnectorUpgrades() {
+	interrupted, err := s.db.InterruptConnectorUpgrades()
+	if err != nil {
+		logger.Errorf("failed to recover connector upgrades: %s", err)
+		return
+	}
+	if interrupted > 0 {
+		logger.Warnf("marked %d connector upgrades left running as interrupted", interrupted)
+	}
+}
+
+func buildConnectorUpgradeResponse(upgrade *models.ConnectorUpgrade, items []*models.ConnectorUpgradeItem) (*dto.ConnectorUpgradeResponse, error) {
+	response := &dto.ConnectorUpgradeResponse{
+		ID:          upgrade.ID,
+		SourceType:  upgrade.SourceType,
+		FromVersion: upgrade.FromVersion,
+		ToVersion:   upgrade.ToVersion,
+		Status:      upgrade.Status,
+		Force:       upgrade.Force,
+		MaxFailures: upgrade.MaxFailures,
+		Items:       make([]dto.ConnectorUpgradeItemResponse, 0, len(items)),
+		CreatedAt:   upgrade.CreatedAt.Format(time.RFC3339),
+	}
+	if err := json.Unmarshal([]byte(upgrade.SpecChanges), &response.SpecChanges); err != nil {
+		return nil, fmt.Errorf("failed to parse spec changes of connector upgrade %d: %s", upgrade.ID, err)
+	}
+	if upgrade.CreatedBy != nil {
+		response.CreatedBy = upgrade.CreatedBy.Username
+	}
+	if upgrade.FinishedAt != nil {
+		response.FinishedAt = upgrade.FinishedAt.Format(time.RFC3339)
+	}
+
+	for _, item := range items {
+		itemResponse := dto.ConnectorUpgradeItemResponse{
+			SourceID:   item.SourceID,
+			SourceName: item.SourceName,
+			Status:     item.Status,
+			Error:      item.Error,
+			UpdatedAt:  item.UpdatedAt.Format(time.RFC3339),
+		}
+		if item.CheckResult != "" {
+			if err := json.Unmarshal([]byte(item.CheckResult), &itemResponse.Check); err != nil {
+				return nil, fmt.Errorf("failed to parse check of connector upgrade item %d: %s", item.ID, err)
+			}
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	return response, nil
+}
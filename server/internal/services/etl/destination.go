@@ -3,13 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"time"
 
-	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
 	"github.com/datazip-inc/olake-ui/server/utils/telemetry"
 )
 
@@ -40,12 +39,18 @@ func (s *ETLService) GetDestination(ctx context.Context, projectID string, desti
 		return nil, fmt.Errorf("failed to build job data items: %s", err)
 	}
 
+	config, err := s.MaskDestinationConfig(ctx, destination.DestType, destination.Version, destination.Config)
+	if err != nil {
+		return nil, err
+	}
+
 	item := &dto.DestinationDataItem{
 		ID:        destination.ID,
 		Name:      destination.Name,
 		Type:      destination.DestType,
 		Version:   destination.Version,
-		Config:    destination.Config,
+		Config:    config,
+		Labels:    decodeLabels(destination.Labels),
 		CreatedAt: destination.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: destination.UpdatedAt.Format(time.RFC3339),
 		Jobs:      jobItems,
@@ -55,12 +60,19 @@ func (s *ETLService) GetDestination(ctx context.Context, projectID string, desti
 	return item, nil
 }
 
-// ListDestinations returns all destinations for a project with lightweight job summaries.
-func (s *ETLService) ListDestinations(ctx context.Context, projectID string) ([]dto.DestinationDataItem, error) {
-	destinations, err := s.db.ListDestinationsByProjectID(projectID)
+// ListDestinations returns all destinations for a project with lightweight job summaries, only
+// the destinations carrying every label of selector when it is set.
+func (s *ETLService) ListDestinations(ctx context.Context, projectID string, selector map[string]string) ([]dto.DestinationDataItem, error) {
+	projectDestinations, err := s.db.ListDestinationsByProjectID(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list destinations: %s", err)
 	}
+	destinations := make([]*models.Destination, 0, len(projectDestinations))
+	for _, dest := range projectDestinations {
+		if labelsMatch(decodeLabels(dest.Labels), selector) {
+			destinations = append(destinations, dest)
+		}
+	}
 
 	destIDs := make([]int, 0, len(destinations))
 	for _, dest := range destinations {
@@ -86,12 +98,18 @@ func (s *ETLService) ListDestinations(ctx context.Context, projectID string) ([]
 
 	destItems := make([]dto.DestinationDataItem, 0, len(destinations))
 	for _, dest := range destinations {
+		config, err := s.MaskDestinationConfig(ctx, dest.DestType, dest.Version, dest.Config)
+		if err != nil {
+			return nil, err
+		}
+
 		entity := dto.DestinationDataItem{
 			ID:        dest.ID,
 			Name:      dest.Name,
 			Type:      dest.DestType,
 			Version:   dest.Version,
-			Config:    dest.Config,
+			Config:    config,
+			Labels:    decodeLabels(dest.Labels),
 			CreatedAt: dest.CreatedAt.Format(time.RFC3339),
 			UpdatedAt: dest.UpdatedAt.Format(time.RFC3339),
 		}
@@ -118,11 +136,26 @@ func (s *ETLService) CreateDestination(ctx context.Context, req *dto.CreateDesti
 		return fmt.Errorf("destination name '%s' is not unique", req.Name)
 	}
 
+	if err := secretref.Validate(req.Config); err != nil {
+		return fmt.Errorf("invalid destination config: %s", err)
+	}
+	if err := s.checkDestinationVersion(ctx, req.Version); err != nil {
+		return err
+	}
+	if err := validateLabels(req.Labels); err != nil {
+		return err
+	}
+	config, err := s.encryptDestinationSecretFields(ctx, req.Type, req.Version, req.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret fields: %s", err)
+	}
+
 	destination := &models.Destination{
 		Name:      req.Name,
 		DestType:  req.Type,
 		Version:   req.Version,
-		Config:    req.Config,
+		Config:    config,
+		Labels:    encodeLabels(req.Labels),
 		ProjectID: projectID,
 	}
 	user := &models.User{ID: *userID}
@@ -143,10 +176,31 @@ func (s *ETLService) UpdateDestination(ctx context.Context, id int, projectID st
 		return fmt.Errorf("failed to get destination: %s", err)
 	}
 
+	// secrets the client left masked keep their stored value
+	config, err := utils.RestoreMaskedSecrets(req.Config, existingDest.Config)
+	if err != nil {
+		return fmt.Errorf("failed to restore masked secrets: %s", err)
+	}
+	if err := secretref.Validate(config); err != nil {
+		return fmt.Errorf("invalid destination config: %s", err)
+	}
+	if err := s.checkDestinationVersion(ctx, req.Version); err != nil {
+		return err
+	}
+	if req.Labels != nil {
+		if err := validateLabels(req.Labels); err != nil {
+			return err
+		}
+		existingDest.Labels = encodeLabels(req.Labels)
+	}
+	if config, err = s.encryptDestinationSecretFields(ctx, req.Type, req.Version, config); err != nil {
+		return fmt.Errorf("failed to encrypt secret fields: %s", err)
+	}
+
 	existingDest.Name = req.Name
 	existingDest.DestType = req.Type
 	existingDest.Version = req.Version
-	existingDest.Config = req.Config
+	existingDest.Config = config
 
 	user := &models.User{ID: *userID}
 	existingDest.UpdatedBy = user
@@ -155,6 +209,11 @@ func (s *ETLService) UpdateDestination(ctx context.Context, id int, projectID st
 	if err != nil {
 		return fmt.Errorf("failed to fetch jobs for destination update: %s", err)
 	}
+	if len(jobs) > 0 {
+		if err := checkSecretRefsSupported(config); err != nil {
+			return err
+		}
+	}
 
 	if err := cancelAllJobWorkflows(ctx, s.temporal, jobs, projectID); err != nil {
 		return fmt.Errorf("failed to cancel workflows for destination update: %s", err)
@@ -204,13 +263,23 @@ func (s *ETLService) TestDestinationConnection(ctx context.Context, req *dto.Des
 	driver := req.SourceType
 	if driver == "" {
 		var err error
-		_, driver, err = utils.GetDriverImageTags(ctx, "", true)
+		if driver, err = s.driverType(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to get driver connector: %s", err)
+		}
+	}
+
+	config := req.Config
+	if req.DestinationID != nil {
+		destination, err := s.db.GetDestinationByID(*req.DestinationID)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get driver image tags: %s", err)
+			return nil, nil, fmt.Errorf("failed to get destination for test connection: %s", err)
+		}
+		if config, err = utils.RestoreMaskedSecrets(config, destination.Config); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore masked secrets: %s", err)
 		}
 	}
 
-	encryptedConfig, err := utils.Encrypt(req.Config)
+	encryptedConfig, err := utils.EncryptForConnector(config)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to encrypt config for test connection: %s", err)
 	}
@@ -228,10 +297,8 @@ func (s *ETLService) TestDestinationConnection(ctx context.Context, req *dto.Des
 		return result, nil, fmt.Errorf("connection test failed: %s", err)
 	}
 
-	homeDir := constants.DefaultConfigDir
-	mainLogDir := filepath.Join(homeDir, workflowID)
 	// Fetch the latest batch of logs by tailing from the end with default limit in the "older" direction.
-	logs, err := utils.ReadLogs(mainLogDir, -1, -1, "older")
+	logs, err := utils.ReadLogs(ctx, workflowID, -1, -1, "older")
 	if err != nil {
 		return result, nil, fmt.Errorf("failed to read logs destination_type[%s] destination_version[%s] error[%s]",
 			req.Type, req.Version, err)
@@ -258,22 +325,25 @@ func (s *ETLService) GetDestinationVersions(ctx context.Context, destType string
 		return nil, fmt.Errorf("destination type is required")
 	}
 
-	versions, _, err := utils.GetDriverImageTags(ctx, "", true)
+	driver, err := s.driverConnector(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get driver image tags: %s", err)
+		return nil, fmt.Errorf("failed to get driver connector: %s", err)
 	}
 
-	return map[string]interface{}{"version": versions}, nil
+	versions, err := s.connectorVersionList(ctx, driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connector versions: %s", err)
+	}
+	return versions, nil
 }
 
-// TODO: cache spec in db for each version
 func (s *ETLService) GetDestinationSpec(ctx context.Context, req *dto.SpecRequest) (dto.SpecResponse, error) {
-	_, driver, err := utils.GetDriverImageTags(ctx, "", true)
+	driver, err := s.driverType(ctx)
 	if err != nil {
-		return dto.SpecResponse{}, fmt.Errorf("failed to get driver image tags: %s", err)
+		return dto.SpecResponse{}, fmt.Errorf("failed to get driver connector: %s", err)
 	}
 
-	specOut, err := s.temporal.GetDriverSpecs(ctx, req.Type, driver, req.Version)
+	spec, cached, err := s.cachedDriverSpec(ctx, req.Type, driver, req.Version, req.Refresh)
 	if err != nil {
 		return dto.SpecResponse{}, fmt.Errorf("failed to get spec: %s", err)
 	}
@@ -281,6 +351,7 @@ func (s *ETLService) GetDestinationSpec(ctx context.Context, req *dto.SpecReques
 	return dto.SpecResponse{
 		Version: req.Version,
 		Type:    req.Type,
-		Spec:    specOut.Spec,
+		Spec:    spec,
+		Cached:  cached,
 	}, nil
 }
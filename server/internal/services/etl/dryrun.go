@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Dry run methods on AppService
+//
+// A dry run syncs a job with a row limit per stream into a scratch writer, so a new job or a
+// connector version can be verified before the first real run. Every stream is read as a full
+// refresh whatever its sync mode, as reading a CDC stream would move the replication position
+// the job resumes from. The job's destination, state and schedule are not touched.
+
+// dryRunSyncMode is the sync mode every stream of a dry run is read with
+const dryRunSyncMode = "full_refresh"
+
+// DryRunJob runs a job with a row limit and returns what it read per stream
+func (s *ETLService) DryRunJob(ctx context.Context, projectID string, jobID int, req *dto.DryRunRequest) (*dto.DryRunResponse, error) {
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	version := job.SourceID.Version
+	if req.Version != "" && req.Version != version {
+		if err := s.checkConnectorVersion(job.SourceID.Type, req.Version); err != nil {
+			return nil, err
+		}
+		version = req.Version
+	}
+	rowLimit := req.RowLimit
+	if rowLimit == 0 {
+		rowLimit = constants.DefaultDryRunRowLimit
+	}
+	sampleSize := req.SampleSize
+	if sampleSize == 0 {
+		sampleSize = constants.DefaultDryRunSampleSize
+	}
+	sampleSize = min(sampleSize, rowLimit)
+
+	streamsConfig := job.StreamsConfig
+	if req.StreamsConfig != "" {
+		streamsConfig = req.StreamsConfig
+	}
+	catalog, streams, syncModes, err := dryRunCatalog(streamsConfig, req.Streams)
+	if err != nil {
+		return nil, err
+	}
+	encodedCatalog, err := json.Marshal(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dry run catalog: %s", err)
+	}
+
+	// samples are masked by the project's masking rules, a dry run is not started without them
+	masker, err := s.sourceMasker(projectID, job.SourceID.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedConfig, err := utils.EncryptForConnector(job.SourceID.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt config for dry run: %s", err)
+	}
+
+	logger.Infof("dry run started job_id[%d] version[%s] streams[%d] row_limit[%d]", job.ID, version, len(streams), rowLimit)
+	output, err := s.temporal.DryRunSync(ctx, job, version, encryptedConfig, string(encodedCatalog), rowLimit, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("dry run failed: %s", err)
+	}
+
+	entries, _ := catalogEntries(catalog)
+	reports, _ := output["streams"].(map[string]interface{})
+	response := &dto.DryRunResponse{
+		JobID:    job.ID,
+		Version:  version,
+		RowLimit: rowLimit,
+		Streams:  make([]dto.DryRunStream, 0, len(streams)),
+	}
+	for _, id := range streams {
+		report, _ := reports[id].(map[string]interface{})
+		stream := dryRunStream(report, rowLimit, sampleSize)
+		stream.Stream = id
+		stream.SyncMode = syncModes[id]
+		stream.Schema = streamColumns(entries[id])
+		stream.MaskedColumns = masker.maskRecords(id, stream.Samples)
+		response.Streams = append(response.Streams, stream)
+	}
+	return response, nil
+}
+
+// dryRunCatalog returns the catalog a dry run reads: the selected streams of a streams config,
+// or only the given ones, all set to full refresh. It also returns the streams read, sorted,
+// and the sync mode each had.
+func dryRunCatalog(streamsConfig string, streams []string) (map[string]interface{}, []string, map[string]string, error) {
+	var catalog map[string]interface{}
+	if err := json.Unmarshal([]byte(streamsConfig), &catalog); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: failed to parse streams config: %s", constants.ErrInvalidDryRun, err)
+	}
+
+	selected := selectedStreamEntries(catalog)
+	wanted := make(map[string]bool, len(selected))
+	if len(streams) == 0 {
+		for id := range selected {
+			wanted[id] = true
+		}
+	}
+	for _, id := range streams {
+		if _, ok := selected[id]; !ok {
+			return nil, nil, nil, fmt.Errorf("%w: stream %s is not selected", constants.ErrInvalidDryRun, id)
+		}
+		wanted[id] = true
+	}
+	if len(wanted) == 0 {
+		return nil, nil, nil, fmt.Errorf("%w: no stream is selected", constants.ErrInvalidDryRun)
+	}
+
+	keepSelectedStreams(catalog, wanted)
+	entries, _ := catalogEntries(catalog)
+	syncModes := make(map[string]string, len(wanted))
+	ids := make([]string, 0, len(wanted))
+	for id := range wanted {
+		if stream, ok := entries[id]["stream"].(map[string]interface{}); ok {
+			syncModes[id], _ = stream["sync_mode"].(string)
+			stream["sync_mode"] = dryRunSyncMode
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return catalog, ids, syncModes, nil
+}
+
+// dryRunStream reads the report of one stream from the dry run output
+func dryRunStream(report map[string]interface{}, rowLimit, sampleSize int) dto.DryRunStream {
+	stream := dto.DryRunStream{InferredSchema: map[string]string{}, Samples: []map[string]interface{}{}}
+
+	records, _ := report["records"].([]interface{})
+	count, ok := report["count"].(float64)
+	if !ok {
+		count = float64(len(records))
+	}
+	stream.Count = int(count)
+	stream.LimitReached = stream.Count >= rowLimit
+
+	types := map[string]map[string]bool{}
+	for _, raw := range records {
+		record, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for column, value := range record {
+			if types[column] == nil {
+				types[column] = map[string]bool{}
+			}
+			types[column][jsonType(value)] = true
+		}
+		if len(stream.Samples) < sampleSize {
+			stream.Samples = append(stream.Samples, record)
+		}
+	}
+	for column, seen := range types {
+		names := make([]string, 0, len(seen))
+		for name := range seen {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		stream.InferredSchema[column] = strings.Join(names, "|")
+	}
+	return stream
+}
+
+// jsonType names the JSON type of a decoded value, telling integers from other numbers
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
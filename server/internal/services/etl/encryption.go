@@ -0,0 +1,156 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Encryption key rotation methods on AppService
+//
+// After a new OLAKE_SECRET_KEY is set and the old one moved to OLAKE_PREVIOUS_SECRET_KEYS,
+// stored source and destination configs are re-encrypted row by row in the background.
+// Rows already encrypted with the primary key are skipped, so an interrupted or partially
+// failed run is resumed by starting it again.
+
+// reEncryptionTables are the tables whose config column is encrypted
+var reEncryptionTables = []constants.TableType{constants.SourceTable, constants.DestinationTable}
+
+var (
+	reEncryptionMu     sync.Mutex
+	reEncryptionStatus = dto.ReEncryptionStatusResponse{Failed: []dto.ReEncryptionFailure{}}
+)
+
+// StartConfigReEncryption starts re-encrypting every stored config with the primary key
+func (s *ETLService) StartConfigReEncryption() (*dto.ReEncryptionStatusResponse, error) {
+	keyID := utils.PrimaryEncryptionKeyID()
+	if keyID == "" {
+		return nil, fmt.Errorf("encryption key is not set")
+	}
+
+	reEncryptionMu.Lock()
+	if reEncryptionStatus.Running {
+		reEncryptionMu.Unlock()
+		return nil, fmt.Errorf("re-encryption is already running")
+	}
+	reEncryptionStatus = dto.ReEncryptionStatusResponse{
+		Running:   true,
+		KeyID:     keyID,
+		Failed:    []dto.ReEncryptionFailure{},
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	status := copyReEncryptionStatus()
+	reEncryptionMu.Unlock()
+
+	go s.reEncryptConfigs()
+	return status, nil
+}
+
+// GetConfigReEncryptionStatus returns the progress of the latest re-encryption run
+func (s *ETLService) GetConfigReEncryptionStatus() *dto.ReEncryptionStatusResponse {
+	reEncryptionMu.Lock()
+	defer reEncryptionMu.Unlock()
+
+	status := copyReEncryptionStatus()
+	if !status.Running && status.KeyID == "" {
+		status.KeyID = utils.PrimaryEncryptionKeyID()
+	}
+	return status
+}
+
+func (s *ETLService) reEncryptConfigs() {
+	err := func() error {
+		for _, table := range reEncryptionTables {
+			configs, err := s.db.ListEncryptedConfigs(table)
+			if err != nil {
+				return err
+			}
+			updateReEncryptionStatus(func(status *dto.ReEncryptionStatusResponse) {
+				status.Total += len(configs)
+			})
+
+			for _, row := range configs {
+				reEncrypted, err := s.reEncryptConfig(table, row.ID, row.Config)
+				updateReEncryptionStatus(func(status *dto.ReEncryptionStatusResponse) {
+					status.Processed++
+					switch {
+					case err != nil:
+						status.Failed = append(status.Failed, dto.ReEncryptionFailure{
+							Table: constants.TableNameMap[table],
+							ID:    row.ID,
+							Error: err.Error(),
+						})
+					case reEncrypted:
+						status.ReEncrypted++
+					default:
+						status.Skipped++
+					}
+				})
+				if err != nil {
+					logger.Errorf("failed to re-encrypt config of %s id[%d]: %s", constants.TableNameMap[table], row.ID, err)
+				}
+			}
+		}
+		return nil
+	}()
+
+	updateReEncryptionStatus(func(status *dto.ReEncryptionStatusResponse) {
+		status.Running = false
+		status.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+		if err != nil {
+			status.Error = err.Error()
+		}
+		logger.Infof("Config re-encryption finished key_id[%s] total[%d] re_encrypted[%d] skipped[%d] failed[%d]",
+			status.KeyID, status.Total, status.ReEncrypted, status.Skipped, len(status.Failed))
+	})
+}
+
+// reEncryptConfig re-encrypts one stored config, it reports false if the row was already up to date
+func (s *ETLService) reEncryptConfig(table constants.TableType, id int, stored string) (bool, error) {
+	if stored == "" {
+		return false, nil
+	}
+
+	needed, err := utils.NeedsReEncryption(stored)
+	if err != nil {
+		return false, err
+	}
+	if !needed {
+		if needed, err = utils.SecretFieldsNeedReEncryption(stored); err != nil || !needed {
+			return false, err
+		}
+	}
+
+	reEncrypted, err := utils.ReEncrypt(stored)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := s.db.ReplaceEncryptedConfig(table, id, stored, reEncrypted)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		// the row was edited or deleted meanwhile, an edit is already saved with the primary key
+		return false, nil
+	}
+	return true, nil
+}
+
+func updateReEncryptionStatus(fn func(status *dto.ReEncryptionStatusResponse)) {
+	reEncryptionMu.Lock()
+	defer reEncryptionMu.Unlock()
+	fn(&reEncryptionStatus)
+}
+
+// copyReEncryptionStatus must be called with reEncryptionMu held
+func copyReEncryptionStatus() *dto.ReEncryptionStatusResponse {
+	status := reEncryptionStatus
+	status.Failed = append([]dto.ReEncryptionFailure{}, reEncryptionStatus.Failed...)
+	return &status
+}
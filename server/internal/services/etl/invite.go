@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Invite and email verification methods on AppService
+//
+// Invites and verification links carry signed tokens (utils.SignToken). Invite tokens name the
+// invite row, which makes them single use and revocable. Verification tokens name the user and
+// the email address, so changing the address invalidates earlier links.
+
+const (
+	tokenPurposeInvite      = "invite"
+	tokenPurposeVerifyEmail = "verify-email"
+)
+
+// appLink builds a link to the UI at APP_URL carrying a token
+func appLink(path, token string) string {
+	base := strings.TrimRight(web.AppConfig.DefaultString(constants.ConfAppURL, constants.DefaultAppURL), "/")
+	return fmt.Sprintf("%s%s?token=%s", base, path, url.QueryEscape(token))
+}
+
+func inviteStatus(invite *models.UserInvite, now time.Time) string {
+	switch {
+	case invite.AcceptedAt != nil:
+		return "accepted"
+	case invite.RevokedAt != nil:
+		return "revoked"
+	case !now.Before(invite.ExpiresAt):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+func buildInviteResponse(invite *models.UserInvite, now time.Time) dto.InviteResponse {
+	response := dto.InviteResponse{
+		ID:        invite.ID,
+		Email:     invite.Email,
+		Status:    inviteStatus(invite, now),
+		CreatedAt: invite.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+	}
+	if invite.InvitedBy != nil {
+		response.InvitedBy = invite.InvitedBy.Username
+	}
+	if invite.AcceptedAt != nil {
+		response.AcceptedAt = invite.AcceptedAt.Format(time.RFC3339)
+	}
+	if invite.RevokedAt != nil {
+		response.RevokedAt = invite.RevokedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// CreateInvite invites an email address to create an account. The invite link is returned and,
+// when SMTP is configured, emailed. An earlier pending invite for the address is revoked.
+func (s *ETLService) CreateInvite(ctx context.Context, invitedBy int, email string, expiresInHours int) (*dto.InviteResponse, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email '%s': %s", email, err)
+	}
+	email = address.Address
+
+	exists, err := s.db.UserEmailExists(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %s", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: a user with email %s already exists", constants.ErrUserAlreadyExists, email)
+	}
+
+	if expiresInHours <= 0 {
+		expiresInHours = web.AppConfig.DefaultInt(constants.ConfInviteExpiryHours, constants.DefaultInviteExpiryHours)
+	}
+	invite := &models.UserInvite{
+		Email:     email,
+		InvitedBy: &models.User{ID: invitedBy},
+		ExpiresAt: time.Now().UTC().Add(time.Duration(expiresInHours) * time.Hour),
+	}
+	if err := s.db.CreateInvite(invite); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.SignToken(tokenPurposeInvite, strconv.Itoa(invite.ID), invite.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign invite: %s", err)
+	}
+
+	response := buildInviteResponse(invite, time.Now())
+	if inviter, err := s.db.GetUserByID(invitedBy); err == nil {
+		response.InvitedBy = inviter.Username
+	}
+	response.InviteURL = appLink("/invite", token)
+
+	if utils.EmailEnabled() {
+		body := fmt.Sprintf("%s invited you to OLake.\n\nCreate your account here:\n%s\n\nThe link expires on %s.\n",
+			response.InvitedBy, response.InviteURL, invite.ExpiresAt.Format(time.RFC1123))
+		if err := utils.SendEmail(ctx, email, "You are invited to OLake", body); err != nil {
+			logger.Warnf("failed to email invite id[%d]: %s", invite.ID, err)
+			response.EmailError = err.Error()
+		} else {
+			response.EmailSent = true
+		}
+	}
+	return &response, nil
+}
+
+// ListInvites returns all invites, newest first
+func (s *ETLService) ListInvites(_ context.Context) ([]dto.InviteResponse, error) {
+	invites, err := s.db.ListInvites()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %s", err)
+	}
+	now := time.Now()
+	response := make([]dto.InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		response = append(response, buildInviteResponse(invite, now))
+	}
+	return response, nil
+}
+
+// RevokeInvite revokes an invite that was not accepted yet
+func (s *ETLService) RevokeInvite(_ context.Context, id int) error {
+	revoked, err := s.db.RevokeInvite(id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %s", err)
+	}
+	if !revoked {
+		return fmt.Errorf("%w: no pending invite with id[%d]", constants.ErrInviteNotFound, id)
+	}
+	return nil
+}
+
+// pendingInvite returns the invite of a token if it can still be accepted
+func (s *ETLService) pendingInvite(token string) (*models.UserInvite, error) {
+	subject, err := utils.VerifyToken(token, tokenPurposeInvite)
+	if err != nil {
+		return nil, err
+	}
+	inviteID, err := strconv.Atoi(subject)
+	if err != nil {
+		return nil, constants.ErrInvalidToken
+	}
+	invite, err := s.db.GetInviteByID(inviteID)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, constants.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get invite: %s", err)
+	}
+	if inviteStatus(invite, time.Now()) != "pending" {
+		return nil, constants.ErrInvalidToken
+	}
+	return invite, nil
+}
+
+// GetInviteInfo returns the email an invite token is for, so the UI can show it before signup
+func (s *ETLService) GetInviteInfo(_ context.Context, token string) (*dto.InviteInfoResponse, error) {
+	invite, err := s.pendingInvite(token)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.InviteInfoResponse{
+		Email:     invite.Email,
+		ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// AcceptInvite creates the account of an invite. The email is verified since the invite was
+// delivered to it. Invites work while open signup is disabled.
+func (s *ETLService) AcceptInvite(_ context.Context, token, username, password string) (*models.User, error) {
+	invite, err := s.pendingInvite(token)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	user := &models.User{
+		Username:        username,
+		Email:           invite.Email,
+		Password:        hashedPassword,
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.db.AcceptInvite(invite.ID, user); err != nil {
+		if errors.Is(err, constants.ErrInvalidToken) || errors.Is(err, constants.ErrUserAlreadyExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to accept invite: %s", err)
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// SendEmailVerification emails a verification link to the address of a user
+func (s *ETLService) SendEmailVerification(ctx context.Context, userID int) error {
+	if !utils.EmailEnabled() {
+		return constants.ErrEmailNotConfigured
+	}
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+	if user.EmailVerified {
+		return fmt.Errorf("%w: email is already verified", constants.ErrInvalidUserAction)
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(web.AppConfig.DefaultInt(constants.ConfEmailVerifyExpiryHours, constants.DefaultEmailVerifyExpiryHours)) * time.Hour)
+	token, err := utils.SignToken(tokenPurposeVerifyEmail, fmt.Sprintf("%d:%s", user.ID, user.Email), expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to sign verification link: %s", err)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your email address for OLake here:\n%s\n\nThe link expires on %s.\n",
+		user.Username, appLink("/verify-email", token), expiresAt.Format(time.RFC1123))
+	if err := utils.SendEmail(ctx, user.Email, "Verify your email for OLake", body); err != nil {
+		return fmt.Errorf("failed to send verification email: %s", err)
+	}
+	return nil
+}
+
+// VerifyEmail marks an email verified from the token of a verification link
+func (s *ETLService) VerifyEmail(_ context.Context, token string) error {
+	subject, err := utils.VerifyToken(token, tokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	rawID, email, ok := strings.Cut(subject, ":")
+	if !ok {
+		return constants.ErrInvalidToken
+	}
+	userID, err := strconv.Atoi(rawID)
+	if err != nil {
+		return constants.ErrInvalidToken
+	}
+
+	verified, err := s.db.SetUserEmailVerified(userID, email)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %s", err)
+	}
+	if !verified {
+		// the user is gone or changed the address since the link was sent
+		return constants.ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// Log retention methods on AppService
+//
+// Every workflow leaves a directory in the storage backend (constants.DefaultConfigDir
+// for local storage, STORAGE_S3_PREFIX in the bucket for s3). Direct executions (discover, check, spec, difference, dry-run, preview) use the
+// workflow ID as directory name, scheduled runs (sync, clear-destination) use the sha256 of the execution workflow ID, and
+// clear-destination additionally writes a "sync-<project>-<job>-<unix>" streams folder, manual
+// syncs a "sync-<project>-<job>-manual-<unix>" one.
+
+// WorkflowKind groups config directories that share a retention period
+type WorkflowKind string
+
+const (
+	WorkflowKindSync       WorkflowKind = "sync"
+	WorkflowKindClearTemp  WorkflowKind = "clear-destination"
+	WorkflowKindDiscover   WorkflowKind = "discover"
+	WorkflowKindCheck      WorkflowKind = "check"
+	WorkflowKindSpec       WorkflowKind = "spec"
+	WorkflowKindDifference WorkflowKind = "difference"
+	WorkflowKindDryRun     WorkflowKind = "dry-run"
+)
+
+// workflowKindPrefixes maps directory name prefixes of direct executions to their kind
+var workflowKindPrefixes = []struct {
+	prefix string
+	kind   WorkflowKind
+}{
+	{"discover-catalog-", WorkflowKindDiscover},
+	{"test-connection-", WorkflowKindCheck},
+	{"upgrade-check-", WorkflowKindCheck},
+	{"fetch-spec-", WorkflowKindSpec},
+	{"difference-", WorkflowKindDifference},
+	{"dry-run-", WorkflowKindDryRun},
+	{"preview-", WorkflowKindDryRun},
+	{"sync-", WorkflowKindClearTemp},
+}
+
+// projectDirPrefixes are the prefixes of directories named after the project they ran for, the
+// project ID follows the prefix. Discover, check and spec directories belong to no project.
+var projectDirPrefixes = []string{"sync-", "dry-run-", "preview-", "difference-"}
+
+// logCleanupMu serializes the periodic janitor and manually triggered cleanups
+var logCleanupMu sync.Mutex
+
+// LogRetentionPolicy holds how long each kind of workflow directory is kept
+type LogRetentionPolicy struct {
+	Retention map[WorkflowKind]time.Duration
+	KeepRuns  int
+}
+
+// configDirEntry is a workflow directory found under the config dir
+type configDirEntry struct {
+	name         string
+	kind         WorkflowKind
+	bytes        int64
+	lastModified time.Time
+}
+
+// workflowRun links a hashed sync directory back to the job run that wrote it
+type workflowRun struct {
+	jobID     int
+	projectID string
+	startTime time.Time
+}
+
+func loadLogRetentionPolicy() LogRetentionPolicy {
+	days := func(key string, def int) time.Duration {
+		return time.Duration(web.AppConfig.DefaultInt(key, def)) * 24 * time.Hour
+	}
+
+	return LogRetentionPolicy{
+		Retention: map[WorkflowKind]time.Duration{
+			WorkflowKindSync:       days(constants.ConfLogRetentionPeriod, constants.DefaultLogRetentionPeriod),
+			WorkflowKindClearTemp:  days(constants.ConfLogRetentionPeriod, constants.DefaultLogRetentionPeriod),
+			WorkflowKindDiscover:   days(constants.ConfLogRetentionDiscover, constants.DefaultShortLivedLogRetention),
+			WorkflowKindCheck:      days(constants.ConfLogRetentionCheck, constants.DefaultShortLivedLogRetention),
+			WorkflowKindSpec:       days(constants.ConfLogRetentionSpec, constants.DefaultShortLivedLogRetention),
+			WorkflowKindDifference: days(constants.ConfLogRetentionDifference, constants.DefaultShortLivedLogRetention),
+			WorkflowKindDryRun:     days(constants.ConfLogRetentionDryRun, constants.DefaultShortLivedLogRetention),
+		},
+		KeepRuns: web.AppConfig.DefaultInt(constants.ConfLogRetentionKeepRuns, constants.DefaultLogRetentionKeepRuns),
+	}
+}
+
+// StartLogJanitor periodically removes expired workflow directories until ctx is done
+func (s *ETLService) StartLogJanitor(ctx context.Context) {
+	interval := time.Duration(web.AppConfig.DefaultInt(constants.ConfLogJanitorInterval, constants.DefaultLogJanitorInterval)) * time.Minute
+	if interval <= 0 {
+		logger.Info("log janitor disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.CleanupLogs(ctx, "")
+				if err != nil {
+					logger.Errorf("log janitor run failed: %s", err)
+					continue
+				}
+				if len(report.Deleted) > 0 {
+					logger.Infof("log janitor removed %d directories, freed %d bytes", len(report.Deleted), report.FreedBytes)
+				}
+			}
+		}
+	}()
+}
+
+// CleanupLogs removes workflow directories past their retention period. The latest
+// KeepRuns runs of every job and directories of running workflows are always kept. With a
+// project ID only the directories of that project are removed, otherwise every directory.
+func (s *ETLService) CleanupLogs(ctx context.Context, projectID string) (*dto.LogCleanupResponse, error) {
+	logCleanupMu.Lock()
+	defer logCleanupMu.Unlock()
+
+	policy := loadLogRetentionPolicy()
+
+	entries, err := scanConfigDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*models.Job
+	if projectID != "" {
+		jobs, err = s.db.ListJobsByProjectID(projectID)
+	} else {
+		jobs, err = s.db.ListJobs()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %s", err)
+	}
+
+	// without a complete view of running workflows nothing can be deleted safely
+	runs, err := collectWorkflowRuns(ctx, s, jobs)
+	if err != nil {
+		return nil, err
+	}
+	running, err := collectRunningWorkflowDirs(ctx, s, jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := latestRunDirs(runs, policy.KeepRuns)
+	now := time.Now()
+	report := &dto.LogCleanupResponse{Deleted: []string{}}
+
+	for _, entry := range entries {
+		if projectID != "" && !entryInProject(entry.name, projectID, runs) {
+			continue
+		}
+		if _, ok := running[entry.name]; ok {
+			continue
+		}
+		if jobWorkflowID, ok := clearDestinationJobWorkflowID(entry.name); ok {
+			if _, ok := running[jobWorkflowID]; ok {
+				continue
+			}
+		}
+		if _, ok := kept[entry.name]; ok {
+			continue
+		}
+		// a workflow that started after the running list was fetched has fresh files,
+		// so the age check also protects it
+		if now.Sub(entry.lastModified) < policy.Retention[entry.kind] {
+			continue
+		}
+
+		if err := storage.Get().Delete(ctx, entry.name); err != nil {
+			logger.Warnf("failed to remove expired workflow directory %s: %s", entry.name, err)
+			continue
+		}
+		report.Deleted = append(report.Deleted, entry.name)
+		report.FreedBytes += entry.bytes
+	}
+
+	return report, nil
+}
+
+// GetLogDiskUsage reports disk usage of the directories of a project per workflow kind and per job
+func (s *ETLService) GetLogDiskUsage(ctx context.Context, projectID string) (*dto.LogDiskUsageResponse, error) {
+	policy := loadLogRetentionPolicy()
+
+	entries, err := scanConfigDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.db.ListJobsByProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %s", err)
+	}
+
+	runs, err := collectWorkflowRuns(ctx, s, jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &dto.LogDiskUsageResponse{
+		ConfigDir:     storage.Get().Location(),
+		KeepRuns:      policy.KeepRuns,
+		RetentionDays: make(map[string]int, len(policy.Retention)),
+		Kinds:         []dto.LogKindUsage{},
+		Jobs:          []dto.LogJobUsage{},
+	}
+	for kind, retention := range policy.Retention {
+		usage.RetentionDays[string(kind)] = int(retention.Hours() / 24)
+	}
+
+	kindUsage := make(map[WorkflowKind]*dto.LogKindUsage)
+	jobUsage := make(map[int]*dto.LogJobUsage)
+	for _, entry := range entries {
+		if !entryInProject(entry.name, projectID, runs) {
+			continue
+		}
+		usage.TotalBytes += entry.bytes
+
+		ku, ok := kindUsage[entry.kind]
+		if !ok {
+			ku = &dto.LogKindUsage{Kind: string(entry.kind)}
+			kindUsage[entry.kind] = ku
+		}
+		ku.Directories++
+		ku.Bytes += entry.bytes
+
+		if run, ok := runs[entry.name]; ok {
+			ju, ok := jobUsage[run.jobID]
+			if !ok {
+				ju = &dto.LogJobUsage{JobID: run.jobID}
+				jobUsage[run.jobID] = ju
+			}
+			ju.Runs++
+			ju.Bytes += entry.bytes
+		}
+	}
+
+	for _, ku := range kindUsage {
+		usage.Kinds = append(usage.Kinds, *ku)
+	}
+	sort.Slice(usage.Kinds, func(i, j int) bool { return usage.Kinds[i].Bytes > usage.Kinds[j].Bytes })
+
+	for _, job := range jobs {
+		if ju, ok := jobUsage[job.ID]; ok {
+			ju.JobName = job.Name
+			usage.Jobs = append(usage.Jobs, *ju)
+		}
+	}
+	sort.Slice(usage.Jobs, func(i, j int) bool { return usage.Jobs[i].Bytes > usage.Jobs[j].Bytes })
+
+	return usage, nil
+}
+
+// classifyConfigDir returns the workflow kind of a config dir entry, or "" for
+// directories the janitor does not own (e.g. telemetry)
+func classifyConfigDir(name string) WorkflowKind {
+	if len(name) == sha256.Size*2 {
+		if _, err := hex.DecodeString(name); err == nil {
+			return WorkflowKindSync
+		}
+	}
+
+	for _, p := range workflowKindPrefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			return p.kind
+		}
+	}
+	return ""
+}
+
+// scanConfigDir lists the workflow directories under the config dir with their size
+// and the modification time of their most recently written file
+func scanConfigDir(ctx context.Context) ([]configDirEntry, error) {
+	files, err := storage.Get().Walk(ctx, "")
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config directory %s: %s", storage.Get().Location(), err)
+	}
+
+	byName := make(map[string]*configDirEntry)
+	for _, file := range files {
+		name, _, isNested := strings.Cut(file.Path, "/")
+		if !isNested {
+			continue
+		}
+		kind := classifyConfigDir(name)
+		if kind == "" {
+			continue
+		}
+
+		entry, ok := byName[name]
+		if !ok {
+			entry = &configDirEntry{name: name, kind: kind}
+			byName[name] = entry
+		}
+		entry.bytes += file.Size
+		if file.ModTime.After(entry.lastModified) {
+			entry.lastModified = file.ModTime
+		}
+	}
+
+	entries := make([]configDirEntry, 0, len(byName))
+	for _, entry := range byName {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	return entries, nil
+}
+
+// entryInProject reports whether a workflow directory belongs to a project, runs maps the hashed
+// sync directories of the project's jobs
+func entryInProject(name, projectID string, runs map[string]workflowRun) bool {
+	if run, ok := runs[name]; ok {
+		return run.projectID == projectID
+	}
+	for _, prefix := range projectDirPrefixes {
+		if strings.HasPrefix(name, prefix+projectID+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// collectWorkflowRuns maps hashed sync directory names to the job runs known to temporal
+func collectWorkflowRuns(ctx context.Context, s *ETLService, jobs []*models.Job) (map[string]workflowRun, error) {
+	projects := make(map[string]struct{})
+	for _, job := range jobs {
+		projects[job.ProjectID] = struct{}{}
+	}
+
+	runs := make(map[string]workflowRun)
+	for projectID := range projects {
+		query := fmt.Sprintf("WorkflowId between 'sync-%s-' and 'sync-%s-~'", projectID, projectID)
+		err := listAllWorkflows(ctx, s, query, func(workflowID string, startTime time.Time) {
+			jobID, ok := utils.ExtractJobIDFromWorkflowID(workflowID, projectID)
+			if !ok {
+				return
+			}
+			runs[fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID)))] = workflowRun{
+				jobID:     jobID,
+				projectID: projectID,
+				startTime: startTime,
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sync workflows project_id[%s]: %s", projectID, err)
+		}
+	}
+	return runs, nil
+}
+
+// collectRunningWorkflowDirs returns the directory names (raw and hashed) of every running workflow,
+// and the sync workflow ID of every job with a running workflow, which names the streams folders of
+// its clear-destination runs
+func collectRunningWorkflowDirs(ctx context.Context, s *ETLService, jobs []*models.Job) (map[string]struct{}, error) {
+	projects := make(map[string]struct{})
+	for _, job := range jobs {
+		projects[job.ProjectID] = struct{}{}
+	}
+
+	running := make(map[string]struct{})
+	err := listAllWorkflows(ctx, s, "ExecutionStatus = 'Running'", func(workflowID string, _ time.Time) {
+		running[workflowID] = struct{}{}
+		running[fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID)))] = struct{}{}
+		for projectID := range projects {
+			if jobID, ok := utils.ExtractJobIDFromWorkflowID(workflowID, projectID); ok {
+				running[fmt.Sprintf("sync-%s-%d", projectID, jobID)] = struct{}{}
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running workflows: %s", err)
+	}
+	return running, nil
+}
+
+// clearDestinationJobWorkflowID returns the sync workflow ID of the job that a clear-destination
+// streams folder ("sync-<project>-<job>-<unix>") was written for. The folder is read by a scheduled
+// run whose workflow ID temporal derives from the schedule, so it is matched by job instead.
+func clearDestinationJobWorkflowID(name string) (string, bool) {
+	if classifyConfigDir(name) != WorkflowKindClearTemp || temporal.IsManualSyncWorkflowID(name) {
+		return "", false
+	}
+	i := strings.LastIndex(name, "-")
+	if _, err := strconv.ParseInt(name[i+1:], 10, 64); err != nil {
+		return "", false
+	}
+	return name[:i], true
+}
+
+// listAllWorkflows pages through temporal visibility results for the query
+func listAllWorkflows(ctx context.Context, s *ETLService, query string, fn func(workflowID string, startTime time.Time)) error {
+	var nextPageToken []byte
+	for {
+		resp, err := s.temporal.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Query:         query,
+			PageSize:      int32(constants.DefaultListWorkflowPageSize),
+			NextPageToken: nextPageToken,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, execution := range resp.Executions {
+			fn(execution.Execution.WorkflowId, execution.StartTime.AsTime())
+		}
+
+		if len(resp.NextPageToken) == 0 {
+			return nil
+		}
+		nextPageToken = resp.NextPageToken
+	}
+}
+
+// latestRunDirs returns the directories of the latest keepRuns runs of every job
+func latestRunDirs(runs map[string]workflowRun, keepRuns int) map[string]struct{} {
+	type runDir struct {
+		dir       string
+		startTime time.Time
+	}
+
+	byJob := make(map[string][]runDir)
+	for dir, run := range runs {
+		key := fmt.Sprintf("%s-%d", run.projectID, run.jobID)
+		byJob[key] = append(byJob[key], runDir{dir: dir, startTime: run.startTime})
+	}
+
+	kept := make(map[string]struct{})
+	for _, dirs := range byJob {
+		sort.Slice(dirs, func(i, j int) bool { return dirs[i].startTime.After(dirs[j].startTime) })
+		for i := 0; i < len(dirs) && i < keepRuns; i++ {
+			kept[dirs[i].dir] = struct{}{}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClearDestinationJobWorkflowID(t *testing.T) {
+	jobWorkflowID, ok := clearDestinationJobWorkflowID("sync-123-7-1760000000")
+	require.True(t, ok)
+	require.Equal(t, "sync-123-7", jobWorkflowID)
+
+	// manual syncs are named after their own workflow and matched directly
+	_, ok = clearDestinationJobWorkflowID("sync-123-7-manual-1760000000")
+	require.False(t, ok)
+
+	_, ok = clearDestinationJobWorkflowID("dry-run-123-1760000000")
+	require.False(t, ok)
+	_, ok = clearDestinationJobWorkflowID("sync-123-7-latest")
+	require.False(t, ok)
+}
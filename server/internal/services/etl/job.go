@@ -2,13 +2,13 @@ package services
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
 	"time"
 
@@ -19,17 +19,27 @@ import (
 	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
 	"github.com/datazip-inc/olake-ui/server/utils"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
 	"github.com/datazip-inc/olake-ui/server/utils/telemetry"
 	"go.temporal.io/api/workflowservice/v1"
 )
 
 // Job-related methods on AppService
 
-func (s *ETLService) ListJobs(ctx context.Context, projectID string) ([]dto.JobResponse, error) {
-	jobs, err := s.db.ListJobsByProjectID(projectID)
+// ListJobs returns the jobs of a project, only the jobs carrying every label of selector when it
+// is set
+func (s *ETLService) ListJobs(ctx context.Context, projectID string, selector map[string]string) ([]dto.JobResponse, error) {
+	projectJobs, err := s.db.ListJobsByProjectID(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list jobs: %s", err)
 	}
+	jobs := make([]*models.Job, 0, len(projectJobs))
+	for _, job := range projectJobs {
+		if labelsMatch(decodeLabels(job.Labels), selector) {
+			jobs = append(jobs, job)
+		}
+	}
 
 	lastRunByJobID, err := fetchLatestJobRunsByJobIDs(ctx, s.temporal, projectID, jobs)
 	if err != nil {
@@ -43,7 +53,7 @@ func (s *ETLService) ListJobs(ctx context.Context, projectID string) ([]dto.JobR
 			lastRun = &lr
 		}
 
-		jobResp, err := s.buildJobResponse(job, lastRun, false)
+		jobResp, err := s.buildJobResponse(ctx, job, lastRun, false)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build job response: %s", err)
 		}
@@ -72,7 +82,7 @@ func (s *ETLService) GetJob(ctx context.Context, projectID string, jobID int) (*
 		lastRun = &lr
 	}
 
-	jobResponse, err := s.buildJobResponse(job, lastRun, true)
+	jobResponse, err := s.buildJobResponse(ctx, job, lastRun, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build job response: %s", err)
 	}
@@ -81,22 +91,41 @@ func (s *ETLService) GetJob(ctx context.Context, projectID string, jobID int) (*
 }
 
 func (s *ETLService) CreateJob(ctx context.Context, req *dto.CreateJobRequest, projectID string, userID *int) error {
+	_, err := s.createJob(ctx, req, projectID, userID)
+	return err
+}
+
+// createJob creates a job and its schedule, creating the source and destination too unless the
+// request references existing ones by ID
+func (s *ETLService) createJob(ctx context.Context, req *dto.CreateJobRequest, projectID string, userID *int) (*models.Job, error) {
 	unique, err := s.db.IsJobNameUniqueInProject(ctx, projectID, req.Name)
 	if err != nil {
-		return fmt.Errorf("failed to check job name uniqueness: %s", err)
+		return nil, fmt.Errorf("failed to check job name uniqueness: %s", err)
 	}
 	if !unique {
-		return fmt.Errorf("job name '%s' is not unique", req.Name)
+		return nil, fmt.Errorf("job name '%s' is not unique", req.Name)
+	}
+	if err := validateLabels(req.Labels); err != nil {
+		return nil, err
+	}
+	if err := validateResourceLimits(req.CPULimit, req.MemoryLimit); err != nil {
+		return nil, err
+	}
+	if err := checkResourceLimitsSupported("", "", req.CPULimit, req.MemoryLimit); err != nil {
+		return nil, err
+	}
+	if err := s.checkJobSecretRefsSupported(req.Source, req.Destination); err != nil {
+		return nil, err
 	}
 
 	source, err := s.upsertSource(ctx, req.Source, projectID, userID)
 	if err != nil {
-		return fmt.Errorf("failed to process source: %s", err)
+		return nil, fmt.Errorf("failed to process source: %s", err)
 	}
 
 	dest, err := s.upsertDestination(ctx, req.Destination, projectID, userID)
 	if err != nil {
-		return fmt.Errorf("failed to process destination: %s", err)
+		return nil, fmt.Errorf("failed to process destination: %s", err)
 	}
 
 	user := &models.User{ID: *userID}
@@ -108,12 +137,15 @@ func (s *ETLService) CreateJob(ctx context.Context, req *dto.CreateJobRequest, p
 		Frequency:     req.Frequency,
 		StreamsConfig: req.StreamsConfig,
 		State:         "{}",
+		Labels:        encodeLabels(req.Labels),
+		CPULimit:      req.CPULimit,
+		MemoryLimit:   req.MemoryLimit,
 		ProjectID:     projectID,
 		CreatedBy:     user,
 		UpdatedBy:     user,
 	}
 	if err := s.db.CreateJob(job); err != nil {
-		return fmt.Errorf("failed to create job: %s", err)
+		return nil, fmt.Errorf("failed to create job: %s", err)
 	}
 
 	defer func() {
@@ -125,11 +157,73 @@ func (s *ETLService) CreateJob(ctx context.Context, req *dto.CreateJobRequest, p
 	}()
 
 	if err = s.temporal.CreateSchedule(ctx, job); err != nil {
-		return fmt.Errorf("failed to create temporal workflow: %s", err)
+		return nil, fmt.Errorf("failed to create temporal workflow: %s", err)
 	}
 
 	telemetry.TrackJobCreation(ctx, job)
-	return nil
+	return job, nil
+}
+
+// getProjectJob returns a job with decrypted configs, failing with ErrJobNotFound when it does
+// not belong to the project
+func (s *ETLService) getProjectJob(projectID string, jobID int) (*models.Job, error) {
+	job, err := s.db.GetJobByID(jobID, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrJobNotFound, err)
+	}
+	if job.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: id %d", constants.ErrJobNotFound, jobID)
+	}
+	return job, nil
+}
+
+// CloneJob creates a copy of a job with its streams config and a fresh state. The source,
+// destination and frequency of the original are kept unless the request overrides them.
+func (s *ETLService) CloneJob(ctx context.Context, projectID string, jobID int, req *dto.CloneJobRequest, userID *int) (*dto.CloneJobResponse, error) {
+	original, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID := original.SourceID.ID
+	if req.SourceID != nil {
+		source, err := s.db.GetSourceByID(*req.SourceID)
+		if err != nil || source.ProjectID != projectID {
+			return nil, fmt.Errorf("source %d not found in project", *req.SourceID)
+		}
+		sourceID = source.ID
+	}
+
+	destinationID := original.DestID.ID
+	if req.DestinationID != nil {
+		dest, err := s.db.GetDestinationByID(*req.DestinationID)
+		if err != nil || dest.ProjectID != projectID {
+			return nil, fmt.Errorf("destination %d not found in project", *req.DestinationID)
+		}
+		destinationID = dest.ID
+	}
+
+	frequency := original.Frequency
+	if req.Frequency != "" {
+		frequency = req.Frequency
+	}
+
+	job, err := s.createJob(ctx, &dto.CreateJobRequest{
+		Name:          req.Name,
+		Source:        &dto.DriverConfig{ID: &sourceID},
+		Destination:   &dto.DriverConfig{ID: &destinationID},
+		Frequency:     frequency,
+		StreamsConfig: original.StreamsConfig,
+		Labels:        decodeLabels(original.Labels),
+		CPULimit:      original.CPULimit,
+		MemoryLimit:   original.MemoryLimit,
+	}, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("cloned job %d into job %d", jobID, job.ID)
+	return &dto.CloneJobResponse{JobID: job.ID}, nil
 }
 
 func (s *ETLService) UpdateJob(ctx context.Context, req *dto.UpdateJobRequest, projectID string, jobID int, userID *int) error {
@@ -138,6 +232,27 @@ func (s *ETLService) UpdateJob(ctx context.Context, req *dto.UpdateJobRequest, p
 	if err != nil {
 		return fmt.Errorf("failed to get job: %s", err)
 	}
+	if req.Labels != nil {
+		if err := validateLabels(req.Labels); err != nil {
+			return err
+		}
+	}
+	cpuLimit, memoryLimit := existingJob.CPULimit, existingJob.MemoryLimit
+	if req.CPULimit != nil {
+		cpuLimit = *req.CPULimit
+	}
+	if req.MemoryLimit != nil {
+		memoryLimit = *req.MemoryLimit
+	}
+	if err := validateResourceLimits(cpuLimit, memoryLimit); err != nil {
+		return err
+	}
+	if err := checkResourceLimitsSupported(existingJob.CPULimit, existingJob.MemoryLimit, cpuLimit, memoryLimit); err != nil {
+		return err
+	}
+	if err := s.checkJobSecretRefsSupported(req.Source, req.Destination); err != nil {
+		return err
+	}
 
 	// Block when clear-destination is running
 	clearRunning, _, err := isWorkflowRunning(ctx, s.temporal, projectID, jobID, temporal.ClearDestination)
@@ -154,6 +269,7 @@ func (s *ETLService) UpdateJob(ctx context.Context, req *dto.UpdateJobRequest, p
 	}
 
 	// Handle stream difference if provided
+	clearTriggered := false
 	if req.DifferenceStreams != "" {
 		var diffCatalog map[string]interface{}
 		if err := json.Unmarshal([]byte(req.DifferenceStreams), &diffCatalog); err != nil {
@@ -163,6 +279,7 @@ func (s *ETLService) UpdateJob(ctx context.Context, req *dto.UpdateJobRequest, p
 			if err := s.ClearDestination(ctx, projectID, jobID, req.DifferenceStreams, constants.DefaultCancelSyncWaitTime, false); err != nil {
 				return fmt.Errorf("failed to run clear destination workflow: %s", err)
 			}
+			clearTriggered = true
 			logger.Infof("successfully triggered clear destination workflow for job %d", existingJob.ID)
 		}
 	}
@@ -196,8 +313,15 @@ func (s *ETLService) UpdateJob(ctx context.Context, req *dto.UpdateJobRequest, p
 		"frequency":      req.Frequency,
 		"streams_config": req.StreamsConfig,
 		"project_id":     projectID,
+		"cpu_limit":      cpuLimit,
+		"memory_limit":   memoryLimit,
 		"updated_by_id":  *userID,
 	}
+	labels := existingJob.Labels
+	if req.Labels != nil {
+		labels = encodeLabels(req.Labels)
+		updateParams["labels"] = labelsParam(labels)
+	}
 
 	// Update job within transaction
 	if err := s.db.UpdateJobWithTx(tx, existingJob.ID, updateParams); err != nil {
@@ -209,14 +333,32 @@ func (s *ETLService) UpdateJob(ctx context.Context, req *dto.UpdateJobRequest, p
 		return fmt.Errorf("failed to commit transaction: %s", err)
 	}
 
-	// Update temporal schedule only if frequency has changed
-	if req.Frequency != existingJob.Frequency {
+	// Rebuild the sync request of the schedule when the resource limits changed, a running
+	// clear-destination restores it once it is done
+	resourcesChanged := cpuLimit != existingJob.CPULimit || memoryLimit != existingJob.MemoryLimit
+	if resourcesChanged && !clearTriggered {
+		updatedJob, err := s.db.GetJobByID(existingJob.ID, false)
+		if err != nil {
+			return fmt.Errorf("failed to get updated job: %s", err)
+		}
+		if err := s.temporal.RestoreSyncSchedule(ctx, updatedJob); err != nil {
+			logger.Errorf("job updated in database but failed to update temporal schedule: %s", err)
+			return fmt.Errorf("failed to update temporal workflow: %s", err)
+		}
+	} else if req.Frequency != existingJob.Frequency {
+		// Update temporal schedule only if frequency has changed
 		err = s.temporal.UpdateSchedule(ctx, req.Frequency, projectID, existingJob.ID, nil)
 		if err != nil {
 			logger.Errorf("job updated in database but failed to update temporal schedule: %s", err)
 			return fmt.Errorf("failed to update temporal workflow: %s", err)
 		}
 	}
+	if labels != existingJob.Labels {
+		if err := s.temporal.UpdateScheduleLabels(ctx, projectID, existingJob.ID, labels); err != nil {
+			logger.Errorf("job updated in database but failed to update temporal schedule labels: %s", err)
+			return fmt.Errorf("failed to update temporal workflow: %s", err)
+		}
+	}
 
 	return nil
 }
@@ -247,6 +389,12 @@ func (s *ETLService) SyncJob(ctx context.Context, projectID string, jobID int) (
 	if !job.Active {
 		return nil, fmt.Errorf("job is paused, please unpause to run sync")
 	}
+	if err := checkSecretRefsSupported(job.SourceID.Config, job.DestID.Config); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNoMaintenance(ctx, projectID, jobID); err != nil {
+		return nil, err
+	}
 
 	if err := s.temporal.TriggerSchedule(ctx, projectID, jobID); err != nil {
 		return nil, fmt.Errorf("failed to trigger sync: %s", err)
@@ -308,7 +456,10 @@ func (s *ETLService) ClearDestination(ctx context.Context, projectID string, job
 		return fmt.Errorf("job not found: %s", err)
 	}
 
-	if err := CheckClearDestinationCompatibility(job.SourceID.Version); err != nil {
+	if err := s.checkClearDestinationCompatibility(job.SourceID.Type, job.SourceID.Version); err != nil {
+		return err
+	}
+	if err := checkSecretRefsSupported(job.SourceID.Config, job.DestID.Config); err != nil {
 		return err
 	}
 
@@ -333,7 +484,7 @@ func (s *ETLService) ClearDestination(ctx context.Context, projectID string, job
 
 	// for manual clear-destination, update the state file to empty object
 	if resetState {
-		if err := s.UpdateStateFile(jobID, "{}"); err != nil {
+		if _, err := s.saveJobState(jobID, map[string]interface{}{}, &models.JobStateVersion{Action: JobStateActionReset}, nil); err != nil {
 			return fmt.Errorf("failed to update state file: %s", err)
 		}
 		logger.Infof("state file updated to {} for manual clear-destination for job_id[%d]", jobID)
@@ -357,7 +508,7 @@ func (s *ETLService) GetStreamDifference(ctx context.Context, _ string, jobID in
 		return nil, fmt.Errorf("job not found: %s", err)
 	}
 
-	if err := CheckClearDestinationCompatibility(job.SourceID.Version); err != nil {
+	if err := s.checkClearDestinationCompatibility(job.SourceID.Type, job.SourceID.Version); err != nil {
 		return nil, err
 	}
 
@@ -410,7 +561,9 @@ func (s *ETLService) CheckUniqueName(ctx context.Context, projectID string, req
 	return unique, nil
 }
 
-func (s *ETLService) GetJobTasks(ctx context.Context, projectID string, jobID int) ([]dto.JobTask, error) {
+// GetJobTasks returns the runs of a job, only the runs started with every label of selector when
+// it is set
+func (s *ETLService) GetJobTasks(ctx context.Context, projectID string, jobID int, selector map[string]string) ([]dto.JobTask, error) {
 	job, err := s.db.GetJobByID(jobID, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find job: %s", err)
@@ -427,6 +580,11 @@ func (s *ETLService) GetJobTasks(ctx context.Context, projectID string, jobID in
 	}
 
 	for _, execution := range resp.Executions {
+		labels := temporal.RunLabels(execution)
+		if !labelsMatch(labels, selector) {
+			continue
+		}
+
 		startTime := execution.StartTime.AsTime().UTC()
 		var runTime string
 		if execution.CloseTime != nil {
@@ -443,41 +601,101 @@ func (s *ETLService) GetJobTasks(ctx context.Context, projectID string, jobID in
 			Status:    execution.Status.String(),
 			FilePath:  execution.Execution.WorkflowId,
 			JobType:   jobType,
+			Labels:    labels,
 		})
 	}
 
 	return tasks, nil
 }
 
-func (s *ETLService) GetTaskLogs(_ context.Context, jobID int, filePath string, cursor int64, limit int, direction string) (*dto.TaskLogsResponse, error) {
+func (s *ETLService) GetTaskLogs(ctx context.Context, jobID int, filePath, attempt, logType string, cursor int64, limit int, direction string) (*dto.TaskLogsResponse, error) {
 	_, err := s.db.GetJobByID(jobID, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find job: %s", err)
 	}
 
+	if logType == constants.LogTypeActivity {
+		return s.readActivityLogs(ctx, filePath, cursor, limit, direction)
+	}
+
 	// Get and validate base directory from file path
-	mainSyncDir, err := utils.GetAndValidateLogBaseDir(filePath)
+	mainSyncDir, err := utils.GetAndValidateLogBaseDir(ctx, filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	logs, err := utils.ReadLogs(mainSyncDir, cursor, limit, direction)
+	logs, err := utils.ReadAttemptLogs(ctx, mainSyncDir, attempt, logType, cursor, limit, direction)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read logs: %s", err)
 	}
-	// TODO: need to add activity logs as well with sync logs
 	return logs, nil
 }
 
+// readActivityLogs pages through the activity history of a run. The history covers every
+// attempt of the run, so cursors are indexes into the events of the run rather than byte offsets.
+func (s *ETLService) readActivityLogs(ctx context.Context, workflowID string, cursor int64, limit int, direction string) (*dto.TaskLogsResponse, error) {
+	events, err := s.temporal.WorkflowActivityEvents(ctx, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read activity logs: %s", err)
+	}
+
+	if limit <= 0 {
+		limit = constants.DefaultLogsLimit
+	}
+	// cursor < 0 tails from the latest event
+	total := int64(len(events))
+	isTail := cursor < 0
+	if isTail || cursor > total {
+		cursor = total
+	}
+
+	start, end := max(0, cursor-int64(limit)), cursor
+	if !isTail && strings.ToLower(strings.TrimSpace(direction)) == "newer" {
+		start, end = cursor, min(total, cursor+int64(limit))
+	}
+
+	response := &dto.TaskLogsResponse{
+		Logs:         activityLogEntries(events[start:end]),
+		OlderCursor:  start,
+		NewerCursor:  end,
+		HasMoreOlder: start > 0,
+		HasMoreNewer: end < total,
+		LogType:      constants.LogTypeActivity,
+	}
+	// attempt folders only exist once the connector started writing logs
+	if baseDir, err := utils.GetAndValidateLogBaseDir(ctx, workflowID); err == nil {
+		if _, attempts, err := utils.GetAndValidateSyncDirs(ctx, baseDir); err == nil {
+			response.Attempts = attempts
+		}
+	}
+	return response, nil
+}
+
+// activityLogEntries formats activity events like connector log entries
+func activityLogEntries(events []temporal.ActivityEvent) []map[string]interface{} {
+	entries := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		entries = append(entries, map[string]interface{}{
+			"level":   event.Level,
+			"time":    event.Time.Format(time.RFC3339),
+			"message": event.Message,
+		})
+	}
+	return entries
+}
+
 // TODO: frontend needs to send source id and destination id
-func (s *ETLService) buildJobResponse(job *models.Job, lastRun *JobLastRunInfo, includeConfig bool) (dto.JobResponse, error) {
+func (s *ETLService) buildJobResponse(ctx context.Context, job *models.Job, lastRun *JobLastRunInfo, includeConfig bool) (dto.JobResponse, error) {
 	jobResp := dto.JobResponse{
-		ID:        job.ID,
-		Name:      job.Name,
-		Frequency: job.Frequency,
-		CreatedAt: job.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: job.UpdatedAt.Format(time.RFC3339),
-		Activate:  job.Active,
+		ID:          job.ID,
+		Name:        job.Name,
+		Frequency:   job.Frequency,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   job.UpdatedAt.Format(time.RFC3339),
+		Activate:    job.Active,
+		Labels:      decodeLabels(job.Labels),
+		CPULimit:    job.CPULimit,
+		MemoryLimit: job.MemoryLimit,
 	}
 
 	jobResp.StreamsConfig = utils.Ternary(includeConfig, job.StreamsConfig, "").(string)
@@ -489,7 +707,13 @@ func (s *ETLService) buildJobResponse(job *models.Job, lastRun *JobLastRunInfo,
 			Type:    job.SourceID.Type,
 			Version: job.SourceID.Version,
 		}
-		jobResp.Source.Config = utils.Ternary(includeConfig, job.SourceID.Config, "").(string)
+		if includeConfig {
+			config, err := s.MaskSourceConfig(ctx, job.SourceID.Type, job.SourceID.Version, job.SourceID.Config)
+			if err != nil {
+				return dto.JobResponse{}, err
+			}
+			jobResp.Source.Config = config
+		}
 	}
 
 	if job.DestID != nil {
@@ -499,7 +723,13 @@ func (s *ETLService) buildJobResponse(job *models.Job, lastRun *JobLastRunInfo,
 			Type:    job.DestID.DestType,
 			Version: job.DestID.Version,
 		}
-		jobResp.Destination.Config = utils.Ternary(includeConfig, job.DestID.Config, "").(string)
+		if includeConfig {
+			config, err := s.MaskDestinationConfig(ctx, job.DestID.DestType, job.DestID.Version, job.DestID.Config)
+			if err != nil {
+				return dto.JobResponse{}, err
+			}
+			jobResp.Destination.Config = config
+		}
 	}
 
 	if job.CreatedBy != nil {
@@ -538,12 +768,20 @@ func (s *ETLService) upsertSource(ctx context.Context, config *dto.DriverConfig,
 		return nil, fmt.Errorf("source name '%s' is not unique", config.Name)
 	}
 
+	if err := secretref.Validate(config.Config); err != nil {
+		return nil, fmt.Errorf("invalid source config: %s", err)
+	}
+	sourceConfig, err := s.encryptSourceSecretFields(ctx, config.Type, config.Version, config.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret fields: %s", err)
+	}
+
 	user := &models.User{ID: *userID}
 
 	newSource := &models.Source{
 		Name:      config.Name,
 		Type:      config.Type,
-		Config:    config.Config,
+		Config:    sourceConfig,
 		Version:   config.Version,
 		ProjectID: projectID,
 		CreatedBy: user,
@@ -576,12 +814,20 @@ func (s *ETLService) upsertDestination(ctx context.Context, config *dto.DriverCo
 		return nil, fmt.Errorf("destination name '%s' is not unique", config.Name)
 	}
 
+	if err := secretref.Validate(config.Config); err != nil {
+		return nil, fmt.Errorf("invalid destination config: %s", err)
+	}
+	destConfig, err := s.encryptDestinationSecretFields(ctx, config.Type, config.Version, config.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret fields: %s", err)
+	}
+
 	user := &models.User{ID: *userID}
 
 	newDest := &models.Destination{
 		Name:      config.Name,
 		DestType:  config.Type,
-		Config:    config.Config,
+		Config:    destConfig,
 		Version:   config.Version,
 		ProjectID: projectID,
 		CreatedBy: user,
@@ -602,8 +848,10 @@ func (s *ETLService) UpdateSyncTelemetry(ctx context.Context, req dto.UpdateSync
 		telemetry.TrackSyncStart(ctx, req.JobID, req.WorkflowID, req.Environment)
 	case "completed":
 		telemetry.TrackSyncCompleted(req.JobID, req.WorkflowID, req.Environment)
+		return s.ReleaseSync(req.WorkflowID)
 	case "failed":
 		telemetry.TrackSyncFailed(req.JobID, req.WorkflowID, req.Environment)
+		return s.ReleaseSync(req.WorkflowID)
 	}
 
 	return nil
@@ -652,18 +900,18 @@ func (s *ETLService) RecoverFromClearDestination(ctx context.Context, projectID
 }
 
 // StreamLogArchive creates and streams a tar.gz archive of job logs to the provided writer
-func (s *ETLService) StreamLogArchive(jobID int, taskLogFilePath string, writer io.Writer) error {
-	baseDir, err := utils.GetAndValidateLogBaseDir(taskLogFilePath)
+func (s *ETLService) StreamLogArchive(ctx context.Context, jobID int, taskLogFilePath string, writer io.Writer) error {
+	baseDir, err := utils.GetAndValidateLogBaseDir(ctx, taskLogFilePath)
 	if err != nil {
 		return err
 	}
 
-	logsDir, _, err := utils.GetAndValidateSyncDir(baseDir)
+	logsDir, attempts, err := utils.GetAndValidateSyncDirs(ctx, baseDir)
 	if err != nil {
 		return err
 	}
 
-	logger.Infof("Starting log archive creation for job_id[%d]", jobID)
+	logger.Infof("Starting log archive creation for job_id[%d] attempts[%d]", jobID, len(attempts))
 
 	// Create streaming pipeline: tarWriter → gzipWriter → writer
 	gzipWriter := gzip.NewWriter(writer)
@@ -672,46 +920,45 @@ func (s *ETLService) StreamLogArchive(jobID int, taskLogFilePath string, writer
 	tarWriter := tar.NewWriter(gzipWriter)
 	defer tarWriter.Close()
 
-	stateFile := filepath.Join(baseDir, "state.json")
-	if err := utils.AddFileToArchive(tarWriter, stateFile, "state.json"); err != nil {
+	stateFile := path.Join(baseDir, "state.json")
+	if err := utils.AddFileToArchive(ctx, tarWriter, stateFile, "state.json"); err != nil {
 		logger.Warnf("failed to add state.json to archive: %s", err)
 		// Continue anyway - state.json might not exist
 	}
 
+	// Keep the folder layout so the logs of every attempt (logs/sync_*/olake.log, ...) end up
+	// in the archive without overwriting each other.
 	logger.Debugf("Adding files from %s to archive", logsDir)
-	err = filepath.Walk(logsDir, func(path string, info os.FileInfo, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-
-		// Only include files, skip directories
-		if info.IsDir() {
-			return nil
-		}
-
-		archivePath := filepath.Join("logs", filepath.Base(path))
-		return utils.AddFileToArchive(tarWriter, path, archivePath)
-	})
-
+	files, err := storage.Get().Walk(ctx, logsDir)
 	if err != nil {
-		return fmt.Errorf("failed to add files from logs directory %s: %s", logsDir, err)
+		return fmt.Errorf("failed to list files in logs directory %s: %s", logsDir, err)
 	}
 
-	logger.Infof("Successfully created log archive for job_id[%d]", jobID)
-
-	return nil
-}
+	for _, file := range files {
+		archivePath := path.Join("logs", strings.TrimPrefix(file.Path, logsDir+"/"))
+		if err := utils.AddFileToArchive(ctx, tarWriter, file.Path, archivePath); err != nil {
+			return fmt.Errorf("failed to add files from logs directory %s: %s", logsDir, err)
+		}
+	}
 
-func (s *ETLService) UpdateStateFile(jobID int, stateFile string) error {
-	_, err := s.db.GetJobByID(jobID, true)
+	events, err := s.temporal.WorkflowActivityEvents(ctx, taskLogFilePath)
 	if err != nil {
-		return fmt.Errorf("job not found: %s", err)
+		logger.Warnf("failed to add activity logs to archive: %s", err)
+		// Continue anyway - the history may have passed the namespace retention
+	} else {
+		var activityLog bytes.Buffer
+		encoder := json.NewEncoder(&activityLog)
+		for _, entry := range activityLogEntries(events) {
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("failed to encode activity logs: %s", err)
+			}
+		}
+		if err := utils.AddBytesToArchive(tarWriter, activityLog.Bytes(), path.Join("logs", constants.ActivityLogArchiveName)); err != nil {
+			return err
+		}
 	}
 
-	if err := s.db.UpdateJob(jobID, orm.Params{"state": stateFile}); err != nil {
-		return fmt.Errorf("failed to update job: %s", err)
-	}
+	logger.Infof("Successfully created log archive for job_id[%d]", jobID)
 
-	logger.Infof("state file updated successfully for job_id[%d] with state: %s", jobID, stateFile)
 	return nil
 }
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Label methods on AppService
+//
+// Sources, destinations and jobs carry free-form key/value labels (team=payments, env=prod),
+// stored as a JSON object. Labels filter the list endpoints and select the jobs of bulk actions
+// and of alert routes. The labels of a job are also set as the memo of its sync runs, so the
+// runs of a job can be filtered by the labels they were started with.
+
+// labelKeyPattern allows keys like "team", "app.kubernetes.io/name" or "cost-center"
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// validateLabels checks the keys, values and number of labels
+func validateLabels(labels map[string]string) error {
+	if len(labels) > constants.MaxLabels {
+		return fmt.Errorf("%w: at most %d labels are allowed", constants.ErrInvalidLabels, constants.MaxLabels)
+	}
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: key '%s' must be 1 to 63 letters, digits, '.', '_', '/' or '-', starting and ending with a letter or digit", constants.ErrInvalidLabels, key)
+		}
+		if len(value) > constants.MaxLabelValueLength {
+			return fmt.Errorf("%w: value of '%s' is longer than %d characters", constants.ErrInvalidLabels, key, constants.MaxLabelValueLength)
+		}
+		if strings.ContainsAny(value, "\n\r\t") {
+			return fmt.Errorf("%w: value of '%s' holds control characters", constants.ErrInvalidLabels, key)
+		}
+	}
+	return nil
+}
+
+// encodeLabels returns the stored form of labels, empty without labels
+func encodeLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(labels)
+	return string(encoded)
+}
+
+// decodeLabels returns the labels of a stored JSON object, nil without labels
+func decodeLabels(raw string) map[string]string {
+	var labels map[string]string
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &labels); err != nil {
+			logger.Warnf("ignoring invalid stored labels: %s", err)
+			return nil
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
+// labelsParam is the value of stored labels in update params, as empty strings are not valid
+// JSON the column is set to NULL without labels
+func labelsParam(labels string) interface{} {
+	if labels == "" {
+		return nil
+	}
+	return labels
+}
+
+// ParseLabelSelector parses "key=value" label filters into the labels they require
+func ParseLabelSelector(filters []string) (map[string]string, error) {
+	selector := make(map[string]string, len(filters))
+	for _, filter := range filters {
+		key, value, found := strings.Cut(filter, "=")
+		if !found || !labelKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%w: label filter '%s' must be key=value", constants.ErrInvalidLabels, filter)
+		}
+		selector[key] = value
+	}
+	return selector, nil
+}
+
+// labelsMatch tells whether labels carry every label of selector, an empty selector matches
+// anything
+func labelsMatch(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if current, ok := labels[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// SetSourceLabels replaces the labels of a source, without touching its jobs
+func (s *ETLService) SetSourceLabels(projectID string, sourceID int, labels map[string]string, userID *int) error {
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	source, err := s.db.GetSourceByID(sourceID)
+	if err != nil || source.ProjectID != projectID {
+		return fmt.Errorf("%w: source_id[%d] is not in project[%s]", constants.ErrSourceNotFound, sourceID, projectID)
+	}
+
+	return s.db.UpdateSourceLabels(source.ID, labelsParam(encodeLabels(labels)), *userID)
+}
+
+// SetDestinationLabels replaces the labels of a destination, without touching its jobs
+func (s *ETLService) SetDestinationLabels(projectID string, destinationID int, labels map[string]string, userID *int) error {
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	destination, err := s.db.GetDestinationByID(destinationID)
+	if err != nil || destination.ProjectID != projectID {
+		return fmt.Errorf("%w: destination_id[%d] is not in project[%s]", constants.ErrDestinationNotFound, destinationID, projectID)
+	}
+
+	return s.db.UpdateDestinationLabels(destination.ID, labelsParam(encodeLabels(labels)), *userID)
+}
+
+// SetJobLabels replaces the labels of a job and of the runs its schedule starts next
+func (s *ETLService) SetJobLabels(ctx context.Context, projectID string, jobID int, labels map[string]string, userID *int) error {
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return err
+	}
+	return s.updateJobLabels(ctx, job, encodeLabels(labels), userID)
+}
+
+// updateJobLabels stores the labels of a job and sets them on its schedule
+func (s *ETLService) updateJobLabels(ctx context.Context, job *models.Job, labels string, userID *int) error {
+	if err := s.db.UpdateJob(job.ID, orm.Params{"labels": labelsParam(labels), "updated_by_id": *userID}); err != nil {
+		return fmt.Errorf("failed to update job labels: %s", err)
+	}
+	if err := s.temporal.UpdateScheduleLabels(ctx, job.ProjectID, job.ID, labels); err != nil {
+		return fmt.Errorf("job labels updated but failed to update temporal schedule: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,671 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Maintenance window methods on AppService
+//
+// A maintenance window pauses the schedules of every active job of a source, a destination or
+// a project while it lasts, and resumes them when it ends. Windows are checked every
+// MAINTENANCE_CHECK_INTERVAL seconds. A schedule is paused with a note naming its window and
+// only resumed when the note is unchanged, so jobs resumed or paused by a user during the
+// window are left alone. A job covered by several windows stays with the first one and is
+// handed over to the next when it ends.
+//
+// Running syncs keep running unless the window drains them: they get DrainTimeout seconds to
+// finish and are cancelled after. Every change to a window, its start and end, and what it
+// did to each job is kept in an audit trail of maintenance events.
+
+// Maintenance window targets
+const (
+	MaintenanceTargetProject     = "project"
+	MaintenanceTargetSource      = "source"
+	MaintenanceTargetDestination = "destination"
+)
+
+// Maintenance window recurrences
+const (
+	MaintenanceWeekly = "weekly"
+	MaintenanceOnce   = "once"
+)
+
+// Maintenance event actions
+const (
+	MaintenanceCreated       = "created"
+	MaintenanceUpdated       = "updated"
+	MaintenanceDeleted       = "deleted"
+	MaintenanceStarted       = "started"
+	MaintenanceEnded         = "ended"
+	MaintenancePaused        = "paused"
+	MaintenanceResumed       = "resumed"
+	MaintenanceHandedOver    = "handed_over"
+	MaintenanceResumeSkipped = "resume_skipped"
+	MaintenanceDraining      = "draining"
+	MaintenanceDrained       = "drained"
+	MaintenanceCancelled     = "cancelled"
+	MaintenanceFailed        = "failed"
+)
+
+// maintenancePauseNotePrefix starts the pause note of schedules paused by a maintenance window
+const maintenancePauseNotePrefix = "paused for maintenance window "
+
+var maintenanceWeekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// maintenanceMu serializes the checks and changes of maintenance windows, which both write
+// the jobs a window paused
+var maintenanceMu sync.Mutex
+
+func maintenancePauseNote(windowID int) string {
+	return fmt.Sprintf("%s%d", maintenancePauseNotePrefix, windowID)
+}
+
+// parseClock parses an "HH:MM" time of day
+func parseClock(clock string) (int, int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", clock)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+// maintenanceWindowActive tells whether an enabled window is in effect at now
+func maintenanceWindowActive(window *models.MaintenanceWindow, now time.Time) bool {
+	if !window.Enabled {
+		return false
+	}
+	if window.Recurrence == MaintenanceOnce {
+		return window.StartsAt != nil && window.EndsAt != nil && !now.Before(*window.StartsAt) && now.Before(*window.EndsAt)
+	}
+
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return false
+	}
+	startHour, startMinute, err := parseClock(window.StartTime)
+	if err != nil {
+		return false
+	}
+	endHour, endMinute, err := parseClock(window.EndTime)
+	if err != nil {
+		return false
+	}
+	days := map[string]bool{}
+	for _, day := range strings.Split(window.Days, ",") {
+		days[day] = true
+	}
+
+	// a window started yesterday may still last when it ends the next day
+	local := now.In(location)
+	for offset := 0; offset >= -1; offset-- {
+		day := local.Day() + offset
+		from := time.Date(local.Year(), local.Month(), day, startHour, startMinute, 0, 0, location)
+		if !days[maintenanceWeekdays[from.Weekday()]] {
+			continue
+		}
+		to := time.Date(local.Year(), local.Month(), day, endHour, endMinute, 0, 0, location)
+		if !to.After(from) {
+			to = time.Date(local.Year(), local.Month(), day+1, endHour, endMinute, 0, 0, location)
+		}
+		if !local.Before(from) && local.Before(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// maintenanceWindowCovers tells whether a job is affected by a window
+func maintenanceWindowCovers(window *models.MaintenanceWindow, job *models.Job) bool {
+	switch window.TargetType {
+	case MaintenanceTargetSource:
+		return job.SourceID != nil && job.SourceID.ID == window.TargetID
+	case MaintenanceTargetDestination:
+		return job.DestID != nil && job.DestID.ID == window.TargetID
+	default:
+		return true
+	}
+}
+
+func maintenancePausedJobs(window *models.MaintenanceWindow) []int {
+	var jobIDs []int
+	if window.PausedJobs != "" {
+		_ = json.Unmarshal([]byte(window.PausedJobs), &jobIDs)
+	}
+	return jobIDs
+}
+
+func setMaintenancePausedJobs(window *models.MaintenanceWindow, jobIDs []int) {
+	if len(jobIDs) == 0 {
+		window.PausedJobs = ""
+		return
+	}
+	encoded, _ := json.Marshal(jobIDs)
+	window.PausedJobs = string(encoded)
+}
+
+// recordMaintenanceEvent adds an event to the audit trail, jobID 0 for events of the window
+func (s *ETLService) recordMaintenanceEvent(window *models.MaintenanceWindow, jobID int, action, message string, userID *int) {
+	logger.Infof("maintenance window %s window_id[%d] job_id[%d]: %s", action, window.ID, jobID, message)
+	event := &models.MaintenanceEvent{
+		ProjectID:  window.ProjectID,
+		WindowID:   window.ID,
+		WindowName: window.Name,
+		JobID:      jobID,
+		Action:     action,
+		Message:    message,
+	}
+	if userID != nil {
+		event.User = &models.User{ID: *userID}
+	}
+	if err := s.db.CreateMaintenanceEvent(event); err != nil {
+		logger.Errorf("failed to record maintenance event window_id[%d] action[%s]: %s", window.ID, action, err)
+	}
+}
+
+// StartMaintenanceWindows periodically pauses and resumes the jobs of maintenance windows
+// until ctx is done
+func (s *ETLService) StartMaintenanceWindows(ctx context.Context) {
+	interval := time.Duration(web.AppConfig.DefaultInt(constants.ConfMaintenanceCheckInterval, constants.DefaultMaintenanceCheck)) * time.Second
+	if interval <= 0 {
+		logger.Info("maintenance window checks disabled")
+		return
+	}
+
+	go func() {
+		s.checkMaintenanceWindows(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkMaintenanceWindows(ctx)
+			}
+		}
+	}()
+}
+
+// checkMaintenanceWindows brings the job schedules of every project in line with the windows
+// in effect
+func (s *ETLService) checkMaintenanceWindows(ctx context.Context) {
+	maintenanceMu.Lock()
+	defer maintenanceMu.Unlock()
+
+	windows, err := s.db.ListAllMaintenanceWindows()
+	if err != nil {
+		logger.Errorf("maintenance window check failed: %s", err)
+		return
+	}
+	byProject := map[string][]*models.MaintenanceWindow{}
+	for _, window := range windows {
+		byProject[window.ProjectID] = append(byProject[window.ProjectID], window)
+	}
+	for projectID, projectWindows := range byProject {
+		if ctx.Err() != nil {
+			return
+		}
+		s.checkProjectMaintenance(ctx, projectID, projectWindows, time.Now())
+	}
+}
+
+func (s *ETLService) checkProjectMaintenance(ctx context.Context, projectID string, windows []*models.MaintenanceWindow, now time.Time) {
+	active := map[int]bool{}
+	inUse := false
+	for _, window := range windows {
+		active[window.ID] = maintenanceWindowActive(window, now)
+		inUse = inUse || active[window.ID] || window.ActiveSince != nil
+	}
+	if !inUse {
+		return
+	}
+
+	jobs, err := s.db.ListJobsByProjectID(projectID)
+	if err != nil {
+		logger.Errorf("maintenance window check failed project_id[%s]: %s", projectID, err)
+		return
+	}
+	jobsByID := make(map[int]*models.Job, len(jobs))
+	// each job is held by the first active window covering it
+	holder := map[int]*models.MaintenanceWindow{}
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+		for _, window := range windows {
+			if active[window.ID] && maintenanceWindowCovers(window, job) {
+				holder[job.ID] = window
+				break
+			}
+		}
+	}
+
+	changed := map[int]bool{}
+	paused := map[int][]int{}
+	for _, window := range windows {
+		paused[window.ID] = maintenancePausedJobs(window)
+	}
+
+	// release the jobs a window no longer holds
+	for _, window := range windows {
+		var kept []int
+		for _, jobID := range paused[window.ID] {
+			next := holder[jobID]
+			if next == window {
+				kept = append(kept, jobID)
+				continue
+			}
+			changed[window.ID] = true
+			if !s.releaseMaintenanceJob(ctx, window, jobsByID[jobID], jobID, next) {
+				kept = append(kept, jobID)
+				continue
+			}
+			if next != nil {
+				paused[next.ID] = append(paused[next.ID], jobID)
+				changed[next.ID] = true
+			}
+		}
+		paused[window.ID] = kept
+	}
+
+	// pause the jobs of windows in effect
+	for _, window := range windows {
+		if !active[window.ID] {
+			continue
+		}
+		if window.ActiveSince == nil {
+			startedAt := now
+			window.ActiveSince = &startedAt
+			changed[window.ID] = true
+			s.recordMaintenanceEvent(window, 0, MaintenanceStarted, fmt.Sprintf("window started for %s", maintenanceTargetName(window)), nil)
+		}
+		held := map[int]bool{}
+		for _, jobID := range paused[window.ID] {
+			held[jobID] = true
+		}
+		for _, job := range jobs {
+			// jobs paused by users have nothing to pause
+			if holder[job.ID] != window || held[job.ID] || !job.Active {
+				continue
+			}
+			if err := s.temporal.PauseScheduleWithNote(ctx, projectID, job.ID, maintenancePauseNote(window.ID)); err != nil {
+				s.recordMaintenanceEvent(window, job.ID, MaintenanceFailed, fmt.Sprintf("failed to pause schedule: %s", err), nil)
+				continue
+			}
+			paused[window.ID] = append(paused[window.ID], job.ID)
+			changed[window.ID] = true
+			s.recordMaintenanceEvent(window, job.ID, MaintenancePaused, fmt.Sprintf("schedule of job '%s' paused", job.Name), nil)
+			if window.Drain {
+				go s.drainMaintenanceJob(*window, job)
+			}
+		}
+	}
+
+	for _, window := range windows {
+		if !active[window.ID] && window.ActiveSince != nil && len(paused[window.ID]) == 0 {
+			window.ActiveSince = nil
+			changed[window.ID] = true
+			s.recordMaintenanceEvent(window, 0, MaintenanceEnded, "window ended", nil)
+		}
+		if !changed[window.ID] {
+			continue
+		}
+		setMaintenancePausedJobs(window, paused[window.ID])
+		if err := s.db.UpdateMaintenanceWindowState(window); err != nil {
+			logger.Errorf("maintenance window check failed: %s", err)
+		}
+	}
+}
+
+// releaseMaintenanceJob hands a job paused by a window over to the next window holding it, or
+// resumes its schedule when none does. It reports false when the job should be released again
+// at the next check.
+func (s *ETLService) releaseMaintenanceJob(ctx context.Context, window *models.MaintenanceWindow, job *models.Job, jobID int, next *models.MaintenanceWindow) bool {
+	if job == nil {
+		// deleted jobs have no schedule left to resume
+		return true
+	}
+	note, err := s.temporal.SchedulePauseNote(ctx, window.ProjectID, jobID)
+	if err != nil {
+		s.recordMaintenanceEvent(window, jobID, MaintenanceFailed, fmt.Sprintf("failed to describe schedule: %s", err), nil)
+		return false
+	}
+	if note != maintenancePauseNote(window.ID) {
+		s.recordMaintenanceEvent(window, jobID, MaintenanceResumeSkipped, "schedule was resumed or paused by someone else during the window", nil)
+		return true
+	}
+
+	if next != nil {
+		if err := s.temporal.PauseScheduleWithNote(ctx, window.ProjectID, jobID, maintenancePauseNote(next.ID)); err != nil {
+			s.recordMaintenanceEvent(window, jobID, MaintenanceFailed, fmt.Sprintf("failed to hand schedule over to window %d: %s", next.ID, err), nil)
+			return false
+		}
+		s.recordMaintenanceEvent(window, jobID, MaintenanceHandedOver, fmt.Sprintf("schedule stays paused for window '%s'", next.Name), nil)
+		return true
+	}
+
+	if !job.Active {
+		s.recordMaintenanceEvent(window, jobID, MaintenanceResumeSkipped, "job was paused during the window", nil)
+		return true
+	}
+	if err := s.temporal.ResumeSchedule(ctx, window.ProjectID, jobID); err != nil {
+		s.recordMaintenanceEvent(window, jobID, MaintenanceFailed, fmt.Sprintf("failed to resume schedule: %s", err), nil)
+		return false
+	}
+	s.recordMaintenanceEvent(window, jobID, MaintenanceResumed, fmt.Sprintf("schedule of job '%s' resumed", job.Name), nil)
+	return true
+}
+
+// drainMaintenanceJob gives the running sync of a job paused by a window DrainTimeout seconds
+// to finish, and cancels it after
+func (s *ETLService) drainMaintenanceJob(window models.MaintenanceWindow, job *models.Job) {
+	ctx := context.Background()
+	running, _, err := isWorkflowRunning(ctx, s.temporal, window.ProjectID, job.ID, temporal.Sync)
+	if err != nil {
+		s.recordMaintenanceEvent(&window, job.ID, MaintenanceFailed, fmt.Sprintf("failed to check running sync: %s", err), nil)
+		return
+	}
+	if !running {
+		return
+	}
+
+	timeout := time.Duration(window.DrainTimeout) * time.Second
+	s.recordMaintenanceEvent(&window, job.ID, MaintenanceDraining, fmt.Sprintf("waiting up to %s for the running sync to finish", timeout), nil)
+	if err := waitForSyncToStop(ctx, s.temporal, window.ProjectID, job.ID, timeout); err == nil {
+		s.recordMaintenanceEvent(&window, job.ID, MaintenanceDrained, "running sync finished", nil)
+		return
+	}
+	if err := cancelAllJobWorkflows(ctx, s.temporal, []*models.Job{job}, window.ProjectID); err != nil {
+		s.recordMaintenanceEvent(&window, job.ID, MaintenanceFailed, fmt.Sprintf("failed to cancel running sync: %s", err), nil)
+		return
+	}
+	s.recordMaintenanceEvent(&window, job.ID, MaintenanceCancelled, fmt.Sprintf("running sync cancelled after %s", timeout), nil)
+}
+
+// ensureNoMaintenance fails when the schedule of a job is paused by a maintenance window, so
+// no sync is started during it
+func (s *ETLService) ensureNoMaintenance(ctx context.Context, projectID string, jobID int) error {
+	note, err := s.temporal.SchedulePauseNote(ctx, projectID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to describe schedule: %s", err)
+	}
+	if strings.HasPrefix(note, maintenancePauseNotePrefix) {
+		return fmt.Errorf("%w: %s", constants.ErrJobInMaintenance, note)
+	}
+	return nil
+}
+
+func maintenanceTargetName(window *models.MaintenanceWindow) string {
+	if window.TargetType == MaintenanceTargetProject {
+		return "the project"
+	}
+	return fmt.Sprintf("%s %d", window.TargetType, window.TargetID)
+}
+
+func buildMaintenanceWindowResponse(window *models.MaintenanceWindow, now time.Time) dto.MaintenanceWindowResponse {
+	response := dto.MaintenanceWindowResponse{
+		ID:           window.ID,
+		Name:         window.Name,
+		TargetType:   window.TargetType,
+		TargetID:     window.TargetID,
+		Recurrence:   window.Recurrence,
+		StartTime:    window.StartTime,
+		EndTime:      window.EndTime,
+		Timezone:     window.Timezone,
+		Drain:        window.Drain,
+		DrainTimeout: window.DrainTimeout,
+		Enabled:      window.Enabled,
+		Active:       maintenanceWindowActive(window, now),
+		PausedJobs:   maintenancePausedJobs(window),
+		CreatedAt:    window.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    window.UpdatedAt.Format(time.RFC3339),
+	}
+	if window.Days != "" {
+		response.Days = strings.Split(window.Days, ",")
+	}
+	if window.StartsAt != nil {
+		response.StartsAt = window.StartsAt.UTC().Format(time.RFC3339)
+	}
+	if window.EndsAt != nil {
+		response.EndsAt = window.EndsAt.UTC().Format(time.RFC3339)
+	}
+	if window.ActiveSince != nil {
+		response.ActiveSince = window.ActiveSince.UTC().Format(time.RFC3339)
+	}
+	if response.PausedJobs == nil {
+		response.PausedJobs = []int{}
+	}
+	setUsernames(&response.CreatedBy, &response.UpdatedBy, window.CreatedBy, window.UpdatedBy)
+	return response
+}
+
+func (s *ETLService) getMaintenanceWindow(projectID string, id int) (*models.MaintenanceWindow, error) {
+	window, err := s.db.GetMaintenanceWindowByID(projectID, id)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %d", constants.ErrMaintenanceWindowNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get maintenance window: %s", err)
+	}
+	return window, nil
+}
+
+// applyMaintenanceWindowRequest validates a maintenance window request and copies it onto
+// window
+func (s *ETLService) applyMaintenanceWindowRequest(projectID string, window *models.MaintenanceWindow, req *dto.MaintenanceWindowRequest) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", constants.ErrInvalidMaintenanceWindow, fmt.Sprintf(format, args...))
+	}
+
+	switch req.TargetType {
+	case MaintenanceTargetProject:
+		if req.TargetID != 0 {
+			return invalid("project windows take no target_id")
+		}
+	case MaintenanceTargetSource:
+		source, err := s.db.GetSourceByID(req.TargetID)
+		if err != nil || source.ProjectID != projectID {
+			return invalid("source %d not found in project", req.TargetID)
+		}
+	case MaintenanceTargetDestination:
+		destination, err := s.db.GetDestinationByID(req.TargetID)
+		if err != nil || destination.ProjectID != projectID {
+			return invalid("destination %d not found in project", req.TargetID)
+		}
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return invalid("unknown timezone '%s'", timezone)
+	}
+
+	window.Days, window.StartTime, window.EndTime = "", "", ""
+	window.StartsAt, window.EndsAt = nil, nil
+	if req.Recurrence == MaintenanceWeekly {
+		if len(req.Days) == 0 {
+			return invalid("weekly windows need days")
+		}
+		startHour, startMinute, err := parseClock(req.StartTime)
+		if err != nil {
+			return invalid("start_time: %s", err)
+		}
+		endHour, endMinute, err := parseClock(req.EndTime)
+		if err != nil {
+			return invalid("end_time: %s", err)
+		}
+		if startHour == endHour && startMinute == endMinute {
+			return invalid("start_time and end_time must differ")
+		}
+		window.Days = strings.Join(req.Days, ",")
+		window.StartTime, window.EndTime = req.StartTime, req.EndTime
+	} else {
+		startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
+		if err != nil {
+			return invalid("starts_at must be an RFC3339 time")
+		}
+		endsAt, err := time.Parse(time.RFC3339, req.EndsAt)
+		if err != nil {
+			return invalid("ends_at must be an RFC3339 time")
+		}
+		if !endsAt.After(startsAt) {
+			return invalid("ends_at must be after starts_at")
+		}
+		window.StartsAt, window.EndsAt = &startsAt, &endsAt
+	}
+
+	window.ProjectID = projectID
+	window.Name = req.Name
+	window.TargetType = req.TargetType
+	window.TargetID = req.TargetID
+	window.Recurrence = req.Recurrence
+	window.Timezone = timezone
+	window.Drain = req.Drain
+	window.DrainTimeout = req.DrainTimeout
+	if window.Drain && window.DrainTimeout == 0 {
+		window.DrainTimeout = constants.DefaultMaintenanceDrain
+	}
+	if req.Enabled != nil {
+		window.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+func (s *ETLService) ListMaintenanceWindows(projectID string) ([]dto.MaintenanceWindowResponse, error) {
+	windows, err := s.db.ListMaintenanceWindows(projectID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	responses := make([]dto.MaintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		responses = append(responses, buildMaintenanceWindowResponse(window, now))
+	}
+	return responses, nil
+}
+
+func (s *ETLService) GetMaintenanceWindow(projectID string, id int) (*dto.MaintenanceWindowResponse, error) {
+	window, err := s.getMaintenanceWindow(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	response := buildMaintenanceWindowResponse(window, time.Now())
+	return &response, nil
+}
+
+func (s *ETLService) CreateMaintenanceWindow(projectID string, req *dto.MaintenanceWindowRequest, userID *int) (*dto.MaintenanceWindowResponse, error) {
+	window := &models.MaintenanceWindow{Enabled: true}
+	if err := s.applyMaintenanceWindowRequest(projectID, window, req); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: *userID}
+	window.CreatedBy = user
+	window.UpdatedBy = user
+	if err := s.db.CreateMaintenanceWindow(window); err != nil {
+		return nil, fmt.Errorf("failed to create maintenance window: %s", err)
+	}
+	s.recordMaintenanceEvent(window, 0, MaintenanceCreated, fmt.Sprintf("%s window created for %s", window.Recurrence, maintenanceTargetName(window)), userID)
+	go s.checkMaintenanceWindows(context.Background())
+
+	return s.GetMaintenanceWindow(projectID, window.ID)
+}
+
+func (s *ETLService) UpdateMaintenanceWindow(projectID string, id int, req *dto.MaintenanceWindowRequest, userID *int) (*dto.MaintenanceWindowResponse, error) {
+	maintenanceMu.Lock()
+	window, err := s.getMaintenanceWindow(projectID, id)
+	if err == nil {
+		err = s.applyMaintenanceWindowRequest(projectID, window, req)
+	}
+	if err == nil {
+		window.UpdatedBy = &models.User{ID: *userID}
+		if err = s.db.UpdateMaintenanceWindow(window); err != nil {
+			err = fmt.Errorf("failed to update maintenance window: %s", err)
+		}
+	}
+	maintenanceMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	s.recordMaintenanceEvent(window, 0, MaintenanceUpdated, fmt.Sprintf("%s window updated for %s, enabled %t", window.Recurrence, maintenanceTargetName(window), window.Enabled), userID)
+	go s.checkMaintenanceWindows(context.Background())
+
+	return s.GetMaintenanceWindow(projectID, id)
+}
+
+// DeleteMaintenanceWindow ends a window, resuming the jobs it paused, and deletes it. A window
+// whose jobs could not all be resumed is left disabled and not deleted.
+func (s *ETLService) DeleteMaintenanceWindow(ctx context.Context, projectID string, id int, userID *int) error {
+	maintenanceMu.Lock()
+	window, err := s.getMaintenanceWindow(projectID, id)
+	if err == nil && window.Enabled {
+		window.Enabled = false
+		if err = s.db.UpdateMaintenanceWindow(window); err != nil {
+			err = fmt.Errorf("failed to disable maintenance window: %s", err)
+		}
+	}
+	maintenanceMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.checkMaintenanceWindows(ctx)
+	if window, err = s.getMaintenanceWindow(projectID, id); err != nil {
+		return err
+	}
+	if jobIDs := maintenancePausedJobs(window); len(jobIDs) > 0 {
+		return fmt.Errorf("failed to resume jobs %v paused by the window, it was disabled instead", jobIDs)
+	}
+
+	if err := s.db.DeleteMaintenanceWindow(id); err != nil {
+		return fmt.Errorf("failed to delete maintenance window: %s", err)
+	}
+	s.recordMaintenanceEvent(window, 0, MaintenanceDeleted, "window deleted", userID)
+	return nil
+}
+
+// ListMaintenanceEvents returns the audit trail of the maintenance windows of a project, newest
+// first. windowID and jobID 0 match any.
+func (s *ETLService) ListMaintenanceEvents(projectID string, windowID, jobID, limit int) ([]dto.MaintenanceEventResponse, error) {
+	if limit < 1 || limit > constants.MaxMaintenanceEventLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", constants.ErrInvalidMaintenanceWindow, constants.MaxMaintenanceEventLimit)
+	}
+	events, err := s.db.ListMaintenanceEvents(projectID, windowID, jobID, limit)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.MaintenanceEventResponse, 0, len(events))
+	for _, event := range events {
+		response := dto.MaintenanceEventResponse{
+			ID:         event.ID,
+			WindowID:   event.WindowID,
+			WindowName: event.WindowName,
+			JobID:      event.JobID,
+			Action:     event.Action,
+			Message:    event.Message,
+			CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+		}
+		if event.User != nil {
+			response.User = event.User.Username
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
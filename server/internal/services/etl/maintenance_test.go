@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	at := func(day, hour, minute int) time.Time {
+		// 8 March 2024 is a Friday
+		return time.Date(2024, time.March, day, hour, minute, 0, 0, berlin).UTC()
+	}
+
+	weekly := &models.MaintenanceWindow{
+		Recurrence: MaintenanceWeekly,
+		Days:       "fri",
+		StartTime:  "22:00",
+		EndTime:    "02:00",
+		Timezone:   "Europe/Berlin",
+		Enabled:    true,
+	}
+	for _, tc := range []struct {
+		name   string
+		now    time.Time
+		active bool
+	}{
+		{"before the start", at(8, 21, 59), false},
+		{"at the start", at(8, 22, 0), true},
+		{"past midnight of a window started the day before", at(9, 1, 30), true},
+		{"at the end", at(9, 2, 0), false},
+		{"on a day without a window", at(9, 23, 0), false},
+		{"on the day before", at(7, 23, 0), false},
+	} {
+		require.Equal(t, tc.active, maintenanceWindowActive(weekly, tc.now), tc.name)
+	}
+
+	disabled := *weekly
+	disabled.Enabled = false
+	require.False(t, maintenanceWindowActive(&disabled, at(8, 23, 0)))
+
+	unknownZone := *weekly
+	unknownZone.Timezone = "Mars/Olympus_Mons"
+	require.False(t, maintenanceWindowActive(&unknownZone, at(8, 23, 0)))
+
+	startsAt, endsAt := at(10, 9, 0), at(10, 11, 0)
+	once := &models.MaintenanceWindow{Recurrence: MaintenanceOnce, StartsAt: &startsAt, EndsAt: &endsAt, Enabled: true}
+	require.False(t, maintenanceWindowActive(once, at(10, 8, 59)))
+	require.True(t, maintenanceWindowActive(once, at(10, 9, 0)))
+	require.False(t, maintenanceWindowActive(once, at(10, 11, 0)))
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
+)
+
+// Manual sync methods on AppService
+//
+// A manual sync runs some selected streams of a job right away, as a one-off run of the sync
+// workflow with a filtered streams config written to storage, like clear-destination does.
+// Older workers ignore that streams config for syncs, so it needs the manual-sync feature.
+// The job's saved streams config is not changed. The schedule is paused while the run lasts,
+// so no scheduled sync runs next to it, and resumed when it ends.
+//
+// The state the run reports only replaces the state of the streams it synced, the other
+// streams keep theirs. A run only moves the shared CDC position when it syncs every CDC stream
+// of the job, otherwise the other CDC streams would skip the changes in between.
+
+// manualSyncPauseNote marks schedules paused for a manual sync, so they are only resumed when
+// nobody else paused them since
+const manualSyncPauseNote = "paused while a manual sync runs"
+
+// SyncJobStreams starts a manual sync of some selected streams of a job
+func (s *ETLService) SyncJobStreams(ctx context.Context, projectID string, jobID int, req *dto.ManualSyncRequest) (*dto.ManualSyncResponse, error) {
+	if err := temporal.RequireWorkerFeatures(temporal.WorkerFeatureManualSync); err != nil {
+		return nil, err
+	}
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Active {
+		return nil, fmt.Errorf("%w: job is paused, please unpause to run sync", constants.ErrInvalidManualSync)
+	}
+	if err := s.ensureNoMaintenance(ctx, projectID, jobID); err != nil {
+		return nil, err
+	}
+	if err := checkSecretRefsSupported(job.SourceID.Config, job.DestID.Config); err != nil {
+		return nil, err
+	}
+
+	catalog, streams, err := manualSyncCatalog(job.StreamsConfig, req.Streams, req.FullRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.temporal.PauseScheduleWithNote(ctx, projectID, jobID, manualSyncPauseNote); err != nil {
+		return nil, fmt.Errorf("failed to pause schedule: %s", err)
+	}
+	// checked once paused, so no scheduled run can start in between
+	if err := ensureNoSyncRunning(ctx, s.temporal, projectID, jobID); err != nil {
+		s.resumeAfterManualSync(ctx, projectID, jobID)
+		return nil, fmt.Errorf("%w: %s", constants.ErrSyncRunning, err)
+	}
+
+	workflowID := temporal.ManualSyncWorkflowID(projectID, jobID)
+	logger.Infof("starting manual sync job_id[%d] workflow_id[%s] streams[%v] full_refresh[%t]", jobID, workflowID, streams, req.FullRefresh)
+	run, err := s.temporal.ManualSync(ctx, job, workflowID, catalog)
+	if err != nil {
+		s.resumeAfterManualSync(ctx, projectID, jobID)
+		return nil, err
+	}
+	go s.awaitManualSync(projectID, jobID, run)
+
+	return &dto.ManualSyncResponse{
+		JobID:       jobID,
+		WorkflowID:  workflowID,
+		Streams:     streams,
+		FullRefresh: req.FullRefresh,
+	}, nil
+}
+
+// manualSyncCatalog returns the streams config of a manual sync: the job's streams config with
+// only the given streams selected, set to full refresh with fullRefresh. It also returns the
+// streams synced, sorted.
+func manualSyncCatalog(streamsConfig string, streams []string, fullRefresh bool) (string, []string, error) {
+	var catalog map[string]interface{}
+	if err := json.Unmarshal([]byte(streamsConfig), &catalog); err != nil {
+		return "", nil, fmt.Errorf("failed to parse streams config of job: %s", err)
+	}
+
+	selected := selectedStreamEntries(catalog)
+	wanted := make(map[string]bool, len(streams))
+	for _, id := range streams {
+		if _, ok := selected[id]; !ok {
+			return "", nil, fmt.Errorf("%w: stream %s is not selected in the job", constants.ErrInvalidManualSync, id)
+		}
+		wanted[id] = true
+	}
+
+	entries, _ := catalogEntries(catalog)
+	readsCDC := false
+	var skippedCDC []string
+	for id := range selected {
+		stream, _ := entries[id]["stream"].(map[string]interface{})
+		mode, _ := stream["sync_mode"].(string)
+		switch {
+		case !wanted[id]:
+			if isCDCSyncMode(mode) {
+				skippedCDC = append(skippedCDC, id)
+			}
+		case fullRefresh:
+			stream["sync_mode"] = dryRunSyncMode
+		case isCDCSyncMode(mode):
+			readsCDC = true
+		}
+	}
+	if readsCDC && len(skippedCDC) > 0 {
+		sort.Strings(skippedCDC)
+		return "", nil, fmt.Errorf("%w: the CDC streams %v share the replication position, sync them too or use full_refresh", constants.ErrInvalidManualSync, skippedCDC)
+	}
+
+	keepSelectedStreams(catalog, wanted)
+	encoded, err := json.Marshal(catalog)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode manual sync catalog: %s", err)
+	}
+
+	ids := make([]string, 0, len(wanted))
+	for id := range wanted {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return string(encoded), ids, nil
+}
+
+func isCDCSyncMode(mode string) bool {
+	return mode == "cdc" || mode == "strict_cdc"
+}
+
+// awaitManualSync resumes the schedule of a job once its manual sync ends
+func (s *ETLService) awaitManualSync(projectID string, jobID int, run client.WorkflowRun) {
+	if err := run.Get(context.Background(), nil); err != nil {
+		logger.Warnf("manual sync failed job_id[%d] workflow_id[%s]: %s", jobID, run.GetID(), err)
+	} else {
+		logger.Infof("manual sync completed job_id[%d] workflow_id[%s]", jobID, run.GetID())
+	}
+	s.resumeAfterManualSync(context.Background(), projectID, jobID)
+}
+
+// resumeAfterManualSync resumes a schedule paused for a manual sync. Schedules paused by
+// someone else since, or of jobs paused meanwhile, are left paused.
+func (s *ETLService) resumeAfterManualSync(ctx context.Context, projectID string, jobID int) {
+	job, err := s.db.GetJobByID(jobID, false)
+	if err != nil {
+		logger.Warnf("failed to get job to resume schedule after manual sync job_id[%d]: %s", jobID, err)
+		return
+	}
+	note, err := s.temporal.SchedulePauseNote(ctx, projectID, jobID)
+	if err != nil {
+		logger.Errorf("failed to describe schedule after manual sync job_id[%d]: %s", jobID, err)
+		return
+	}
+	if note != manualSyncPauseNote || !job.Active {
+		return
+	}
+	if err := s.temporal.ResumeSchedule(ctx, projectID, jobID); err != nil {
+		logger.Errorf("failed to resume schedule after manual sync job_id[%d]: %s", jobID, err)
+	}
+}
+
+// RecoverManualSyncs picks up the schedules a previous process paused for manual syncs. They
+// are resumed when their manual sync ended, or once it does.
+func (s *ETLService) RecoverManualSyncs() {
+	go func() {
+		ctx := context.Background()
+		jobs, err := s.db.ListJobs()
+		if err != nil {
+			logger.Errorf("failed to list jobs to recover manual syncs: %s", err)
+			return
+		}
+		for _, job := range jobs {
+			if !job.Active {
+				continue
+			}
+			note, err := s.temporal.SchedulePauseNote(ctx, job.ProjectID, job.ID)
+			if err != nil || note != manualSyncPauseNote {
+				continue
+			}
+
+			running, executions, err := isWorkflowRunning(ctx, s.temporal, job.ProjectID, job.ID, temporal.Sync)
+			if err != nil {
+				logger.Warnf("failed to check manual sync of job_id[%d]: %s", job.ID, err)
+				continue
+			}
+			if running && temporal.IsManualSyncWorkflowID(executions[0].Execution.WorkflowId) {
+				execution := executions[0].Execution
+				logger.Infof("waiting for manual sync job_id[%d] workflow_id[%s] to resume its schedule", job.ID, execution.WorkflowId)
+				go s.awaitManualSync(job.ProjectID, job.ID, s.temporal.Client.GetWorkflow(ctx, execution.WorkflowId, execution.RunId))
+				continue
+			}
+			logger.Infof("resuming schedule left paused by a manual sync job_id[%d]", job.ID)
+			s.resumeAfterManualSync(ctx, job.ProjectID, job.ID)
+		}
+	}()
+}
+
+// manualSyncState merges the state reported by a manual sync into the job's state. Only the
+// entries of the streams synced are replaced, the global state only when the run read CDC.
+func (s *ETLService) manualSyncState(ctx context.Context, jobID int, workflowID, reported string) (string, error) {
+	job, err := s.db.GetJobByID(jobID, false)
+	if err != nil {
+		return "", fmt.Errorf("job not found: %s", err)
+	}
+	current, err := parseJobState(job.State)
+	if err != nil {
+		return "", fmt.Errorf("stored state of job_id[%d] is invalid: %s", jobID, err)
+	}
+	reportedState, err := parseJobState(reported)
+	if err != nil {
+		return "", fmt.Errorf("reported state is invalid: %s", err)
+	}
+
+	raw, err := storage.Get().Read(ctx, temporal.ManualSyncStreamsPath(workflowID))
+	if err != nil {
+		return "", fmt.Errorf("failed to read streams config of manual sync: %s", err)
+	}
+	var catalog map[string]interface{}
+	if err := json.Unmarshal(raw, &catalog); err != nil {
+		return "", fmt.Errorf("failed to parse streams config of manual sync: %s", err)
+	}
+
+	synced := map[string]bool{}
+	readsCDC := false
+	entries, _ := catalogEntries(catalog)
+	for id := range selectedStreamEntries(catalog) {
+		synced[id] = true
+		stream, _ := entries[id]["stream"].(map[string]interface{})
+		if mode, _ := stream["sync_mode"].(string); isCDCSyncMode(mode) {
+			readsCDC = true
+		}
+	}
+
+	encoded, err := json.Marshal(mergeStreamsState(current, reportedState, synced, readsCDC))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode merged state: %s", err)
+	}
+	return string(encoded), nil
+}
+
+// mergeStreamsState returns current with the per stream entries of the synced streams
+// ("namespace.stream") taken from reported, and its global state too with withGlobal
+func mergeStreamsState(current, reported map[string]interface{}, synced map[string]bool, withGlobal bool) map[string]interface{} {
+	if len(current) == 0 {
+		return reported
+	}
+
+	merged := make(map[string]interface{}, len(current))
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	streamID := func(raw interface{}) string {
+		entry, _ := raw.(map[string]interface{})
+		name, _ := entry["stream"].(string)
+		namespace, _ := entry["namespace"].(string)
+		return namespace + "." + name
+	}
+
+	reportedStreams, _ := reported["streams"].([]interface{})
+	replacements := map[string]interface{}{}
+	var added []string
+	for _, raw := range reportedStreams {
+		if id := streamID(raw); synced[id] {
+			replacements[id] = raw
+			added = append(added, id)
+		}
+	}
+
+	currentStreams, _ := current["streams"].([]interface{})
+	streams := make([]interface{}, 0, len(currentStreams)+len(replacements))
+	for _, raw := range currentStreams {
+		id := streamID(raw)
+		if replacement, ok := replacements[id]; ok {
+			streams = append(streams, replacement)
+			delete(replacements, id)
+			continue
+		}
+		streams = append(streams, raw)
+	}
+	for _, id := range added {
+		if replacement, ok := replacements[id]; ok {
+			streams = append(streams, replacement)
+		}
+	}
+	merged["streams"] = streams
+
+	if withGlobal {
+		if global, ok := reported["global"]; ok {
+			merged["global"] = global
+		}
+		if stateType, ok := reported["type"]; ok {
+			merged["type"] = stateType
+		}
+	}
+	if _, ok := merged["type"]; !ok {
+		if stateType, ok := reported["type"]; ok {
+			merged["type"] = stateType
+		}
+	}
+	return merged
+}
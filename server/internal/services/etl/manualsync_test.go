@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+)
+
+func TestManualSyncRequiresWorkerSupport(t *testing.T) {
+	require.NoError(t, web.AppConfig.Set(constants.ConfWorkerFeatures, "dry-run"))
+	t.Cleanup(func() { _ = web.AppConfig.Set(constants.ConfWorkerFeatures, "") })
+
+	// refused before the job is read or its schedule paused
+	s := &ETLService{}
+	_, err := s.SyncJobStreams(context.Background(), "123", 7, &dto.ManualSyncRequest{Streams: []string{"public.users"}})
+	require.ErrorIs(t, err, constants.ErrWorkerFeatureUnsupported)
+	require.ErrorContains(t, err, "manual-sync")
+}
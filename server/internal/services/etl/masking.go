@@ -0,0 +1,281 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+)
+
+// Masking rule methods on AppService
+//
+// Masking rules hide PII columns in the rows the server returns from a source: stream previews
+// and dry run samples. Rows are masked before they are cached or returned, so no unmasked value
+// leaves the service. When several rules match a column the strongest strategy wins.
+
+// Masking strategies, from the strongest
+const (
+	MaskRedact  = "redact"  // replaces the value with constants.PIIMask
+	MaskHash    = "hash"    // replaces the value with a keyed hash, equal values stay equal
+	MaskPartial = "partial" // keeps the last partialMaskVisible characters
+)
+
+var maskStrength = map[string]int{MaskRedact: 3, MaskHash: 2, MaskPartial: 1}
+
+// partialMaskVisible is the number of trailing characters a partial mask keeps, shorter values
+// are masked whole
+const partialMaskVisible = 4
+
+type compiledMaskingRule struct {
+	namespace *regexp.Regexp
+	stream    *regexp.Regexp
+	column    *regexp.Regexp
+	strategy  string
+}
+
+// columnMasker masks the columns of records by the masking rules of a source
+type columnMasker []compiledMaskingRule
+
+func compileMaskingRule(namespace, stream, column, strategy string) (compiledMaskingRule, error) {
+	if _, ok := maskStrength[strategy]; !ok {
+		return compiledMaskingRule{}, fmt.Errorf("unsupported strategy '%s'", strategy)
+	}
+	if column == "" {
+		return compiledMaskingRule{}, fmt.Errorf("column pattern is required")
+	}
+	compiled := compiledMaskingRule{strategy: strategy}
+	var err error
+	if compiled.namespace, err = compileFullMatch(namespace); err != nil {
+		return compiledMaskingRule{}, fmt.Errorf("invalid namespace pattern: %s", err)
+	}
+	if compiled.stream, err = compileFullMatch(stream); err != nil {
+		return compiledMaskingRule{}, fmt.Errorf("invalid stream pattern: %s", err)
+	}
+	if compiled.column, err = compileFullMatch(column); err != nil {
+		return compiledMaskingRule{}, fmt.Errorf("invalid column pattern: %s", err)
+	}
+	return compiled, nil
+}
+
+// strategy returns the strongest strategy of the rules matching a column of a stream
+func (m columnMasker) strategy(namespace, stream, column string) (string, bool) {
+	strategy := ""
+	for _, rule := range m {
+		if rule.namespace != nil && !rule.namespace.MatchString(namespace) ||
+			rule.stream != nil && !rule.stream.MatchString(stream) ||
+			!rule.column.MatchString(column) {
+			continue
+		}
+		if maskStrength[rule.strategy] > maskStrength[strategy] {
+			strategy = rule.strategy
+		}
+	}
+	return strategy, strategy != ""
+}
+
+// maskRecords masks the records of a stream ("namespace.stream") in place and returns the
+// masked columns, sorted
+func (m columnMasker) maskRecords(streamID string, records []map[string]interface{}) []string {
+	masked := []string{}
+	if len(m) == 0 {
+		return masked
+	}
+	namespace, stream := splitStreamID(streamID)
+
+	strategies := map[string]string{}
+	for _, record := range records {
+		for column, value := range record {
+			strategy, seen := strategies[column]
+			if !seen {
+				strategy, _ = m.strategy(namespace, stream, column)
+				strategies[column] = strategy
+			}
+			if strategy != "" {
+				record[column] = maskValue(strategy, value)
+			}
+		}
+	}
+	for column, strategy := range strategies {
+		if strategy != "" {
+			masked = append(masked, column)
+		}
+	}
+	sort.Strings(masked)
+	return masked
+}
+
+// splitStreamID splits "namespace.stream" at the first dot, as namespaces hold no dots
+func splitStreamID(streamID string) (string, string) {
+	namespace, stream, found := strings.Cut(streamID, ".")
+	if !found {
+		return "", streamID
+	}
+	return namespace, stream
+}
+
+// maskValue masks a column value, nulls are kept as they do not reveal anything
+func maskValue(strategy string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		encoded, _ := json.Marshal(value)
+		text = string(encoded)
+	}
+
+	switch strategy {
+	case MaskHash:
+		return utils.KeyedHash("pii-mask", text)[:16]
+	case MaskPartial:
+		runes := []rune(text)
+		if len(runes) <= partialMaskVisible {
+			return constants.PIIMask
+		}
+		return strings.Repeat("*", len(runes)-partialMaskVisible) + string(runes[len(runes)-partialMaskVisible:])
+	default:
+		return constants.PIIMask
+	}
+}
+
+// sourceMasker returns the masker of the masking rules that apply to a source
+func (s *ETLService) sourceMasker(projectID string, sourceID int) (columnMasker, error) {
+	rules, err := s.db.ListSourceMaskingRules(projectID, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get masking rules: %s", err)
+	}
+	masker := make(columnMasker, 0, len(rules))
+	for _, rule := range rules {
+		compiled, err := compileMaskingRule(rule.Namespace, rule.Stream, rule.Column, rule.Strategy)
+		if err != nil {
+			// a rule that no longer compiles must not leave its columns unmasked
+			return nil, fmt.Errorf("failed to compile masking rule %d: %s", rule.ID, err)
+		}
+		masker = append(masker, compiled)
+	}
+	return masker, nil
+}
+
+func buildMaskingRuleResponse(rule *models.MaskingRule) dto.MaskingRuleResponse {
+	response := dto.MaskingRuleResponse{
+		ID:        rule.ID,
+		SourceID:  rule.SourceID,
+		Namespace: rule.Namespace,
+		Stream:    rule.Stream,
+		Column:    rule.Column,
+		Strategy:  rule.Strategy,
+		CreatedAt: rule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: rule.UpdatedAt.Format(time.RFC3339),
+	}
+	setUsernames(&response.CreatedBy, &response.UpdatedBy, rule.CreatedBy, rule.UpdatedBy)
+	return response
+}
+
+func (s *ETLService) getMaskingRule(projectID string, id int) (*models.MaskingRule, error) {
+	rule, err := s.db.GetMaskingRuleByID(projectID, id)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %d", constants.ErrMaskingRuleNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get masking rule: %s", err)
+	}
+	return rule, nil
+}
+
+// applyMaskingRuleRequest validates a masking rule request and copies it onto rule
+func (s *ETLService) applyMaskingRuleRequest(projectID string, rule *models.MaskingRule, req *dto.MaskingRuleRequest) error {
+	if _, err := compileMaskingRule(req.Namespace, req.Stream, req.Column, req.Strategy); err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrInvalidMaskingRule, err)
+	}
+	if req.SourceID != 0 {
+		source, err := s.db.GetSourceByID(req.SourceID)
+		if err != nil || source.ProjectID != projectID {
+			return fmt.Errorf("%w: source %d not found in project", constants.ErrInvalidMaskingRule, req.SourceID)
+		}
+	}
+
+	rule.ProjectID = projectID
+	rule.SourceID = req.SourceID
+	rule.Namespace = req.Namespace
+	rule.Stream = req.Stream
+	rule.Column = req.Column
+	rule.Strategy = req.Strategy
+	return nil
+}
+
+func (s *ETLService) ListMaskingRules(projectID string) ([]dto.MaskingRuleResponse, error) {
+	rules, err := s.db.ListMaskingRules(projectID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.MaskingRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, buildMaskingRuleResponse(rule))
+	}
+	return responses, nil
+}
+
+func (s *ETLService) GetMaskingRule(projectID string, id int) (*dto.MaskingRuleResponse, error) {
+	rule, err := s.getMaskingRule(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	response := buildMaskingRuleResponse(rule)
+	return &response, nil
+}
+
+func (s *ETLService) CreateMaskingRule(projectID string, req *dto.MaskingRuleRequest, userID *int) (*dto.MaskingRuleResponse, error) {
+	rule := &models.MaskingRule{}
+	if err := s.applyMaskingRuleRequest(projectID, rule, req); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: *userID}
+	rule.CreatedBy = user
+	rule.UpdatedBy = user
+	if err := s.db.CreateMaskingRule(rule); err != nil {
+		return nil, fmt.Errorf("failed to create masking rule: %s", err)
+	}
+	clearStreamPreviewCache(projectID)
+
+	return s.GetMaskingRule(projectID, rule.ID)
+}
+
+func (s *ETLService) UpdateMaskingRule(projectID string, id int, req *dto.MaskingRuleRequest, userID *int) (*dto.MaskingRuleResponse, error) {
+	rule, err := s.getMaskingRule(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyMaskingRuleRequest(projectID, rule, req); err != nil {
+		return nil, err
+	}
+
+	rule.UpdatedBy = &models.User{ID: *userID}
+	if err := s.db.UpdateMaskingRule(rule); err != nil {
+		return nil, fmt.Errorf("failed to update masking rule: %s", err)
+	}
+	clearStreamPreviewCache(projectID)
+
+	return s.GetMaskingRule(projectID, id)
+}
+
+func (s *ETLService) DeleteMaskingRule(projectID string, id int) error {
+	if _, err := s.getMaskingRule(projectID, id); err != nil {
+		return err
+	}
+	if err := s.db.DeleteMaskingRule(id); err != nil {
+		return fmt.Errorf("failed to delete masking rule: %s", err)
+	}
+	clearStreamPreviewCache(projectID)
+	return nil
+}
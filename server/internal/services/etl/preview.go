@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Stream preview methods on AppService
+//
+// A preview reads a few rows of one stream of a saved source, so users can see the data while
+// selecting streams. The stream is taken from the source's discovered catalog (served from the
+// catalog cache when enabled) and read as a full refresh by a dry run without a job. Previews
+// are masked by the project's masking rules and then kept in memory for
+// STREAM_PREVIEW_CACHE_TTL seconds.
+
+type streamPreviewEntry struct {
+	projectID string
+	preview   dto.StreamPreviewResponse
+	expiresAt time.Time
+}
+
+// streamPreviewCache holds masked previews keyed by streamPreviewKey
+var streamPreviewCache sync.Map
+
+// streamPreviewCacheTTL is how long a preview is served from cache, zero when disabled
+func streamPreviewCacheTTL() time.Duration {
+	return time.Duration(web.AppConfig.DefaultInt(constants.ConfStreamPreviewCacheTTL, constants.DefaultStreamPreviewCacheTTL)) * time.Second
+}
+
+// streamPreviewKey keys a preview by everything it was read with. The config holds secrets,
+// so the key is a keyed hash.
+func streamPreviewKey(projectID string, sourceID int, version, config, stream string, limit int) string {
+	return utils.KeyedHash("stream-preview", fmt.Sprintf("%s\x00%d\x00%s\x00%s\x00%s\x00%d", projectID, sourceID, version, config, stream, limit))
+}
+
+// cachedStreamPreview returns a preview that has not expired yet
+func cachedStreamPreview(key string) (*dto.StreamPreviewResponse, bool) {
+	cached, ok := streamPreviewCache.Load(key)
+	if !ok {
+		return nil, false
+	}
+	entry := cached.(*streamPreviewEntry)
+	if time.Now().After(entry.expiresAt) {
+		streamPreviewCache.Delete(key)
+		return nil, false
+	}
+	preview := entry.preview
+	preview.Cached = true
+	return &preview, true
+}
+
+// cacheStreamPreview stores a masked preview for ttl and drops expired ones
+func cacheStreamPreview(key, projectID string, preview *dto.StreamPreviewResponse, ttl time.Duration) {
+	now := time.Now()
+	streamPreviewCache.Range(func(key, value interface{}) bool {
+		if now.After(value.(*streamPreviewEntry).expiresAt) {
+			streamPreviewCache.Delete(key)
+		}
+		return true
+	})
+	streamPreviewCache.Store(key, &streamPreviewEntry{projectID: projectID, preview: *preview, expiresAt: now.Add(ttl)})
+}
+
+// clearStreamPreviewCache drops the cached previews of a project, so changed masking rules
+// apply right away
+func clearStreamPreviewCache(projectID string) {
+	streamPreviewCache.Range(func(key, value interface{}) bool {
+		if value.(*streamPreviewEntry).projectID == projectID {
+			streamPreviewCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// PreviewSourceStream returns up to limit rows of a stream ("namespace.stream") of a source,
+// from the cache unless refresh is set
+func (s *ETLService) PreviewSourceStream(ctx context.Context, projectID string, sourceID int, stream string, limit int, refresh bool) (*dto.StreamPreviewResponse, error) {
+	if stream == "" {
+		return nil, fmt.Errorf("%w: stream is required", constants.ErrInvalidStreamPreview)
+	}
+	if limit < 1 || limit > constants.MaxStreamPreviewLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", constants.ErrInvalidStreamPreview, constants.MaxStreamPreviewLimit)
+	}
+
+	source, err := s.db.GetSourceByID(sourceID)
+	if err != nil || source.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: source_id[%d] is not in project[%s]", constants.ErrSourceNotFound, sourceID, projectID)
+	}
+
+	ttl := streamPreviewCacheTTL()
+	key := streamPreviewKey(projectID, source.ID, source.Version, source.Config, stream, limit)
+	if ttl > 0 && !refresh {
+		if preview, ok := cachedStreamPreview(key); ok {
+			return preview, nil
+		}
+	}
+
+	// the masker is loaded first, a preview is not read when its rows cannot be masked
+	masker, err := s.sourceMasker(projectID, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := s.GetSourceCatalog(ctx, &dto.StreamsRequest{
+		Type:     source.Type,
+		Version:  source.Version,
+		Config:   source.Config,
+		JobID:    -1,
+		SourceID: &source.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	streamsConfig, columns, err := previewCatalog(catalog, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedConfig, err := utils.EncryptForConnector(source.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt config for preview: %s", err)
+	}
+
+	logger.Infof("stream preview started source_id[%d] stream[%s] limit[%d]", source.ID, stream, limit)
+	output, err := s.temporal.PreviewStream(ctx, source, encryptedConfig, streamsConfig, limit)
+	if err != nil {
+		return nil, fmt.Errorf("stream preview failed: %s", err)
+	}
+
+	reports, _ := output["streams"].(map[string]interface{})
+	report, _ := reports[stream].(map[string]interface{})
+	records, _ := report["records"].([]interface{})
+	preview := &dto.StreamPreviewResponse{
+		SourceID:  source.ID,
+		Stream:    stream,
+		Limit:     limit,
+		Columns:   columns,
+		Rows:      make([]map[string]interface{}, 0, len(records)),
+		FetchedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, raw := range records {
+		if record, ok := raw.(map[string]interface{}); ok && len(preview.Rows) < limit {
+			preview.Rows = append(preview.Rows, record)
+		}
+	}
+	preview.MaskedColumns = masker.maskRecords(stream, preview.Rows)
+
+	if ttl > 0 {
+		cacheStreamPreview(key, projectID, preview, ttl)
+	}
+	return preview, nil
+}
+
+// previewCatalog returns the streams config reading only the given stream of a discovered
+// catalog as a full refresh, with the column types of the stream
+func previewCatalog(catalog map[string]interface{}, stream string) (string, map[string]string, error) {
+	entries, _ := catalogEntries(catalog)
+	entry, ok := entries[stream]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: stream %s not found in source", constants.ErrInvalidStreamPreview, stream)
+	}
+	details, _ := entry["stream"].(map[string]interface{})
+	namespace, _ := details["namespace"].(string)
+	name, _ := details["name"].(string)
+
+	selected, ok := selectedStreamEntries(catalog)[stream]
+	if !ok {
+		selected = map[string]interface{}{"stream_name": name, "partition_regex": "", "normalization": false}
+	}
+	details["sync_mode"] = dryRunSyncMode
+
+	encoded, err := json.Marshal(map[string]interface{}{
+		"selected_streams": map[string]interface{}{namespace: []interface{}{selected}},
+		"streams":          []interface{}{entry},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode preview catalog: %s", err)
+	}
+	return string(encoded), streamColumns(entry), nil
+}
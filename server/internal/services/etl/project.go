@@ -1,12 +1,21 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/datazip-inc/olake-ui/server/internal/models"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
 )
 
+// webhookTimeout bounds a single alert sent to a project webhook
+const webhookTimeout = 10 * time.Second
+
 func (s *ETLService) GetProjectSettings(projectID string) (dto.ProjectSettingsResponse, error) {
 	settings, err := s.db.GetProjectSettingsByProjectID(projectID)
 	if err != nil {
@@ -18,9 +27,11 @@ func (s *ETLService) GetProjectSettings(projectID string) (dto.ProjectSettingsRe
 	}
 
 	return dto.ProjectSettingsResponse{
-		ID:              settings.ID,
-		ProjectID:       settings.ProjectID,
-		WebhookAlertURL: settings.WebhookAlertURL,
+		ID:                 settings.ID,
+		ProjectID:          settings.ProjectID,
+		WebhookAlertURL:    settings.WebhookAlertURL,
+		AlertRoutes:        decodeAlertRoutes(settings.AlertRoutes),
+		MaxConcurrentSyncs: settings.MaxConcurrentSyncs,
 	}, nil
 }
 
@@ -31,9 +42,106 @@ func (s *ETLService) UpsertProjectSettings(req dto.UpsertProjectSettingsRequest)
 		WebhookAlertURL: req.WebhookAlertURL,
 	}
 
+	existing, err := s.db.GetProjectSettingsByProjectID(req.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project settings: %s", err)
+	}
+	projectSettings.MaxConcurrentSyncs = existing.MaxConcurrentSyncs
+	if req.MaxConcurrentSyncs != nil {
+		if err := checkSyncCapSupported(existing.MaxConcurrentSyncs, *req.MaxConcurrentSyncs); err != nil {
+			return err
+		}
+		projectSettings.MaxConcurrentSyncs = *req.MaxConcurrentSyncs
+	}
+
+	if req.AlertRoutes == nil {
+		projectSettings.AlertRoutes = existing.AlertRoutes
+	} else if len(*req.AlertRoutes) > 0 {
+		for _, route := range *req.AlertRoutes {
+			if err := validateLabels(route.Labels); err != nil {
+				return err
+			}
+		}
+		routes, err := json.Marshal(*req.AlertRoutes)
+		if err != nil {
+			return fmt.Errorf("failed to encode alert routes: %s", err)
+		}
+		projectSettings.AlertRoutes = string(routes)
+	}
+
 	if err := s.db.UpsertProjectSettingsModel(projectSettings); err != nil {
 		return fmt.Errorf("failed to update project settings: %s", err)
 	}
 
 	return nil
 }
+
+// decodeAlertRoutes returns the alert routes stored in project settings, empty without routes
+func decodeAlertRoutes(raw string) []dto.AlertRoute {
+	routes := []dto.AlertRoute{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &routes); err != nil {
+			logger.Warnf("ignoring invalid stored alert routes: %s", err)
+			return []dto.AlertRoute{}
+		}
+	}
+	return routes
+}
+
+// notifyProject posts a message to the webhooks of a project, as a Slack compatible
+// {"text": message} payload. The alert goes to every alert route whose labels the alert labels
+// carry, or to the webhook alert URL of the project when no route matches. Projects without a
+// webhook are skipped, failures are only logged.
+func (s *ETLService) notifyProject(ctx context.Context, projectID string, labels map[string]string, message string) {
+	settings, err := s.db.GetProjectSettingsByProjectID(projectID)
+	if err != nil {
+		logger.Warnf("failed to get project settings to send alert project_id[%s]: %s", projectID, err)
+		return
+	}
+
+	var webhooks []string
+	if len(labels) > 0 {
+		for _, route := range decodeAlertRoutes(settings.AlertRoutes) {
+			if labelsMatch(labels, route.Labels) {
+				webhooks = append(webhooks, route.WebhookURL)
+			}
+		}
+	}
+	if len(webhooks) == 0 && settings.WebhookAlertURL != "" {
+		webhooks = append(webhooks, settings.WebhookAlertURL)
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]string{"text": message})
+	if err != nil {
+		logger.Warnf("failed to encode alert project_id[%s]: %s", projectID, err)
+		return
+	}
+	for _, webhook := range webhooks {
+		sendAlert(ctx, projectID, webhook, payload)
+	}
+}
+
+// sendAlert posts an alert payload to a webhook
+func sendAlert(ctx context.Context, projectID, webhook string, payload []byte) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(payload))
+	if err != nil {
+		logger.Warnf("failed to create alert request project_id[%s]: %s", projectID, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Warnf("failed to send alert project_id[%s]: %s", projectID, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		logger.Warnf("alert webhook returned status %d project_id[%s]", resp.StatusCode, projectID)
+	}
+}
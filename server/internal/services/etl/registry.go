@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"golang.org/x/mod/semver"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/database"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Connector registry methods on AppService
+//
+// The registry keeps the connector images, their versions and the minimum connector version of
+// features in the database, so version lists are served without reaching a registry and keep
+// working air-gapped. A background refresh adds the versions found in the container registry,
+// or only the images pulled on the docker host when CONNECTOR_REGISTRY_OFFLINE is set.
+// Versions can also be added by hand.
+
+// registryRefreshMu serializes refreshes, the background loop and API calls may overlap
+var registryRefreshMu sync.Mutex
+
+// StartConnectorRegistry seeds the registry with the supported connectors and refreshes it
+// every CONNECTOR_REGISTRY_REFRESH_INTERVAL minutes, starting right away
+func (s *ETLService) StartConnectorRegistry(ctx context.Context) {
+	if err := s.db.SeedConnectors(defaultConnectors()); err != nil {
+		logger.Errorf("failed to seed connector registry: %s", err)
+	}
+
+	interval := time.Duration(web.AppConfig.DefaultInt(constants.ConfConnectorRegistryRefresh, constants.DefaultConnectorRegistryRefresh)) * time.Minute
+	if interval <= 0 {
+		logger.Info("connector registry refresh disabled")
+		return
+	}
+
+	go func() {
+		s.refreshConnectorRegistry(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.refreshConnectorRegistry(ctx)
+			}
+		}
+	}()
+}
+
+func defaultConnectors() []*models.Connector {
+	features, _ := json.Marshal(constants.DefaultConnectorFeatures)
+	connectors := make([]*models.Connector, 0, len(constants.SupportedSourceTypes))
+	for i, connectorType := range constants.SupportedSourceTypes {
+		connectors = append(connectors, &models.Connector{
+			Type:      connectorType,
+			Image:     constants.DefaultConnectorImagePrefix + connectorType,
+			Position:  i,
+			AutoAllow: true,
+			Features:  string(features),
+		})
+	}
+	return connectors
+}
+
+func (s *ETLService) refreshConnectorRegistry(ctx context.Context) {
+	connectors, err := s.db.ListConnectors()
+	if err != nil {
+		logger.Errorf("connector registry refresh failed: %s", err)
+		return
+	}
+	for _, connector := range connectors {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.refreshConnector(ctx, connector); err != nil {
+			logger.Warnf("connector registry refresh failed type[%s]: %s", connector.Type, err)
+		}
+	}
+}
+
+// refreshConnector records the versions of a connector image found in the container registry,
+// falling back to the images on the docker host when the registry cannot be reached
+func (s *ETLService) refreshConnector(ctx context.Context, connector *models.Connector) error {
+	registryRefreshMu.Lock()
+	defer registryRefreshMu.Unlock()
+
+	var (
+		tags       []string
+		origin     = database.ConnectorVersionRegistry
+		refreshErr error
+	)
+	if !web.AppConfig.DefaultBool(constants.ConfConnectorRegistryOffline, constants.DefaultConnectorRegistryOffline) {
+		tags, refreshErr = utils.GetRegistryImageTags(ctx, connector.Image)
+	}
+	if tags == nil {
+		if refreshErr != nil {
+			logger.Warnf("failed to fetch image tags online for %s: %s, falling back to local images", connector.Image, refreshErr)
+		}
+		origin = database.ConnectorVersionLocal
+		localTags, err := utils.GetLocalImageTags(ctx, connector.Image)
+		if err != nil {
+			refreshErr = errors.Join(refreshErr, err)
+		}
+		tags = localTags
+	}
+
+	if err := s.db.RecordConnectorRefresh(connector, tags, origin, refreshErr); err != nil {
+		return err
+	}
+	return refreshErr
+}
+
+// connectorVersions returns the allowed versions of a connector highest first, and the ones of
+// them that are deprecated. A connector that was never refreshed is refreshed first.
+func (s *ETLService) connectorVersions(ctx context.Context, connector *models.Connector) ([]string, []string, error) {
+	entries, err := s.db.ListConnectorVersions(connector.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entries) == 0 && connector.RefreshedAt == nil {
+		if err := s.refreshConnector(ctx, connector); err != nil {
+			logger.Warnf("connector refresh type[%s]: %s", connector.Type, err)
+		}
+		if entries, err = s.db.ListConnectorVersions(connector.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var versions, deprecated []string
+	for _, entry := range entries {
+		if !entry.Allowed {
+			continue
+		}
+		versions = append(versions, entry.Version)
+		if entry.Deprecated {
+			deprecated = append(deprecated, entry.Version)
+		}
+	}
+	utils.SortImageTags(versions)
+	utils.SortImageTags(deprecated)
+	return versions, deprecated, nil
+}
+
+// connectorVersionList is the versions response of a connector
+func (s *ETLService) connectorVersionList(ctx context.Context, connector *models.Connector) (map[string]interface{}, error) {
+	versions, deprecated, err := s.connectorVersions(ctx, connector)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions found for image: %s", connector.Image)
+	}
+
+	result := map[string]interface{}{"version": versions}
+	if len(deprecated) > 0 {
+		result["deprecated"] = deprecated
+	}
+	if connector.Deprecated {
+		result["connector_deprecated"] = true
+		result["deprecation_note"] = connector.DeprecationNote
+	}
+	return result, nil
+}
+
+// driverConnector returns the connector that writes destinations, the first connector in
+// registry order that is not deprecated and has allowed versions
+func (s *ETLService) driverConnector(ctx context.Context) (*models.Connector, error) {
+	connectors, err := s.db.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+	for _, connector := range connectors {
+		if connector.Deprecated {
+			continue
+		}
+		versions, _, err := s.connectorVersions(ctx, connector)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) > 0 {
+			return connector, nil
+		}
+	}
+	return nil, fmt.Errorf("no connector with allowed versions in the registry")
+}
+
+// driverType returns the connector type that writes destinations
+func (s *ETLService) driverType(ctx context.Context) (string, error) {
+	connector, err := s.driverConnector(ctx)
+	if err != nil {
+		return "", err
+	}
+	return connector.Type, nil
+}
+
+// featureVersion returns the minimum version of a connector for a feature
+func (s *ETLService) featureVersion(connectorType, feature string) string {
+	connector, err := s.db.GetConnector(connectorType)
+	if err == nil && connector.Features != "" {
+		var features map[string]string
+		if err := json.Unmarshal([]byte(connector.Features), &features); err == nil {
+			if version, ok := features[feature]; ok {
+				return version
+			}
+		}
+	}
+	return constants.DefaultConnectorFeatures[feature]
+}
+
+// checkConnectorVersion rejects a version the registry knows and does not allow. Versions the
+// registry has not seen are accepted, so an install with an empty registry keeps working.
+func (s *ETLService) checkConnectorVersion(connectorType, version string) error {
+	connector, err := s.db.GetConnector(connectorType)
+	if err != nil {
+		if errors.Is(err, constants.ErrConnectorNotFound) {
+			return nil
+		}
+		return err
+	}
+	entry, err := s.db.GetConnectorVersion(connector.ID, version)
+	if err != nil {
+		if errors.Is(err, constants.ErrConnectorVersionNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !entry.Allowed {
+		return fmt.Errorf("%w: %s %s", constants.ErrConnectorVersionNotAllowed, connectorType, version)
+	}
+	return nil
+}
+
+// checkDestinationVersion checks a destination version against the driver connector, it is
+// skipped when the registry has no driver yet
+func (s *ETLService) checkDestinationVersion(ctx context.Context, version string) error {
+	driver, err := s.driverType(ctx)
+	if err != nil {
+		logger.Warnf("skipping destination version check: %s", err)
+		return nil
+	}
+	return s.checkConnectorVersion(driver, version)
+}
+
+// checkClearDestinationCompatibility checks the source version supports clear-destination and
+// stream difference
+func (s *ETLService) checkClearDestinationCompatibility(sourceType, sourceVersion string) error {
+	minVersion := s.featureVersion(sourceType, constants.FeatureClearDestination)
+	if semver.Compare(sourceVersion, minVersion) < 0 {
+		return fmt.Errorf("source version %s is not supported for clear destination. please update the source version to %s or higher", sourceVersion, minVersion)
+	}
+	return nil
+}
+
+// ListConnectors returns the registry with the versions of every connector
+func (s *ETLService) ListConnectors() ([]dto.ConnectorResponse, error) {
+	connectors, err := s.db.ListConnectors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connectors: %s", err)
+	}
+
+	result := make([]dto.ConnectorResponse, 0, len(connectors))
+	for _, connector := range connectors {
+		item, err := s.connectorResponse(connector)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GetConnector returns a registry connector with its versions
+func (s *ETLService) GetConnector(connectorType string) (*dto.ConnectorResponse, error) {
+	connector, err := s.db.GetConnector(connectorType)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.connectorResponse(connector)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateConnector changes the image, order, deprecation, auto allow and features of a connector
+func (s *ETLService) UpdateConnector(connectorType string, req *dto.UpdateConnectorRequest) (*dto.ConnectorResponse, error) {
+	connector, err := s.db.GetConnector(connectorType)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Image != nil {
+		connector.Image = *req.Image
+	}
+	if req.Position != nil {
+		connector.Position = *req.Position
+	}
+	if req.Deprecated != nil {
+		connector.Deprecated = *req.Deprecated
+	}
+	if req.DeprecationNote != nil {
+		connector.DeprecationNote = *req.DeprecationNote
+	}
+	if req.AutoAllow != nil {
+		connector.AutoAllow = *req.AutoAllow
+	}
+	if req.Features != nil {
+		for feature, version := range req.Features {
+			if !semver.IsValid(version) {
+				return nil, fmt.Errorf("%w: feature %s needs a semantic version, got '%s'", constants.ErrInvalidConnector, feature, version)
+			}
+		}
+		features, err := json.Marshal(req.Features)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode connector features: %s", err)
+		}
+		connector.Features = string(features)
+	}
+
+	if err := s.db.UpdateConnector(connector); err != nil {
+		return nil, err
+	}
+	return s.GetConnector(connectorType)
+}
+
+// SetConnectorVersion allows, disallows or deprecates a version of a connector, adding it when
+// it is not known yet, e.g. for an image loaded into an air-gapped install
+func (s *ETLService) SetConnectorVersion(connectorType string, req *dto.ConnectorVersionRequest) (*dto.ConnectorResponse, error) {
+	connector, err := s.db.GetConnector(connectorType)
+	if err != nil {
+		return nil, err
+	}
+	if !semver.IsValid(req.Version) {
+		return nil, fmt.Errorf("%w: '%s' is not a semantic version", constants.ErrInvalidConnector, req.Version)
+	}
+
+	if err := s.db.SaveConnectorVersion(&models.ConnectorVersion{
+		Connector:  connector,
+		Version:    req.Version,
+		Allowed:    *req.Allowed,
+		Deprecated: req.Deprecated,
+		Origin:     database.ConnectorVersionManual,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetConnector(connectorType)
+}
+
+// DeleteConnectorVersion removes a version of a connector. A later refresh adds it back if it
+// is still found, disallow the version to keep it out.
+func (s *ETLService) DeleteConnectorVersion(connectorType, version string) error {
+	connector, err := s.db.GetConnector(connectorType)
+	if err != nil {
+		return err
+	}
+	return s.db.DeleteConnectorVersion(connector.ID, version)
+}
+
+// RefreshConnectors refreshes every connector of the registry now
+func (s *ETLService) RefreshConnectors(ctx context.Context) ([]dto.ConnectorResponse, error) {
+	s.refreshConnectorRegistry(ctx)
+	return s.ListConnectors()
+}
+
+func (s *ETLService) connectorResponse(connector *models.Connector) (dto.ConnectorResponse, error) {
+	entries, err := s.db.ListConnectorVersions(connector.ID)
+	if err != nil {
+		return dto.ConnectorResponse{}, err
+	}
+
+	features := map[string]string{}
+	if connector.Features != "" {
+		if err := json.Unmarshal([]byte(connector.Features), &features); err != nil {
+			logger.Warnf("ignoring unreadable features of connector type[%s]: %s", connector.Type, err)
+		}
+	}
+
+	versions := make([]dto.ConnectorVersionItem, 0, len(entries))
+	for _, entry := range entries {
+		versions = append(versions, dto.ConnectorVersionItem{
+			Version:    entry.Version,
+			Allowed:    entry.Allowed,
+			Deprecated: entry.Deprecated,
+			Origin:     entry.Origin,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return semver.Compare(versions[i].Version, versions[j].Version) > 0 })
+
+	response := dto.ConnectorResponse{
+		Type:            connector.Type,
+		Image:           connector.Image,
+		Position:        connector.Position,
+		Deprecated:      connector.Deprecated,
+		DeprecationNote: connector.DeprecationNote,
+		AutoAllow:       connector.AutoAllow,
+		Features:        features,
+		RefreshError:    connector.RefreshError,
+		Versions:        versions,
+	}
+	if connector.RefreshedAt != nil {
+		response.RefreshedAt = connector.RefreshedAt.Format(time.RFC3339)
+	}
+	return response, nil
+}
@@ -0,0 +1,497 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/database"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Schema drift methods on AppService
+//
+// Jobs with a drift policy have their source re-discovered periodically and the fresh schema
+// compared with the one in their streams config. Tables are compared across the whole catalog,
+// columns only for the selected streams. What happens on drift depends on the policy:
+//
+//	notify       the drift waits for approval and the project webhook is alerted
+//	auto_accept  the drift is written to the streams config right away
+//	pause        like notify, and the job is paused until the drift is approved
+
+// Drift policies of a job
+const (
+	DriftPolicyNotify     = "notify"
+	DriftPolicyAutoAccept = "auto_accept"
+	DriftPolicyPause      = "pause"
+)
+
+// Actions a drift policy takes
+const (
+	driftActionNotified = "notified"
+	driftActionAccepted = "accepted"
+	driftActionPaused   = "paused"
+)
+
+// Schema change kinds
+const (
+	schemaTableAdded    = "table_added"
+	schemaTableDropped  = "table_dropped"
+	schemaColumnAdded   = "column_added"
+	schemaColumnDropped = "column_dropped"
+	schemaColumnRetyped = "column_retyped"
+)
+
+// schemaCheckRunning holds the IDs of jobs being checked
+var schemaCheckRunning sync.Map
+
+// catalogEntries indexes the streams[] entries of a catalog by "namespace.stream"
+func catalogEntries(catalog map[string]interface{}) (map[string]map[string]interface{}, []string) {
+	entries, _ := catalog["streams"].([]interface{})
+	index := make(map[string]map[string]interface{}, len(entries))
+	order := make([]string, 0, len(entries))
+	for _, raw := range entries {
+		entry, _ := raw.(map[string]interface{})
+		stream, _ := entry["stream"].(map[string]interface{})
+		name, _ := stream["name"].(string)
+		if name == "" {
+			continue
+		}
+		namespace, _ := stream["namespace"].(string)
+		id := namespace + "." + name
+		index[id] = entry
+		order = append(order, id)
+	}
+	return index, order
+}
+
+// streamColumns returns the columns of a catalog entry with their type, "null|string" for a
+// column typed ["string", "null"]
+func streamColumns(entry map[string]interface{}) map[string]string {
+	stream, _ := entry["stream"].(map[string]interface{})
+	typeSchema, _ := stream["type_schema"].(map[string]interface{})
+	properties, _ := typeSchema["properties"].(map[string]interface{})
+
+	columns := make(map[string]string, len(properties))
+	for name, raw := range properties {
+		property, _ := raw.(map[string]interface{})
+		switch t := property["type"].(type) {
+		case string:
+			columns[name] = t
+		case []interface{}:
+			types := make([]string, 0, len(t))
+			for _, v := range t {
+				if s, ok := v.(string); ok {
+					types = append(types, s)
+				}
+			}
+			sort.Strings(types)
+			columns[name] = strings.Join(types, "|")
+		default:
+			columns[name] = ""
+		}
+	}
+	return columns
+}
+
+// diffSchemas compares the catalog of a job with a discovered one
+func diffSchemas(current, discovered map[string]interface{}) []dto.SchemaChange {
+	currentEntries, currentOrder := catalogEntries(current)
+	discoveredEntries, discoveredOrder := catalogEntries(discovered)
+	selected := selectedStreamEntries(current)
+
+	var changes []dto.SchemaChange
+	for _, id := range discoveredOrder {
+		if _, ok := currentEntries[id]; !ok {
+			changes = append(changes, dto.SchemaChange{Change: schemaTableAdded, Stream: id})
+		}
+	}
+	for _, id := range currentOrder {
+		discoveredEntry, ok := discoveredEntries[id]
+		if !ok {
+			changes = append(changes, dto.SchemaChange{Change: schemaTableDropped, Stream: id})
+			continue
+		}
+		if _, ok := selected[id]; !ok {
+			continue
+		}
+
+		oldColumns := streamColumns(currentEntries[id])
+		newColumns := streamColumns(discoveredEntry)
+		for column, newType := range newColumns {
+			oldType, ok := oldColumns[column]
+			switch {
+			case !ok:
+				changes = append(changes, dto.SchemaChange{Change: schemaColumnAdded, Stream: id, Column: column, NewType: newType})
+			case oldType != newType:
+				changes = append(changes, dto.SchemaChange{Change: schemaColumnRetyped, Stream: id, Column: column, OldType: oldType, NewType: newType})
+			}
+		}
+		for column, oldType := range oldColumns {
+			if _, ok := newColumns[column]; !ok {
+				changes = append(changes, dto.SchemaChange{Change: schemaColumnDropped, Stream: id, Column: column, OldType: oldType})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Stream != changes[j].Stream {
+			return changes[i].Stream < changes[j].Stream
+		}
+		if changes[i].Column != changes[j].Column {
+			return changes[i].Column < changes[j].Column
+		}
+		return changes[i].Change < changes[j].Change
+	})
+	return changes
+}
+
+// acceptSchemaDrift returns the catalog of a job moved to the discovered schema. Streams keep
+// their sync settings and only take the new schema, new tables are added unselected and
+// dropped tables leave the selection.
+func acceptSchemaDrift(current, discovered map[string]interface{}) (string, error) {
+	currentEntries, _ := catalogEntries(current)
+	discoveredEntries, discoveredOrder := catalogEntries(discovered)
+
+	streams := make([]interface{}, 0, len(discoveredOrder))
+	for _, id := range discoveredOrder {
+		entry := discoveredEntries[id]
+		if old, ok := currentEntries[id]; ok {
+			oldStream, _ := old["stream"].(map[string]interface{})
+			newStream, _ := entry["stream"].(map[string]interface{})
+			merged := make(map[string]interface{}, len(oldStream))
+			for key, value := range oldStream {
+				merged[key] = value
+			}
+			for _, key := range []string{"type_schema", "supported_sync_modes", "source_defined_primary_key"} {
+				if value, ok := newStream[key]; ok {
+					merged[key] = value
+				}
+			}
+			updated := make(map[string]interface{}, len(old))
+			for key, value := range old {
+				updated[key] = value
+			}
+			updated["stream"] = merged
+			entry = updated
+		}
+		streams = append(streams, entry)
+	}
+
+	accepted := make(map[string]interface{}, len(current))
+	for key, value := range current {
+		accepted[key] = value
+	}
+	accepted["streams"] = streams
+
+	selectedStreams := map[string]interface{}{}
+	oldSelected, _ := current["selected_streams"].(map[string]interface{})
+	for namespace, raw := range oldSelected {
+		list, _ := raw.([]interface{})
+		kept := make([]interface{}, 0, len(list))
+		for _, rawEntry := range list {
+			entry, _ := rawEntry.(map[string]interface{})
+			name, _ := entry["stream_name"].(string)
+			if _, ok := discoveredEntries[namespace+"."+name]; ok {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) > 0 {
+			selectedStreams[namespace] = kept
+		}
+	}
+	accepted["selected_streams"] = selectedStreams
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(accepted); err != nil {
+		return "", fmt.Errorf("failed to encode streams config: %s", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func buildSchemaDriftResponse(drift *models.SchemaDrift, includeConfig bool) (dto.SchemaDriftResponse, error) {
+	response := dto.SchemaDriftResponse{
+		ID:        drift.ID,
+		JobID:     drift.Job.ID,
+		JobName:   drift.Job.Name,
+		Status:    drift.Status,
+		Action:    drift.Action,
+		CreatedAt: drift.CreatedAt.Format(time.RFC3339),
+	}
+	if err := json.Unmarshal([]byte(drift.Changes), &response.Changes); err != nil {
+		return dto.SchemaDriftResponse{}, fmt.Errorf("failed to parse changes of schema drift %d: %s", drift.ID, err)
+	}
+	if includeConfig {
+		response.StreamsConfig = drift.StreamsConfig
+	}
+	if drift.ResolvedAt != nil {
+		response.ResolvedAt = drift.ResolvedAt.Format(time.RFC3339)
+	}
+	if drift.ResolvedBy != nil {
+		response.ResolvedBy = drift.ResolvedBy.Username
+	}
+	return response, nil
+}
+
+func (s *ETLService) GetJobDriftPolicy(_ context.Context, projectID string, jobID int) (*dto.DriftPolicyResponse, error) {
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DriftPolicyResponse{JobID: job.ID, Policy: job.DriftPolicy}, nil
+}
+
+func (s *ETLService) SetJobDriftPolicy(_ context.Context, projectID string, jobID int, req *dto.DriftPolicyRequest, userID *int) (*dto.DriftPolicyResponse, error) {
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateJob(job.ID, orm.Params{"drift_policy": req.Policy, "updated_by_id": *userID}); err != nil {
+		return nil, fmt.Errorf("failed to update drift policy: %s", err)
+	}
+	logger.Infof("drift policy of job %d set to '%s'", job.ID, req.Policy)
+	return &dto.DriftPolicyResponse{JobID: job.ID, Policy: req.Policy}, nil
+}
+
+// CheckJobSchema compares the schema of a job with its source now
+func (s *ETLService) CheckJobSchema(ctx context.Context, projectID string, jobID int, userID *int) (*dto.SchemaCheckResponse, error) {
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.DriftPolicy == "" {
+		return nil, constants.ErrNoDriftPolicy
+	}
+	return s.checkJobSchema(ctx, job, userID)
+}
+
+func (s *ETLService) checkJobSchema(ctx context.Context, job *models.Job, userID *int) (*dto.SchemaCheckResponse, error) {
+	if _, running := schemaCheckRunning.LoadOrStore(job.ID, struct{}{}); running {
+		return nil, fmt.Errorf("schema check of job %d is already running", job.ID)
+	}
+	defer schemaCheckRunning.Delete(job.ID)
+
+	var current map[string]interface{}
+	if err := json.Unmarshal([]byte(job.StreamsConfig), &current); err != nil {
+		return nil, fmt.Errorf("failed to parse streams config: %s", err)
+	}
+
+	// discover without the job's catalog, so the connector reports the schema as it is now
+	source := job.SourceID
+	encryptedConfig, err := utils.EncryptForConnector(source.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt config for catalog: %s", err)
+	}
+	discovered, err := s.temporal.DiscoverStreams(ctx, source.Type, source.Version, encryptedConfig, "", job.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get catalog: %s", err)
+	}
+
+	changes := diffSchemas(current, discovered)
+	result := &dto.SchemaCheckResponse{Changes: changes}
+	if len(changes) == 0 {
+		result.Changes = []dto.SchemaChange{}
+		return result, nil
+	}
+	result.Drifted = true
+
+	encodedChanges, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema changes: %s", err)
+	}
+
+	// the same drift found again keeps its record instead of alerting twice
+	pending, err := s.db.ListSchemaDrifts(job.ProjectID, job.ID, database.SchemaDriftPending)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 && pending[0].Changes != "" {
+		var pendingChanges []dto.SchemaChange
+		if json.Unmarshal([]byte(pending[0].Changes), &pendingChanges) == nil && schemaChangesEqual(pendingChanges, changes) {
+			result.DriftID = pending[0].ID
+			result.Action = pending[0].Action
+			return result, nil
+		}
+	}
+
+	streamsConfig, err := acceptSchemaDrift(current, discovered)
+	if err != nil {
+		return nil, err
+	}
+	drift := &models.SchemaDrift{
+		Job:           job,
+		Status:        database.SchemaDriftPending,
+		Action:        driftActionNotified,
+		Changes:       string(encodedChanges),
+		StreamsConfig: streamsConfig,
+	}
+	switch job.DriftPolicy {
+	case DriftPolicyAutoAccept:
+		drift.Status = database.SchemaDriftAccepted
+		drift.Action = driftActionAccepted
+	case DriftPolicyPause:
+		drift.Action = driftActionPaused
+	}
+
+	if err := s.db.CreateSchemaDrift(drift, job.StreamsConfig); err != nil {
+		return nil, fmt.Errorf("failed to store schema drift: %w", err)
+	}
+	result.DriftID = drift.ID
+	result.Action = drift.Action
+
+	if drift.Action == driftActionPaused && job.Active {
+		if err := s.pauseJobOnSchemaDrift(ctx, job, drift.ID, userID); err != nil {
+			return nil, fmt.Errorf("failed to pause job on schema drift: %s", err)
+		}
+	}
+
+	logger.Warnf("schema drift found for job %d changes[%d] action[%s]", job.ID, len(changes), drift.Action)
+	if drift.Action != driftActionAccepted {
+		s.notifyProject(ctx, job.ProjectID, decodeLabels(job.Labels), fmt.Sprintf("Schema drift found for job '%s' (%d changes), the job was %s. Review drift %d to accept it.", job.Name, len(changes), drift.Action, drift.ID))
+	}
+	return result, nil
+}
+
+// pauseJobOnSchemaDrift pauses a job for a drift. A check run by a user pauses the job on their
+// behalf, scheduled checks pause it as a system action that leaves the job's last editor as is
+// and notes the drift on the schedule.
+func (s *ETLService) pauseJobOnSchemaDrift(ctx context.Context, job *models.Job, driftID int, userID *int) error {
+	if userID != nil {
+		return s.ActivateJob(ctx, job.ID, dto.JobStatusRequest{Activate: false}, userID)
+	}
+
+	if err := s.temporal.PauseScheduleWithNote(ctx, job.ProjectID, job.ID, fmt.Sprintf("schema drift %d paused the schedule", driftID)); err != nil {
+		return fmt.Errorf("failed to pause schedule: %s", err)
+	}
+	if err := s.db.UpdateJob(job.ID, orm.Params{"active": false}); err != nil {
+		return fmt.Errorf("failed to update job activation status: %s", err)
+	}
+	logger.Infof("job %d paused by schema drift %d", job.ID, driftID)
+	return nil
+}
+
+func schemaChangesEqual(a, b []dto.SchemaChange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// StartSchemaDriftChecks periodically checks the schema of active jobs with a drift policy
+// until ctx is done
+func (s *ETLService) StartSchemaDriftChecks(ctx context.Context) {
+	interval := time.Duration(web.AppConfig.DefaultInt(constants.ConfSchemaDriftCheckInterval, constants.DefaultSchemaDriftCheck)) * time.Minute
+	if interval <= 0 {
+		logger.Info("schema drift checks disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkAllJobSchemas(ctx)
+			}
+		}
+	}()
+}
+
+func (s *ETLService) checkAllJobSchemas(ctx context.Context) {
+	jobs, err := s.db.ListJobsWithDriftPolicy()
+	if err != nil {
+		logger.Errorf("schema drift check failed: %s", err)
+		return
+	}
+
+	for _, listed := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := s.db.GetJobByID(listed.ID, true)
+		if err != nil {
+			logger.Warnf("schema drift check skipped job_id[%d]: %s", listed.ID, err)
+			continue
+		}
+		if _, err := s.checkJobSchema(ctx, job, nil); err != nil {
+			logger.Warnf("schema drift check failed job_id[%d]: %s", job.ID, err)
+		}
+	}
+}
+
+// ListSchemaDrifts returns the schema drifts of a project, jobID 0 and an empty status match any
+func (s *ETLService) ListSchemaDrifts(_ context.Context, projectID string, jobID int, status string) ([]dto.SchemaDriftResponse, error) {
+	drifts, err := s.db.ListSchemaDrifts(projectID, jobID, status)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.SchemaDriftResponse, 0, len(drifts))
+	for _, drift := range drifts {
+		response, err := buildSchemaDriftResponse(drift, false)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *ETLService) GetSchemaDrift(_ context.Context, projectID string, id int) (*dto.SchemaDriftResponse, error) {
+	drift, err := s.db.GetSchemaDrift(projectID, id)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, constants.ErrSchemaDriftNotFound
+		}
+		return nil, fmt.Errorf("failed to get schema drift: %s", err)
+	}
+	response, err := buildSchemaDriftResponse(drift, true)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ResolveSchemaDrift accepts a pending drift into the job's streams config or rejects it. A job
+// paused by the drift is resumed once the drift is accepted.
+func (s *ETLService) ResolveSchemaDrift(ctx context.Context, projectID string, id int, accept bool, userID *int) (*dto.SchemaDriftResponse, error) {
+	drift, err := s.GetSchemaDrift(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.ResolveSchemaDrift(id, accept, *userID); err != nil {
+		return nil, err
+	}
+
+	if accept && drift.Action == driftActionPaused {
+		if err := s.ActivateJob(ctx, drift.JobID, dto.JobStatusRequest{Activate: true}, userID); err != nil {
+			return nil, fmt.Errorf("drift accepted but failed to resume job: %s", err)
+		}
+	}
+
+	logger.Infof("schema drift %d resolved accept[%t] by user %d", id, accept, *userID)
+	return s.GetSchemaDrift(ctx, projectID, id)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	decode := func(catalog string) map[string]interface{} {
+		var parsed map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(catalog), &parsed))
+		return parsed
+	}
+
+	current := decode(`{
+		"streams": [
+			{"stream": {"name": "orders", "namespace": "public", "type_schema": {"properties": {
+				"id": {"type": "integer"}, "note": {"type": ["string", "null"]}, "legacy": {"type": "string"}}}}},
+			{"stream": {"name": "users", "namespace": "public", "type_schema": {"properties": {"id": {"type": "integer"}}}}},
+			{"stream": {"name": "audit", "namespace": "public"}}
+		],
+		"selected_streams": {"public": [{"stream_name": "orders"}]}
+	}`)
+	discovered := decode(`{
+		"streams": [
+			{"stream": {"name": "orders", "namespace": "public", "type_schema": {"properties": {
+				"id": {"type": "string"}, "note": {"type": ["null", "string"]}, "total": {"type": "number"}}}}},
+			{"stream": {"name": "users", "namespace": "public", "type_schema": {"properties": {"email": {"type": "string"}}}}},
+			{"stream": {"name": "invoices", "namespace": "billing"}}
+		]
+	}`)
+
+	require.Equal(t, []dto.SchemaChange{
+		{Change: schemaTableAdded, Stream: "billing.invoices"},
+		{Change: schemaTableDropped, Stream: "public.audit"},
+		{Change: schemaColumnRetyped, Stream: "public.orders", Column: "id", OldType: "integer", NewType: "string"},
+		{Change: schemaColumnDropped, Stream: "public.orders", Column: "legacy", OldType: "string"},
+		{Change: schemaColumnAdded, Stream: "public.orders", Column: "total", NewType: "number"},
+	}, diffSchemas(current, discovered), "column changes of unselected streams and reordered types are ignored")
+
+	require.Empty(t, diffSchemas(current, current))
+}
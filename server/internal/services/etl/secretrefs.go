@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
+)
+
+// Secret reference methods on AppService
+//
+// The server resolves secret references when it writes connector configs itself, for check,
+// discover, dry runs and previews. Syncs and clear-destination runs read the stored configs on
+// the worker, which resolves the references right before starting the connector, so resolved
+// secrets never reach the database, a Temporal schedule or a workflow history. Jobs using
+// references are refused unless the worker declares it does so.
+
+// referencesSecrets reports whether any of the decrypted configs references a secret
+func referencesSecrets(configs ...string) bool {
+	for _, config := range configs {
+		if secretref.HasReferences(config) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSecretRefsSupported refuses to sync with decrypted configs that reference secrets
+// unless the worker resolves them
+func checkSecretRefsSupported(configs ...string) error {
+	if !referencesSecrets(configs...) {
+		return nil
+	}
+	return temporal.RequireWorkerFeatures(temporal.WorkerFeatureSecretRefs)
+}
+
+// checkJobSecretRefsSupported checks the source and destination of a job request, which are
+// either new configs or existing sources and destinations referenced by ID
+func (s *ETLService) checkJobSecretRefsSupported(source, destination *dto.DriverConfig) error {
+	var configs []string
+	if source != nil {
+		if source.ID == nil {
+			configs = append(configs, source.Config)
+		} else if existing, err := s.db.GetSourceByID(*source.ID); err == nil {
+			configs = append(configs, existing.Config)
+		} else {
+			return fmt.Errorf("failed to get source: %s", err)
+		}
+	}
+	if destination != nil {
+		if destination.ID == nil {
+			configs = append(configs, destination.Config)
+		} else if existing, err := s.db.GetDestinationByID(*destination.ID); err == nil {
+			configs = append(configs, existing.Config)
+		} else {
+			return fmt.Errorf("failed to get destination: %s", err)
+		}
+	}
+	return checkSecretRefsSupported(configs...)
+}
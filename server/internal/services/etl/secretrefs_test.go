@@ -0,0 +1,24 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+func TestCheckSecretRefsSupported(t *testing.T) {
+	require.NoError(t, web.AppConfig.Set(constants.ConfWorkerFeatures, ""))
+	t.Cleanup(func() { _ = web.AppConfig.Set(constants.ConfWorkerFeatures, "") })
+
+	plain := `{"host": "mysql", "password": "s3cret"}`
+	referencing := `{"host": "mysql", "password": {"$secret": "vault://kv/data/mysql#password"}}`
+
+	require.NoError(t, checkSecretRefsSupported(plain, `{"type": "PARQUET"}`))
+	require.ErrorIs(t, checkSecretRefsSupported(plain, referencing), constants.ErrWorkerFeatureUnsupported)
+
+	require.NoError(t, web.AppConfig.Set(constants.ConfWorkerFeatures, "secret-refs"))
+	require.NoError(t, checkSecretRefsSupported(plain, referencing))
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Secret field methods on AppService
+//
+// Secret fields are identified from the connector spec and masked in every response that
+// carries a config. Clients send the mask back for secrets they did not change and the stored
+// value is kept, see utils.RestoreMaskedSecrets. When the worker declares the secret-fields
+// feature, secret fields are also encrypted on their own before a config is saved, and only
+// decrypted when a connector config file is written.
+
+const (
+	// specLookupTimeout bounds fetching a spec in the background to find secret fields
+	specLookupTimeout = 30 * time.Second
+	// specLookupRetryAfter is how long a failed spec lookup is not retried, every field is
+	// masked meanwhile
+	specLookupRetryAfter = 5 * time.Minute
+)
+
+type secretPathsEntry struct {
+	paths     utils.SecretPaths
+	checkedAt time.Time
+	available bool
+}
+
+// secretPathsCache holds the secret fields of each connector type and version
+var secretPathsCache sync.Map
+
+// specLookups holds the keys of specs being fetched in the background
+var specLookups sync.Map
+
+// secretPaths returns the secret fields of a source (destType empty) or destination connector.
+// Masking runs inside list and get requests, so it only reads specs from the spec cache. On a
+// cache miss, every field is treated as a secret and the spec is fetched in the background, so
+// later responses mask by spec.
+func (s *ETLService) secretPaths(destType, sourceType, version string) utils.SecretPaths {
+	key := fmt.Sprintf("%s|%s|%s", destType, sourceType, version)
+	if cached, ok := secretPathsCache.Load(key); ok {
+		entry := cached.(*secretPathsEntry)
+		if entry.available || time.Since(entry.checkedAt) < specLookupRetryAfter {
+			return entry.paths
+		}
+	}
+
+	entry := &secretPathsEntry{paths: utils.AllSecretPaths(), checkedAt: time.Now()}
+	if spec, ok := s.storedDriverSpec(destType, sourceType, version); ok {
+		entry.paths = utils.SecretPathsFromSpec(spec)
+		entry.available = true
+	} else {
+		s.lookupSpecInBackground(key, destType, sourceType, version)
+	}
+	secretPathsCache.Store(key, entry)
+	return entry.paths
+}
+
+// lookupSpecInBackground fetches a spec into the spec cache, at most once at a time per key
+func (s *ETLService) lookupSpecInBackground(key, destType, sourceType, version string) {
+	if _, running := specLookups.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer specLookups.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.Background(), specLookupTimeout)
+		defer cancel()
+
+		spec, _, err := s.cachedDriverSpec(ctx, destType, sourceType, version, false)
+		if err != nil {
+			logger.Warnf("failed to get spec to find secret fields dest_type[%s] source_type[%s] version[%s], masking every field: %s", destType, sourceType, version, err)
+			return
+		}
+		secretPathsCache.Store(key, &secretPathsEntry{paths: utils.SecretPathsFromSpec(spec), checkedAt: time.Now(), available: true})
+	}()
+}
+
+// sourceSecretPaths returns the secret fields of a source connector
+func (s *ETLService) sourceSecretPaths(sourceType, version string) utils.SecretPaths {
+	return s.secretPaths("", sourceType, version)
+}
+
+// destinationSecretPaths returns the secret fields of a destination connector
+func (s *ETLService) destinationSecretPaths(ctx context.Context, destType, version string) utils.SecretPaths {
+	driver, err := s.driverType(ctx)
+	if err != nil {
+		logger.Warnf("failed to get driver connector to find secret fields dest_type[%s], masking every field: %s", destType, err)
+		return utils.AllSecretPaths()
+	}
+	return s.secretPaths(destType, driver, version)
+}
+
+// MaskSourceConfig masks the secrets of a source config for a response
+func (s *ETLService) MaskSourceConfig(ctx context.Context, sourceType, version, config string) (string, error) {
+	masked, err := utils.MaskSecrets(config, s.sourceSecretPaths(sourceType, version))
+	if err != nil {
+		return "", fmt.Errorf("failed to mask source config: %s", err)
+	}
+	return masked, nil
+}
+
+// MaskDestinationConfig masks the secrets of a destination config for a response
+func (s *ETLService) MaskDestinationConfig(ctx context.Context, destType, version, config string) (string, error) {
+	masked, err := utils.MaskSecrets(config, s.destinationSecretPaths(ctx, destType, version))
+	if err != nil {
+		return "", fmt.Errorf("failed to mask destination config: %s", err)
+	}
+	return masked, nil
+}
+
+// encryptSecretFields encrypts the secret fields of a config on their own before it is saved,
+// when the worker decrypts them. Saving runs the spec command if the spec is not cached, and
+// every string field is encrypted if the spec is not available.
+func (s *ETLService) encryptSecretFields(ctx context.Context, destType, sourceType, version, config string) (string, error) {
+	if !temporal.WorkerSupports(temporal.WorkerFeatureSecretFields) {
+		return config, nil
+	}
+
+	paths := utils.AllSecretPaths()
+	if spec, _, err := s.cachedDriverSpec(ctx, destType, sourceType, version, false); err == nil {
+		paths = utils.SecretPathsFromSpec(spec)
+	} else {
+		logger.Warnf("failed to get spec to find secret fields dest_type[%s] source_type[%s] version[%s], encrypting every field: %s", destType, sourceType, version, err)
+	}
+
+	encrypted, err := utils.EncryptSecretFields(config, paths)
+	if err != nil {
+		return "", err
+	}
+	return encrypted, nil
+}
+
+// encryptSourceSecretFields encrypts the secret fields of a source config before it is saved
+func (s *ETLService) encryptSourceSecretFields(ctx context.Context, sourceType, version, config string) (string, error) {
+	return s.encryptSecretFields(ctx, "", sourceType, version, config)
+}
+
+// encryptDestinationSecretFields encrypts the secret fields of a destination config before it is saved
+func (s *ETLService) encryptDestinationSecretFields(ctx context.Context, destType, version, config string) (string, error) {
+	if !temporal.WorkerSupports(temporal.WorkerFeatureSecretFields) {
+		return config, nil
+	}
+	driver, err := s.driverType(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get driver connector to find secret fields: %s", err)
+	}
+	return s.encryptSecretFields(ctx, destType, driver, version, config)
+}
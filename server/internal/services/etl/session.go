@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Session-related methods on AppService
+//
+// Every login session is tracked next to the session data, so sessions can be listed, revoked
+// and expired independently of the session cookie.
+
+// sessionTouchInterval is how often the last activity of a session is written
+const sessionTouchInterval = time.Minute
+
+// maxUserAgentLength is the size of the user agent column
+const maxUserAgentLength = 512
+
+func sessionTimeouts() (idle, absolute time.Duration) {
+	idle = time.Duration(web.AppConfig.DefaultInt(constants.ConfSessionIdleTimeout, constants.DefaultSessionIdleTimeout)) * time.Minute
+	absolute = time.Duration(web.AppConfig.DefaultInt(constants.ConfSessionAbsoluteTimeout, constants.DefaultSessionAbsoluteTimeout)) * time.Minute
+	return idle, absolute
+}
+
+// StartUserSession starts tracking the session a user just logged in with
+func (s *ETLService) StartUserSession(_ context.Context, sessionKey string, userID int, ipAddress, userAgent string) error {
+	// drop sessions of the user that expired since they were last used
+	if err := s.revokeSessions(userID, func(session *models.UserSession) bool {
+		return s.sessionExpired(session, time.Now().UTC())
+	}); err != nil {
+		logger.Warnf("failed to remove expired sessions user_id[%d]: %s", userID, err)
+	}
+
+	for len(userAgent) > maxUserAgentLength {
+		_, size := utf8.DecodeLastRuneInString(userAgent)
+		userAgent = userAgent[:len(userAgent)-size]
+	}
+
+	_, absolute := sessionTimeouts()
+	now := time.Now().UTC()
+	session := &models.UserSession{
+		SessionKey: sessionKey,
+		UserID:     userID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(absolute),
+	}
+	if err := s.db.CreateUserSession(session); err != nil {
+		return fmt.Errorf("failed to create session: %s", err)
+	}
+	return nil
+}
+
+// ValidateSession checks that a session of a user is still active and records the activity.
+// An expired session is removed.
+func (s *ETLService) ValidateSession(_ context.Context, sessionKey string, userID int) error {
+	session, err := s.db.GetUserSessionByKey(sessionKey)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return constants.ErrSessionExpired
+		}
+		return fmt.Errorf("failed to get session: %s", err)
+	}
+	if session.UserID != userID {
+		return constants.ErrSessionExpired
+	}
+
+	now := time.Now().UTC()
+	if s.sessionExpired(session, now) {
+		if err := s.db.DeleteUserSessions([]*models.UserSession{session}); err != nil {
+			logger.Warnf("failed to remove expired session id[%d]: %s", session.ID, err)
+		}
+		return constants.ErrSessionExpired
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := s.db.TouchUserSession(session.ID, now); err != nil {
+			logger.Warnf("failed to update session activity id[%d]: %s", session.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ETLService) sessionExpired(session *models.UserSession, now time.Time) bool {
+	if !now.Before(session.ExpiresAt) {
+		return true
+	}
+	idle, _ := sessionTimeouts()
+	return idle > 0 && now.Sub(session.LastSeenAt) > idle
+}
+
+// EndUserSession stops tracking a session on logout
+func (s *ETLService) EndUserSession(_ context.Context, sessionKey string) error {
+	session, err := s.db.GetUserSessionByKey(sessionKey)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get session: %s", err)
+	}
+	if err := s.db.DeleteUserSessions([]*models.UserSession{session}); err != nil {
+		return fmt.Errorf("failed to end session: %s", err)
+	}
+	return nil
+}
+
+// ListUserSessions returns the active sessions of a user, marking the one of currentKey
+func (s *ETLService) ListUserSessions(_ context.Context, userID int, currentKey string) ([]dto.UserSessionResponse, error) {
+	now := time.Now().UTC()
+	sessions, err := s.db.ListUserSessions(userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %s", err)
+	}
+
+	response := make([]dto.UserSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		if s.sessionExpired(session, now) {
+			continue
+		}
+		response = append(response, dto.UserSessionResponse{
+			ID:         session.ID,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastSeenAt: session.LastSeenAt.Format(time.RFC3339),
+			ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+			Current:    session.SessionKey == currentKey,
+		})
+	}
+	return response, nil
+}
+
+// RevokeUserSession ends one session of a user
+func (s *ETLService) RevokeUserSession(_ context.Context, userID, sessionID int) error {
+	sessions, err := s.db.ListAllUserSessions(userID)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %s", err)
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			if err := s.db.DeleteUserSessions([]*models.UserSession{session}); err != nil {
+				return fmt.Errorf("failed to revoke session: %s", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: id[%d]", constants.ErrSessionNotFound, sessionID)
+}
+
+// RevokeUserSessions ends every session of a user except the one of exceptKey, if set
+func (s *ETLService) RevokeUserSessions(_ context.Context, userID int, exceptKey string) error {
+	if err := s.revokeSessions(userID, func(session *models.UserSession) bool {
+		return exceptKey == "" || session.SessionKey != exceptKey
+	}); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %s", err)
+	}
+	return nil
+}
+
+func (s *ETLService) revokeSessions(userID int, match func(session *models.UserSession) bool) error {
+	sessions, err := s.db.ListAllUserSessions(userID)
+	if err != nil {
+		return err
+	}
+	var revoke []*models.UserSession
+	for _, session := range sessions {
+		if match(session) {
+			revoke = append(revoke, session)
+		}
+	}
+	return s.db.DeleteUserSessions(revoke)
+}
@@ -3,13 +3,13 @@ package services
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"time"
 
-	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
 	"github.com/datazip-inc/olake-ui/server/utils/telemetry"
 )
 
@@ -40,27 +40,41 @@ func (s *ETLService) GetSource(ctx context.Context, projectID string, sourceID i
 		return nil, fmt.Errorf("failed to build job data items: %s", err)
 	}
 
+	config, err := s.MaskSourceConfig(ctx, source.Type, source.Version, source.Config)
+	if err != nil {
+		return nil, err
+	}
+
 	item := &dto.SourceDataItem{
-		ID:        source.ID,
-		Name:      source.Name,
-		Type:      source.Type,
-		Version:   source.Version,
-		Config:    source.Config,
-		CreatedAt: source.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: source.UpdatedAt.Format(time.RFC3339),
-		Jobs:      jobItems,
+		ID:                 source.ID,
+		Name:               source.Name,
+		Type:               source.Type,
+		Version:            source.Version,
+		Config:             config,
+		Labels:             decodeLabels(source.Labels),
+		CreatedAt:          source.CreatedAt.Format(time.RFC3339),
+		MaxConcurrentSyncs: source.MaxConcurrentSyncs,
+		UpdatedAt:          source.UpdatedAt.Format(time.RFC3339),
+		Jobs:               jobItems,
 	}
 	setUsernames(&item.CreatedBy, &item.UpdatedBy, source.CreatedBy, source.UpdatedBy)
 
 	return item, nil
 }
 
-// GetAllSources returns all sources for a project with lightweight job summaries.
-func (s *ETLService) ListSources(ctx context.Context, projectID string) ([]dto.SourceDataItem, error) {
-	sources, err := s.db.ListSourcesByProjectID(projectID)
+// GetAllSources returns all sources for a project with lightweight job summaries, only the
+// sources carrying every label of selector when it is set.
+func (s *ETLService) ListSources(ctx context.Context, projectID string, selector map[string]string) ([]dto.SourceDataItem, error) {
+	projectSources, err := s.db.ListSourcesByProjectID(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sources: %s", err)
 	}
+	sources := make([]*models.Source, 0, len(projectSources))
+	for _, src := range projectSources {
+		if labelsMatch(decodeLabels(src.Labels), selector) {
+			sources = append(sources, src)
+		}
+	}
 
 	sourceIDs := make([]int, 0, len(sources))
 	for _, src := range sources {
@@ -88,14 +102,21 @@ func (s *ETLService) ListSources(ctx context.Context, projectID string) ([]dto.S
 
 	items := make([]dto.SourceDataItem, 0, len(sources))
 	for _, src := range sources {
+		config, err := s.MaskSourceConfig(ctx, src.Type, src.Version, src.Config)
+		if err != nil {
+			return nil, err
+		}
+
 		item := dto.SourceDataItem{
-			ID:        src.ID,
-			Name:      src.Name,
-			Type:      src.Type,
-			Version:   src.Version,
-			Config:    src.Config,
-			CreatedAt: src.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: src.UpdatedAt.Format(time.RFC3339),
+			ID:                 src.ID,
+			Name:               src.Name,
+			Type:               src.Type,
+			Version:            src.Version,
+			Config:             config,
+			Labels:             decodeLabels(src.Labels),
+			CreatedAt:          src.CreatedAt.Format(time.RFC3339),
+			MaxConcurrentSyncs: src.MaxConcurrentSyncs,
+			UpdatedAt:          src.UpdatedAt.Format(time.RFC3339),
 		}
 		setUsernames(&item.CreatedBy, &item.UpdatedBy, src.CreatedBy, src.UpdatedBy)
 
@@ -121,12 +142,31 @@ func (s *ETLService) CreateSource(ctx context.Context, req *dto.CreateSourceRequ
 		return fmt.Errorf("source name '%s' is not unique", req.Name)
 	}
 
+	if err := secretref.Validate(req.Config); err != nil {
+		return fmt.Errorf("invalid source config: %s", err)
+	}
+	if err := s.checkConnectorVersion(req.Type, req.Version); err != nil {
+		return err
+	}
+	if err := validateLabels(req.Labels); err != nil {
+		return err
+	}
+	if err := checkSyncCapSupported(0, req.MaxConcurrentSyncs); err != nil {
+		return err
+	}
+	config, err := s.encryptSourceSecretFields(ctx, req.Type, req.Version, req.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret fields: %s", err)
+	}
+
 	src := &models.Source{
-		Name:      req.Name,
-		Type:      req.Type,
-		Version:   req.Version,
-		Config:    req.Config,
-		ProjectID: projectID,
+		Name:               req.Name,
+		Type:               req.Type,
+		Version:            req.Version,
+		Config:             config,
+		Labels:             encodeLabels(req.Labels),
+		ProjectID:          projectID,
+		MaxConcurrentSyncs: req.MaxConcurrentSyncs,
 	}
 
 	user := &models.User{ID: *userID}
@@ -147,8 +187,35 @@ func (s *ETLService) UpdateSource(ctx context.Context, projectID string, id int,
 		return fmt.Errorf("failed to get source: %s", err)
 	}
 
+	// secrets the client left masked keep their stored value
+	config, err := utils.RestoreMaskedSecrets(req.Config, existing.Config)
+	if err != nil {
+		return fmt.Errorf("failed to restore masked secrets: %s", err)
+	}
+	if err := secretref.Validate(config); err != nil {
+		return fmt.Errorf("invalid source config: %s", err)
+	}
+	if err := s.checkConnectorVersion(req.Type, req.Version); err != nil {
+		return err
+	}
+	if req.Labels != nil {
+		if err := validateLabels(req.Labels); err != nil {
+			return err
+		}
+		existing.Labels = encodeLabels(req.Labels)
+	}
+	if req.MaxConcurrentSyncs != nil {
+		if err := checkSyncCapSupported(existing.MaxConcurrentSyncs, *req.MaxConcurrentSyncs); err != nil {
+			return err
+		}
+		existing.MaxConcurrentSyncs = *req.MaxConcurrentSyncs
+	}
+	if config, err = s.encryptSourceSecretFields(ctx, req.Type, req.Version, config); err != nil {
+		return fmt.Errorf("failed to encrypt secret fields: %s", err)
+	}
+
 	existing.Name = req.Name
-	existing.Config = req.Config
+	existing.Config = config
 	existing.Type = req.Type
 	existing.Version = req.Version
 
@@ -159,6 +226,11 @@ func (s *ETLService) UpdateSource(ctx context.Context, projectID string, id int,
 	if err != nil {
 		return fmt.Errorf("failed to fetch jobs for source update: %s", err)
 	}
+	if len(jobs) > 0 {
+		if err := checkSecretRefsSupported(config); err != nil {
+			return err
+		}
+	}
 
 	if err := cancelAllJobWorkflows(ctx, s.temporal, jobs, projectID); err != nil {
 		return fmt.Errorf("failed to cancel workflows for source update: %s", err)
@@ -167,6 +239,9 @@ func (s *ETLService) UpdateSource(ctx context.Context, projectID string, id int,
 	if err := s.db.UpdateSource(existing); err != nil {
 		return fmt.Errorf("failed to update source: %s", err)
 	}
+	if _, err := s.db.DeleteCachedSourceCatalogs(existing.ID); err != nil {
+		logger.Warnf("failed to invalidate catalog cache for source update: %s", err)
+	}
 
 	telemetry.TrackSourcesStatus(ctx)
 	return nil
@@ -197,6 +272,9 @@ func (s *ETLService) DeleteSource(ctx context.Context, id int) (*dto.DeleteSourc
 	if err := s.db.DeleteSource(id); err != nil {
 		return nil, fmt.Errorf("failed to delete source: %s", err)
 	}
+	if _, err := s.db.DeleteCachedSourceCatalogs(id); err != nil {
+		logger.Warnf("failed to invalidate catalog cache for source deletion: %s", err)
+	}
 
 	telemetry.TrackSourcesStatus(ctx)
 	return &dto.DeleteSourceResponse{Name: src.Name}, nil
@@ -207,7 +285,18 @@ func (s *ETLService) TestSourceConnection(ctx context.Context, req *dto.SourceTe
 		return nil, nil, fmt.Errorf("temporal client not available")
 	}
 
-	encryptedConfig, err := utils.Encrypt(req.Config)
+	config := req.Config
+	if req.SourceID != nil {
+		source, err := s.db.GetSourceByID(*req.SourceID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get source for test connection: %s", err)
+		}
+		if config, err = utils.RestoreMaskedSecrets(config, source.Config); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore masked secrets: %s", err)
+		}
+	}
+
+	encryptedConfig, err := utils.EncryptForConnector(config)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to encrypt config for test connection: %s", err)
 	}
@@ -224,10 +313,8 @@ func (s *ETLService) TestSourceConnection(ctx context.Context, req *dto.SourceTe
 	if err != nil {
 		return result, nil, fmt.Errorf("connection test failed: %s", err)
 	}
-	homeDir := constants.DefaultConfigDir
-	mainLogDir := filepath.Join(homeDir, workflowID)
 	// Fetch the latest batch of logs by tailing from the end with default limit in the "older" direction.
-	logs, err := utils.ReadLogs(mainLogDir, -1, -1, "older")
+	logs, err := utils.ReadLogs(ctx, workflowID, -1, -1, "older")
 	if err != nil {
 		return result, nil, fmt.Errorf("failed to read logs source_type[%s] source_version[%s]: %s",
 			req.Type, req.Version, err)
@@ -238,15 +325,46 @@ func (s *ETLService) TestSourceConnection(ctx context.Context, req *dto.SourceTe
 
 func (s *ETLService) GetSourceCatalog(ctx context.Context, req *dto.StreamsRequest) (map[string]interface{}, error) {
 	oldStreams := ""
+	storedConfig := ""
+	sourceID := 0
 	if req.JobID >= 0 {
 		job, err := s.db.GetJobByID(req.JobID, true)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find job for catalog: %s", err)
 		}
 		oldStreams = job.StreamsConfig
+		if job.SourceID != nil {
+			storedConfig = job.SourceID.Config
+			sourceID = job.SourceID.ID
+		}
+	}
+	if req.SourceID != nil {
+		source, err := s.db.GetSourceByID(*req.SourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source for catalog: %s", err)
+		}
+		storedConfig = source.Config
+		sourceID = source.ID
 	}
 
-	encryptedConfig, err := utils.Encrypt(req.Config)
+	config, err := utils.RestoreMaskedSecrets(req.Config, storedConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore masked secrets: %s", err)
+	}
+
+	// only catalogs of saved sources are cached, so they can be invalidated with the source
+	ttl := catalogCacheTTL()
+	fingerprint := ""
+	if ttl > 0 && sourceID > 0 {
+		fingerprint = discoverFingerprint(req.Type, req.Version, config, oldStreams, req.JobName)
+		if !req.Refresh {
+			if catalog, ok := s.cachedSourceCatalog(req.Type, req.Version, fingerprint); ok {
+				return catalog, nil
+			}
+		}
+	}
+
+	encryptedConfig, err := utils.EncryptForConnector(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt config for catalog: %s", err)
 	}
@@ -263,6 +381,9 @@ func (s *ETLService) GetSourceCatalog(ctx context.Context, req *dto.StreamsReque
 		return nil, fmt.Errorf("failed to get catalog: %s", err)
 	}
 
+	if fingerprint != "" {
+		s.cacheSourceCatalog(sourceID, req.Type, req.Version, fingerprint, newStreams, ttl)
+	}
 	return newStreams, nil
 }
 
@@ -280,18 +401,20 @@ func (s *ETLService) GetSourceJobs(_ context.Context, id int) ([]*models.Job, er
 }
 
 func (s *ETLService) GetSourceVersions(ctx context.Context, sourceType string) (map[string]interface{}, error) {
-	imageName := fmt.Sprintf("olakego/source-%s", sourceType)
-	versions, _, err := utils.GetDriverImageTags(ctx, imageName, true)
+	connector, err := s.db.GetConnector(sourceType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get Docker versions: %s", err)
+		return nil, fmt.Errorf("failed to get connector: %s", err)
 	}
 
-	return map[string]interface{}{"version": versions}, nil
+	versions, err := s.connectorVersionList(ctx, connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connector versions: %s", err)
+	}
+	return versions, nil
 }
 
-// TODO: cache spec in db for each version
 func (s *ETLService) GetSourceSpec(ctx context.Context, req *dto.SpecRequest) (dto.SpecResponse, error) {
-	specOut, err := s.temporal.GetDriverSpecs(ctx, "", req.Type, req.Version)
+	spec, cached, err := s.cachedDriverSpec(ctx, "", req.Type, req.Version, req.Refresh)
 	if err != nil {
 		return dto.SpecResponse{}, fmt.Errorf("failed to get spec: %s", err)
 	}
@@ -299,6 +422,7 @@ func (s *ETLService) GetSourceSpec(ctx context.Context, req *dto.SpecRequest) (d
 	return dto.SpecResponse{
 		Version: req.Version,
 		Type:    req.Type,
-		Spec:    specOut.Spec,
+		Spec:    spec,
+		Cached:  cached,
 	}, nil
 }
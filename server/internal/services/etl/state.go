@@ -0,0 +1,593 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// Job state methods on AppService
+//
+// The state written by the connectors looks like
+//
+//	{"type": "STREAM|GLOBAL|MIXED",
+//	 "global": {"state": {...}, "streams": ["<namespace>.<stream>"]},
+//	 "streams": [{"stream": "...", "namespace": "...", "sync_mode": "...", "state": {...}}]}
+//
+// Streams listed in global.streams have finished their backfill and continue from the
+// shared (CDC) position, entries of streams hold per stream cursors. Keys the server does
+// not know about are kept untouched.
+
+const (
+	JobStateActionEdit    = "edit"
+	JobStateActionReset   = "reset"
+	JobStateActionSync    = "sync"
+	JobStateActionRestore = "restore"
+)
+
+var supportedStateTypes = []string{"STREAM", "GLOBAL", "MIXED"}
+
+func (s *ETLService) GetJobState(_ context.Context, jobID int) (*dto.JobStateResponse, error) {
+	job, err := s.db.GetJobByID(jobID, false)
+	if err != nil {
+		return nil, fmt.Errorf("job not found: %s", err)
+	}
+
+	state, err := parseJobState(job.State)
+	if err != nil {
+		return nil, fmt.Errorf("stored state of job_id[%d] is invalid: %s", jobID, err)
+	}
+
+	version, err := s.db.GetLatestJobStateVersionNumber(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildJobStateResponse(jobID, version, state)
+}
+
+// UpdateJobState replaces the state of a job with an edited one
+func (s *ETLService) UpdateJobState(ctx context.Context, projectID string, jobID int, req *dto.UpdateJobStateRequest, userID *int) (*dto.JobStateResponse, error) {
+	state, err := parseJobState(req.State)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state: %s", err)
+	}
+
+	if _, err := s.db.GetJobByID(jobID, false); err != nil {
+		return nil, fmt.Errorf("job not found: %s", err)
+	}
+
+	if err := ensureNoSyncRunning(ctx, s.temporal, projectID, jobID); err != nil {
+		return nil, err
+	}
+
+	return s.saveJobState(jobID, state, &models.JobStateVersion{Action: JobStateActionEdit}, userID)
+}
+
+// ResetJobStreamsState drops the state of the given streams only, so the next sync
+// re-syncs them from scratch while every other stream continues where it left off
+func (s *ETLService) ResetJobStreamsState(ctx context.Context, projectID string, jobID int, req *dto.ResetJobStateRequest, userID *int) (*dto.JobStateResponse, error) {
+	job, err := s.db.GetJobByID(jobID, false)
+	if err != nil {
+		return nil, fmt.Errorf("job not found: %s", err)
+	}
+
+	state, err := parseJobState(job.State)
+	if err != nil {
+		return nil, fmt.Errorf("stored state of job_id[%d] is invalid: %s", jobID, err)
+	}
+
+	if err := resetStreamsState(state, req.Streams); err != nil {
+		return nil, err
+	}
+
+	if err := ensureNoSyncRunning(ctx, s.temporal, projectID, jobID); err != nil {
+		return nil, err
+	}
+
+	return s.saveJobState(jobID, state, &models.JobStateVersion{
+		Action:  JobStateActionReset,
+		Streams: strings.Join(req.Streams, ","),
+	}, userID)
+}
+
+// RestoreJobState moves a job back to a past state version. Like clear-destination, the
+// schedule is paused and running syncs are stopped first so no run overwrites the
+// restored state, the schedule is resumed afterwards if the job is active.
+func (s *ETLService) RestoreJobState(ctx context.Context, projectID string, jobID int, req *dto.RestoreJobStateRequest, userID *int) (*dto.JobStateResponse, error) {
+	job, err := s.db.GetJobByID(jobID, false)
+	if err != nil {
+		return nil, fmt.Errorf("job not found: %s", err)
+	}
+
+	var snapshot *models.JobStateVersion
+	if req.Version > 0 {
+		snapshot, err = s.db.GetJobStateVersion(jobID, req.Version)
+	} else {
+		snapshot, err = s.db.GetJobStateVersionByWorkflowID(jobID, req.WorkflowID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := parseJobState(snapshot.State)
+	if err != nil {
+		return nil, fmt.Errorf("stored state of job_id[%d] version[%d] is invalid: %s", jobID, snapshot.Version, err)
+	}
+
+	running, _, err := isWorkflowRunning(ctx, s.temporal, projectID, jobID, temporal.ClearDestination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check clear-destination status: %s", err)
+	}
+	if running {
+		return nil, fmt.Errorf("clear-destination is in progress, please wait for it to finish before restoring the state")
+	}
+
+	if err := s.temporal.PauseSchedule(ctx, projectID, jobID); err != nil {
+		return nil, fmt.Errorf("failed to pause schedule: %s", err)
+	}
+	logger.Infof("paused schedule of job_id[%d] to restore state version[%d]", jobID, snapshot.Version)
+
+	response, err := s.restoreJobState(ctx, job, state, snapshot, userID)
+	if job.Active {
+		if rerr := s.temporal.ResumeSchedule(ctx, projectID, jobID); rerr != nil {
+			if err != nil {
+				return nil, fmt.Errorf("restore error: %s, resume error: %s", err, rerr)
+			}
+			return nil, fmt.Errorf("state restored but failed to resume schedule: %s", rerr)
+		}
+	}
+	return response, err
+}
+
+// restoreJobState cancels running syncs of a paused job and saves the snapshot state as a new version
+func (s *ETLService) restoreJobState(ctx context.Context, job *models.Job, state map[string]interface{}, snapshot *models.JobStateVersion, userID *int) (*dto.JobStateResponse, error) {
+	if err := cancelAllJobWorkflows(ctx, s.temporal, []*models.Job{job}, job.ProjectID); err != nil {
+		return nil, fmt.Errorf("failed to cancel running syncs: %s", err)
+	}
+
+	if err := waitForSyncToStop(ctx, s.temporal, job.ProjectID, job.ID, constants.DefaultCancelSyncWaitTime); err != nil {
+		return nil, fmt.Errorf("failed to wait for sync to stop: %s", err)
+	}
+
+	return s.saveJobState(job.ID, state, &models.JobStateVersion{
+		Action:       JobStateActionRestore,
+		Streams:      snapshot.Streams,
+		RestoredFrom: snapshot.Version,
+	}, userID)
+}
+
+// UpdateStateFile records the state pushed by the worker after a run as a snapshot
+// tagged with the run, falling back to the running sync when the worker does not send it.
+// Repeated pushes of a run replace its snapshot, see AddJobStateVersion.
+// The state of a manual sync is merged into the job's state, see manualSyncState.
+func (s *ETLService) UpdateStateFile(ctx context.Context, projectID string, jobID int, req *dto.UpdateStateFileRequest) error {
+	if _, err := s.db.GetJobByID(jobID, false); err != nil {
+		return fmt.Errorf("job not found: %s", err)
+	}
+
+	workflowID := req.WorkflowID
+	if workflowID == "" {
+		running, executions, err := isWorkflowRunning(ctx, s.temporal, projectID, jobID, temporal.Sync)
+		if err != nil {
+			logger.Warnf("failed to resolve sync run of state update job_id[%d]: %s", jobID, err)
+		} else if running {
+			workflowID = executions[0].Execution.WorkflowId
+		}
+	}
+
+	stateFile := req.StateFile
+	if temporal.IsManualSyncWorkflowID(workflowID) {
+		merged, err := s.manualSyncState(ctx, jobID, workflowID, req.StateFile)
+		if err != nil {
+			return fmt.Errorf("failed to merge state of manual sync: %s", err)
+		}
+		stateFile = merged
+	}
+
+	version := &models.JobStateVersion{
+		Action:     JobStateActionSync,
+		State:      stateFile,
+		WorkflowID: workflowID,
+		RunStatus:  req.Status,
+	}
+	if err := s.db.AddJobStateVersion(jobID, version, stateHistoryLimit()); err != nil {
+		return fmt.Errorf("failed to update job: %s", err)
+	}
+
+	logger.Infof("state file updated successfully for job_id[%d] version[%d] workflow_id[%s] with state: %s", jobID, version.Version, workflowID, stateFile)
+	return nil
+}
+
+func (s *ETLService) ListJobStateVersions(ctx context.Context, jobID int) ([]dto.JobStateVersionResponse, error) {
+	if _, err := s.db.GetJobByID(jobID, false); err != nil {
+		return nil, fmt.Errorf("job not found: %s", err)
+	}
+
+	versions, err := s.db.ListJobStateVersions(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resolveRunStatuses(ctx, versions); err != nil {
+		logger.Warnf("failed to resolve run status of state versions job_id[%d]: %s", jobID, err)
+	}
+
+	response := make([]dto.JobStateVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		response = append(response, buildJobStateVersionResponse(version))
+	}
+	return response, nil
+}
+
+func (s *ETLService) GetJobStateVersion(_ context.Context, jobID, version int) (*dto.JobStateVersionResponse, error) {
+	stateVersion, err := s.db.GetJobStateVersion(jobID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := parseJobState(stateVersion.State)
+	if err != nil {
+		return nil, fmt.Errorf("stored state of job_id[%d] version[%d] is invalid: %s", jobID, version, err)
+	}
+
+	response := buildJobStateVersionResponse(stateVersion)
+	if response.State, err = prettyJSON(state); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// saveJobState stores the state as the next version of the job state and returns the new state
+func (s *ETLService) saveJobState(jobID int, state map[string]interface{}, version *models.JobStateVersion, userID *int) (*dto.JobStateResponse, error) {
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %s", err)
+	}
+
+	version.State = string(stateBytes)
+	if userID != nil {
+		version.CreatedBy = &models.User{ID: *userID}
+	}
+
+	if err := s.db.AddJobStateVersion(jobID, version, stateHistoryLimit()); err != nil {
+		return nil, fmt.Errorf("failed to save state: %s", err)
+	}
+	logger.Infof("state of job_id[%d] moved to version[%d] action[%s] streams[%s]", jobID, version.Version, version.Action, version.Streams)
+
+	return buildJobStateResponse(jobID, version.Version, state)
+}
+
+// resolveRunStatuses fills in the outcome of sync snapshots whose run status was not
+// sent by the worker, finished runs are saved so they are looked up only once
+func (s *ETLService) resolveRunStatuses(ctx context.Context, versions []*models.JobStateVersion) error {
+	var workflowIDs []string
+	for _, version := range versions {
+		if version.WorkflowID != "" && version.RunStatus == "" {
+			workflowIDs = append(workflowIDs, quoteQueryValue(version.WorkflowID))
+		}
+	}
+	if len(workflowIDs) == 0 {
+		return nil
+	}
+
+	resp, err := s.temporal.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Query:    fmt.Sprintf("WorkflowId IN (%s)", strings.Join(workflowIDs, ", ")),
+		PageSize: int32(len(workflowIDs)),
+	})
+	if err != nil {
+		return err
+	}
+
+	statuses := make(map[string]enumspb.WorkflowExecutionStatus, len(resp.Executions))
+	for _, execution := range resp.Executions {
+		statuses[execution.Execution.WorkflowId] = execution.Status
+	}
+
+	for _, version := range versions {
+		status, ok := statuses[version.WorkflowID]
+		if version.RunStatus != "" || !ok {
+			continue
+		}
+		version.RunStatus = status.String()
+		if status == enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+			continue
+		}
+		if err := s.db.UpdateJobStateVersionRunStatus(version.ID, version.RunStatus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stateHistoryLimit returns the number of state versions kept per job
+func stateHistoryLimit() int {
+	return web.AppConfig.DefaultInt(constants.ConfStateHistoryLimit, constants.DefaultStateHistoryLimit)
+}
+
+// ensureNoSyncRunning rejects state changes while a run could still overwrite them
+func ensureNoSyncRunning(ctx context.Context, tempClient *temporal.Temporal, projectID string, jobID int) error {
+	for _, opType := range []temporal.Command{temporal.Sync, temporal.ClearDestination} {
+		running, _, err := isWorkflowRunning(ctx, tempClient, projectID, jobID, opType)
+		if err != nil {
+			return fmt.Errorf("failed to check %s status: %s", opType, err)
+		}
+		if running {
+			return fmt.Errorf("%s is in progress, please wait or cancel it before changing the state", opType)
+		}
+	}
+	return nil
+}
+
+// parseJobState validates a state document. Numbers are kept as json.Number so
+// offsets (LSNs, binlog positions, resume tokens) survive a round trip unchanged.
+func parseJobState(raw string) (map[string]interface{}, error) {
+	state := map[string]interface{}{}
+	if strings.TrimSpace(raw) == "" || strings.TrimSpace(raw) == "null" {
+		return state, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&state); err != nil {
+		return nil, fmt.Errorf("state must be a JSON object: %s", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("state must contain a single JSON object")
+	}
+
+	if stateType, ok := state["type"]; ok && stateType != nil {
+		typeStr, ok := stateType.(string)
+		if !ok || !slices.Contains(supportedStateTypes, typeStr) {
+			return nil, fmt.Errorf("invalid state type '%v', supported types are: %s", stateType, strings.Join(supportedStateTypes, ", "))
+		}
+	}
+
+	if global, ok := state["global"]; ok && global != nil {
+		globalMap, ok := global.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("global must be an object")
+		}
+		if _, err := globalStreamIDs(globalMap); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := streamStateEntries(state)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		id := streamStateID(entry)
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("duplicate state for stream %s", id)
+		}
+		seen[id] = struct{}{}
+	}
+
+	return state, nil
+}
+
+// streamStateEntries returns the per stream entries of a state
+func streamStateEntries(state map[string]interface{}) ([]map[string]interface{}, error) {
+	raw, ok := state["streams"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("streams must be an array")
+	}
+
+	entries := make([]map[string]interface{}, 0, len(list))
+	for idx, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("streams[%d] must be an object", idx)
+		}
+		if name, _ := entry["stream"].(string); name == "" {
+			return nil, fmt.Errorf("streams[%d] is missing the stream name", idx)
+		}
+		if namespace, ok := entry["namespace"]; ok && namespace != nil {
+			if _, ok := namespace.(string); !ok {
+				return nil, fmt.Errorf("streams[%d] namespace must be a string", idx)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// globalStreamIDs returns the ids of the streams tracked by the global state
+func globalStreamIDs(global map[string]interface{}) ([]string, error) {
+	raw, ok := global["streams"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("global.streams must be an array")
+	}
+
+	ids := make([]string, 0, len(list))
+	for idx, item := range list {
+		id, ok := item.(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("global.streams[%d] must be a stream id", idx)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// streamStateID returns the "<namespace>.<stream>" id of a stream state entry
+func streamStateID(entry map[string]interface{}) string {
+	name, _ := entry["stream"].(string)
+	namespace, _ := entry["namespace"].(string)
+	if namespace == "" {
+		return name
+	}
+	return fmt.Sprintf("%s.%s", namespace, name)
+}
+
+// resetStreamsState removes the given streams from the per stream entries and from the
+// global state, every stream must be present in the state
+func resetStreamsState(state map[string]interface{}, streams []string) error {
+	entries, err := streamStateEntries(state)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(streams))
+	if len(entries) > 0 {
+		kept := make([]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			id := streamStateID(entry)
+			if slices.Contains(streams, id) {
+				found[id] = true
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		state["streams"] = kept
+	}
+
+	if global, ok := state["global"].(map[string]interface{}); ok {
+		ids, err := globalStreamIDs(global)
+		if err != nil {
+			return err
+		}
+		if ids != nil {
+			kept := make([]interface{}, 0, len(ids))
+			for _, id := range ids {
+				if slices.Contains(streams, id) {
+					found[id] = true
+					continue
+				}
+				kept = append(kept, id)
+			}
+			global["streams"] = kept
+		}
+	}
+
+	var missing []string
+	for _, stream := range streams {
+		if !found[stream] {
+			missing = append(missing, stream)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no state found for streams: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func buildJobStateResponse(jobID, version int, state map[string]interface{}) (*dto.JobStateResponse, error) {
+	pretty, err := prettyJSON(state)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.JobStateResponse{
+		JobID:   jobID,
+		Version: version,
+		Streams: []dto.StreamStateResponse{},
+		State:   pretty,
+	}
+	response.Type, _ = state["type"].(string)
+
+	listed := make(map[string]bool)
+	var globalIDs []string
+	if global, ok := state["global"].(map[string]interface{}); ok {
+		globalIDs, _ = globalStreamIDs(global)
+		globalState, err := prettyJSON(global["state"])
+		if err != nil {
+			return nil, err
+		}
+		response.Global = &dto.GlobalStateResponse{State: globalState, Streams: append([]string{}, globalIDs...)}
+	}
+
+	entries, _ := streamStateEntries(state)
+	for _, entry := range entries {
+		id := streamStateID(entry)
+		streamState, err := prettyJSON(entry["state"])
+		if err != nil {
+			return nil, err
+		}
+
+		stream := dto.StreamStateResponse{State: streamState, Global: slices.Contains(globalIDs, id)}
+		stream.Stream, _ = entry["stream"].(string)
+		stream.Namespace, _ = entry["namespace"].(string)
+		stream.SyncMode, _ = entry["sync_mode"].(string)
+		response.Streams = append(response.Streams, stream)
+		listed[id] = true
+	}
+
+	// streams only tracked by the global state have no per stream entry
+	for _, id := range globalIDs {
+		if listed[id] {
+			continue
+		}
+		stream := dto.StreamStateResponse{Stream: id, Global: true}
+		if idx := strings.LastIndex(id, "."); idx > 0 {
+			stream.Namespace, stream.Stream = id[:idx], id[idx+1:]
+		}
+		response.Streams = append(response.Streams, stream)
+	}
+
+	sort.SliceStable(response.Streams, func(i, j int) bool {
+		if response.Streams[i].Namespace != response.Streams[j].Namespace {
+			return response.Streams[i].Namespace < response.Streams[j].Namespace
+		}
+		return response.Streams[i].Stream < response.Streams[j].Stream
+	})
+
+	return response, nil
+}
+
+func buildJobStateVersionResponse(version *models.JobStateVersion) dto.JobStateVersionResponse {
+	response := dto.JobStateVersionResponse{
+		Version:      version.Version,
+		Action:       version.Action,
+		WorkflowID:   version.WorkflowID,
+		RunStatus:    version.RunStatus,
+		RestoredFrom: version.RestoredFrom,
+		CreatedAt:    version.CreatedAt.Format(time.RFC3339),
+	}
+	if version.Streams != "" {
+		response.Streams = strings.Split(version.Streams, ",")
+	}
+	if version.CreatedBy != nil {
+		response.CreatedBy = version.CreatedBy.Username
+	}
+	return response
+}
+
+// prettyJSON returns the indented JSON of v, "" for nil
+func prettyJSON(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal state: %s", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+)
+
+// Stream selection rules turn a discovered catalog into the streams config of a job. A catalog
+// lists every stream under "streams" and the selected ones, with their settings, under
+// "selected_streams" keyed by namespace:
+//
+//	{"streams": [{"stream": {"name": "orders", "namespace": "public", "sync_mode": "cdc", ...}}],
+//	 "selected_streams": {"public": [{"stream_name": "orders", "normalization": true, "partition_regex": ""}]}}
+
+// pattern types of stream selection rules
+const (
+	patternTypeRegex = "regex"
+	patternTypeGlob  = "glob"
+)
+
+var validSyncModes = map[string]struct{}{"full_refresh": {}, "incremental": {}, "cdc": {}, "strict_cdc": {}}
+
+type compiledStreamRule struct {
+	namespace *regexp.Regexp
+	stream    *regexp.Regexp
+	rule      dto.StreamSelectionRule
+}
+
+type compiledStreamRules struct {
+	rules    []compiledStreamRule
+	defaults dto.StreamSettings
+}
+
+// compileStreamRules validates rules and compiles their patterns
+func compileStreamRules(rules *dto.StreamSelectionRules) (*compiledStreamRules, error) {
+	if rules == nil || len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("at least one stream selection rule is required")
+	}
+	if err := validateStreamSettings(rules.Defaults); err != nil {
+		return nil, fmt.Errorf("invalid defaults: %s", err)
+	}
+
+	compiled := &compiledStreamRules{defaults: rules.Defaults}
+	for i, rule := range rules.Rules {
+		namespace, err := compilePattern(rule.Namespace, rule.PatternType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern of rule %d: %s", i, err)
+		}
+		stream, err := compilePattern(rule.Stream, rule.PatternType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stream pattern of rule %d: %s", i, err)
+		}
+		if err := validateStreamSettings(rule.StreamSettings); err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %s", i, err)
+		}
+		compiled.rules = append(compiled.rules, compiledStreamRule{namespace: namespace, stream: stream, rule: rule})
+	}
+	return compiled, nil
+}
+
+// compilePattern compiles a full match pattern of the given type, regex when empty
+func compilePattern(pattern, patternType string) (*regexp.Regexp, error) {
+	switch patternType {
+	case "", patternTypeRegex:
+		return compileFullMatch(pattern)
+	case patternTypeGlob:
+		if pattern == "" {
+			return nil, nil
+		}
+		expr, err := globToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return regexp.Compile("^(?:" + expr + ")$")
+	default:
+		return nil, fmt.Errorf("unsupported pattern type '%s', supported types are: %s, %s", patternType, patternTypeRegex, patternTypeGlob)
+	}
+}
+
+func compileFullMatch(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// globToRegexp translates a glob to a regular expression. "*" matches any run of characters,
+// "?" a single character and "[...]" a character class, negated with a leading "!" or "^".
+// A backslash escapes the next character.
+func globToRegexp(glob string) (string, error) {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 == len(glob) {
+				return "", fmt.Errorf("trailing escape in glob '%s'", glob)
+			}
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class in glob '%s'", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			negate := strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			if class == "" {
+				return "", fmt.Errorf("empty character class in glob '%s'", glob)
+			}
+			expr.WriteString(utils.Ternary(negate, "[^", "[").(string))
+			expr.WriteString(strings.NewReplacer("\\", "\\\\", "[", "\\[").Replace(class))
+			expr.WriteString("]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String(), nil
+}
+
+func validateStreamSettings(settings dto.StreamSettings) error {
+	if settings.SyncMode != "" {
+		if _, ok := validSyncModes[settings.SyncMode]; !ok {
+			return fmt.Errorf("unsupported sync mode '%s'", settings.SyncMode)
+		}
+	}
+	return nil
+}
+
+func (r compiledStreamRule) matches(namespace, stream string) bool {
+	return (r.namespace == nil || r.namespace.MatchString(namespace)) && (r.stream == nil || r.stream.MatchString(stream))
+}
+
+// match reports whether a stream is selected and with which settings
+func (c *compiledStreamRules) match(namespace, stream string) (dto.StreamSettings, bool) {
+	var include *dto.StreamSelectionRule
+	for i := range c.rules {
+		if !c.rules[i].matches(namespace, stream) {
+			continue
+		}
+		if c.rules[i].rule.Exclude {
+			return dto.StreamSettings{}, false
+		}
+		if include == nil {
+			include = &c.rules[i].rule
+		}
+	}
+	if include == nil {
+		return dto.StreamSettings{}, false
+	}
+	return overlayStreamSettings(c.defaults, include.StreamSettings), true
+}
+
+func overlayStreamSettings(base, override dto.StreamSettings) dto.StreamSettings {
+	if override.Normalization != nil {
+		base.Normalization = override.Normalization
+	}
+	if override.PartitionRegex != nil {
+		base.PartitionRegex = override.PartitionRegex
+	}
+	if override.AppendMode != nil {
+		base.AppendMode = override.AppendMode
+	}
+	if override.SyncMode != "" {
+		base.SyncMode = override.SyncMode
+	}
+	return base
+}
+
+// catalogStream is a stream of a catalog with the raw objects it is made of
+type catalogStream struct {
+	namespace string
+	name      string
+	stream    map[string]interface{} // streams[].stream
+}
+
+func catalogStreams(catalog map[string]interface{}) []catalogStream {
+	entries, _ := catalog["streams"].([]interface{})
+	streams := make([]catalogStream, 0, len(entries))
+	for _, raw := range entries {
+		entry, _ := raw.(map[string]interface{})
+		stream, _ := entry["stream"].(map[string]interface{})
+		if stream == nil {
+			continue
+		}
+		name, _ := stream["name"].(string)
+		namespace, _ := stream["namespace"].(string)
+		if name == "" {
+			continue
+		}
+		streams = append(streams, catalogStream{namespace: namespace, name: name, stream: stream})
+	}
+	return streams
+}
+
+// selectedStreamEntries indexes the selected_streams entries of a catalog by namespace and name
+func selectedStreamEntries(catalog map[string]interface{}) map[string]map[string]interface{} {
+	index := map[string]map[string]interface{}{}
+	selected, _ := catalog["selected_streams"].(map[string]interface{})
+	for namespace, raw := range selected {
+		entries, _ := raw.([]interface{})
+		for _, rawEntry := range entries {
+			entry, _ := rawEntry.(map[string]interface{})
+			if name, _ := entry["stream_name"].(string); name != "" {
+				index[namespace+"."+name] = entry
+			}
+		}
+	}
+	return index
+}
+
+// keepSelectedStreams unselects every stream of a catalog that is not in wanted
+// ("namespace.stream"), the streams list is left as is
+func keepSelectedStreams(catalog map[string]interface{}, wanted map[string]bool) {
+	selectedStreams, _ := catalog["selected_streams"].(map[string]interface{})
+	for namespace, raw := range selectedStreams {
+		list, _ := raw.([]interface{})
+		kept := make([]interface{}, 0, len(list))
+		for _, rawEntry := range list {
+			entry, _ := rawEntry.(map[string]interface{})
+			if name, _ := entry["stream_name"].(string); wanted[namespace+"."+name] {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(selectedStreams, namespace)
+			continue
+		}
+		selectedStreams[namespace] = kept
+	}
+}
+
+// applyStreamRules selects the streams of a catalog by rules and returns the resulting streams
+// config with the selected "namespace.stream" names, sorted, and the number of streams skipped.
+// Newly selected streams are set up by the rules on top of what discovery proposed for them.
+// Selected streams found in keep are taken from it unchanged, so re-applying rules to a job
+// does not touch the streams it already syncs.
+func applyStreamRules(catalog map[string]interface{}, rules *compiledStreamRules, keep map[string]map[string]interface{}) (string, []string, int, error) {
+	discovered := selectedStreamEntries(catalog)
+	selectedStreams := map[string]interface{}{}
+	var selectedNames []string
+	skipped := 0
+
+	for _, stream := range catalogStreams(catalog) {
+		settings, ok := rules.match(stream.namespace, stream.name)
+		if !ok {
+			skipped++
+			continue
+		}
+
+		id := stream.namespace + "." + stream.name
+		entry, kept := keep[id]
+		if !kept {
+			entry = map[string]interface{}{"stream_name": stream.name, "partition_regex": "", "normalization": false}
+			for key, value := range discovered[id] {
+				entry[key] = value
+			}
+			if settings.Normalization != nil {
+				entry["normalization"] = *settings.Normalization
+			}
+			if settings.PartitionRegex != nil {
+				entry["partition_regex"] = *settings.PartitionRegex
+			}
+			if settings.AppendMode != nil {
+				entry["append_mode"] = *settings.AppendMode
+			}
+			if settings.SyncMode != "" && supportsSyncMode(stream.stream, settings.SyncMode) {
+				stream.stream["sync_mode"] = settings.SyncMode
+			}
+		}
+
+		list, _ := selectedStreams[stream.namespace].([]interface{})
+		selectedStreams[stream.namespace] = append(list, entry)
+		selectedNames = append(selectedNames, id)
+	}
+	catalog["selected_streams"] = selectedStreams
+	sort.Strings(selectedNames)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(catalog); err != nil {
+		return "", nil, 0, fmt.Errorf("failed to encode streams config: %s", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), selectedNames, skipped, nil
+}
+
+// supportsSyncMode reports whether a stream lists a sync mode as supported, streams that do not
+// list their modes accept any
+func supportsSyncMode(stream map[string]interface{}, mode string) bool {
+	supported, ok := stream["supported_sync_modes"].([]interface{})
+	if !ok {
+		return true
+	}
+	for _, raw := range supported {
+		if value, _ := raw.(string); value == mode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+)
+
+func TestCompilePattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, patternType string
+		matches, misses      []string
+	}{
+		{pattern: "tmp_.*", matches: []string{"tmp_", "tmp_orders"}, misses: []string{"orders_tmp_x", "tmp"}},
+		{pattern: "tmp_*", patternType: "glob", matches: []string{"tmp_", "tmp_orders"}, misses: []string{"tmp", "xtmp_"}},
+		{pattern: "events_20??", patternType: "glob", matches: []string{"events_2024"}, misses: []string{"events_202", "events_20245"}},
+		{pattern: "log.[!a-c]*", patternType: "glob", matches: []string{"log.d", "log.zz"}, misses: []string{"log.a", "logxd"}},
+		{pattern: `a\*b+`, patternType: "glob", matches: []string{"a*b+"}, misses: []string{"aab+", "a*bb"}},
+	} {
+		re, err := compilePattern(tc.pattern, tc.patternType)
+		require.NoError(t, err, tc.pattern)
+		for _, name := range tc.matches {
+			require.True(t, re.MatchString(name), "%s should match %s", tc.pattern, name)
+		}
+		for _, name := range tc.misses {
+			require.False(t, re.MatchString(name), "%s should not match %s", tc.pattern, name)
+		}
+	}
+
+	re, err := compilePattern("", "glob")
+	require.NoError(t, err)
+	require.Nil(t, re, "an empty pattern matches anything")
+
+	for _, tc := range [][2]string{{"orders[", "glob"}, {"orders[]", "glob"}, {`orders\`, "glob"}, {"orders(", "regex"}, {"orders", "like"}} {
+		_, err := compilePattern(tc[0], tc[1])
+		require.Error(t, err, tc)
+	}
+}
+
+func TestApplyStreamRules(t *testing.T) {
+	var catalog map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"streams": [
+			{"stream": {"name": "orders", "namespace": "public", "supported_sync_modes": ["full_refresh", "cdc"]}},
+			{"stream": {"name": "tmp_orders", "namespace": "public"}},
+			{"stream": {"name": "users", "namespace": "public", "supported_sync_modes": ["full_refresh"]}},
+			{"stream": {"name": "events", "namespace": "audit"}}
+		],
+		"selected_streams": {"public": [{"stream_name": "orders", "partition_regex": "/{id}", "normalization": false}]}
+	}`), &catalog))
+
+	normalize := true
+	rules, err := compileStreamRules(&dto.StreamSelectionRules{
+		Rules: []dto.StreamSelectionRule{
+			{Namespace: "public", StreamSettings: dto.StreamSettings{Normalization: &normalize}},
+			{Stream: "tmp_*", PatternType: "glob", Exclude: true},
+		},
+		Defaults: dto.StreamSettings{SyncMode: "cdc"},
+	})
+	require.NoError(t, err)
+
+	keep := map[string]map[string]interface{}{
+		"public.users": {"stream_name": "users", "partition_regex": "", "normalization": false},
+	}
+	streamsConfig, selected, skipped, err := applyStreamRules(catalog, rules, keep)
+	require.NoError(t, err)
+	require.Equal(t, []string{"public.orders", "public.users"}, selected)
+	require.Equal(t, 2, skipped)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(streamsConfig), &result))
+	require.Equal(t, map[string]interface{}{
+		"public": []interface{}{
+			map[string]interface{}{"stream_name": "orders", "partition_regex": "/{id}", "normalization": true},
+			map[string]interface{}{"stream_name": "users", "partition_regex": "", "normalization": false},
+		},
+	}, result["selected_streams"], "new streams get discovery and rule settings, kept streams are unchanged")
+
+	streams := result["streams"].([]interface{})
+	require.Equal(t, "cdc", streams[0].(map[string]interface{})["stream"].(map[string]interface{})["sync_mode"])
+	require.NotContains(t, streams[2].(map[string]interface{})["stream"], "sync_mode", "kept streams keep their sync mode")
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/database"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Stream selection rule methods on AppService
+//
+// Jobs with stream selection rules are re-discovered periodically and the rules applied to the
+// fresh catalog. Streams the job already syncs keep their settings, newly matched streams are
+// set up by the rules. A change that only adds streams is applied right away when the job
+// auto-includes, any other change waits in the review queue until it is approved.
+
+// rediscoveryRunning holds the IDs of jobs being re-discovered
+var rediscoveryRunning sync.Map
+
+func buildJobStreamRulesResponse(rules *models.JobStreamRules) (*dto.JobStreamRulesResponse, error) {
+	var selection dto.StreamSelectionRules
+	if err := json.Unmarshal([]byte(rules.Rules), &selection); err != nil {
+		return nil, fmt.Errorf("failed to parse stream rules job_id[%d]: %s", rules.Job.ID, err)
+	}
+
+	response := &dto.JobStreamRulesResponse{
+		JobID:       rules.Job.ID,
+		Rules:       &selection,
+		AutoInclude: rules.AutoInclude,
+		LastError:   rules.LastError,
+		UpdatedAt:   rules.UpdatedAt.Format(time.RFC3339),
+	}
+	if rules.LastCheckedAt != nil {
+		response.LastCheckedAt = rules.LastCheckedAt.Format(time.RFC3339)
+	}
+	if rules.UpdatedBy != nil {
+		response.UpdatedBy = rules.UpdatedBy.Username
+	}
+	return response, nil
+}
+
+func buildStreamReviewResponse(review *models.StreamReview, includeConfig bool) dto.StreamReviewResponse {
+	response := dto.StreamReviewResponse{
+		ID:        review.ID,
+		JobID:     review.Job.ID,
+		JobName:   review.Job.Name,
+		Status:    review.Status,
+		Added:     splitStreamList(review.Added),
+		Removed:   splitStreamList(review.Removed),
+		CreatedAt: review.CreatedAt.Format(time.RFC3339),
+	}
+	if includeConfig {
+		response.StreamsConfig = review.StreamsConfig
+	}
+	if review.ResolvedAt != nil {
+		response.ResolvedAt = review.ResolvedAt.Format(time.RFC3339)
+	}
+	if review.ResolvedBy != nil {
+		response.ResolvedBy = review.ResolvedBy.Username
+	}
+	return response
+}
+
+func splitStreamList(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
+}
+
+// diffStreamSelection returns the streams of next missing from current and the other way round
+func diffStreamSelection(current, next []string) (added, removed []string) {
+	inCurrent := make(map[string]struct{}, len(current))
+	for _, id := range current {
+		inCurrent[id] = struct{}{}
+	}
+	inNext := make(map[string]struct{}, len(next))
+	for _, id := range next {
+		inNext[id] = struct{}{}
+		if _, ok := inCurrent[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	for _, id := range current {
+		if _, ok := inNext[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func (s *ETLService) GetJobStreamRules(_ context.Context, projectID string, jobID int) (*dto.JobStreamRulesResponse, error) {
+	if _, err := s.getProjectJob(projectID, jobID); err != nil {
+		return nil, err
+	}
+	rules, err := s.db.GetJobStreamRules(jobID)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, constants.ErrStreamRulesNotFound
+		}
+		return nil, fmt.Errorf("failed to get stream rules: %s", err)
+	}
+	return buildJobStreamRulesResponse(rules)
+}
+
+func (s *ETLService) SetJobStreamRules(ctx context.Context, projectID string, jobID int, req *dto.JobStreamRulesRequest, userID *int) (*dto.JobStreamRulesResponse, error) {
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := compileStreamRules(&req.StreamSelectionRules); err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrInvalidStreamRules, err)
+	}
+
+	encoded, err := json.Marshal(req.StreamSelectionRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rules: %s", err)
+	}
+	rules := &models.JobStreamRules{
+		Job:         job,
+		Rules:       string(encoded),
+		AutoInclude: req.AutoInclude,
+		UpdatedBy:   &models.User{ID: *userID},
+	}
+	if err := s.db.SaveJobStreamRules(rules); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("stream rules of job %d updated auto_include[%t]", jobID, req.AutoInclude)
+	return s.GetJobStreamRules(ctx, projectID, jobID)
+}
+
+// DeleteJobStreamRules stops applying rules to a job, its pending reviews are superseded
+func (s *ETLService) DeleteJobStreamRules(_ context.Context, projectID string, jobID int) error {
+	if _, err := s.getProjectJob(projectID, jobID); err != nil {
+		return err
+	}
+	deleted, err := s.db.DeleteJobStreamRules(jobID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return constants.ErrStreamRulesNotFound
+	}
+	return nil
+}
+
+// RediscoverJobStreams re-discovers the source of a job and applies its stream selection rules
+func (s *ETLService) RediscoverJobStreams(ctx context.Context, projectID string, jobID int) (*dto.RediscoverStreamsResponse, error) {
+	job, err := s.getProjectJob(projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.db.GetJobStreamRules(jobID)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, constants.ErrStreamRulesNotFound
+		}
+		return nil, fmt.Errorf("failed to get stream rules: %s", err)
+	}
+	return s.rediscoverJobStreams(ctx, job, rules)
+}
+
+// rediscoverJobStreams applies rules to a fresh catalog of the job's source and records the
+// outcome on the rules
+func (s *ETLService) rediscoverJobStreams(ctx context.Context, job *models.Job, rules *models.JobStreamRules) (*dto.RediscoverStreamsResponse, error) {
+	if _, running := rediscoveryRunning.LoadOrStore(job.ID, struct{}{}); running {
+		return nil, fmt.Errorf("re-discovery of job %d is already running", job.ID)
+	}
+	defer rediscoveryRunning.Delete(job.ID)
+
+	result, err := s.applyJobStreamRules(ctx, job, rules)
+	checkErr := ""
+	if err != nil {
+		checkErr = err.Error()
+	}
+	if recordErr := s.db.RecordStreamRulesCheck(rules.ID, time.Now().UTC(), checkErr); recordErr != nil {
+		logger.Warnf("failed to record stream rules check job_id[%d]: %s", job.ID, recordErr)
+	}
+	return result, err
+}
+
+func (s *ETLService) applyJobStreamRules(ctx context.Context, job *models.Job, rules *models.JobStreamRules) (*dto.RediscoverStreamsResponse, error) {
+	var selection dto.StreamSelectionRules
+	if err := json.Unmarshal([]byte(rules.Rules), &selection); err != nil {
+		return nil, fmt.Errorf("failed to parse stream rules: %s", err)
+	}
+	compiled, err := compileStreamRules(&selection)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream rules: %s", err)
+	}
+
+	var current map[string]interface{}
+	if err := json.Unmarshal([]byte(job.StreamsConfig), &current); err != nil {
+		return nil, fmt.Errorf("failed to parse streams config: %s", err)
+	}
+	kept := selectedStreamEntries(current)
+	currentSelection := make([]string, 0, len(kept))
+	for id := range kept {
+		currentSelection = append(currentSelection, id)
+	}
+
+	source := job.SourceID
+	encryptedConfig, err := utils.EncryptForConnector(source.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt config for catalog: %s", err)
+	}
+	catalog, err := s.temporal.DiscoverStreams(ctx, source.Type, source.Version, encryptedConfig, job.StreamsConfig, job.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get catalog: %s", err)
+	}
+
+	streamsConfig, selected, _, err := applyStreamRules(catalog, compiled, kept)
+	if err != nil {
+		return nil, err
+	}
+
+	added, removed := diffStreamSelection(currentSelection, selected)
+	result := &dto.RediscoverStreamsResponse{Added: added, Removed: removed}
+	if len(added) == 0 && len(removed) == 0 {
+		return result, nil
+	}
+	result.Changed = true
+
+	review := &models.StreamReview{
+		Job:           job,
+		Status:        database.StreamReviewPending,
+		Added:         strings.Join(added, ","),
+		Removed:       strings.Join(removed, ","),
+		StreamsConfig: streamsConfig,
+	}
+
+	// the same change found again keeps its review instead of queueing a duplicate
+	pending, err := s.db.ListStreamReviews(job.ProjectID, job.ID, database.StreamReviewPending)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 && pending[0].Added == review.Added && pending[0].Removed == review.Removed {
+		result.ReviewID = pending[0].ID
+		return result, nil
+	}
+
+	if rules.AutoInclude && len(removed) == 0 {
+		review.Status = database.StreamReviewApplied
+	}
+	if err := s.db.CreateStreamReview(review, job.StreamsConfig); err != nil {
+		return nil, fmt.Errorf("failed to store stream review: %w", err)
+	}
+
+	result.ReviewID = review.ID
+	result.Applied = review.Status == database.StreamReviewApplied
+	logger.Infof("stream rules of job %d changed the selection added[%d] removed[%d] applied[%t]", job.ID, len(added), len(removed), result.Applied)
+	return result, nil
+}
+
+// StartStreamRediscovery periodically re-discovers the active jobs with stream selection rules
+// until ctx is done
+func (s *ETLService) StartStreamRediscovery(ctx context.Context) {
+	interval := time.Duration(web.AppConfig.DefaultInt(constants.ConfStreamRediscoveryInterval, constants.DefaultStreamRediscovery)) * time.Minute
+	if interval <= 0 {
+		logger.Info("stream re-discovery disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.rediscoverAllJobStreams(ctx)
+			}
+		}
+	}()
+}
+
+func (s *ETLService) rediscoverAllJobStreams(ctx context.Context) {
+	allRules, err := s.db.ListJobStreamRules()
+	if err != nil {
+		logger.Errorf("stream re-discovery failed: %s", err)
+		return
+	}
+
+	for _, rules := range allRules {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := s.db.GetJobByID(rules.Job.ID, true)
+		if err != nil {
+			logger.Warnf("stream re-discovery skipped job_id[%d]: %s", rules.Job.ID, err)
+			continue
+		}
+		if !job.Active {
+			continue
+		}
+		if _, err := s.rediscoverJobStreams(ctx, job, rules); err != nil {
+			logger.Warnf("stream re-discovery failed job_id[%d]: %s", job.ID, err)
+		}
+	}
+}
+
+// ListStreamReviews returns the stream reviews of a project, jobID 0 and an empty status match any
+func (s *ETLService) ListStreamReviews(_ context.Context, projectID string, jobID int, status string) ([]dto.StreamReviewResponse, error) {
+	reviews, err := s.db.ListStreamReviews(projectID, jobID, status)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.StreamReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		responses = append(responses, buildStreamReviewResponse(review, false))
+	}
+	return responses, nil
+}
+
+func (s *ETLService) GetStreamReview(_ context.Context, projectID string, id int) (*dto.StreamReviewResponse, error) {
+	review, err := s.db.GetStreamReview(projectID, id)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, constants.ErrStreamReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to get stream review: %s", err)
+	}
+	response := buildStreamReviewResponse(review, true)
+	return &response, nil
+}
+
+// ResolveStreamReview approves a pending review into the job's streams config or rejects it
+func (s *ETLService) ResolveStreamReview(ctx context.Context, projectID string, id int, approve bool, userID *int) (*dto.StreamReviewResponse, error) {
+	if _, err := s.GetStreamReview(ctx, projectID, id); err != nil {
+		return nil, err
+	}
+	if err := s.db.ResolveStreamReview(id, approve, *userID); err != nil {
+		return nil, err
+	}
+	logger.Infof("stream review %d resolved approve[%t] by user %d", id, approve, *userID)
+	return s.GetStreamReview(ctx, projectID, id)
+}
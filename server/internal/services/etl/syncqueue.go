@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+	"go.temporal.io/api/workflowservice/v1"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Sync queue methods on AppService
+//
+// Syncs are admitted by the server before they start. The worker asks for a slot with the
+// workflow ID of the run, and the run is admitted while its project and the source of its job
+// are under their caps of concurrent syncs. Otherwise the run is queued and the worker asks again
+// every few seconds. Queued runs start in the order they asked, but a run waiting for its own
+// source does not hold back the runs of other sources. The worker releases the slot when the run
+// ends. Slots of runs that ended without a release, and of queued runs that stopped asking, are
+// dropped periodically.
+//
+// The CPU and memory limits of a job are passed to the worker in the ExecutionRequest of its
+// runs.
+
+// Sync slot statuses
+const (
+	SyncQueued  = "queued"
+	SyncRunning = "running"
+)
+
+// syncSlotGrace keeps new slots from being dropped before their run shows up in Temporal
+const syncSlotGrace = time.Minute
+
+var (
+	cpuLimitPattern    = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?|[0-9]+m)$`)
+	memoryLimitPattern = regexp.MustCompile(`^[0-9]+(Ki|Mi|Gi|Ti|k|M|G|T)?$`)
+)
+
+// syncQueueMu serializes admissions, releases and checks of the sync queue, which all count the
+// slots of a project
+var syncQueueMu sync.Mutex
+
+// syncDecision is the outcome of the queue for a queued sync
+type syncDecision struct {
+	admit    bool
+	position int
+	reason   string
+}
+
+// syncQueuePlan is the state of the sync queue of a project
+type syncQueuePlan struct {
+	slots     []*models.SyncSlot
+	decisions map[int]syncDecision // by slot ID, for live queued slots
+	sources   map[int]*models.Source
+	limit     int
+	running   int
+}
+
+// validateResourceLimits checks the CPU and memory limits of a job, empty values are not limited
+func validateResourceLimits(cpu, memory string) error {
+	if cpu != "" && (!cpuLimitPattern.MatchString(cpu) || !positiveQuantity(strings.TrimSuffix(cpu, "m"))) {
+		return fmt.Errorf("%w: cpu_limit '%s' must be a number of cores like '2' or '0.5', or millicores like '500m'", constants.ErrInvalidResourceLimits, cpu)
+	}
+	if memory != "" && (!memoryLimitPattern.MatchString(memory) || !positiveQuantity(strings.TrimRight(memory, "KMGTkii"))) {
+		return fmt.Errorf("%w: memory_limit '%s' must be a number of bytes with an optional unit like '512Mi' or '4Gi'", constants.ErrInvalidResourceLimits, memory)
+	}
+	return nil
+}
+
+func positiveQuantity(number string) bool {
+	value, err := strconv.ParseFloat(number, 64)
+	return err == nil && value > 0
+}
+
+// checkResourceLimitsSupported refuses to set resource limits the worker would not apply,
+// removing a limit or keeping the current one is always allowed
+func checkResourceLimitsSupported(currentCPU, currentMemory, cpu, memory string) error {
+	if (cpu == "" || cpu == currentCPU) && (memory == "" || memory == currentMemory) {
+		return nil
+	}
+	return temporal.RequireWorkerFeatures(temporal.WorkerFeatureResourceLimits)
+}
+
+// checkSyncCapSupported refuses to set a cap of concurrent syncs the worker would not ask
+// admission for, removing a cap or keeping the current one is always allowed
+func checkSyncCapSupported(current, limit int) error {
+	if limit == 0 || limit == current {
+		return nil
+	}
+	return temporal.RequireWorkerFeatures(temporal.WorkerFeatureSyncAdmission)
+}
+
+// AdmitSync admits or queues a sync run, it is called by the worker until the run is admitted
+func (s *ETLService) AdmitSync(_ context.Context, req *dto.SyncAdmissionRequest) (*dto.SyncAdmissionResponse, error) {
+	syncQueueMu.Lock()
+	defer syncQueueMu.Unlock()
+
+	job, err := s.db.GetJobByID(req.JobID, false)
+	if err != nil || job.ProjectID != req.ProjectID || job.SourceID == nil {
+		return nil, fmt.Errorf("%w: id %d", constants.ErrJobNotFound, req.JobID)
+	}
+
+	now := time.Now()
+	slot, err := s.db.GetSyncSlotByWorkflowID(req.WorkflowID)
+	switch {
+	case err == orm.ErrNoRows:
+		slot = &models.SyncSlot{
+			ProjectID:  req.ProjectID,
+			JobID:      job.ID,
+			SourceID:   job.SourceID.ID,
+			WorkflowID: req.WorkflowID,
+			Status:     SyncQueued,
+			EnqueuedAt: now,
+			LastSeenAt: now,
+		}
+		if err := s.db.CreateSyncSlot(slot); err != nil {
+			return nil, fmt.Errorf("failed to queue sync: %s", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get sync slot: %s", err)
+	case slot.Status == SyncRunning:
+		return &dto.SyncAdmissionResponse{Admitted: true}, nil
+	default:
+		slot.LastSeenAt = now
+		if err := s.db.UpdateSyncSlot(slot, "LastSeenAt"); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := s.planSyncQueue(req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	decision := plan.decisions[slot.ID]
+	if !decision.admit {
+		logger.Debugf("sync queued job_id[%d] workflow_id[%s] position[%d]: %s", job.ID, req.WorkflowID, decision.position, decision.reason)
+		return &dto.SyncAdmissionResponse{
+			Position:   decision.position,
+			RetryAfter: constants.DefaultSyncAdmissionRetry,
+			Reason:     decision.reason,
+		}, nil
+	}
+
+	slot.Status = SyncRunning
+	slot.StartedAt = &now
+	if err := s.db.UpdateSyncSlot(slot, "Status", "StartedAt"); err != nil {
+		return nil, err
+	}
+	logger.Infof("sync admitted job_id[%d] workflow_id[%s] after %s", job.ID, req.WorkflowID, now.Sub(slot.EnqueuedAt).Round(time.Second))
+	return &dto.SyncAdmissionResponse{Admitted: true}, nil
+}
+
+// ReleaseSync frees the slot of a sync run that ended, releasing an unknown run does nothing
+func (s *ETLService) ReleaseSync(workflowID string) error {
+	syncQueueMu.Lock()
+	defer syncQueueMu.Unlock()
+
+	released, err := s.db.DeleteSyncSlot(workflowID)
+	if err != nil {
+		return err
+	}
+	if released {
+		logger.Infof("sync slot released workflow_id[%s]", workflowID)
+	}
+	return nil
+}
+
+// GetSyncQueue returns the running and queued syncs of a project
+func (s *ETLService) GetSyncQueue(projectID string) (*dto.SyncQueueResponse, error) {
+	syncQueueMu.Lock()
+	plan, err := s.planSyncQueue(projectID)
+	syncQueueMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.db.ListJobsByProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %s", err)
+	}
+	jobNames := make(map[int]string, len(jobs))
+	for _, job := range jobs {
+		jobNames[job.ID] = job.Name
+	}
+
+	response := &dto.SyncQueueResponse{MaxConcurrentSyncs: plan.limit, Running: plan.running, Syncs: []dto.SyncSlotItem{}}
+	var queued []dto.SyncSlotItem
+	for _, slot := range plan.slots {
+		item := dto.SyncSlotItem{
+			JobID:      slot.JobID,
+			JobName:    jobNames[slot.JobID],
+			SourceID:   slot.SourceID,
+			WorkflowID: slot.WorkflowID,
+			Status:     slot.Status,
+			EnqueuedAt: slot.EnqueuedAt.Format(time.RFC3339),
+		}
+		if source, ok := plan.sources[slot.SourceID]; ok {
+			item.SourceName = source.Name
+		}
+		if slot.Status == SyncRunning {
+			if slot.StartedAt != nil {
+				item.StartedAt = slot.StartedAt.Format(time.RFC3339)
+			}
+			response.Syncs = append(response.Syncs, item)
+			continue
+		}
+		decision, live := plan.decisions[slot.ID]
+		if !live {
+			continue
+		}
+		item.Position = decision.position
+		item.Reason = decision.reason
+		queued = append(queued, item)
+	}
+	response.Queued = len(queued)
+	response.Syncs = append(response.Syncs, queued...)
+	return response, nil
+}
+
+// planSyncQueue decides which queued syncs of a project may start. Queued syncs are taken in
+// the order they asked, each one starts while the project and its source are under their caps.
+// Queued syncs that stopped asking are left out. Callers hold syncQueueMu.
+func (s *ETLService) planSyncQueue(projectID string) (*syncQueuePlan, error) {
+	slots, err := s.db.ListSyncSlots(projectID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.db.GetProjectSettingsByProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project settings: %s", err)
+	}
+	sources, err := s.db.ListSourcesByProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %s", err)
+	}
+
+	plan := &syncQueuePlan{
+		slots:     slots,
+		decisions: make(map[int]syncDecision),
+		sources:   make(map[int]*models.Source, len(sources)),
+		limit:     settings.MaxConcurrentSyncs,
+	}
+	for _, source := range sources {
+		plan.sources[source.ID] = source
+	}
+	plan.decide(time.Now().Add(-time.Duration(constants.DefaultSyncQueueStale) * time.Second))
+	return plan, nil
+}
+
+// decide counts the running syncs of the plan and decides on every queued sync that asked since
+// stale
+func (p *syncQueuePlan) decide(stale time.Time) {
+	runningBySource := make(map[int]int)
+	for _, slot := range p.slots {
+		if slot.Status == SyncRunning {
+			p.running++
+			runningBySource[slot.SourceID]++
+		}
+	}
+
+	running, position := p.running, 0
+	for _, slot := range p.slots {
+		if slot.Status != SyncQueued || slot.LastSeenAt.Before(stale) {
+			continue
+		}
+		position++
+		decision := syncDecision{position: position}
+		sourceLimit := 0
+		if source, ok := p.sources[slot.SourceID]; ok {
+			sourceLimit = source.MaxConcurrentSyncs
+		}
+		switch {
+		case p.limit > 0 && running >= p.limit:
+			decision.reason = fmt.Sprintf("project limit of %d concurrent syncs reached", p.limit)
+		case sourceLimit > 0 && runningBySource[slot.SourceID] >= sourceLimit:
+			decision.reason = fmt.Sprintf("source limit of %d concurrent syncs reached", sourceLimit)
+		default:
+			// syncs ahead in the queue keep their place until they ask again
+			decision.admit = true
+			running++
+			runningBySource[slot.SourceID]++
+		}
+		p.decisions[slot.ID] = decision
+	}
+}
+
+// StartSyncQueue periodically drops the slots of syncs that ended without releasing them and of
+// queued syncs that stopped asking
+func (s *ETLService) StartSyncQueue(ctx context.Context) {
+	interval := time.Duration(web.AppConfig.DefaultInt(constants.ConfSyncQueueCheckInterval, constants.DefaultSyncQueueCheck)) * time.Second
+	if interval <= 0 {
+		logger.Info("sync queue checks disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkSyncQueue(ctx)
+			}
+		}
+	}()
+}
+
+// checkSyncQueue drops the slots whose run is no longer running in Temporal, and the queued
+// slots not asked for within DefaultSyncQueueStale seconds
+func (s *ETLService) checkSyncQueue(ctx context.Context) {
+	syncQueueMu.Lock()
+	defer syncQueueMu.Unlock()
+
+	slots, err := s.db.ListAllSyncSlots()
+	if err != nil {
+		logger.Errorf("failed to check sync queue: %s", err)
+		return
+	}
+
+	slotsByProject := make(map[string][]*models.SyncSlot)
+	for _, slot := range slots {
+		slotsByProject[slot.ProjectID] = append(slotsByProject[slot.ProjectID], slot)
+	}
+
+	now := time.Now()
+	stale := now.Add(-time.Duration(constants.DefaultSyncQueueStale) * time.Second)
+	for projectID, projectSlots := range slotsByProject {
+		resp, err := s.temporal.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Query: fmt.Sprintf("WorkflowId BETWEEN 'sync-%s-' AND 'sync-%s-~' AND ExecutionStatus = 'Running'", projectID, projectID),
+		})
+		if err != nil {
+			logger.Errorf("failed to list running syncs project_id[%s]: %s", projectID, err)
+			continue
+		}
+		runningWorkflows := make(map[string]bool, len(resp.Executions))
+		for _, execution := range resp.Executions {
+			runningWorkflows[execution.Execution.WorkflowId] = true
+		}
+
+		for _, slot := range projectSlots {
+			var reason string
+			switch {
+			case slot.EnqueuedAt.After(now.Add(-syncSlotGrace)):
+				continue
+			case !runningWorkflows[slot.WorkflowID]:
+				reason = "its run is no longer running"
+			case slot.Status == SyncQueued && slot.LastSeenAt.Before(stale):
+				reason = "its run stopped asking to start"
+			default:
+				continue
+			}
+			if _, err := s.db.DeleteSyncSlot(slot.WorkflowID); err != nil {
+				logger.Errorf("failed to drop sync slot: %s", err)
+				continue
+			}
+			logger.Infof("dropped %s sync slot workflow_id[%s] as %s", slot.Status, slot.WorkflowID, reason)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func TestValidateResourceLimits(t *testing.T) {
+	for _, limits := range [][2]string{{"", ""}, {"2", "4Gi"}, {"0.5", "512Mi"}, {"500m", "1073741824"}, {"", "2G"}} {
+		require.NoError(t, validateResourceLimits(limits[0], limits[1]), limits)
+	}
+	for _, limits := range [][2]string{{"0", ""}, {"0m", ""}, {"-1", ""}, {"2 cores", ""}, {".5", ""}, {"", "0Gi"}, {"", "4GB"}, {"", "1.5Gi"}} {
+		require.ErrorIs(t, validateResourceLimits(limits[0], limits[1]), constants.ErrInvalidResourceLimits, limits)
+	}
+}
+
+func TestWorkerFeatureChecks(t *testing.T) {
+	require.NoError(t, web.AppConfig.Set(constants.ConfWorkerFeatures, ""))
+	t.Cleanup(func() { _ = web.AppConfig.Set(constants.ConfWorkerFeatures, "") })
+
+	require.NoError(t, checkResourceLimitsSupported("2", "4Gi", "2", ""), "keeping or removing limits needs no support")
+	require.ErrorIs(t, checkResourceLimitsSupported("2", "4Gi", "1", "4Gi"), constants.ErrWorkerFeatureUnsupported)
+	require.NoError(t, checkSyncCapSupported(3, 0))
+	require.NoError(t, checkSyncCapSupported(3, 3))
+	require.ErrorIs(t, checkSyncCapSupported(0, 2), constants.ErrWorkerFeatureUnsupported)
+
+	require.NoError(t, web.AppConfig.Set(constants.ConfWorkerFeatures, " Resource-Limits , sync-admission"))
+	require.NoError(t, checkResourceLimitsSupported("", "", "1", "1Gi"))
+	require.NoError(t, checkSyncCapSupported(0, 2))
+}
+
+func TestSyncQueueDecisions(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-time.Minute)
+	slot := func(id, sourceID int, status string, lastSeen time.Time) *models.SyncSlot {
+		return &models.SyncSlot{ID: id, SourceID: sourceID, Status: status, LastSeenAt: lastSeen}
+	}
+
+	plan := &syncQueuePlan{
+		slots: []*models.SyncSlot{
+			slot(1, 10, SyncRunning, now),
+			slot(2, 10, SyncQueued, now),                     // source 10 is at its cap
+			slot(3, 20, SyncQueued, now.Add(-2*time.Minute)), // stopped asking
+			slot(4, 20, SyncQueued, now),
+			slot(5, 30, SyncQueued, now), // the project is at its cap once 4 starts
+		},
+		decisions: make(map[int]syncDecision),
+		sources: map[int]*models.Source{
+			10: {ID: 10, MaxConcurrentSyncs: 1},
+			20: {ID: 20},
+		},
+		limit: 2,
+	}
+	plan.decide(stale)
+
+	require.Equal(t, 1, plan.running)
+	require.Equal(t, map[int]syncDecision{
+		2: {position: 1, reason: "source limit of 1 concurrent syncs reached"},
+		4: {position: 2, admit: true},
+		5: {position: 3, reason: "project limit of 2 concurrent syncs reached"},
+	}, plan.decisions)
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Job template methods on AppService
+//
+// A template keeps stream selection rules and job defaults. Applying it to a source discovers
+// the source, selects streams by the rules and either returns the resulting streams config for
+// review or creates the job right away.
+
+func buildJobTemplateResponse(template *models.JobTemplate) (dto.JobTemplateResponse, error) {
+	response := dto.JobTemplateResponse{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		SourceType:  template.SourceType,
+		Frequency:   template.Frequency,
+		CreatedAt:   template.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   template.UpdatedAt.Format(time.RFC3339),
+	}
+	rules, err := templateRules(template)
+	if err != nil {
+		return dto.JobTemplateResponse{}, err
+	}
+	response.Rules = rules
+	setUsernames(&response.CreatedBy, &response.UpdatedBy, template.CreatedBy, template.UpdatedBy)
+	return response, nil
+}
+
+func templateRules(template *models.JobTemplate) (*dto.StreamSelectionRules, error) {
+	var rules dto.StreamSelectionRules
+	if err := json.Unmarshal([]byte(template.Rules), &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules of job template %d: %s", template.ID, err)
+	}
+	return &rules, nil
+}
+
+func (s *ETLService) getJobTemplate(projectID string, id int) (*models.JobTemplate, error) {
+	template, err := s.db.GetJobTemplateByID(projectID, id)
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %d", constants.ErrJobTemplateNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get job template: %s", err)
+	}
+	return template, nil
+}
+
+// applyJobTemplateRequest validates a template request and copies it onto template
+func (s *ETLService) applyJobTemplateRequest(ctx context.Context, projectID string, template *models.JobTemplate, req *dto.JobTemplateRequest) error {
+	if _, err := compileStreamRules(req.Rules); err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrInvalidJobTemplate, err)
+	}
+	if req.SourceType != "" {
+		if err := dto.ValidateSourceType(req.SourceType); err != nil {
+			return fmt.Errorf("%w: %s", constants.ErrInvalidJobTemplate, err)
+		}
+	}
+
+	if template.ID == 0 || template.Name != req.Name {
+		unique, err := s.db.IsNameUniqueInProject(ctx, projectID, req.Name, constants.JobTemplateTable)
+		if err != nil {
+			return fmt.Errorf("failed to check job template name uniqueness: %s", err)
+		}
+		if !unique {
+			return fmt.Errorf("%w: '%s'", constants.ErrJobTemplateNameTaken, req.Name)
+		}
+	}
+
+	rules, err := json.Marshal(req.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode rules: %s", err)
+	}
+
+	template.ProjectID = projectID
+	template.Name = req.Name
+	template.Description = req.Description
+	template.SourceType = req.SourceType
+	template.Frequency = req.Frequency
+	template.Rules = string(rules)
+	return nil
+}
+
+func (s *ETLService) ListJobTemplates(_ context.Context, projectID string) ([]dto.JobTemplateResponse, error) {
+	templates, err := s.db.ListJobTemplatesByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.JobTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		response, err := buildJobTemplateResponse(template)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *ETLService) GetJobTemplate(_ context.Context, projectID string, id int) (*dto.JobTemplateResponse, error) {
+	template, err := s.getJobTemplate(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	response, err := buildJobTemplateResponse(template)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *ETLService) CreateJobTemplate(ctx context.Context, projectID string, req *dto.JobTemplateRequest, userID *int) (*dto.JobTemplateResponse, error) {
+	template := &models.JobTemplate{}
+	if err := s.applyJobTemplateRequest(ctx, projectID, template, req); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: *userID}
+	template.CreatedBy = user
+	template.UpdatedBy = user
+	if err := s.db.CreateJobTemplate(template); err != nil {
+		return nil, fmt.Errorf("failed to create job template: %s", err)
+	}
+
+	return s.GetJobTemplate(ctx, projectID, template.ID)
+}
+
+func (s *ETLService) UpdateJobTemplate(ctx context.Context, projectID string, id int, req *dto.JobTemplateRequest, userID *int) (*dto.JobTemplateResponse, error) {
+	template, err := s.getJobTemplate(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyJobTemplateRequest(ctx, projectID, template, req); err != nil {
+		return nil, err
+	}
+
+	template.UpdatedBy = &models.User{ID: *userID}
+	if err := s.db.UpdateJobTemplate(template); err != nil {
+		return nil, fmt.Errorf("failed to update job template: %s", err)
+	}
+
+	return s.GetJobTemplate(ctx, projectID, id)
+}
+
+func (s *ETLService) DeleteJobTemplate(_ context.Context, projectID string, id int) error {
+	if _, err := s.getJobTemplate(projectID, id); err != nil {
+		return err
+	}
+	if err := s.db.DeleteJobTemplate(id); err != nil {
+		return fmt.Errorf("failed to delete job template: %s", err)
+	}
+	return nil
+}
+
+// ApplyJobTemplate discovers a source and selects its streams by the rules of a template. With
+// req.Create the job is created from the result, using the template frequency unless the
+// request sets one.
+func (s *ETLService) ApplyJobTemplate(ctx context.Context, projectID string, id int, req *dto.ApplyJobTemplateRequest, userID *int) (*dto.ApplyJobTemplateResponse, error) {
+	template, err := s.getJobTemplate(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	frequency := template.Frequency
+	if req.Frequency != "" {
+		frequency = req.Frequency
+	}
+	if req.Create && (req.Name == "" || req.DestinationID == nil || frequency == "") {
+		return nil, fmt.Errorf("%w: name, destination_id and frequency are required to create a job", constants.ErrInvalidJobTemplate)
+	}
+
+	source, err := s.db.GetSourceByID(req.SourceID)
+	if err != nil || source.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: source %d not found in project", constants.ErrInvalidJobTemplate, req.SourceID)
+	}
+	if template.SourceType != "" && template.SourceType != source.Type {
+		return nil, fmt.Errorf("%w: template applies to %s sources, source %d is %s", constants.ErrInvalidJobTemplate, template.SourceType, source.ID, source.Type)
+	}
+
+	storedRules, err := templateRules(template)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := compileStreamRules(storedRules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrInvalidJobTemplate, err)
+	}
+
+	encryptedConfig, err := utils.EncryptForConnector(source.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt config for catalog: %s", err)
+	}
+	catalog, err := s.temporal.DiscoverStreams(ctx, source.Type, source.Version, encryptedConfig, "", req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get catalog: %s", err)
+	}
+
+	streamsConfig, selected, skipped, err := applyStreamRules(catalog, rules, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.ApplyJobTemplateResponse{
+		StreamsConfig:   streamsConfig,
+		SelectedStreams: selected,
+		SkippedStreams:  skipped,
+	}
+	if !req.Create {
+		return response, nil
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: the rules select no stream of source %d", constants.ErrInvalidJobTemplate, source.ID)
+	}
+	dest, err := s.db.GetDestinationByID(*req.DestinationID)
+	if err != nil || dest.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: destination %d not found in project", constants.ErrInvalidJobTemplate, *req.DestinationID)
+	}
+
+	job, err := s.createJob(ctx, &dto.CreateJobRequest{
+		Name:          req.Name,
+		Source:        &dto.DriverConfig{ID: &source.ID},
+		Destination:   &dto.DriverConfig{ID: &dest.ID},
+		Frequency:     frequency,
+		StreamsConfig: streamsConfig,
+	}, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("created job %d from job template %d with %d streams", job.ID, template.ID, len(selected))
+	response.JobID = job.ID
+	return response, nil
+}
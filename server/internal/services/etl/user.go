@@ -3,13 +3,26 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
+	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 )
 
 // User-related methods on AppService
 
+// CreateUser creates a user on behalf of an admin, who can set MustChangePassword so the
+// user picks their own password on the first login
 func (s *ETLService) CreateUser(_ context.Context, req *models.User) error {
+	hashedPassword, err := hashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+	req.Password = hashedPassword
+	req.FailedLoginAttempts = 0
+	req.LockedUntil = nil
+
 	if err := s.db.CreateUser(req); err != nil {
 		return fmt.Errorf("failed to create user: %s", err)
 	}
@@ -22,6 +35,10 @@ func (s *ETLService) GetAllUsers(_ context.Context) ([]*models.User, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %s", err)
 	}
+	// password hashes never leave the server
+	for _, user := range users {
+		user.Password = ""
+	}
 	return users, nil
 }
 
@@ -32,20 +49,103 @@ func (s *ETLService) UpdateUser(_ context.Context, id int, req *models.User) (*m
 	}
 
 	existingUser.Username = req.Username
+	if !strings.EqualFold(existingUser.Email, req.Email) {
+		// a new address has to be verified again
+		existingUser.EmailVerified = false
+		existingUser.EmailVerifiedAt = nil
+	}
 	existingUser.Email = req.Email
 
 	if err := s.db.UpdateUser(existingUser); err != nil {
 		return nil, fmt.Errorf("failed to update user: %s", err)
 	}
 
+	existingUser.Password = ""
 	return existingUser, nil
 }
 
-func (s *ETLService) DeleteUser(_ context.Context, id int) error {
+// DeleteUser removes a user who owns nothing. Users that still own sources, destinations or jobs
+// are disabled, or their ownership is transferred first.
+func (s *ETLService) DeleteUser(ctx context.Context, id int) error {
+	references, err := s.db.CountUserReferences(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %s", err)
+	}
+	if references > 0 {
+		return fmt.Errorf("%w: %d references, transfer ownership or disable the user instead", constants.ErrUserOwnsResources, references)
+	}
+
 	if err := s.db.DeleteUser(id); err != nil {
 		return fmt.Errorf("failed to delete user: %s", err)
 	}
+	if err := s.RevokeUserSessions(ctx, id, ""); err != nil {
+		return fmt.Errorf("user deleted but %s", err)
+	}
+	return nil
+}
+
+// DisableUser stops a user from logging in and ends their sessions, what they own stays as it is
+func (s *ETLService) DisableUser(ctx context.Context, actorID, id int) error {
+	if actorID == id {
+		return fmt.Errorf("%w: you cannot disable yourself", constants.ErrInvalidUserAction)
+	}
+	user, err := s.db.GetUserByID(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+	if user.Disabled {
+		return nil
+	}
+
+	if err := s.db.SetUserDisabled(id, true); err != nil {
+		return fmt.Errorf("failed to disable user: %s", err)
+	}
+	if err := s.RevokeUserSessions(ctx, id, ""); err != nil {
+		return fmt.Errorf("user disabled but %s", err)
+	}
 	return nil
 }
 
+// EnableUser lets a disabled user log in again
+func (s *ETLService) EnableUser(_ context.Context, id int) error {
+	if _, err := s.db.GetUserByID(id); err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+	if err := s.db.SetUserDisabled(id, false); err != nil {
+		return fmt.Errorf("failed to enable user: %s", err)
+	}
+	return nil
+}
+
+// TransferOwnership makes another user the creator and last updater of everything a user created
+// or updated, e.g. before the user leaves and is deleted
+func (s *ETLService) TransferOwnership(_ context.Context, fromID, toID int) (*dto.OwnershipTransferResponse, error) {
+	if fromID == toID {
+		return nil, fmt.Errorf("%w: cannot transfer ownership to the same user", constants.ErrInvalidUserAction)
+	}
+	if _, err := s.db.GetUserByID(fromID); err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+	target, err := s.db.GetUserByID(toID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrUserNotFound, err)
+	}
+	if target.Disabled {
+		return nil, fmt.Errorf("%w: cannot transfer ownership to a disabled user", constants.ErrInvalidUserAction)
+	}
+
+	changed, err := s.db.TransferUserOwnership(fromID, toID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer ownership: %s", err)
+	}
+	return &dto.OwnershipTransferResponse{
+		Sources:            changed[constants.SourceTable],
+		Destinations:       changed[constants.DestinationTable],
+		Jobs:               changed[constants.JobTable],
+		StateVersions:      changed[constants.JobStateVersionTable],
+		MaskingRules:       changed[constants.MaskingRuleTable],
+		MaintenanceWindows: changed[constants.MaintenanceWindowTable],
+	}, nil
+}
+
 // removed: duplicate of auth.GetUserByID
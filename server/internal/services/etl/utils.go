@@ -16,7 +16,6 @@ import (
 	"go.temporal.io/api/workflow/v1"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/converter"
-	"golang.org/x/mod/semver"
 )
 
 // JobLastRunInfo holds the latest run information for a job
@@ -26,6 +25,11 @@ type JobLastRunInfo struct {
 	LastRunType  string
 }
 
+// quoteQueryValue quotes a string for a Temporal visibility query, escaping backslashes and quotes
+func quoteQueryValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
 // fetchLatestJobRunsByJobIDs batches workflow queries for multiple jobs into a single/few temporal API calls
 func fetchLatestJobRunsByJobIDs(ctx context.Context, tempClient *temporal.Temporal, projectID string, jobs []*models.Job) (map[int]JobLastRunInfo, error) {
 	if len(jobs) == 0 {
@@ -94,8 +98,24 @@ func fetchLatestJobRunsByJobIDs(ctx context.Context, tempClient *temporal.Tempor
 }
 
 func cancelAllJobWorkflows(ctx context.Context, tempClient *temporal.Temporal, jobs []*models.Job, projectID string) error {
+	failures, err := cancelJobWorkflows(ctx, tempClient, jobs, projectID)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err, failed := failures[job.ID]; failed {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelJobWorkflows cancels the running workflows of jobs with a single list query and returns
+// the cancel errors by job ID. The error is set when the workflows could not be listed.
+func cancelJobWorkflows(ctx context.Context, tempClient *temporal.Temporal, jobs []*models.Job, projectID string) (map[int]error, error) {
+	failures := map[int]error{}
 	if len(jobs) == 0 {
-		return nil
+		return failures, nil
 	}
 
 	// Build combined query
@@ -114,20 +134,21 @@ func cancelAllJobWorkflows(ctx context.Context, tempClient *temporal.Temporal, j
 		Query: query,
 	})
 	if err != nil {
-		return fmt.Errorf("list workflows failed: %s", err)
-	}
-	if len(resp.Executions) == 0 {
-		return nil // no running workflows
+		return nil, fmt.Errorf("list workflows failed: %s", err)
 	}
 
 	// Cancel each found workflow (still a loop, but only one list RPC)
 	for _, wfExec := range resp.Executions {
+		jobID, _ := utils.ExtractJobIDFromWorkflowID(wfExec.Execution.WorkflowId, projectID)
+		if _, failed := failures[jobID]; failed {
+			continue
+		}
 		if err := tempClient.CancelWorkflow(ctx,
 			wfExec.Execution.WorkflowId, wfExec.Execution.RunId); err != nil {
-			return fmt.Errorf("failed to cancel workflow[%s]: %s", wfExec.Execution.WorkflowId, err)
+			failures[jobID] = fmt.Errorf("failed to cancel workflow[%s]: %s", wfExec.Execution.WorkflowId, err)
 		}
 	}
-	return nil
+	return failures, nil
 }
 
 func buildJobDataItems(jobs []*models.Job, lastRunByJobID map[int]JobLastRunInfo, contextType string) ([]dto.JobDataItem, error) {
@@ -246,12 +267,3 @@ func waitForSyncToStop(ctx context.Context, tempClient *temporal.Temporal, proje
 
 	return nil
 }
-
-// checks the version compatibility for clear-destination and stream difference operation
-// supported in versions >= v0.3.0
-func CheckClearDestinationCompatibility(sourceVersion string) error {
-	if semver.Compare(sourceVersion, constants.DefaultClearDestinationVersion) < 0 {
-		return fmt.Errorf("source version %s is not supported for clear destination. please update the source version to %s or higher", sourceVersion, constants.DefaultClearDestinationVersion)
-	}
-	return nil
-}
@@ -77,6 +77,7 @@ func (t *Temporal) CreateSchedule(ctx context.Context, job *models.Job) error {
 			Workflow:  RunSyncWorkflow,
 			Args:      []any{*req},
 			TaskQueue: t.taskQueue,
+			Memo:      labelsMemo(job.Labels),
 		},
 		Overlap: enums.SCHEDULE_OVERLAP_POLICY_SKIP,
 	})
@@ -98,13 +99,18 @@ func (t *Temporal) UpdateSchedule(ctx context.Context, frequency, projectID stri
 				}
 			}
 
-			// update schedule action
+			// update schedule action, keeping the labels memo
 			if args != nil {
+				var memo map[string]interface{}
+				if action, ok := input.Description.Schedule.Action.(*client.ScheduleWorkflowAction); ok {
+					memo = action.Memo
+				}
 				input.Description.Schedule.Action = &client.ScheduleWorkflowAction{
 					ID:        args.WorkflowID,
 					Workflow:  RunSyncWorkflow,
 					Args:      []any{*args},
 					TaskQueue: t.taskQueue,
+					Memo:      memo,
 				}
 			}
 
@@ -115,6 +121,26 @@ func (t *Temporal) UpdateSchedule(ctx context.Context, frequency, projectID stri
 	})
 }
 
+// UpdateScheduleLabels sets the labels memo of the runs a schedule starts, labels is the JSON
+// object stored on the job
+func (t *Temporal) UpdateScheduleLabels(ctx context.Context, projectID string, jobID int, labels string) error {
+	_, scheduleID := t.WorkflowAndScheduleID(projectID, jobID)
+
+	handle := t.Client.ScheduleClient().GetHandle(ctx, scheduleID)
+	return handle.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			action, ok := input.Description.Schedule.Action.(*client.ScheduleWorkflowAction)
+			if !ok {
+				return nil, fmt.Errorf("schedule %s has no workflow action", scheduleID)
+			}
+			action.Memo = labelsMemo(labels)
+			return &client.ScheduleUpdate{
+				Schedule: &input.Description.Schedule,
+			}, nil
+		},
+	})
+}
+
 func (t *Temporal) PauseSchedule(ctx context.Context, projectID string, jobID int) error {
 	_, scheduleID := t.WorkflowAndScheduleID(projectID, jobID)
 	return t.Client.ScheduleClient().GetHandle(ctx, scheduleID).Pause(ctx, client.SchedulePauseOptions{
@@ -129,6 +155,27 @@ func (t *Temporal) ResumeSchedule(ctx context.Context, projectID string, jobID i
 	})
 }
 
+// PauseScheduleWithNote pauses a schedule, the note tells who paused it
+func (t *Temporal) PauseScheduleWithNote(ctx context.Context, projectID string, jobID int, note string) error {
+	_, scheduleID := t.WorkflowAndScheduleID(projectID, jobID)
+	return t.Client.ScheduleClient().GetHandle(ctx, scheduleID).Pause(ctx, client.SchedulePauseOptions{
+		Note: note,
+	})
+}
+
+// SchedulePauseNote returns the note of a paused schedule, empty when it is not paused
+func (t *Temporal) SchedulePauseNote(ctx context.Context, projectID string, jobID int) (string, error) {
+	_, scheduleID := t.WorkflowAndScheduleID(projectID, jobID)
+	desc, err := t.Client.ScheduleClient().GetHandle(ctx, scheduleID).Describe(ctx)
+	if err != nil {
+		return "", err
+	}
+	if desc.Schedule.State == nil || !desc.Schedule.State.Paused {
+		return "", nil
+	}
+	return desc.Schedule.State.Note, nil
+}
+
 func (t *Temporal) DeleteSchedule(ctx context.Context, projectID string, jobID int) error {
 	_, scheduleID := t.WorkflowAndScheduleID(projectID, jobID)
 	return t.Client.ScheduleClient().GetHandle(ctx, scheduleID).Delete(ctx)
@@ -26,7 +26,24 @@ type ExecutionRequest struct {
 	Timeout       time.Duration `json:"timeout"`
 	OutputFile    string        `json:"output_file"` // to get the output file from the workflow
 
+	// TempPath is a streams config in storage that clear-destination and manual syncs run
+	// with instead of the job's streams config
 	TempPath string `json:"temp_path"`
+
+	// RowLimit and SampleSize are set for dry runs, the worker stops every stream after
+	// RowLimit rows and reports the first SampleSize records of each stream in OutputFile
+	RowLimit   int `json:"row_limit,omitempty"`
+	SampleSize int `json:"sample_size,omitempty"`
+
+	// Resources limits the CPU and memory of the connector container, unlimited when nil
+	Resources *ResourceLimits `json:"resources,omitempty"`
+}
+
+// ResourceLimits are the CPU and memory limits of a job, as Kubernetes quantities ("500m",
+// "2", "4Gi"). Empty values are not limited.
+type ResourceLimits struct {
+	CPU    string `json:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty"`
 }
 
 type JobConfig struct {
@@ -42,6 +59,7 @@ const (
 	Sync             Command = "sync"
 	Spec             Command = "spec"
 	ClearDestination Command = "clear-destination"
+	DryRun           Command = "dry-run"
 
 	RunSyncWorkflow = "RunSyncWorkflow"
 	ExecuteWorkflow = "ExecuteWorkflow"
@@ -74,7 +92,7 @@ func (t *Temporal) DiscoverStreams(ctx context.Context, sourceType, version, con
 		{Name: "user_id.txt", Data: telemetry.GetTelemetryUserID()},
 	}
 
-	if err := SetupConfigFiles(Discover, workflowID, configs); err != nil {
+	if err := SetupConfigFiles(ctx, Discover, workflowID, configs); err != nil {
 		return nil, fmt.Errorf("failed to setup config files: %s", err)
 	}
 
@@ -130,11 +148,6 @@ func (t *Temporal) DiscoverStreams(ctx context.Context, sourceType, version, con
 func (t *Temporal) GetDriverSpecs(ctx context.Context, destinationType, sourceType, version string) (dto.SpecOutput, error) {
 	workflowID := fmt.Sprintf("fetch-spec-%s-%d", sourceType, time.Now().Unix())
 
-	// spec version >= DefaultSpecVersion is required
-	if semver.Compare(version, constants.DefaultSpecVersion) < 0 {
-		version = constants.DefaultSpecVersion
-	}
-
 	cmdArgs := []string{
 		"spec",
 	}
@@ -180,7 +193,7 @@ func (t *Temporal) VerifyDriverCredentials(ctx context.Context, workflowID, flag
 		{Name: "config.json", Data: config},
 	}
 
-	if err := SetupConfigFiles(Check, workflowID, configs); err != nil {
+	if err := SetupConfigFiles(ctx, Check, workflowID, configs); err != nil {
 		return nil, fmt.Errorf("failed to setup config files: %s", err)
 	}
 
@@ -245,7 +258,7 @@ func (t *Temporal) ClearDestination(ctx context.Context, job *models.Job, stream
 	}
 
 	// update schedule to use clear-destination request
-	clearReq, err := buildExecutionReqForClearDestination(job, workflowID, streamsConfig)
+	clearReq, err := buildExecutionReqForClearDestination(ctx, job, workflowID, streamsConfig)
 	if err != nil {
 		return fmt.Errorf("failed to build execution request for clear-destination: %s", err)
 	}
@@ -257,8 +270,7 @@ func (t *Temporal) ClearDestination(ctx context.Context, job *models.Job, stream
 
 	if err := t.TriggerSchedule(ctx, job.ProjectID, job.ID); err != nil {
 		// revert back to sync
-		syncReq := buildExecutionReqForSync(job, workflowID)
-		if uerr := t.UpdateSchedule(ctx, job.Frequency, job.ProjectID, job.ID, syncReq); uerr != nil {
+		if uerr := t.RestoreSyncSchedule(ctx, job); uerr != nil {
 			return fmt.Errorf("trigger clear destination workflow failed: %s, revert to sync failed: %s", err, uerr)
 		}
 		return fmt.Errorf("failed to trigger clear destination workflow: %s", err)
@@ -275,7 +287,7 @@ func (t *Temporal) GetStreamDifference(ctx context.Context, job *models.Job, old
 		{Name: "new_streams.json", Data: newConfig},
 	}
 
-	if err := SetupConfigFiles(Discover, workflowID, configs); err != nil {
+	if err := SetupConfigFiles(ctx, Discover, workflowID, configs); err != nil {
 		return nil, fmt.Errorf("failed to setup config files: %s", err)
 	}
 
@@ -317,3 +329,116 @@ func (t *Temporal) GetStreamDifference(ctx context.Context, job *models.Job, old
 
 	return result, nil
 }
+
+// DryRunSync runs a sync of a job that reads at most rowLimit rows per stream into a scratch
+// Parquet writer in the workflow directory, instead of the job's destination. The job's state
+// is not read or written. The worker reports the rows read per stream in dry_run.json:
+//
+//	{"streams": {"namespace.stream": {"count": 100, "records": [{...}]}}}
+func (t *Temporal) DryRunSync(ctx context.Context, job *models.Job, version, sourceConfig, streamsConfig string, rowLimit, sampleSize int) (map[string]interface{}, error) {
+	if err := RequireWorkerFeatures(WorkerFeatureDryRun); err != nil {
+		return nil, err
+	}
+	destinationConfig, err := dryRunDestinationConfig()
+	if err != nil {
+		return nil, err
+	}
+	workflowID := fmt.Sprintf("dry-run-%s-%d-%d", job.ProjectID, job.ID, time.Now().Unix())
+
+	configs := []JobConfig{
+		{Name: "source.json", Data: sourceConfig},
+		{Name: "destination.json", Data: destinationConfig},
+		{Name: "streams.json", Data: streamsConfig},
+		{Name: "state.json", Data: "{}"},
+		{Name: "user_id.txt", Data: telemetry.GetTelemetryUserID()},
+	}
+
+	if err := SetupConfigFiles(ctx, DryRun, workflowID, configs); err != nil {
+		return nil, fmt.Errorf("failed to setup config files: %s", err)
+	}
+
+	req := buildExecutionReqForDryRun(job, workflowID, version, rowLimit, sampleSize)
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: t.taskQueue,
+	}
+
+	run, err := t.Client.ExecuteWorkflow(ctx, workflowOptions, ExecuteWorkflow, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute dry run workflow: %s", err)
+	}
+
+	result, err := ExtractWorkflowResponse(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract workflow response: %v", err)
+	}
+
+	return result, nil
+}
+
+// PreviewStream reads at most limit rows of the single stream selected in streamsConfig from a
+// source. It runs as a dry run without a job, the rows are reported in dry_run.json.
+func (t *Temporal) PreviewStream(ctx context.Context, source *models.Source, sourceConfig, streamsConfig string, limit int) (map[string]interface{}, error) {
+	if err := RequireWorkerFeatures(WorkerFeatureDryRun); err != nil {
+		return nil, err
+	}
+	destinationConfig, err := dryRunDestinationConfig()
+	if err != nil {
+		return nil, err
+	}
+	workflowID := fmt.Sprintf("preview-%s-%d-%d", source.ProjectID, source.ID, time.Now().UnixNano())
+
+	configs := []JobConfig{
+		{Name: "source.json", Data: sourceConfig},
+		{Name: "destination.json", Data: destinationConfig},
+		{Name: "streams.json", Data: streamsConfig},
+		{Name: "state.json", Data: "{}"},
+		{Name: "user_id.txt", Data: telemetry.GetTelemetryUserID()},
+	}
+
+	if err := SetupConfigFiles(ctx, DryRun, workflowID, configs); err != nil {
+		return nil, fmt.Errorf("failed to setup config files: %s", err)
+	}
+
+	req := buildExecutionReqForPreview(source, workflowID, limit)
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: t.taskQueue,
+	}
+
+	run, err := t.Client.ExecuteWorkflow(ctx, workflowOptions, ExecuteWorkflow, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute preview workflow: %s", err)
+	}
+
+	result, err := ExtractWorkflowResponse(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract workflow response: %v", err)
+	}
+
+	return result, nil
+}
+
+// ManualSync starts a one-off sync of a job with the given streams config, without waiting
+// for it. It runs the sync workflow the schedule runs, the job's saved config and its
+// schedule are not changed.
+func (t *Temporal) ManualSync(ctx context.Context, job *models.Job, workflowID, streamsConfig string) (client.WorkflowRun, error) {
+	req, err := buildExecutionReqForManualSync(ctx, job, workflowID, streamsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build execution request for manual sync: %s", err)
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: t.taskQueue,
+		Memo:      labelsMemo(job.Labels),
+	}
+
+	run, err := t.Client.ExecuteWorkflow(ctx, workflowOptions, RunSyncWorkflow, *req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute manual sync workflow: %s", err)
+	}
+	return run, nil
+}
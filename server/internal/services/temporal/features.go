@@ -0,0 +1,58 @@
+package temporal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// Worker features are parts of the worker contract that older workers do not implement. The
+// operator lists the features of the deployed worker in WORKER_FEATURES, and the server refuses
+// to use a feature the worker does not declare, rather than starting runs the worker would fail
+// or silently run differently.
+const (
+	// WorkerFeatureDryRun runs the dry-run command: a sync that stops every stream after
+	// RowLimit rows and reports the first SampleSize records of each stream in OutputFile
+	WorkerFeatureDryRun = "dry-run"
+	// WorkerFeatureSyncAdmission asks /internal/worker/callback/sync-admission for a slot before
+	// every sync, retries after RetryAfter seconds while it is queued, and calls
+	// /internal/worker/callback/sync-release when the sync ends
+	WorkerFeatureSyncAdmission = "sync-admission"
+	// WorkerFeatureResourceLimits applies the Resources of an ExecutionRequest to the connector
+	// container
+	WorkerFeatureResourceLimits = "resource-limits"
+	// WorkerFeatureS3Storage reads the configs of a run from the STORAGE_BACKEND=s3 bucket and
+	// uploads the logs, state and outputs of the run to it, instead of the shared config volume
+	WorkerFeatureS3Storage = "s3-storage"
+	// WorkerFeatureSecretRefs resolves the {"$secret": ...} references of the stored source and
+	// destination configs of syncs and clear-destination runs right before starting the connector
+	WorkerFeatureSecretRefs = "secret-refs"
+	// WorkerFeatureSecretFields decrypts the {"$encrypted": ...} secret fields of the stored source
+	// and destination configs of syncs and clear-destination runs before starting the connector
+	WorkerFeatureSecretFields = "secret-fields"
+	// WorkerFeatureManualSync runs a sync with the streams config at the TempPath of its
+	// ExecutionRequest instead of the job's, for one-off syncs of some streams of a job
+	WorkerFeatureManualSync = "manual-sync"
+)
+
+// WorkerSupports reports whether the worker declares a feature in WORKER_FEATURES
+func WorkerSupports(feature string) bool {
+	features, _ := web.AppConfig.String(constants.ConfWorkerFeatures)
+	for _, declared := range strings.Split(features, ",") {
+		if strings.EqualFold(strings.TrimSpace(declared), feature) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireWorkerFeatures returns an error naming the first feature the worker does not declare
+func RequireWorkerFeatures(features ...string) error {
+	if i := slices.IndexFunc(features, func(feature string) bool { return !WorkerSupports(feature) }); i >= 0 {
+		return fmt.Errorf("%w: '%s', add it to WORKER_FEATURES once the worker implements it", constants.ErrWorkerFeatureUnsupported, features[i])
+	}
+	return nil
+}
@@ -1,18 +1,22 @@
 package temporal
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"slices"
 
-	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
 )
 
 var (
 	AsyncCommands = []Command{Sync, ClearDestination}
+	// connectorConfigFiles are the files that hold connector configs and may reference secrets
+	connectorConfigFiles = []string{"config.json", "source.json", "destination.json"}
 )
 
 // getWorkflowDirectory determines the directory name based on operation and workflow ID
@@ -23,29 +27,19 @@ func getWorkflowDirectory(operation Command, originalWorkflowID string) string {
 	return originalWorkflowID
 }
 
-// createDirectory creates a directory with the specified permissions if it doesn't exist
-func createDirectory(dirPath string, perm os.FileMode) error {
-	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(dirPath, perm); err != nil {
-			return fmt.Errorf("failed to create directory %s: %s", dirPath, err)
-		}
-	}
-	return nil
-}
-
 // writeConfigFiles writes the config files to the work directory
-func writeConfigFiles(workDir string, configs []JobConfig) error {
+func writeConfigFiles(ctx context.Context, workDir string, configs []JobConfig) error {
 	for _, config := range configs {
-		filePath := filepath.Join(workDir, config.Name)
-		if err := os.WriteFile(filePath, []byte(config.Data), 0644); err != nil {
+		if err := storage.Get().Write(ctx, path.Join(workDir, config.Name), []byte(config.Data)); err != nil {
 			return fmt.Errorf("failed to write %s: %s", config.Name, err)
 		}
 	}
 	return nil
 }
 
-func ReadJSONFile(filePath string) (map[string]interface{}, error) {
-	fileOutput, err := os.ReadFile(filePath)
+// ReadJSONFile reads a JSON file from the storage backend, filePath is relative to the config directory
+func ReadJSONFile(ctx context.Context, filePath string) (map[string]interface{}, error) {
+	fileOutput, err := storage.Get().Read(ctx, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %v", err)
 	}
@@ -57,17 +51,52 @@ func ReadJSONFile(filePath string) (map[string]interface{}, error) {
 	return result, nil
 }
 
-// SetupConfigFiles creates the work directory and writes the config files to it
-// It writes to the mounted path and can be accessed by the worker.
-func SetupConfigFiles(cmd Command, workflowID string, configs []JobConfig) error {
-	subDir := getWorkflowDirectory(cmd, workflowID)
-	workDir := filepath.Join(constants.DefaultConfigDir, subDir)
+// resolveSecretReferences decrypts the secret fields of an encrypted connector config that are
+// encrypted on their own and replaces its secret references with the secrets they point to,
+// then encrypts it again for the connector
+func resolveSecretReferences(ctx context.Context, config string) (string, error) {
+	decrypted, err := utils.Decrypt(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt config: %s", err)
+	}
+	fieldsDecrypted, err := utils.DecryptSecretFields(decrypted)
+	if err != nil {
+		return "", err
+	}
+	if !secretref.HasReferences(fieldsDecrypted) {
+		if fieldsDecrypted == decrypted {
+			return config, nil
+		}
+		return utils.EncryptForConnector(fieldsDecrypted)
+	}
+
+	resolved, err := secretref.ResolveConfig(ctx, fieldsDecrypted)
+	if err != nil {
+		return "", err
+	}
+	return utils.EncryptForConnector(resolved)
+}
+
+// SetupConfigFiles writes the config files to the work directory of the workflow
+// It writes to the configured storage backend and can be accessed by the worker.
+// Secret fields encrypted on their own are decrypted and secret references resolved here,
+// just before the connector reads them, so resolved secrets are never stored in the database.
+func SetupConfigFiles(ctx context.Context, cmd Command, workflowID string, configs []JobConfig) error {
+	workDir := getWorkflowDirectory(cmd, workflowID)
 
-	if err := createDirectory(workDir, 0755); err != nil {
-		return fmt.Errorf("failed to create work directory %s: %s", workDir, err)
+	resolved := make([]JobConfig, 0, len(configs))
+	for _, config := range configs {
+		if slices.Contains(connectorConfigFiles, config.Name) && config.Data != "" {
+			data, err := resolveSecretReferences(ctx, config.Data)
+			if err != nil {
+				return fmt.Errorf("failed to resolve secret references in %s: %s", config.Name, err)
+			}
+			config.Data = data
+		}
+		resolved = append(resolved, config)
 	}
 
-	if err := writeConfigFiles(workDir, configs); err != nil {
+	if err := writeConfigFiles(ctx, workDir, resolved); err != nil {
 		return fmt.Errorf("failed to write config files: %s", err)
 	}
 
@@ -0,0 +1,119 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/failure/v1"
+)
+
+// ActivityEvent is one step of a workflow run as the worker reported it to Temporal: the run
+// starting and ending, and every activity being scheduled, attempted, retried and finished.
+// Level is "info", "warn" or "error", like connector log levels.
+type ActivityEvent struct {
+	Time    time.Time
+	Level   string
+	Message string
+}
+
+// WorkflowActivityEvents reads the activity events of the latest run of a workflow from its
+// history. Temporal records the start of an activity once its last attempt ends, so activities
+// that are still running are read from the pending activities of the run.
+func (t *Temporal) WorkflowActivityEvents(ctx context.Context, workflowID string) ([]ActivityEvent, error) {
+	activityNames := make(map[int64]string) // by scheduled event ID
+	var events []ActivityEvent
+	add := func(at time.Time, level, format string, args ...any) {
+		events = append(events, ActivityEvent{Time: at.UTC(), Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+
+	iter := t.Client.GetWorkflowHistory(ctx, workflowID, "", false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	running := true
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workflow history: %s", err)
+		}
+		at := event.GetEventTime().AsTime()
+
+		switch event.GetEventType() {
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:
+			attrs := event.GetWorkflowExecutionStartedEventAttributes()
+			add(at, "info", "workflow %s started on task queue %s", attrs.GetWorkflowType().GetName(), attrs.GetTaskQueue().GetName())
+		case enums.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED:
+			attrs := event.GetActivityTaskScheduledEventAttributes()
+			activityNames[event.GetEventId()] = attrs.GetActivityType().GetName()
+			add(at, "info", "activity %s scheduled", attrs.GetActivityType().GetName())
+		case enums.EVENT_TYPE_ACTIVITY_TASK_STARTED:
+			attrs := event.GetActivityTaskStartedEventAttributes()
+			name := activityNames[attrs.GetScheduledEventId()]
+			if attrs.GetAttempt() > 1 {
+				add(at, "warn", "activity %s retried %d times, last failure: %s", name, attrs.GetAttempt()-1, failureMessage(attrs.GetLastFailure()))
+			}
+			add(at, "info", "activity %s started attempt %d on %s", name, attrs.GetAttempt(), attrs.GetIdentity())
+		case enums.EVENT_TYPE_ACTIVITY_TASK_COMPLETED:
+			attrs := event.GetActivityTaskCompletedEventAttributes()
+			add(at, "info", "activity %s completed", activityNames[attrs.GetScheduledEventId()])
+		case enums.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+			attrs := event.GetActivityTaskFailedEventAttributes()
+			add(at, "error", "activity %s failed: %s", activityNames[attrs.GetScheduledEventId()], failureMessage(attrs.GetFailure()))
+		case enums.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+			attrs := event.GetActivityTaskTimedOutEventAttributes()
+			add(at, "error", "activity %s timed out: %s", activityNames[attrs.GetScheduledEventId()], failureMessage(attrs.GetFailure()))
+		case enums.EVENT_TYPE_ACTIVITY_TASK_CANCELED:
+			attrs := event.GetActivityTaskCanceledEventAttributes()
+			add(at, "warn", "activity %s cancelled", activityNames[attrs.GetScheduledEventId()])
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+			running = false
+			add(at, "info", "workflow completed")
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+			running = false
+			add(at, "error", "workflow failed: %s", failureMessage(event.GetWorkflowExecutionFailedEventAttributes().GetFailure()))
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+			running = false
+			add(at, "error", "workflow timed out")
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:
+			running = false
+			add(at, "warn", "workflow cancelled")
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
+			running = false
+			add(at, "warn", "workflow terminated: %s", event.GetWorkflowExecutionTerminatedEventAttributes().GetReason())
+		case enums.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+			running = false
+			add(at, "info", "workflow continued as a new run")
+		}
+	}
+	if !running {
+		return events, nil
+	}
+
+	desc, err := t.Client.DescribeWorkflowExecution(ctx, workflowID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe workflow: %s", err)
+	}
+	for _, pending := range desc.GetPendingActivities() {
+		name := pending.GetActivityType().GetName()
+		if pending.GetAttempt() > 1 && pending.GetLastFailure() != nil {
+			add(pending.GetLastAttemptCompleteTime().AsTime(), "warn", "activity %s attempt %d failed: %s", name, pending.GetAttempt()-1, failureMessage(pending.GetLastFailure()))
+		}
+		if pending.GetState() == enums.PENDING_ACTIVITY_STATE_STARTED {
+			add(pending.GetLastStartedTime().AsTime(), "info", "activity %s running attempt %d on %s", name, pending.GetAttempt(), pending.GetLastWorkerIdentity())
+		} else {
+			add(pending.GetNextAttemptScheduleTime().AsTime(), "info", "activity %s waiting for attempt %d", name, pending.GetAttempt())
+		}
+	}
+	return events, nil
+}
+
+// failureMessage joins the message of a failure with the messages of its causes
+func failureMessage(f *failure.Failure) string {
+	if f == nil {
+		return "unknown failure"
+	}
+	message := f.GetMessage()
+	for cause := f.GetCause(); cause != nil; cause = cause.GetCause() {
+		message = fmt.Sprintf("%s: %s", message, cause.GetMessage())
+	}
+	return message
+}
@@ -2,14 +2,20 @@ package temporal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"path/filepath"
+	"path"
+	"strings"
 	"time"
 
+	"github.com/beego/beego/v2/server/web"
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models"
 	"github.com/datazip-inc/olake-ui/server/utils"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
+	"go.temporal.io/api/workflow/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 )
 
 // buildExecutionReqForSync builds the ExecutionRequest for a sync job
@@ -33,21 +39,106 @@ func buildExecutionReqForSync(job *models.Job, workflowID string) *ExecutionRequ
 		ProjectID:     job.ProjectID,
 		Timeout:       GetWorkflowTimeout(Sync),
 		OutputFile:    "state.json",
+		Resources:     jobResources(job),
+	}
+}
+
+// jobResources returns the resource limits of a job, nil when it has none
+func jobResources(job *models.Job) *ResourceLimits {
+	if job.CPULimit == "" && job.MemoryLimit == "" {
+		return nil
+	}
+	return &ResourceLimits{CPU: job.CPULimit, Memory: job.MemoryLimit}
+}
+
+// dryRunDestination writes the rows of a dry run to the workflow directory, which the worker
+// removes with the rest of the run
+const dryRunDestination = `{"type": "PARQUET", "writer": {"local_path": "/mnt/config/dry-run"}}`
+
+// dryRunDestinationConfig returns the dry run destination encrypted like stored configs, the
+// connector decrypts it with --encryption-key and secret references are looked for in it
+func dryRunDestinationConfig() (string, error) {
+	config, err := utils.EncryptForConnector(dryRunDestination)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt dry run destination: %s", err)
+	}
+	return config, nil
+}
+
+// buildExecutionReqForDryRun builds the ExecutionRequest for a dry run of a sync job with the
+// given source connector version
+func buildExecutionReqForDryRun(job *models.Job, workflowID, version string, rowLimit, sampleSize int) *ExecutionRequest {
+	args := []string{
+		"sync",
+		"--config", "/mnt/config/source.json",
+		"--destination", "/mnt/config/destination.json",
+		"--catalog", "/mnt/config/streams.json",
+		"--state", "/mnt/config/state.json",
+	}
+	if encryptionKey, _ := web.AppConfig.String(constants.ConfEncryptionKey); encryptionKey != "" {
+		args = append(args, "--encryption-key", encryptionKey)
+	}
+
+	return &ExecutionRequest{
+		Command:       DryRun,
+		ConnectorType: job.SourceID.Type,
+		Version:       version,
+		Args:          args,
+		Configs:       nil,
+		WorkflowID:    workflowID,
+		ProjectID:     job.ProjectID,
+		JobID:         job.ID,
+		Timeout:       GetWorkflowTimeout(DryRun),
+		OutputFile:    "dry_run.json",
+		RowLimit:      rowLimit,
+		SampleSize:    sampleSize,
+		Resources:     jobResources(job),
+	}
+}
+
+// previewTimeout bounds a stream preview, which reads a handful of rows of a single stream
+const previewTimeout = 5 * time.Minute
+
+// buildExecutionReqForPreview builds the ExecutionRequest for a preview of a source stream. A
+// preview is a dry run of a single stream without a job, every row read is returned.
+func buildExecutionReqForPreview(source *models.Source, workflowID string, limit int) *ExecutionRequest {
+	args := []string{
+		"sync",
+		"--config", "/mnt/config/source.json",
+		"--destination", "/mnt/config/destination.json",
+		"--catalog", "/mnt/config/streams.json",
+		"--state", "/mnt/config/state.json",
+	}
+	if encryptionKey, _ := web.AppConfig.String(constants.ConfEncryptionKey); encryptionKey != "" {
+		args = append(args, "--encryption-key", encryptionKey)
+	}
+
+	return &ExecutionRequest{
+		Command:       DryRun,
+		ConnectorType: source.Type,
+		Version:       source.Version,
+		Args:          args,
+		Configs:       nil,
+		WorkflowID:    workflowID,
+		ProjectID:     source.ProjectID,
+		Timeout:       previewTimeout,
+		OutputFile:    "dry_run.json",
+		RowLimit:      limit,
+		SampleSize:    limit,
 	}
 }
 
 // buildExecutionReqForClearDestination builds the ExecutionRequest for a clear-destination job
-func buildExecutionReqForClearDestination(job *models.Job, workflowID, streamsConfig string) (*ExecutionRequest, error) {
+func buildExecutionReqForClearDestination(ctx context.Context, job *models.Job, workflowID, streamsConfig string) (*ExecutionRequest, error) {
 	catalog := streamsConfig
 	if catalog == "" {
 		catalog = job.StreamsConfig
 	}
 
 	streamsDir := fmt.Sprintf("%s-%d", workflowID, time.Now().Unix())
-	relativePath := filepath.Join(streamsDir, "streams.json")
-	streamsPath := filepath.Join(constants.DefaultConfigDir, relativePath)
+	relativePath := path.Join(streamsDir, "streams.json")
 
-	if err := utils.WriteFile(streamsPath, []byte(catalog), 0644); err != nil {
+	if err := storage.Get().Write(ctx, relativePath, []byte(catalog)); err != nil {
 		return nil, fmt.Errorf("failed to write streams config to file: %v", err)
 	}
 
@@ -70,9 +161,39 @@ func buildExecutionReqForClearDestination(job *models.Job, workflowID, streamsCo
 		Timeout:       GetWorkflowTimeout(ClearDestination),
 		OutputFile:    "state.json",
 		TempPath:      relativePath,
+		Resources:     jobResources(job),
 	}, nil
 }
 
+// ManualSyncWorkflowID returns the ID of a manual sync of a job. It sorts between the IDs of
+// the job's scheduled runs, so job history and running sync checks include manual syncs.
+func ManualSyncWorkflowID(projectID string, jobID int) string {
+	return fmt.Sprintf("sync-%s-%d-manual-%d", projectID, jobID, time.Now().Unix())
+}
+
+// IsManualSyncWorkflowID reports whether a workflow ID is the ID of a manual sync
+func IsManualSyncWorkflowID(workflowID string) bool {
+	return strings.HasPrefix(workflowID, "sync-") && strings.Contains(workflowID, "-manual-")
+}
+
+// ManualSyncStreamsPath is where the streams config of a manual sync is written
+func ManualSyncStreamsPath(workflowID string) string {
+	return path.Join(workflowID, "streams.json")
+}
+
+// buildExecutionReqForManualSync builds the ExecutionRequest for a one-off sync of a job
+// with its own streams config, written to storage as the worker reads it from TempPath
+func buildExecutionReqForManualSync(ctx context.Context, job *models.Job, workflowID, streamsConfig string) (*ExecutionRequest, error) {
+	relativePath := ManualSyncStreamsPath(workflowID)
+	if err := storage.Get().Write(ctx, relativePath, []byte(streamsConfig)); err != nil {
+		return nil, fmt.Errorf("failed to write streams config to file: %v", err)
+	}
+
+	req := buildExecutionReqForSync(job, workflowID)
+	req.TempPath = relativePath
+	return req, nil
+}
+
 // extractWorkflowResponse extracts and parses the JSON response from a workflow execution result
 func ExtractWorkflowResponse(ctx context.Context, run client.WorkflowRun) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
@@ -86,8 +207,7 @@ func ExtractWorkflowResponse(ctx context.Context, run client.WorkflowRun) (map[s
 		return nil, fmt.Errorf("invalid response format from worker")
 	}
 
-	responsePath := filepath.Join(constants.DefaultConfigDir, response)
-	workflowResponse, err := ReadJSONFile(responsePath)
+	workflowResponse, err := ReadJSONFile(ctx, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read workflow response: %v", err)
 	}
@@ -107,8 +227,35 @@ func GetWorkflowTimeout(op Command) time.Duration {
 		return time.Hour * 24 * 30
 	case ClearDestination:
 		return time.Hour * 24 * 30
+	case DryRun:
+		return time.Minute * 30
 	// check what can the fallback time be
 	default:
 		return time.Minute * 5
 	}
 }
+
+// LabelsMemoKey is the memo key holding the labels of a job on its schedule and sync runs
+const LabelsMemoKey = "labels"
+
+// labelsMemo returns the memo carrying the labels of a job (a JSON object), nil without labels
+func labelsMemo(labels string) map[string]interface{} {
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(labels), &decoded); err != nil || len(decoded) == 0 {
+		return nil
+	}
+	return map[string]interface{}{LabelsMemoKey: decoded}
+}
+
+// RunLabels returns the labels a sync run was started with, from its memo
+func RunLabels(execution *workflow.WorkflowExecutionInfo) map[string]string {
+	payload, ok := execution.GetMemo().GetFields()[LabelsMemoKey]
+	if !ok {
+		return nil
+	}
+	var labels map[string]string
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &labels); err != nil {
+		return nil
+	}
+	return labels
+}
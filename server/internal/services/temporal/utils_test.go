@@ -0,0 +1,24 @@
+package temporal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/models"
+)
+
+func TestSyncRequestKeepsSecretReferences(t *testing.T) {
+	job := &models.Job{
+		ID:        7,
+		ProjectID: "123",
+		SourceID:  &models.Source{Type: "mysql", Version: "v0.2.0", Config: `{"password": {"$secret": "env://OLAKE_REF_MYSQL_PASSWORD"}}`},
+		DestID:    &models.Destination{Config: `{"type": "PARQUET"}`},
+	}
+
+	// the worker reads and resolves the stored configs, nothing resolved is kept in the schedule
+	req := buildExecutionReqForSync(job, "sync-123-7")
+	require.Empty(t, req.Configs)
+	require.Equal(t, Sync, req.Command)
+	require.Equal(t, "v0.2.0", req.Version)
+}
@@ -1,20 +1,35 @@
 package main
 
 import (
+	"context"
+
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/database"
 	"github.com/datazip-inc/olake-ui/server/internal/handlers"
 	services "github.com/datazip-inc/olake-ui/server/internal/services/etl"
+	"github.com/datazip-inc/olake-ui/server/internal/services/temporal"
 	"github.com/datazip-inc/olake-ui/server/routes"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
 	"github.com/datazip-inc/olake-ui/server/utils/telemetry"
 )
 
 func main() {
 	constants.Init()
 	logger.Init()
+	if storage.Backend() == "s3" {
+		// the worker would keep using the shared volume and every run would miss its configs
+		if err := temporal.RequireWorkerFeatures(temporal.WorkerFeatureS3Storage); err != nil {
+			logger.Fatalf("Failed to initialize storage: %s", err)
+			return
+		}
+	}
+	if err := storage.Init(); err != nil {
+		logger.Fatalf("Failed to initialize storage: %s", err)
+		return
+	}
 	db, err := database.Init()
 	if err != nil {
 		logger.Fatalf("Failed to initialize database: %s", err)
@@ -28,6 +43,14 @@ func main() {
 		return
 	}
 	logger.Info("Application services initialized successfully")
+	appSvc.StartLogJanitor(context.Background())
+	appSvc.StartStreamRediscovery(context.Background())
+	appSvc.StartSchemaDriftChecks(context.Background())
+	appSvc.StartConnectorRegistry(context.Background())
+	appSvc.StartMaintenanceWindows(context.Background())
+	appSvc.StartSyncQueue(context.Background())
+	appSvc.RecoverConnectorUpgrades()
+	appSvc.RecoverManualSyncs()
 	telemetry.InitTelemetry(db)
 
 	routes.Init(handlers.NewHandler(appSvc))
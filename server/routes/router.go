@@ -45,18 +45,41 @@ func Init(h *handlers.Handler) {
 	}
 
 	// Apply auth middleware to protected routes
-	web.InsertFilter("/api/v1/*", web.BeforeRouter, middleware.AuthMiddleware)
+	web.InsertFilter("/api/v1/*", web.BeforeRouter, middleware.AuthMiddleware(h.SessionValidator()))
+	web.InsertFilter("/login", web.BeforeRouter, middleware.LoginRateLimit)
 	// Auth routes
 	web.Router("/login", h, "post:Login")
+	web.Router("/logout", h, "post:Logout")
 	web.Router("/signup", h, "post:Signup")
 	web.Router("/auth/check", h, "get:CheckAuth")
 	web.Router("/telemetry-id", h, "get:GetTelemetryID")
+	web.Router("/invites/info", h, "get:GetInviteInfo")
+	web.Router("/invites/accept", h, "post:AcceptInvite")
+	web.Router("/verify-email", h, "post:VerifyEmail")
+	web.Router("/api/v1/auth/password", h, "put:ChangePassword")
+	web.Router("/api/v1/auth/settings", h, "get:GetAuthSettings")
+	web.Router("/api/v1/auth/settings", h, "put:UpdateAuthSettings")
+	web.Router("/api/v1/auth/sessions", h, "get:ListSessions")
+	web.Router("/api/v1/auth/sessions", h, "delete:RevokeOtherSessions")
+	web.Router("/api/v1/auth/sessions/:id", h, "delete:RevokeSession")
+	web.Router("/api/v1/auth/verify-email", h, "post:SendEmailVerification")
 
 	// User routes
 	web.Router("/api/v1/users", h, "post:CreateUser")
 	web.Router("/api/v1/users", h, "get:GetAllUsers")
 	web.Router("/api/v1/users/:id", h, "put:UpdateUser")
 	web.Router("/api/v1/users/:id", h, "delete:DeleteUser")
+	web.Router("/api/v1/users/:id/reset-password", h, "post:ResetUserPassword")
+	web.Router("/api/v1/users/:id/unlock", h, "post:UnlockUser")
+	web.Router("/api/v1/users/:id/sessions", h, "delete:RevokeUserSessions")
+	web.Router("/api/v1/users/:id/disable", h, "post:DisableUser")
+	web.Router("/api/v1/users/:id/enable", h, "post:EnableUser")
+	web.Router("/api/v1/users/:id/transfer-ownership", h, "post:TransferOwnership")
+
+	// Invite routes
+	web.Router("/api/v1/invites", h, "post:CreateInvite")
+	web.Router("/api/v1/invites", h, "get:ListInvites")
+	web.Router("/api/v1/invites/:id", h, "delete:RevokeInvite")
 
 	// Source routes
 	web.Router("/api/v1/project/:projectid/sources", h, "get:ListSources")
@@ -64,10 +87,13 @@ func Init(h *handlers.Handler) {
 	web.Router("/api/v1/project/:projectid/sources/:id", h, "get:GetSource")
 	web.Router("/api/v1/project/:projectid/sources/:id", h, "put:UpdateSource")
 	web.Router("/api/v1/project/:projectid/sources/:id", h, "delete:DeleteSource")
+	web.Router("/api/v1/project/:projectid/sources/:id/labels", h, "put:SetSourceLabels")
 	web.Router("/api/v1/project/:projectid/sources/test", h, "post:TestSourceConnection")
 	web.Router("/api/v1/project/:projectid/sources/streams", h, "post:GetSourceCatalog")
 	web.Router("/api/v1/project/:projectid/sources/versions", h, "get:GetSourceVersions")
 	web.Router("/api/v1/project/:projectid/sources/spec", h, "post:GetSourceSpec")
+	web.Router("/api/v1/project/:projectid/sources/:id/catalog-cache", h, "delete:InvalidateSourceCatalogCache")
+	web.Router("/api/v1/project/:projectid/sources/:id/streams/preview", h, "get:PreviewSourceStream")
 
 	// Destination routes
 	web.Router("/api/v1/project/:projectid/destinations", h, "get:ListDestinations")
@@ -75,6 +101,7 @@ func Init(h *handlers.Handler) {
 	web.Router("/api/v1/project/:projectid/destinations/:id", h, "get:GetDestination")
 	web.Router("/api/v1/project/:projectid/destinations/:id", h, "put:UpdateDestination")
 	web.Router("/api/v1/project/:projectid/destinations/:id", h, "delete:DeleteDestination")
+	web.Router("/api/v1/project/:projectid/destinations/:id/labels", h, "put:SetDestinationLabels")
 	web.Router("/api/v1/project/:projectid/destinations/test", h, "post:TestDestinationConnection")
 	web.Router("/api/v1/project/:projectid/destinations/versions", h, "get:GetDestinationVersions")
 	web.Router("/api/v1/project/:projectid/destinations/spec", h, "post:GetDestinationSpec")
@@ -82,11 +109,15 @@ func Init(h *handlers.Handler) {
 	// Job routes
 	web.Router("/api/v1/project/:projectid/jobs", h, "get:ListJobs")
 	web.Router("/api/v1/project/:projectid/jobs", h, "post:CreateJob")
+	web.Router("/api/v1/project/:projectid/jobs/bulk", h, "post:BulkJobAction")
+	web.Router("/api/v1/project/:projectid/sync-queue", h, "get:GetSyncQueue")
 	web.Router("/api/v1/project/:projectid/jobs/:id", h, "get:GetJob")
 	web.Router("/api/v1/project/:projectid/jobs/:id", h, "put:UpdateJob")
 	web.Router("/api/v1/project/:projectid/jobs/:id", h, "delete:DeleteJob")
 	web.Router("/api/v1/project/:projectid/jobs/:id/sync", h, "post:SyncJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/sync/streams", h, "post:SyncJobStreams")
 	web.Router("/api/v1/project/:projectid/jobs/:id/activate", h, "post:ActivateJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/labels", h, "put:SetJobLabels")
 	web.Router("/api/v1/project/:projectid/jobs/:id/tasks", h, "get:GetJobTasks")
 	web.Router("/api/v1/project/:projectid/jobs/:id/cancel", h, "get:CancelJobRun")
 	web.Router("/api/v1/project/:projectid/jobs/:id/tasks/:taskid/logs", h, "post:GetTaskLogs")
@@ -94,16 +125,90 @@ func Init(h *handlers.Handler) {
 	web.Router("/api/v1/project/:projectid/jobs/:id/clear-destination", h, "post:ClearDestination")
 	web.Router("/api/v1/project/:projectid/jobs/:id/clear-destination", h, "get:GetClearDestinationStatus")
 	web.Router("/api/v1/project/:projectid/jobs/:id/stream-difference", h, "post:GetStreamDifference")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state", h, "get:GetJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state", h, "put:UpdateJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state/reset", h, "post:ResetJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state/restore", h, "post:RestoreJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state/versions", h, "get:ListJobStateVersions")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state/versions/:version", h, "get:GetJobStateVersion")
+	web.Router("/api/v1/project/:projectid/jobs/:id/clone", h, "post:CloneJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/dry-run", h, "post:DryRunJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/stream-rules", h, "get:GetJobStreamRules")
+	web.Router("/api/v1/project/:projectid/jobs/:id/stream-rules", h, "put:SetJobStreamRules")
+	web.Router("/api/v1/project/:projectid/jobs/:id/stream-rules", h, "delete:DeleteJobStreamRules")
+	web.Router("/api/v1/project/:projectid/jobs/:id/stream-rules/rediscover", h, "post:RediscoverJobStreams")
+	web.Router("/api/v1/project/:projectid/jobs/:id/drift-policy", h, "get:GetJobDriftPolicy")
+	web.Router("/api/v1/project/:projectid/jobs/:id/drift-policy", h, "put:SetJobDriftPolicy")
+	web.Router("/api/v1/project/:projectid/jobs/:id/schema-check", h, "post:CheckJobSchema")
+	web.Router("/api/v1/project/:projectid/stream-reviews", h, "get:ListStreamReviews")
+	web.Router("/api/v1/project/:projectid/stream-reviews/:id", h, "get:GetStreamReview")
+	web.Router("/api/v1/project/:projectid/stream-reviews/:id/approve", h, "post:ApproveStreamReview")
+	web.Router("/api/v1/project/:projectid/stream-reviews/:id/reject", h, "post:RejectStreamReview")
+	web.Router("/api/v1/project/:projectid/schema-drifts", h, "get:ListSchemaDrifts")
+	web.Router("/api/v1/project/:projectid/schema-drifts/:id", h, "get:GetSchemaDrift")
+	web.Router("/api/v1/project/:projectid/schema-drifts/:id/approve", h, "post:ApproveSchemaDrift")
+	web.Router("/api/v1/project/:projectid/schema-drifts/:id/reject", h, "post:RejectSchemaDrift")
+
+	// job template routes
+	web.Router("/api/v1/project/:projectid/job-templates", h, "get:ListJobTemplates")
+	web.Router("/api/v1/project/:projectid/job-templates", h, "post:CreateJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id", h, "get:GetJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id", h, "put:UpdateJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id", h, "delete:DeleteJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id/apply", h, "post:ApplyJobTemplate")
+
+	// Masking rule routes
+	web.Router("/api/v1/project/:projectid/masking-rules", h, "get:ListMaskingRules")
+	web.Router("/api/v1/project/:projectid/masking-rules", h, "post:CreateMaskingRule")
+	web.Router("/api/v1/project/:projectid/masking-rules/:id", h, "get:GetMaskingRule")
+	web.Router("/api/v1/project/:projectid/masking-rules/:id", h, "put:UpdateMaskingRule")
+	web.Router("/api/v1/project/:projectid/masking-rules/:id", h, "delete:DeleteMaskingRule")
+
+	// Maintenance window routes
+	web.Router("/api/v1/project/:projectid/maintenance-windows", h, "get:ListMaintenanceWindows")
+	web.Router("/api/v1/project/:projectid/maintenance-windows", h, "post:CreateMaintenanceWindow")
+	web.Router("/api/v1/project/:projectid/maintenance-windows/events", h, "get:ListMaintenanceEvents")
+	web.Router("/api/v1/project/:projectid/maintenance-windows/:id", h, "get:GetMaintenanceWindow")
+	web.Router("/api/v1/project/:projectid/maintenance-windows/:id", h, "put:UpdateMaintenanceWindow")
+	web.Router("/api/v1/project/:projectid/maintenance-windows/:id", h, "delete:DeleteMaintenanceWindow")
 
 	// Project settings routes
 	web.Router("/api/v1/project/:projectid/settings", h, "put:UpsertProjectSettings")
 	web.Router("/api/v1/project/:projectid/settings", h, "get:GetProjectSettings")
 
+	// Log retention routes
+	web.Router("/api/v1/project/:projectid/logs/usage", h, "get:GetLogDiskUsage")
+	web.Router("/api/v1/project/:projectid/logs/cleanup", h, "post:CleanupLogs")
+
+	// Encryption key rotation routes
+	web.Router("/api/v1/encryption/re-encrypt", h, "post:StartConfigReEncryption")
+	web.Router("/api/v1/encryption/re-encrypt", h, "get:GetConfigReEncryptionStatus")
+
+	// Connector registry routes
+	web.Router("/api/v1/connectors", h, "get:ListConnectors")
+	web.Router("/api/v1/connectors/refresh", h, "post:RefreshConnectors")
+	web.Router("/api/v1/connectors/:type", h, "get:GetConnector")
+	web.Router("/api/v1/connectors/:type", h, "put:UpdateConnector")
+	web.Router("/api/v1/connectors/:type/versions", h, "put:SetConnectorVersion")
+	web.Router("/api/v1/connectors/:type/versions", h, "delete:DeleteConnectorVersion")
+
+	// Connector upgrade routes
+	web.Router("/api/v1/project/:projectid/connector-upgrades", h, "get:ListConnectorUpgrades")
+	web.Router("/api/v1/project/:projectid/connector-upgrades", h, "post:StartConnectorUpgrade")
+	web.Router("/api/v1/project/:projectid/connector-upgrades/check", h, "post:CheckConnectorUpgrade")
+	web.Router("/api/v1/project/:projectid/connector-upgrades/:id", h, "get:GetConnectorUpgrade")
+	web.Router("/api/v1/project/:projectid/connector-upgrades/:id/rollback", h, "post:RollbackConnectorUpgrade")
+
+	// Connector cache routes
+	web.Router("/api/v1/connector-cache/specs", h, "delete:InvalidateSpecCache")
+
 	// validation routes
 	web.Router("/api/v1/project/:projectid/check-unique", h, "post:CheckUniqueName")
 
 	// internal routes
 	web.Router("/internal/worker/callback/sync-telemetry", h, "post:UpdateSyncTelemetry")
+	web.Router("/internal/worker/callback/sync-admission", h, "post:AdmitSync")
+	web.Router("/internal/worker/callback/sync-release", h, "post:ReleaseSync")
 	web.Router("/internal/project/:projectid/jobs/:id/clear-destination/recover", h, "post:RecoverClearDestination")
 	web.Router("/internal/project/:projectid/jobs/:id/statefile", h, "put:UpdateStateFile")
 }
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils"
+)
+
+// startMailHog starts a MailHog SMTP stub and points the SMTP config at it, returning the
+// endpoint of its HTTP API
+func startMailHog(t *testing.T) string {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	mailhog, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "mailhog/mailhog:v1.0.1",
+			ExposedPorts: []string{"1025/tcp", "8025/tcp"},
+			WaitingFor:   wait.ForHTTP("/api/v2/messages").WithPort("8025/tcp"),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	testcontainers.CleanupContainer(t, mailhog)
+
+	host, err := mailhog.Host(ctx)
+	require.NoError(t, err)
+	smtpPort, err := mailhog.MappedPort(ctx, "1025/tcp")
+	require.NoError(t, err)
+	api, err := mailhog.PortEndpoint(ctx, "8025/tcp", "http")
+	require.NoError(t, err)
+
+	for key, value := range map[string]string{
+		constants.ConfSMTPHost: host,
+		constants.ConfSMTPPort: smtpPort.Port(),
+		constants.ConfSMTPTLS:  "none",
+		constants.ConfSMTPFrom: "OLake <noreply@olake.io>",
+	} {
+		require.NoError(t, web.AppConfig.Set(key, value))
+	}
+	t.Cleanup(func() {
+		_ = web.AppConfig.Set(constants.ConfSMTPHost, "")
+	})
+	return api
+}
+
+// mailHogMessage is the part of a message of the MailHog API the tests check
+type mailHogMessage struct {
+	Raw struct {
+		From string   `json:"From"`
+		To   []string `json:"To"`
+	} `json:"Raw"`
+	Content struct {
+		Headers map[string][]string `json:"Headers"`
+		Body    string              `json:"Body"`
+	} `json:"Content"`
+}
+
+// receivedEmails lists the messages MailHog received, newest first
+func receivedEmails(t *testing.T, api string) []mailHogMessage {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v2/messages", api))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var messages struct {
+		Items []mailHogMessage `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&messages))
+	return messages.Items
+}
+
+func TestSendEmail(t *testing.T) {
+	api := startMailHog(t)
+	ctx := context.Background()
+	require.True(t, utils.EmailEnabled())
+
+	t.Run("delivers plain text emails", func(t *testing.T) {
+		body := "Open the link below to join OLake:\nhttps://olake.example.com/invite?token=abc"
+		require.NoError(t, utils.SendEmail(ctx, "Jane <jane@example.com>", "You are invited to OLake", body))
+
+		messages := receivedEmails(t, api)
+		require.Len(t, messages, 1)
+		message := messages[0]
+		require.Equal(t, "noreply@olake.io", message.Raw.From)
+		require.Equal(t, []string{"jane@example.com"}, message.Raw.To)
+		require.Equal(t, []string{"You are invited to OLake"}, message.Content.Headers["Subject"])
+		require.Equal(t, []string{"text/plain; charset=utf-8"}, message.Content.Headers["Content-Type"])
+		require.Equal(t, "Open the link below to join OLake:\r\nhttps://olake.example.com/invite?token=abc", message.Content.Body)
+	})
+
+	t.Run("keeps subjects on one header line", func(t *testing.T) {
+		require.NoError(t, utils.SendEmail(ctx, "jane@example.com", "Verify\r\nBcc: eve@example.com", "body"))
+
+		message := receivedEmails(t, api)[0]
+		require.Equal(t, []string{"jane@example.com"}, message.Raw.To)
+		require.NotContains(t, message.Content.Headers, "Bcc")
+	})
+
+	t.Run("rejects invalid recipients", func(t *testing.T) {
+		require.ErrorContains(t, utils.SendEmail(ctx, "not an address", "subject", "body"), "invalid recipient")
+	})
+
+	t.Run("fails without a server", func(t *testing.T) {
+		require.NoError(t, web.AppConfig.Set(constants.ConfSMTPHost, ""))
+		require.False(t, utils.EmailEnabled())
+		require.ErrorIs(t, utils.SendEmail(ctx, "jane@example.com", "subject", "body"), constants.ErrEmailNotConfigured)
+	})
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/datazip-inc/olake-ui/server/utils/ociregistry"
+)
+
+const (
+	registryUser     = "olake"
+	registryPassword = "olake-test-secret"
+)
+
+// startRegistry starts a registry:2 container, behind htpasswd basic auth when authenticated
+// is set, and returns its host
+func startRegistry(t *testing.T, authenticated bool) string {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "registry:2",
+		ExposedPorts: []string{"5000/tcp"},
+		WaitingFor: wait.ForHTTP("/v2/").WithPort("5000/tcp").WithStatusCodeMatcher(func(status int) bool {
+			return status == http.StatusOK || status == http.StatusUnauthorized
+		}),
+	}
+	if authenticated {
+		hash, err := bcrypt.GenerateFromPassword([]byte(registryPassword), bcrypt.DefaultCost)
+		require.NoError(t, err)
+		req.Files = []testcontainers.ContainerFile{{
+			Reader:            strings.NewReader(fmt.Sprintf("%s:%s\n", registryUser, hash)),
+			ContainerFilePath: "/auth/htpasswd",
+			FileMode:          0o644,
+		}}
+		req.Env = map[string]string{
+			"REGISTRY_AUTH":                "htpasswd",
+			"REGISTRY_AUTH_HTPASSWD_REALM": "olake",
+			"REGISTRY_AUTH_HTPASSWD_PATH":  "/auth/htpasswd",
+		}
+	}
+
+	registry, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{ContainerRequest: req, Started: true})
+	require.NoError(t, err)
+	testcontainers.CleanupContainer(t, registry)
+
+	endpoint, err := registry.PortEndpoint(ctx, "5000/tcp", "")
+	require.NoError(t, err)
+	return endpoint
+}
+
+// pushImage uploads an image with a single empty layer under the given tags
+func pushImage(t *testing.T, host, repository string, tags ...string) {
+	config := pushBlob(t, host, repository, []byte(`{}`))
+	layer := pushBlob(t, host, repository, []byte("layer"))
+	manifest := fmt.Sprintf(`{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": %q, "size": 2},
+		"layers": [{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": %q, "size": 5}]
+	}`, config, layer)
+
+	for _, tag := range tags {
+		resp := registryRequest(t, http.MethodPut, fmt.Sprintf("http://%s/v2/%s/manifests/%s", host, repository, tag),
+			"application/vnd.oci.image.manifest.v1+json", []byte(manifest))
+		require.Equal(t, http.StatusCreated, resp.StatusCode, "push manifest %s:%s", repository, tag)
+	}
+}
+
+// pushBlob uploads a blob in a single request and returns its digest
+func pushBlob(t *testing.T, host, repository string, content []byte) string {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+
+	resp := registryRequest(t, http.MethodPost, fmt.Sprintf("http://%s/v2/%s/blobs/uploads/", host, repository), "", nil)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode, "start blob upload")
+
+	location, err := url.Parse(fmt.Sprintf("http://%s/", host))
+	require.NoError(t, err)
+	location, err = location.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	resp = registryRequest(t, http.MethodPut, location.String(), "application/octet-stream", content)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "finish blob upload")
+	return digest
+}
+
+// registryRequest sends a request with the test credentials, which an anonymous registry ignores
+func registryRequest(t *testing.T, method, target, contentType string, body []byte) *http.Response {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.SetBasicAuth(registryUser, registryPassword)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestRegistryListTags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("anonymous registry", func(t *testing.T) {
+		host := startRegistry(t, false)
+		pushImage(t, host, "olakego/source-mysql", "v0.1.0", "v0.2.0", "latest")
+
+		client := ociregistry.NewClient(nil, []string{host})
+		tags, err := client.ListTags(ctx, host+"/olakego/source-mysql")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"v0.1.0", "v0.2.0", "latest"}, tags)
+
+		_, err = client.ListTags(ctx, host+"/olakego/source-missing")
+		require.ErrorContains(t, err, "status 404")
+	})
+
+	t.Run("registry with basic auth", func(t *testing.T) {
+		host := startRegistry(t, true)
+		pushImage(t, host, "olakego/source-postgres", "v0.3.0")
+
+		_, err := ociregistry.NewClient(nil, []string{host}).ListTags(ctx, host+"/olakego/source-postgres")
+		require.ErrorContains(t, err, "requires credentials")
+
+		credentials := func(password string) ociregistry.CredentialFunc {
+			return func(_ context.Context, registryHost string) (ociregistry.Credential, error) {
+				if registryHost != host {
+					return ociregistry.Credential{}, nil
+				}
+				return ociregistry.Credential{Username: registryUser, Password: password}, nil
+			}
+		}
+		_, err = ociregistry.NewClient(credentials("wrong"), []string{host}).ListTags(ctx, host+"/olakego/source-postgres")
+		require.ErrorContains(t, err, "status 401")
+
+		tags, err := ociregistry.NewClient(credentials(registryPassword), []string{host}).ListTags(ctx, host+"/olakego/source-postgres:v0.3.0")
+		require.NoError(t, err)
+		require.Equal(t, []string{"v0.3.0"}, tags)
+	})
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
+)
+
+const vaultRootToken = "olake-test-root"
+
+// startVault starts a Vault dev server, whose "secret" mount is a KV v2 engine, and points
+// the server config at it
+func startVault(t *testing.T) string {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	vault, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "hashicorp/vault:1.17",
+			ExposedPorts: []string{"8200/tcp"},
+			Env: map[string]string{
+				"VAULT_DEV_ROOT_TOKEN_ID":  vaultRootToken,
+				"VAULT_DEV_LISTEN_ADDRESS": "0.0.0.0:8200",
+			},
+			WaitingFor: wait.ForHTTP("/v1/sys/health").WithPort("8200/tcp"),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	testcontainers.CleanupContainer(t, vault)
+
+	endpoint, err := vault.PortEndpoint(ctx, "8200/tcp", "http")
+	require.NoError(t, err)
+
+	require.NoError(t, web.AppConfig.Set(constants.ConfVaultAddr, endpoint))
+	require.NoError(t, web.AppConfig.Set(constants.ConfVaultToken, vaultRootToken))
+	t.Cleanup(func() {
+		_ = web.AppConfig.Set(constants.ConfVaultAddr, "")
+		_ = web.AppConfig.Set(constants.ConfVaultToken, "")
+	})
+	return endpoint
+}
+
+// writeVaultSecret writes a new version of a KV v2 secret
+func writeVaultSecret(t *testing.T, endpoint, path, data string) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/secret/data/%s", endpoint, path), bytes.NewBufferString(fmt.Sprintf(`{"data": %s}`, data)))
+	require.NoError(t, err)
+	req.Header.Set("X-Vault-Token", vaultRootToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestVaultSecretReferences(t *testing.T) {
+	endpoint := startVault(t)
+	ctx := context.Background()
+
+	writeVaultSecret(t, endpoint, "mysql", `{"username": "olake", "password": "first"}`)
+	writeVaultSecret(t, endpoint, "mysql", `{"username": "olake", "password": "second"}`)
+	writeVaultSecret(t, endpoint, "token", `{"value": "t0ken"}`)
+
+	t.Run("resolves fields of the latest version", func(t *testing.T) {
+		resolved, err := secretref.ResolveConfig(ctx, `{"host": "mysql", "username": {"$secret": "vault://secret/data/mysql#username"}, "password": {"$secret": "vault://secret/data/mysql#password"}}`)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"host": "mysql", "username": "olake", "password": "second"}`, resolved)
+	})
+
+	t.Run("pins a version", func(t *testing.T) {
+		resolved, err := secretref.ResolveConfig(ctx, `{"password": {"$secret": "vault://secret/data/mysql?version=1#password"}}`)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"password": "first"}`, resolved)
+	})
+
+	t.Run("selects the only field", func(t *testing.T) {
+		resolved, err := secretref.ResolveConfig(ctx, `{"token": {"$secret": "vault://secret/data/token"}}`)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"token": "t0ken"}`, resolved)
+	})
+
+	t.Run("fails on ambiguous, missing and unknown secrets", func(t *testing.T) {
+		for _, ref := range []string{
+			"vault://secret/data/mysql",
+			"vault://secret/data/mysql#port",
+			"vault://secret/data/missing#password",
+		} {
+			_, err := secretref.ResolveConfig(ctx, fmt.Sprintf(`{"password": {"$secret": %q}}`, ref))
+			require.Error(t, err, ref)
+		}
+	})
+}
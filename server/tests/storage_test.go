@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
+)
+
+const (
+	minioUser     = "olake"
+	minioPassword = "olake-test-secret"
+	minioBucket   = "olake-workflows"
+)
+
+// startMinio starts a MinIO server with an empty bucket and points the s3 storage backend at it
+func startMinio(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	minio, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "minio/minio:RELEASE.2024-08-17T01-24-54Z",
+			Cmd:          []string{"server", "/data"},
+			ExposedPorts: []string{"9000/tcp"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     minioUser,
+				"MINIO_ROOT_PASSWORD": minioPassword,
+			},
+			WaitingFor: wait.ForHTTP("/minio/health/live").WithPort("9000/tcp"),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	testcontainers.CleanupContainer(t, minio)
+
+	endpoint, err := minio.PortEndpoint(ctx, "9000/tcp", "http")
+	require.NoError(t, err)
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion("us-east-1"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(minioUser, minioPassword, "")),
+	)
+	require.NoError(t, err)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	})
+	_, err = client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(minioBucket)})
+	require.NoError(t, err)
+
+	for key, value := range map[string]string{
+		constants.ConfStorageBackend:     "s3",
+		constants.ConfStorageS3Bucket:    minioBucket,
+		constants.ConfStorageS3Prefix:    "olake",
+		constants.ConfStorageS3Endpoint:  endpoint,
+		constants.ConfStorageS3Region:    "us-east-1",
+		constants.ConfStorageS3AccessKey: minioUser,
+		constants.ConfStorageS3SecretKey: minioPassword,
+		constants.ConfStorageS3PathStyle: "true",
+	} {
+		require.NoError(t, web.AppConfig.Set(key, value))
+	}
+	require.NoError(t, storage.Init())
+	t.Cleanup(func() {
+		_ = web.AppConfig.Set(constants.ConfStorageBackend, "local")
+		_ = storage.Init()
+	})
+}
+
+func TestS3Storage(t *testing.T) {
+	startMinio(t)
+	ctx := context.Background()
+	store := storage.Get()
+
+	runDir := "3f5c0a/logs"
+	require.NoError(t, store.Write(ctx, "3f5c0a/state.json", []byte(`{"cursor": 42}`)))
+	require.NoError(t, store.Write(ctx, runDir+"/sync_2024-03-10_09-00-00/olake.log", []byte("line one\nline two\n")))
+	require.NoError(t, store.Write(ctx, runDir+"/sync_2024-03-10_10-00-00/olake.log", []byte("retry\n")))
+
+	t.Run("reads files", func(t *testing.T) {
+		data, err := store.Read(ctx, "3f5c0a/state.json")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"cursor": 42}`, string(data))
+
+		_, err = store.Read(ctx, "3f5c0a/missing.json")
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("reads at offsets", func(t *testing.T) {
+		object, err := store.Open(ctx, runDir+"/sync_2024-03-10_09-00-00/olake.log")
+		require.NoError(t, err)
+		defer object.Close()
+		require.EqualValues(t, 18, object.Size())
+
+		buf := make([]byte, 8)
+		n, err := object.ReadAt(buf, 9)
+		require.NoError(t, err)
+		require.Equal(t, "line two", string(buf[:n]))
+
+		n, err = object.ReadAt(buf, 14)
+		require.ErrorIs(t, err, io.EOF)
+		require.Equal(t, "two\n", string(buf[:n]))
+	})
+
+	t.Run("lists folders", func(t *testing.T) {
+		for _, p := range []string{"3f5c0a", runDir, runDir + "/sync_2024-03-10_09-00-00/olake.log"} {
+			exists, err := store.Exists(ctx, p)
+			require.NoError(t, err)
+			require.True(t, exists, p)
+		}
+		exists, err := store.Exists(ctx, "3f5c0")
+		require.NoError(t, err)
+		require.False(t, exists, "key prefixes are not folders")
+
+		entries, err := store.ReadDir(ctx, "3f5c0a")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []storage.Entry{{Name: "logs", IsDir: true}, {Name: "state.json"}}, entries)
+
+		objects, err := store.Walk(ctx, runDir)
+		require.NoError(t, err)
+		var paths []string
+		for _, object := range objects {
+			paths = append(paths, object.Path)
+		}
+		require.ElementsMatch(t, []string{
+			runDir + "/sync_2024-03-10_09-00-00/olake.log",
+			runDir + "/sync_2024-03-10_10-00-00/olake.log",
+		}, paths)
+	})
+
+	t.Run("deletes folders", func(t *testing.T) {
+		require.Error(t, store.Delete(ctx, "/"))
+		require.NoError(t, store.Delete(ctx, runDir))
+
+		exists, err := store.Exists(ctx, runDir)
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		exists, err = store.Exists(ctx, "3f5c0a/state.json")
+		require.NoError(t, err)
+		require.True(t, exists, "siblings of the deleted folder are kept")
+	})
+}
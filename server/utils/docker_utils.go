@@ -3,9 +3,7 @@ package utils
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"os/exec"
 	"regexp"
@@ -17,38 +15,27 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/beego/beego/v2/server/web"
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
-	"github.com/datazip-inc/olake-ui/server/utils/logger"
 	"golang.org/x/mod/semver"
 )
 
-// docker hub tags api url template
-const dockerHubTagsURLTemplate = "https://hub.docker.com/v2/repositories/%s/tags/?page_size=100"
-
-// DockerHubTag represents a single tag from Docker Hub API response
-type DockerHubTag struct {
-	Name string `json:"name"`
-}
-
-// DockerHubTagsResponse represents the response structure from Docker Hub tags API
-type DockerHubTagsResponse struct {
-	Results []DockerHubTag `json:"results"`
-}
-
-var defaultImages = []string{"olakego/source-mysql", "olakego/source-postgres", "olakego/source-oracle", "olakego/source-mongodb", "olakego/source-kafka", "olakego/source-s3"}
-
 // ignoredWorkerEnv is a map of environment variables that are ignored from the worker container.
 var ignoredWorkerEnv = map[string]any{ // A map is chosen because it gives O(1) lookup time for key existence.
-	"HOSTNAME":                nil,
-	"PATH":                    nil,
-	"PWD":                     nil,
-	"HOME":                    nil,
-	"SHLVL":                   nil,
-	"TERM":                    nil,
-	"PERSISTENT_DIR":          nil,
-	"CONTAINER_REGISTRY_BASE": nil,
-	"TEMPORAL_ADDRESS":        nil,
-	"OLAKE_SECRET_KEY":        nil,
-	"_":                       nil,
+	"HOSTNAME":                    nil,
+	"PATH":                        nil,
+	"PWD":                         nil,
+	"HOME":                        nil,
+	"SHLVL":                       nil,
+	"TERM":                        nil,
+	"PERSISTENT_DIR":              nil,
+	"CONTAINER_REGISTRY_BASE":     nil,
+	"CONTAINER_REGISTRY_PASSWORD": nil,
+	"TEMPORAL_ADDRESS":            nil,
+	"OLAKE_SECRET_KEY":            nil,
+	"OLAKE_PREVIOUS_SECRET_KEYS":  nil,
+	"VAULT_TOKEN":                 nil,
+	"AUTH_TOKEN_SECRET":           nil,
+	"SMTP_PASSWORD":               nil,
+	"_":                           nil,
 }
 
 // GetWorkerEnvVars returns the environment variables from the worker container.
@@ -65,125 +52,61 @@ func GetWorkerEnvVars() map[string]string {
 	return vars
 }
 
-// GetDriverImageTags returns image tags from ECR or Docker Hub with fallback to cached images
-func GetDriverImageTags(ctx context.Context, imageName string, cachedTags bool) ([]string, string, error) {
+// GetRegistryImageTags returns the tags of an image in CONTAINER_REGISTRY_BASE, highest first.
+// Any registry implementing the OCI distribution API is supported, see registryCredentials for
+// how it is authorized.
+func GetRegistryImageTags(ctx context.Context, imageName string) ([]string, error) {
 	// TODO: make constants file and validate all env vars in start of server
 	repositoryBase, err := web.AppConfig.String(constants.ConfContainerRegistryBase)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get CONTAINER_REGISTRY_BASE: %s", err)
-	}
-	var tags []string
-	images := []string{imageName}
-	if imageName == "" {
-		images = defaultImages
-	}
-	driverImage := ""
-	for _, imageName := range images {
-		if strings.Contains(repositoryBase, "ecr") {
-			fullImage := fmt.Sprintf("%s/%s", repositoryBase, imageName)
-			tags, err = getECRImageTags(ctx, fullImage)
-		} else {
-			tags, err = getDockerHubImageTags(ctx, imageName)
-		}
-
-		// Fallback to cached if online fetch fails or explicitly requested
-		if err != nil && cachedTags {
-			logger.Warn("failed to fetch image tags online for %s: %s, falling back to cached tags", imageName, err)
-			tags, err = fetchCachedImageTags(ctx, imageName, repositoryBase)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to fetch cached image tags for %s: %s", imageName, err)
-			}
-		}
-
-		if len(tags) == 0 {
-			// if no tags found continue
-			continue
-		}
-
-		// TODO : return highest tag out of all sources (currently breaking loop once any tag found on any image)
-		driverImage = imageName
-		break
-	}
-
-	if len(tags) == 0 {
-		return nil, "", fmt.Errorf("no tags found for image: %s", imageName)
-	}
-	driverImage = strings.TrimPrefix(driverImage, "olakego/source-")
-	sort.Slice(tags, func(i, j int) bool { return semver.Compare(tags[i], tags[j]) > 0 }) // highest first
-	return tags, driverImage, err
-}
-
-// getECRImageTags fetches tags from AWS ECR
-func getECRImageTags(ctx context.Context, fullImageName string) ([]string, error) {
-	accountID, region, repoName, err := ParseECRDetails(fullImageName)
-	if err != nil {
-		return nil, fmt.Errorf("invalid ECR URI: %s", err)
+		return nil, fmt.Errorf("failed to get CONTAINER_REGISTRY_BASE: %s", err)
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
-	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %s", err)
+	reference := imageName
+	if repositoryBase != "" {
+		reference = fmt.Sprintf("%s/%s", strings.TrimSuffix(repositoryBase, "/"), imageName)
 	}
-
-	client := ecr.NewFromConfig(cfg)
-	resp, err := client.DescribeImages(ctx, &ecr.DescribeImagesInput{
-		RepositoryName: aws.String(repoName),
-		RegistryId:     aws.String(accountID),
-		MaxResults:     aws.Int32(100),
-	})
+	allTags, err := getRegistryClient().ListTags(ctx, reference)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch ECR tags: %s", err)
+		return nil, fmt.Errorf("failed to fetch tags of %s: %s", reference, err)
 	}
 
 	var tags []string
-	for _, imageDetail := range resp.ImageDetails {
-		for _, tag := range imageDetail.ImageTags {
-			if isValidTag(tag) {
-				tags = append(tags, tag)
-			}
+	for _, tag := range allTags {
+		if isValidTag(tag) {
+			tags = append(tags, tag)
 		}
 	}
+	SortImageTags(tags)
 	return tags, nil
 }
 
-// getDockerHubImageTags fetches tags from Docker Hub
-func getDockerHubImageTags(ctx context.Context, imageName string) ([]string, error) {
-	// Create a new HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "GET",
-		fmt.Sprintf(dockerHubTagsURLTemplate, imageName), http.NoBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %s", err)
-	}
-	// Make the HTTP request
-	resp, err := http.DefaultClient.Do(req)
+// GetLocalImageTags returns the tags of an image pulled on the docker host, highest first
+func GetLocalImageTags(ctx context.Context, imageName string) ([]string, error) {
+	repositoryBase, err := web.AppConfig.String(constants.ConfContainerRegistryBase)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch tags from Docker Hub: %s", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("docker hub api request failed with status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to get CONTAINER_REGISTRY_BASE: %s", err)
 	}
 
-	var responseData DockerHubTagsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		return nil, fmt.Errorf("failed to decode Docker Hub response: %s", err)
-	}
-
-	var tags []string
-	for _, tagData := range responseData.Results {
-		if isValidTag(tagData.Name) {
-			tags = append(tags, tagData.Name)
-		}
+	tags, err := fetchCachedImageTags(ctx, imageName, repositoryBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cached image tags for %s: %s", imageName, err)
 	}
+	SortImageTags(tags)
 	return tags, nil
 }
 
-// fetchCachedImageTags retrieves locally cached tags for an image
+// SortImageTags orders semver tags highest first
+func SortImageTags(tags []string) {
+	sort.Slice(tags, func(i, j int) bool { return semver.Compare(tags[i], tags[j]) > 0 })
+}
+
+// fetchCachedImageTags retrieves locally cached tags for an image, pulled either by its short
+// name or with the registry base, e.g. "ghcr.io/org/olakego/source-mysql"
 func fetchCachedImageTags(ctx context.Context, imageName, repositoryBase string) ([]string, error) {
-	if strings.Contains(repositoryBase, "ecr") {
-		// after making it ecr, it will be like "123456789012.dkr.ecr.us-west-2.amazonaws.com/olakego/source-mysql"
-		imageName = fmt.Sprintf("%s/%s", strings.TrimSuffix(repositoryBase, "/"), imageName)
+	names := map[string]struct{}{imageName: {}}
+	if repositoryBase != "" {
+		names[fmt.Sprintf("%s/%s", strings.TrimSuffix(repositoryBase, "/"), imageName)] = struct{}{}
 	}
 
 	images, err := GetCachedImages(ctx)
@@ -193,13 +116,15 @@ func fetchCachedImageTags(ctx context.Context, imageName, repositoryBase string)
 
 	tagsMap := make(map[string]struct{})
 	for _, image := range images {
-		if strings.HasPrefix(image, imageName) {
-			parts := strings.Split(image, ":")
-			if len(parts) != 2 || !isValidTag(parts[1]) {
-				continue
-			}
-			tagsMap[parts[1]] = struct{}{}
+		// the tag follows the last colon, earlier colons belong to a registry port
+		colon := strings.LastIndex(image, ":")
+		if colon < 0 {
+			continue
+		}
+		if _, ok := names[image[:colon]]; !ok || !isValidTag(image[colon+1:]) {
+			continue
 		}
+		tagsMap[image[colon+1:]] = struct{}{}
 	}
 
 	var tags []string
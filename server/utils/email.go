@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// smtpTimeout bounds delivering a single email
+const smtpTimeout = 30 * time.Second
+
+// SMTP_TLS modes: starttls upgrades the connection when the server offers it, tls connects over
+// TLS from the start (usually port 465) and none never encrypts, e.g. for a local stub like MailHog
+const (
+	smtpTLSStartTLS = "starttls"
+	smtpTLSImplicit = "tls"
+	smtpTLSNone     = "none"
+)
+
+// EmailEnabled reports whether an SMTP server is configured
+func EmailEnabled() bool {
+	return web.AppConfig.DefaultString(constants.ConfSMTPHost, "") != ""
+}
+
+// SendEmail sends a plain text email through the configured SMTP server
+func SendEmail(ctx context.Context, to, subject, body string) error {
+	host := web.AppConfig.DefaultString(constants.ConfSMTPHost, "")
+	if host == "" {
+		return constants.ErrEmailNotConfigured
+	}
+	port := web.AppConfig.DefaultInt(constants.ConfSMTPPort, constants.DefaultSMTPPort)
+	tlsMode := strings.ToLower(web.AppConfig.DefaultString(constants.ConfSMTPTLS, constants.DefaultSMTPTLS))
+	username := web.AppConfig.DefaultString(constants.ConfSMTPUsername, "")
+	password := web.AppConfig.DefaultString(constants.ConfSMTPPassword, "")
+
+	from, err := mail.ParseAddress(web.AppConfig.DefaultString(constants.ConfSMTPFrom, username))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", constants.ConfSMTPFrom, err)
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient '%s': %s", to, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	dialer := &net.Dialer{}
+	switch tlsMode {
+	case smtpTLSImplicit:
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	case smtpTLSStartTLS, smtpTLSNone:
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	default:
+		return fmt.Errorf("invalid %s '%s', use %s, %s or %s", constants.ConfSMTPTLS, tlsMode, smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server %s: %s", address, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %s", err)
+	}
+	defer client.Close()
+
+	if tlsMode == smtpTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start tls: %s", err)
+			}
+		}
+	}
+	if username != "" {
+		if err := client.Auth(smtp.PlainAuth("", username, password, host)); err != nil {
+			return fmt.Errorf("failed to authenticate with smtp server: %s", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp server rejected sender: %s", err)
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return fmt.Errorf("smtp server rejected recipient: %s", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %s", err)
+	}
+	if _, err := writer.Write(buildEmail(from, recipient, subject, body)); err != nil {
+		return fmt.Errorf("failed to send email: %s", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %s", err)
+	}
+	return client.Quit()
+}
+
+func buildEmail(from, to *mail.Address, subject, body string) []byte {
+	// header values must not break out of their line
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
+	var message strings.Builder
+	message.WriteString("From: " + from.String() + "\r\n")
+	message.WriteString("To: " + to.String() + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	message.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(message.String())
+}
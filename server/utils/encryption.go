@@ -7,16 +7,19 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/beego/beego/v2/server/web"
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
 )
 
 // utility provides encryption and decryption functionality using either AWS KMS or local AES-256-GCM.
@@ -26,79 +29,169 @@ import (
 // - For AWS KMS: Set OLAKE_SECRET_KEY to a KMS ARN (e.g., "arn:aws:kms:us-east-1:123456789012:key/12345678-1234-1234-1234-123456789012")
 // - For local AES: Set OLAKE_SECRET_KEY to any non-empty string (will be hashed to 256-bit key)
 // - For no encryption: Leave OLAKE_SECRET_KEY empty (not recommended for production)
+// - For key rotation: Move the old key to OLAKE_PREVIOUS_SECRET_KEYS (comma separated) and set the new one
+//   as OLAKE_SECRET_KEY, previous keys are only used for decryption
+//
+// Ciphertexts are JSON quoted strings. The v1 format is "v1:<key id>:<base64>" where the key id is a
+// fingerprint of the key that encrypted the data; legacy ciphertexts are plain base64 and are decrypted
+// by trying every configured key, primary key first.
+
+const (
+	// EncryptionFormatV1 prefixes ciphertexts with the ID of the key that produced them
+	EncryptionFormatV1 = "v1"
+	// EncryptionFormatLegacy is the unprefixed format understood by connectors and older workers
+	EncryptionFormatLegacy = "legacy"
+)
+
+// encryptionKey is either a local AES-256 key or a KMS key ARN
+type encryptionKey struct {
+	id        string
+	aesKey    []byte
+	kmsARN    string
+	kmsClient *kms.Client
+}
+
+// keyRing holds the primary key used for encryption and every key accepted for decryption
+type keyRing struct {
+	primary   *encryptionKey
+	keys      []*encryptionKey
+	byID      map[string]*encryptionKey
+	format    string
+	kmsClient *kms.Client
+}
 
-// getSecretKey returns the encryption key, KMS client (if using KMS), and error
-func getSecretKey() ([]byte, *kms.Client, error) {
-	// TODO: can we move this to constants and set key and kms client
+var (
+	keyRingOnce sync.Once
+	keyRingInst *keyRing
+	keyRingErr  error
+)
+
+// getKeyRing loads the configured keys once, a nil primary key means encryption is disabled
+func getKeyRing() (*keyRing, error) {
+	keyRingOnce.Do(func() {
+		keyRingInst, keyRingErr = loadKeyRing()
+	})
+	return keyRingInst, keyRingErr
+}
+
+func loadKeyRing() (*keyRing, error) {
 	// TODO: use viper package to read environment variables
-	envKey, _ := web.AppConfig.String(constants.ConfEncryptionKey)
-	if strings.TrimSpace(envKey) == "" {
-		return []byte{}, nil, nil // Encryption is disabled
+	primaryKey, _ := web.AppConfig.String(constants.ConfEncryptionKey)
+	previousKeys, _ := web.AppConfig.String(constants.ConfPreviousEncryptionKeys)
+	format, _ := web.AppConfig.String(constants.ConfEncryptionFormat)
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = constants.DefaultEncryptionFormat
+	}
+	if format != EncryptionFormatV1 && format != EncryptionFormatLegacy {
+		return nil, fmt.Errorf("unsupported encryption format '%s', supported formats are: %s, %s", format, EncryptionFormatV1, EncryptionFormatLegacy)
 	}
 
-	if strings.HasPrefix(envKey, "arn:aws:kms:") {
-		cfg, err := config.LoadDefaultConfig(context.Background())
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to load AWS config: %s", err)
+	ring := &keyRing{byID: map[string]*encryptionKey{}, format: format}
+	if strings.TrimSpace(primaryKey) == "" {
+		if strings.TrimSpace(previousKeys) != "" {
+			logger.Warn("Previous encryption keys are set without an encryption key, they will be ignored")
 		}
-		return []byte(envKey), kms.NewFromConfig(cfg), nil
+		return ring, nil // Encryption is disabled
 	}
 
-	// Local AES-GCM Mode with SHA-256 derived key
-	hash := sha256.Sum256([]byte(envKey))
-	return hash[:], nil, nil
+	secrets := append([]string{primaryKey}, strings.Split(previousKeys, ",")...)
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if secret == "" {
+			continue
+		}
+
+		key := &encryptionKey{id: encryptionKeyID(secret)}
+		if _, exists := ring.byID[key.id]; exists {
+			continue
+		}
+
+		if strings.HasPrefix(secret, "arn:aws:kms:") {
+			if ring.kmsClient == nil {
+				cfg, err := config.LoadDefaultConfig(context.Background())
+				if err != nil {
+					return nil, fmt.Errorf("failed to load AWS config: %s", err)
+				}
+				ring.kmsClient = kms.NewFromConfig(cfg)
+			}
+			key.kmsARN = secret
+			key.kmsClient = ring.kmsClient
+		} else {
+			// Local AES-GCM Mode with SHA-256 derived key
+			hash := sha256.Sum256([]byte(secret))
+			key.aesKey = hash[:]
+		}
+
+		if ring.primary == nil {
+			ring.primary = key
+		}
+		ring.keys = append(ring.keys, key)
+		ring.byID[key.id] = key
+	}
+	return ring, nil
 }
 
-func Encrypt(plaintext string) (string, error) {
-	if strings.TrimSpace(plaintext) == "" {
-		return plaintext, nil
+// encryptionKeyID returns a stable fingerprint of a key that does not reveal the key itself
+func encryptionKeyID(secret string) string {
+	hash := sha256.Sum256([]byte("olake:" + secret))
+	return hex.EncodeToString(hash[:8])
+}
+
+// PrimaryEncryptionKeyID returns the ID of the key new ciphertexts are encrypted with, empty if encryption is disabled
+func PrimaryEncryptionKeyID() string {
+	ring, err := getKeyRing()
+	if err != nil || ring.primary == nil {
+		return ""
 	}
+	return ring.primary.id
+}
 
-	key, kmsClient, err := getSecretKey()
-	if err != nil || key == nil || len(key) == 0 {
+// Encrypt encrypts plaintext with the primary key in the configured storage format
+func Encrypt(plaintext string) (string, error) {
+	ring, err := getKeyRing()
+	if err != nil {
 		return plaintext, err
 	}
+	return ring.encrypt(plaintext, ring.format)
+}
 
-	// Use KMS if client is provided
-	if kmsClient != nil {
-		keyID := string(key)
-		result, err := kmsClient.Encrypt(context.Background(), &kms.EncryptInput{
-			KeyId:     &keyID,
-			Plaintext: []byte(plaintext),
-		})
-		if err != nil {
-			return "", fmt.Errorf("failed to encrypt with KMS: %s", err)
-		}
-		return fmt.Sprintf("%q", base64.StdEncoding.EncodeToString(result.CiphertextBlob)), nil
+// EncryptForConnector encrypts plaintext in the legacy format, which is what connectors
+// decrypt with the --encryption-key argument
+func EncryptForConnector(plaintext string) (string, error) {
+	ring, err := getKeyRing()
+	if err != nil {
+		return plaintext, err
 	}
+	return ring.encrypt(plaintext, EncryptionFormatLegacy)
+}
 
-	// Local AES-GCM encryption
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %s", err)
+func (r *keyRing) encrypt(plaintext, format string) (string, error) {
+	if strings.TrimSpace(plaintext) == "" || r.primary == nil {
+		return plaintext, nil
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	ciphertext, err := r.primary.encrypt([]byte(plaintext))
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %s", err)
+		return "", err
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("failed to generate nonce: %s", err)
+	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+	if format == EncryptionFormatV1 {
+		encoded = fmt.Sprintf("%s:%s:%s", EncryptionFormatV1, r.primary.id, encoded)
 	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return fmt.Sprintf("%q", base64.StdEncoding.EncodeToString(ciphertext)), nil
+	return fmt.Sprintf("%q", encoded), nil
 }
 
+// Decrypt decrypts a v1 or legacy ciphertext with any configured key
 func Decrypt(encryptedText string) (string, error) {
 	if strings.TrimSpace(encryptedText) == "" {
 		return "", fmt.Errorf("cannot decrypt empty or whitespace-only input")
 	}
 
-	key, kmsClient, err := getSecretKey()
-	if err != nil || key == nil || len(key) == 0 {
+	ring, err := getKeyRing()
+	if err != nil || ring.primary == nil {
 		return encryptedText, err
 	}
 
@@ -108,40 +201,183 @@ func Decrypt(encryptedText string) (string, error) {
 		return "", fmt.Errorf("failed to unmarshal JSON string: %s", err)
 	}
 
-	encryptedData, err := base64.StdEncoding.DecodeString(config)
+	keyID, encoded := parseEnvelope(config)
+	encryptedData, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode base64 data: %s", err)
 	}
 
-	// Use KMS if client is provided
-	if kmsClient != nil {
-		result, err := kmsClient.Decrypt(context.Background(), &kms.DecryptInput{
-			CiphertextBlob: encryptedData,
+	if keyID != "" {
+		key, ok := ring.byID[keyID]
+		if !ok {
+			return "", fmt.Errorf("no encryption key configured for key id %s", keyID)
+		}
+		plaintext, err := key.decrypt(encryptedData)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	}
+
+	// legacy ciphertexts carry no key id, try every key starting with the primary one
+	var lastErr error
+	for _, key := range ring.keys {
+		plaintext, err := key.decrypt(encryptedData)
+		if err == nil {
+			return string(plaintext), nil
+		}
+		lastErr = err
+	}
+	return "", fmt.Errorf("failed to decrypt with any configured key: %s", lastErr)
+}
+
+// NeedsReEncryption reports whether a stored value is not yet encrypted with the primary key
+// in the configured format. Plaintext JSON objects, left from before encryption was enabled,
+// also need encryption.
+func NeedsReEncryption(storedText string) (bool, error) {
+	ring, err := getKeyRing()
+	if err != nil {
+		return false, err
+	}
+	if ring.primary == nil {
+		return false, fmt.Errorf("encryption key is not set")
+	}
+	if isPlaintextObject(storedText) {
+		return true, nil
+	}
+
+	var config string
+	if err := json.Unmarshal([]byte(storedText), &config); err != nil {
+		return false, fmt.Errorf("failed to unmarshal JSON string: %s", err)
+	}
+
+	keyID, encoded := parseEnvelope(config)
+	if keyID != "" {
+		return ring.format != EncryptionFormatV1 || keyID != ring.primary.id, nil
+	}
+	if ring.format != EncryptionFormatLegacy {
+		return true, nil
+	}
+
+	encryptedData, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode base64 data: %s", err)
+	}
+	_, err = ring.primary.decrypt(encryptedData)
+	return err != nil, nil
+}
+
+// SecretFieldsNeedReEncryption reports whether a stored config holds secret fields encrypted on
+// their own that are not yet encrypted with the primary key in the configured format
+func SecretFieldsNeedReEncryption(storedText string) (bool, error) {
+	plaintext, err := decryptStored(storedText)
+	if err != nil {
+		return false, err
+	}
+	return secretFieldsNeedReEncryption(plaintext)
+}
+
+// ReEncrypt decrypts a stored value with whichever key produced it and encrypts it again
+// with the primary key, plaintext JSON objects are encrypted as they are. Secret fields
+// encrypted on their own are encrypted again with the primary key too.
+func ReEncrypt(storedText string) (string, error) {
+	plaintext, err := decryptStored(storedText)
+	if err != nil {
+		return "", err
+	}
+	if plaintext, err = reEncryptSecretFields(plaintext); err != nil {
+		return "", err
+	}
+	return Encrypt(plaintext)
+}
+
+// decryptStored decrypts a stored value, plaintext JSON objects are returned as they are
+func decryptStored(storedText string) (string, error) {
+	if isPlaintextObject(storedText) {
+		return storedText, nil
+	}
+	return Decrypt(storedText)
+}
+
+// parseEnvelope splits a v1 envelope into key id and payload, legacy payloads have no key id
+func parseEnvelope(config string) (string, string) {
+	parts := strings.SplitN(config, ":", 3)
+	if len(parts) == 3 && parts[0] == EncryptionFormatV1 {
+		return parts[1], parts[2]
+	}
+	return "", config
+}
+
+func isPlaintextObject(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	return strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed))
+}
+
+func (k *encryptionKey) encrypt(plaintext []byte) ([]byte, error) {
+	// Use KMS if the key is a KMS ARN
+	if k.kmsARN != "" {
+		result, err := k.kmsClient.Encrypt(context.Background(), &kms.EncryptInput{
+			KeyId:     &k.kmsARN,
+			Plaintext: plaintext,
 		})
 		if err != nil {
-			return "", fmt.Errorf("failed to decrypt with KMS: %s", err)
+			return nil, fmt.Errorf("failed to encrypt with KMS: %s", err)
 		}
-		return string(result.Plaintext), nil
+		return result.CiphertextBlob, nil
 	}
 
-	// Local AES-GCM decryption
-	block, err := aes.NewCipher(key)
+	// Local AES-GCM encryption
+	gcm, err := newGCM(k.aesKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %s", err)
+		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %s", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (k *encryptionKey) decrypt(encryptedData []byte) ([]byte, error) {
+	// Use KMS if the key is a KMS ARN
+	if k.kmsARN != "" {
+		result, err := k.kmsClient.Decrypt(context.Background(), &kms.DecryptInput{
+			KeyId:          &k.kmsARN,
+			CiphertextBlob: encryptedData,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt with KMS: %s", err)
+		}
+		return result.Plaintext, nil
+	}
+
+	// Local AES-GCM decryption
+	gcm, err := newGCM(k.aesKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %s", err)
+		return nil, err
 	}
 
 	if len(encryptedData) < gcm.NonceSize() {
-		return "", errors.New("ciphertext too short")
+		return nil, errors.New("ciphertext too short")
 	}
 
 	plaintext, err := gcm.Open(nil, encryptedData[:gcm.NonceSize()], encryptedData[gcm.NonceSize():], nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt: %s", err)
+		return nil, fmt.Errorf("failed to decrypt: %s", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %s", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %s", err)
 	}
-	return string(plaintext), nil
+	return gcm, nil
 }
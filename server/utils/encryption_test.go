@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// useKeys replaces the loaded key ring with one built from the given config
+func useKeys(t *testing.T, primary, previous, format string) {
+	require.NoError(t, web.AppConfig.Set(constants.ConfEncryptionKey, primary))
+	require.NoError(t, web.AppConfig.Set(constants.ConfPreviousEncryptionKeys, previous))
+	require.NoError(t, web.AppConfig.Set(constants.ConfEncryptionFormat, format))
+
+	keyRingOnce.Do(func() {})
+	keyRingInst, keyRingErr = loadKeyRing()
+	require.NoError(t, keyRingErr)
+	t.Cleanup(func() {
+		_ = web.AppConfig.Set(constants.ConfEncryptionKey, "")
+		_ = web.AppConfig.Set(constants.ConfPreviousEncryptionKeys, "")
+		_ = web.AppConfig.Set(constants.ConfEncryptionFormat, "")
+		keyRingInst, keyRingErr = loadKeyRing()
+	})
+}
+
+func TestEncryptionEnvelope(t *testing.T) {
+	const config = `{"host": "mysql", "password": "s3cret"}`
+
+	useKeys(t, "old-key", "", EncryptionFormatLegacy)
+	legacy, err := Encrypt(config)
+	require.NoError(t, err)
+	var payload string
+	require.NoError(t, json.Unmarshal([]byte(legacy), &payload))
+	require.NotContains(t, payload, ":", "legacy ciphertexts are unprefixed")
+
+	useKeys(t, "new-key", "old-key", EncryptionFormatV1)
+	v1, err := Encrypt(config)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(v1), &payload))
+	require.True(t, strings.HasPrefix(payload, "v1:"+PrimaryEncryptionKeyID()+":"), payload)
+	require.NotContains(t, payload, "new-key", "the key ID does not reveal the key")
+
+	t.Run("decrypts both formats with any configured key", func(t *testing.T) {
+		for _, ciphertext := range []string{legacy, v1} {
+			decrypted, err := Decrypt(ciphertext)
+			require.NoError(t, err)
+			require.Equal(t, config, decrypted)
+		}
+	})
+
+	t.Run("re-encrypts what the primary key did not produce in the configured format", func(t *testing.T) {
+		for ciphertext, needed := range map[string]bool{legacy: true, v1: false, config: true} {
+			needs, err := NeedsReEncryption(ciphertext)
+			require.NoError(t, err)
+			require.Equal(t, needed, needs, ciphertext)
+		}
+
+		reEncrypted, err := ReEncrypt(legacy)
+		require.NoError(t, err)
+		needs, err := NeedsReEncryption(reEncrypted)
+		require.NoError(t, err)
+		require.False(t, needs)
+	})
+
+	t.Run("connectors get the legacy format", func(t *testing.T) {
+		forConnector, err := EncryptForConnector(config)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(forConnector), &payload))
+		require.NotContains(t, payload, ":")
+	})
+
+	t.Run("fails without the key of a ciphertext", func(t *testing.T) {
+		useKeys(t, "new-key", "", EncryptionFormatV1)
+		_, err := Decrypt(legacy)
+		require.Error(t, err)
+
+		useKeys(t, "other-key", "", EncryptionFormatV1)
+		_, err = Decrypt(v1)
+		require.ErrorContains(t, err, "no encryption key configured")
+	})
+}
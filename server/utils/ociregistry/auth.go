@@ -0,0 +1,176 @@
+package ociregistry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultTokenLifetime is used when a token service does not say when its token expires
+	defaultTokenLifetime = 60 * time.Second
+	// tokenExpiryMargin renews cached tokens a bit before they expire
+	tokenExpiryMargin = 10 * time.Second
+)
+
+// challenge is a parsed WWW-Authenticate header, e.g.
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:x:pull"
+type challenge struct {
+	scheme string
+	params map[string]string
+}
+
+type bearerToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// tokenResponse is the answer of a token service, registries use either field
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// cachedAuthorization returns the Authorization header of a token still valid for host and scope
+func (c *Client) cachedAuthorization(host, scope string) string {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+
+	token, ok := c.tokens[host+"|"+scope]
+	if !ok || time.Now().After(token.expiresAt) {
+		return ""
+	}
+	return "Bearer " + token.value
+}
+
+// authorize answers a challenge with the credential of host, returning the Authorization header
+func (c *Client) authorize(ctx context.Context, host, scope string, ch challenge) (string, error) {
+	credential, err := c.credentials(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials for registry %s: %s", host, err)
+	}
+
+	switch ch.scheme {
+	case "basic":
+		if credential.Username == "" && credential.Password == "" {
+			return "", fmt.Errorf("registry %s requires credentials", host)
+		}
+		return "Basic " + basicAuth(credential), nil
+	case "bearer":
+		if credential.Token != "" {
+			return "Bearer " + credential.Token, nil
+		}
+		token, err := c.fetchToken(ctx, scope, ch, credential)
+		if err != nil {
+			return "", fmt.Errorf("failed to get token for registry %s: %s", host, err)
+		}
+
+		c.tokensMu.Lock()
+		c.tokens[host+"|"+scope] = token
+		c.tokensMu.Unlock()
+		return "Bearer " + token.value, nil
+	default:
+		return "", fmt.Errorf("registry %s asked for unsupported authentication '%s'", host, ch.scheme)
+	}
+}
+
+// fetchToken gets a bearer token from the token service named by the challenge, anonymously
+// or with basic auth when the registry has credentials
+func (c *Client) fetchToken(ctx context.Context, scope string, ch challenge, credential Credential) (bearerToken, error) {
+	realm := ch.params["realm"]
+	if realm == "" {
+		return bearerToken{}, fmt.Errorf("challenge has no realm")
+	}
+	endpoint, err := url.Parse(realm)
+	if err != nil {
+		return bearerToken{}, fmt.Errorf("invalid token realm '%s': %s", realm, err)
+	}
+
+	query := endpoint.Query()
+	if service := ch.params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if challengeScope := ch.params["scope"]; challengeScope != "" {
+		scope = challengeScope
+	}
+	query.Set("scope", scope)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	if err != nil {
+		return bearerToken{}, fmt.Errorf("failed to create token request: %s", err)
+	}
+	if credential.Username != "" || credential.Password != "" {
+		req.Header.Set("Authorization", "Basic "+basicAuth(credential))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return bearerToken{}, fmt.Errorf("failed to reach token service: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return bearerToken{}, fmt.Errorf("token service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&parsed); err != nil {
+		return bearerToken{}, fmt.Errorf("failed to decode token response: %s", err)
+	}
+	value := parsed.Token
+	if value == "" {
+		value = parsed.AccessToken
+	}
+	if value == "" {
+		return bearerToken{}, fmt.Errorf("token service returned no token")
+	}
+
+	lifetime := defaultTokenLifetime
+	if parsed.ExpiresIn > 0 {
+		lifetime = time.Duration(parsed.ExpiresIn) * time.Second
+	}
+	return bearerToken{value: value, expiresAt: time.Now().Add(lifetime - tokenExpiryMargin)}, nil
+}
+
+func basicAuth(credential Credential) string {
+	return base64.StdEncoding.EncodeToString([]byte(credential.Username + ":" + credential.Password))
+}
+
+// parseChallenge parses a WWW-Authenticate header, quoted values may hold commas
+func parseChallenge(header string) challenge {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	ch := challenge{scheme: strings.ToLower(scheme), params: map[string]string{}}
+
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				ch.params[key] = value[1:]
+				break
+			}
+			ch.params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			ch.params[key] = strings.TrimSpace(value)
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ","))
+	}
+	return ch
+}
@@ -0,0 +1,237 @@
+package ociregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The client lists image tags from any registry implementing the OCI distribution API
+// (Docker Hub, GHCR, Harbor, ECR, registry:2, ...). Registries that answer 401 are
+// authorized following their WWW-Authenticate challenge, with basic auth or a bearer token
+// fetched from the registry's token service. Credentials are looked up per registry host.
+
+const (
+	// requestTimeout bounds a single registry request
+	requestTimeout = 30 * time.Second
+	// tagsPageSize is the number of tags asked for per page, registries may return fewer
+	tagsPageSize = 1000
+	// maxTagPages stops following pagination links of a misbehaving registry
+	maxTagPages = 100
+	// dockerHubHost is where Docker Hub serves the distribution API
+	dockerHubHost = "registry-1.docker.io"
+)
+
+// Credential authorizes against one registry. Token is a bearer token sent as it is,
+// otherwise Username and Password are used for basic auth and for token requests.
+type Credential struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// CredentialFunc returns the credential of a registry host, a zero credential for anonymous access
+type CredentialFunc func(ctx context.Context, host string) (Credential, error)
+
+// Client lists tags of repositories. It is safe for concurrent use and caches bearer tokens
+// until they expire.
+type Client struct {
+	httpClient  *http.Client
+	credentials CredentialFunc
+	insecure    map[string]bool
+
+	tokensMu sync.Mutex
+	tokens   map[string]bearerToken
+}
+
+// NewClient creates a client, insecureHosts are reached over plain HTTP. Loopback hosts such as
+// a local registry:2 container are always reached over plain HTTP.
+func NewClient(credentials CredentialFunc, insecureHosts []string) *Client {
+	insecure := make(map[string]bool, len(insecureHosts))
+	for _, host := range insecureHosts {
+		if host = strings.TrimSpace(host); host != "" {
+			insecure[NormalizeHost(host)] = true
+		}
+	}
+	if credentials == nil {
+		credentials = func(context.Context, string) (Credential, error) { return Credential{}, nil }
+	}
+	return &Client{
+		httpClient:  &http.Client{Timeout: requestTimeout},
+		credentials: credentials,
+		insecure:    insecure,
+		tokens:      map[string]bearerToken{},
+	}
+}
+
+// tagList is the response of GET /v2/<name>/tags/list
+type tagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// ListTags returns every tag of an image reference such as "ghcr.io/org/image". A reference
+// without a registry host is looked up on Docker Hub.
+func (c *Client) ListTags(ctx context.Context, reference string) ([]string, error) {
+	host, repository := SplitReference(reference)
+	if repository == "" {
+		return nil, fmt.Errorf("invalid image reference '%s'", reference)
+	}
+
+	next := fmt.Sprintf("%s/v2/%s/tags/list?n=%d", c.baseURL(host), repository, tagsPageSize)
+	var tags []string
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		resp, err := c.get(ctx, host, repository, next)
+		if err != nil {
+			return nil, err
+		}
+
+		var list tagList
+		err = json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&list)
+		link := resp.Header.Get("Link")
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s: %s", reference, err)
+		}
+		tags = append(tags, list.Tags...)
+
+		if next, err = nextPage(next, link); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// get sends an authorized GET, answering an authentication challenge once
+func (c *Client) get(ctx context.Context, host, repository, target string) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	resp, err := c.send(ctx, target, c.cachedAuthorization(host, scope))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+		resp.Body.Close()
+
+		authorization, err := c.authorize(ctx, host, scope, challenge)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = c.send(ctx, target, authorization); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry %s returned status %d for %s: %s", host, resp.StatusCode, repository, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+func (c *Client) send(ctx context.Context, target, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry request: %s", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry: %s", err)
+	}
+	return resp, nil
+}
+
+func (c *Client) baseURL(host string) string {
+	if c.insecure[host] || isLoopback(host) {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+// SplitReference splits an image reference into the registry host and repository, e.g.
+// "localhost:5000/olakego/source-mysql" into "localhost:5000" and "olakego/source-mysql".
+// Docker Hub references get the host where Docker Hub serves the API, and single name
+// repositories get the "library/" namespace.
+func SplitReference(reference string) (host, repository string) {
+	reference = strings.TrimPrefix(strings.TrimPrefix(reference, "https://"), "http://")
+	reference = strings.Trim(reference, "/")
+	if at := strings.Index(reference, "@"); at >= 0 {
+		reference = reference[:at]
+	}
+	// a colon after the last slash starts a tag, before it a registry port
+	if colon := strings.LastIndex(reference, ":"); colon > strings.LastIndex(reference, "/") {
+		reference = reference[:colon]
+	}
+
+	first, rest, found := strings.Cut(reference, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		host, repository = NormalizeHost(first), rest
+	} else {
+		host, repository = dockerHubHost, reference
+	}
+	if host == dockerHubHost && !strings.Contains(repository, "/") && repository != "" {
+		repository = "library/" + repository
+	}
+	return host, repository
+}
+
+// NormalizeHost reduces a registry address to its host, mapping the Docker Hub aliases to the
+// host Docker Hub serves the API from
+func NormalizeHost(address string) string {
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	if slash := strings.Index(address, "/"); slash >= 0 {
+		address = address[:slash]
+	}
+	address = strings.ToLower(address)
+	switch address {
+	case "docker.io", "index.docker.io", "registry.hub.docker.com":
+		return dockerHubHost
+	}
+	return address
+}
+
+func isLoopback(host string) bool {
+	name := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		name = h
+	}
+	name = strings.Trim(name, "[]")
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// nextPage resolves the rel="next" Link header of a tags page against the current page
+func nextPage(current, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	for _, part := range strings.Split(link, ",") {
+		target, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+		base, err := url.Parse(current)
+		if err != nil {
+			return "", fmt.Errorf("invalid registry url '%s': %s", current, err)
+		}
+		ref, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return "", fmt.Errorf("invalid registry pagination link '%s': %s", link, err)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,57 @@
+package ociregistry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// authFile is the docker config.json format, e.g.
+//
+//	{"auths": {"ghcr.io": {"auth": "<base64 user:password>"}, "harbor.example.com": {"username": "robot", "password": "..."}}}
+//
+// registrytoken is sent as a bearer token as it is.
+type authFile struct {
+	Auths map[string]authEntry `json:"auths"`
+}
+
+type authEntry struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	RegistryToken string `json:"registrytoken"`
+}
+
+// LoadAuthFile reads the credentials of every registry of a docker config.json style file,
+// keyed by normalized registry host
+func LoadAuthFile(path string) (map[string]Credential, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry auth file: %s", err)
+	}
+
+	var parsed authFile
+	if err := json.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse registry auth file: %s", err)
+	}
+
+	credentials := make(map[string]Credential, len(parsed.Auths))
+	for address, entry := range parsed.Auths {
+		credential := Credential{Username: entry.Username, Password: entry.Password, Token: entry.RegistryToken}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of registry '%s' in registry auth file: %s", address, err)
+			}
+			username, password, found := strings.Cut(string(decoded), ":")
+			if !found {
+				return nil, fmt.Errorf("invalid auth of registry '%s' in registry auth file, expected user:password", address)
+			}
+			credential.Username, credential.Password = username, password
+		}
+		credentials[NormalizeHost(address)] = credential
+	}
+	return credentials, nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/ociregistry"
+)
+
+var (
+	registryClient     *ociregistry.Client
+	registryClientOnce sync.Once
+
+	ecrCredentialsMu sync.Mutex
+	ecrCredentials   = map[string]ecrCredential{}
+)
+
+type ecrCredential struct {
+	credential ociregistry.Credential
+	expiresAt  time.Time
+}
+
+// getRegistryClient returns the shared registry client, so bearer tokens are reused across calls
+func getRegistryClient() *ociregistry.Client {
+	registryClientOnce.Do(func() {
+		insecure := strings.Split(web.AppConfig.DefaultString(constants.ConfContainerRegistryInsecure, ""), ",")
+		registryClient = ociregistry.NewClient(registryCredentials, insecure)
+	})
+	return registryClient
+}
+
+// registryCredentials returns the credential of a registry host. In order it is taken from
+// CONTAINER_REGISTRY_AUTH_FILE, from CONTAINER_REGISTRY_USERNAME and CONTAINER_REGISTRY_PASSWORD
+// for the CONTAINER_REGISTRY_BASE registry, and from AWS for ECR registries. Other registries
+// are accessed anonymously.
+func registryCredentials(ctx context.Context, host string) (ociregistry.Credential, error) {
+	if authFile := web.AppConfig.DefaultString(constants.ConfContainerRegistryAuthFile, ""); authFile != "" {
+		credentials, err := ociregistry.LoadAuthFile(authFile)
+		if err != nil {
+			return ociregistry.Credential{}, err
+		}
+		if credential, ok := credentials[host]; ok {
+			return credential, nil
+		}
+	}
+
+	username := web.AppConfig.DefaultString(constants.ConfContainerRegistryUsername, "")
+	password := web.AppConfig.DefaultString(constants.ConfContainerRegistryPassword, "")
+	if username != "" || password != "" {
+		baseHost, _ := ociregistry.SplitReference(web.AppConfig.DefaultString(constants.ConfContainerRegistryBase, "") + "/image")
+		if baseHost == host {
+			return ociregistry.Credential{Username: username, Password: password}, nil
+		}
+	}
+
+	if accountID, region, _, err := ParseECRDetails(host + "/image"); err == nil && accountID != "public" {
+		return getECRCredential(ctx, host, accountID, region)
+	}
+	return ociregistry.Credential{}, nil
+}
+
+// getECRCredential exchanges the AWS credentials of the server for an ECR registry login,
+// cached until shortly before it expires
+func getECRCredential(ctx context.Context, host, accountID, region string) (ociregistry.Credential, error) {
+	ecrCredentialsMu.Lock()
+	defer ecrCredentialsMu.Unlock()
+
+	if cached, ok := ecrCredentials[host]; ok && time.Now().Before(cached.expiresAt) {
+		return cached.credential, nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return ociregistry.Credential{}, fmt.Errorf("failed to load AWS config: %s", err)
+	}
+	authResp, err := ecr.NewFromConfig(cfg).GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{
+		RegistryIds: []string{accountID},
+	})
+	if err != nil {
+		return ociregistry.Credential{}, fmt.Errorf("failed to get ECR authorization token: %s", err)
+	}
+	if len(authResp.AuthorizationData) == 0 {
+		return ociregistry.Credential{}, fmt.Errorf("no authorization data received from ECR")
+	}
+
+	authData := authResp.AuthorizationData[0]
+	decodedToken, err := base64.StdEncoding.DecodeString(aws.ToString(authData.AuthorizationToken))
+	if err != nil {
+		return ociregistry.Credential{}, fmt.Errorf("failed to decode authorization token: %s", err)
+	}
+	username, password, found := strings.Cut(string(decodedToken), ":")
+	if !found {
+		return ociregistry.Credential{}, fmt.Errorf("invalid authorization token format")
+	}
+
+	credential := ociregistry.Credential{Username: username, Password: password}
+	expiresAt := time.Now().Add(time.Hour)
+	if authData.ExpiresAt != nil {
+		expiresAt = authData.ExpiresAt.Add(-5 * time.Minute)
+	}
+	ecrCredentials[host] = ecrCredential{credential: credential, expiresAt: expiresAt}
+	return credential, nil
+}
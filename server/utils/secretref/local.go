@@ -0,0 +1,70 @@
+package secretref
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// envResolver reads env://NAME from the server environment. Only variables starting with
+// SECRET_REF_ENV_PREFIX can be referenced, so configs cannot read the server's own settings.
+type envResolver struct{}
+
+func (envResolver) Resolve(_ context.Context, ref *url.URL) (string, error) {
+	name := ref.Host + ref.Path
+	if name == "" {
+		return "", fmt.Errorf("env reference has no variable name")
+	}
+
+	prefix := web.AppConfig.DefaultString(constants.ConfSecretRefEnvPrefix, constants.DefaultSecretRefEnvPrefix)
+	if prefix == "" || !strings.HasPrefix(name, prefix) {
+		return "", fmt.Errorf("env variable '%s' does not start with the allowed prefix '%s'", name, prefix)
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("env variable '%s' is not set", name)
+	}
+	return selectField([]byte(value), ref.Fragment)
+}
+
+// fileResolver reads file:///path from the server filesystem, the optional fragment selects a
+// field of a JSON file. Only files under SECRET_REF_FILE_DIR can be referenced.
+type fileResolver struct{}
+
+func (fileResolver) Resolve(_ context.Context, ref *url.URL) (string, error) {
+	if ref.Host != "" && ref.Host != "localhost" {
+		return "", fmt.Errorf("file reference must be an absolute path, e.g. file:///run/secrets/name")
+	}
+
+	baseDir := web.AppConfig.DefaultString(constants.ConfSecretRefFileDir, constants.DefaultSecretRefFileDir)
+	if baseDir == "" {
+		return "", fmt.Errorf("file references are disabled")
+	}
+
+	// resolve symlinks first so a link inside the directory cannot point outside of it
+	filePath, err := filepath.EvalSymlinks(filepath.Clean(ref.Path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file '%s': %s", ref.Path, err)
+	}
+	allowedDir, err := filepath.EvalSymlinks(filepath.Clean(baseDir))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve allowed directory '%s': %s", baseDir, err)
+	}
+	rel, err := filepath.Rel(allowedDir, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file '%s' is outside the allowed directory '%s'", ref.Path, baseDir)
+	}
+
+	secret, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file '%s': %s", filePath, err)
+	}
+	return selectField(secret, ref.Fragment)
+}
@@ -0,0 +1,222 @@
+package secretref
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Secret references let connector configs point at secrets kept outside OLake instead of
+// holding them, e.g.
+//
+//	{"password": {"$secret": "vault://kv/data/mysql#password"}}
+//	{"password": {"$secret": "env://OLAKE_REF_MYSQL_PASSWORD"}}
+//	{"password": {"$secret": "file:///run/secrets/mysql#password"}}
+//
+// References are stored as they are and only resolved when the config is handed to a
+// connector or the worker. Resolved values are never stored in the database.
+
+// ReferenceKey is the only key of an object that references a secret
+const ReferenceKey = "$secret"
+
+// Resolver resolves references of one scheme. ref is the parsed reference, the fragment
+// selects a field when the secret holds several values.
+type Resolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// Register makes a resolver available for a scheme, replacing any resolver registered before
+func Register(scheme string, resolver Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[strings.ToLower(scheme)] = resolver
+}
+
+func init() {
+	Register("env", envResolver{})
+	Register("file", fileResolver{})
+	Register("vault", newVaultResolver())
+}
+
+// IsReference reports whether value is a {"$secret": "<scheme>://..."} object
+func IsReference(value interface{}) bool {
+	_, ok := referenceOf(value)
+	return ok
+}
+
+func referenceOf(value interface{}) (string, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok || len(object) != 1 {
+		return "", false
+	}
+	ref, ok := object[ReferenceKey].(string)
+	return ref, ok
+}
+
+// HasReferences reports whether a JSON config contains any secret reference
+func HasReferences(config string) bool {
+	if !strings.Contains(config, ReferenceKey) {
+		return false
+	}
+	parsed, err := decode(config)
+	if err != nil {
+		return false
+	}
+	found := false
+	walk(parsed, func(ref string) (string, error) {
+		found = true
+		return ref, nil
+	})
+	return found
+}
+
+// Validate checks that every reference of a JSON config is well formed and uses a registered scheme
+func Validate(config string) error {
+	if !strings.Contains(config, ReferenceKey) {
+		return nil
+	}
+	parsed, err := decode(config)
+	if err != nil {
+		return err
+	}
+	_, err = walk(parsed, func(ref string) (string, error) {
+		if _, _, err := parse(ref); err != nil {
+			return "", err
+		}
+		return ref, nil
+	})
+	return err
+}
+
+// ResolveConfig replaces every secret reference of a JSON config with the secret it points to
+func ResolveConfig(ctx context.Context, config string) (string, error) {
+	if !strings.Contains(config, ReferenceKey) {
+		return config, nil
+	}
+	parsed, err := decode(config)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := walk(parsed, func(ref string) (string, error) {
+		resolver, parsedRef, err := parse(ref)
+		if err != nil {
+			return "", err
+		}
+		value, err := resolver.Resolve(ctx, parsedRef)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret %s: %s", redact(parsedRef), err)
+		}
+		return value, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(resolved); err != nil {
+		return "", fmt.Errorf("failed to encode config: %s", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func parse(ref string) (Resolver, *url.URL, error) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid secret reference '%s': %s", ref, err)
+	}
+
+	resolversMu.RLock()
+	resolver, ok := resolvers[strings.ToLower(parsed.Scheme)]
+	resolversMu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported secret reference scheme '%s'", parsed.Scheme)
+	}
+	return resolver, parsed, nil
+}
+
+// walk rebuilds value with every reference replaced by fn
+func walk(value interface{}, fn func(ref string) (string, error)) (interface{}, error) {
+	if ref, ok := referenceOf(value); ok {
+		return fn(ref)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			resolved, err := walk(child, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+	case []interface{}:
+		for i, child := range v {
+			resolved, err := walk(child, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	}
+	return value, nil
+}
+
+func decode(config string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(config))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return parsed, nil
+}
+
+// redact drops credentials and query parameters from a reference before it is logged
+func redact(ref *url.URL) string {
+	clean := *ref
+	clean.User = nil
+	clean.RawQuery = ""
+	return clean.String()
+}
+
+// selectField returns the named field of a JSON object secret, or the whole secret without a field
+func selectField(secret []byte, field string) (string, error) {
+	if field == "" {
+		return strings.TrimRight(string(secret), "\r\n"), nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(secret, &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select field '%s'", field)
+	}
+	return stringField(fields, field)
+}
+
+func stringField(fields map[string]interface{}, field string) (string, error) {
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("field '%s' not found in secret", field)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode field '%s': %s", field, err)
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,107 @@
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// vaultRequestTimeout bounds a single read from Vault
+const vaultRequestTimeout = 10 * time.Second
+
+// vaultResolver reads secrets from a Vault compatible KV v2 engine over HTTP.
+// vault://kv/data/mysql#password reads GET <VAULT_ADDR>/v1/kv/data/mysql and returns the
+// "password" field, ?version=N pins a secret version. KV v1 mounts (no nested "data") are
+// read the same way. The server authenticates with VAULT_TOKEN and VAULT_NAMESPACE.
+type vaultResolver struct {
+	client *http.Client
+}
+
+func newVaultResolver() *vaultResolver {
+	return &vaultResolver{client: &http.Client{Timeout: vaultRequestTimeout}}
+}
+
+// vaultResponse is the envelope of a Vault read, KV v2 nests the secret under data.data
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+func (v *vaultResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	addr := strings.TrimRight(web.AppConfig.DefaultString(constants.ConfVaultAddr, ""), "/")
+	if addr == "" {
+		return "", fmt.Errorf("%s is not set", constants.ConfVaultAddr)
+	}
+	token := web.AppConfig.DefaultString(constants.ConfVaultToken, "")
+	if token == "" {
+		return "", fmt.Errorf("%s is not set", constants.ConfVaultToken)
+	}
+
+	secretPath := strings.Trim(ref.Host+ref.Path, "/")
+	if secretPath == "" {
+		return "", fmt.Errorf("vault reference has no secret path")
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s", addr, secretPath)
+	if version := ref.Query().Get("version"); version != "" {
+		endpoint += "?version=" + url.QueryEscape(version)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %s", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := web.AppConfig.DefaultString(constants.ConfVaultNamespace, ""); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from vault: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault response: %s", err)
+	}
+
+	var parsed vaultResponse
+	_ = json.Unmarshal(body, &parsed)
+	if resp.StatusCode != http.StatusOK {
+		if len(parsed.Errors) > 0 {
+			return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.Join(parsed.Errors, ", "))
+		}
+		return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	fields := parsed.Data
+	if nested, ok := fields["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := fields["metadata"]; hasMetadata {
+			fields = nested
+		}
+	}
+	if fields == nil {
+		return "", fmt.Errorf("vault secret '%s' has no data", secretPath)
+	}
+
+	field := ref.Fragment
+	if field == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("vault secret '%s' has %d fields, select one with #field", secretPath, len(fields))
+		}
+		for name := range fields {
+			field = name
+		}
+	}
+	return stringField(fields, field)
+}
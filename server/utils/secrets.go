@@ -0,0 +1,427 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/secretref"
+)
+
+// SecretPaths is the set of config fields holding secrets, keyed by dot separated path.
+// Array items are addressed with "[]", e.g. "brokers[].password".
+type SecretPaths map[string]struct{}
+
+// encryptedFieldKey is the key of the object replacing a secret encrypted on its own,
+// {"$encrypted": "<ciphertext>"}
+const encryptedFieldKey = "$encrypted"
+
+// anySecretPath marks every field as secret, see AllSecretPaths
+const anySecretPath = "*"
+
+// AllSecretPaths treats every field as a secret, for configs whose connector spec is unknown
+func AllSecretPaths() SecretPaths {
+	return SecretPaths{anySecretPath: {}}
+}
+
+// secretNameHints flag fields as secrets when the connector spec does not mark them
+var secretNameHints = []string{"password", "passphrase", "secret", "token", "private_key", "api_key", "access_key", "credential"}
+
+// SecretPathsFromSpec collects the secret fields of a connector spec. A field is secret when its
+// schema has format "password", writeOnly, secret or airbyte_secret set, or when its uischema
+// uses the password widget. The spec is the output of the spec command, {"jsonschema", "uischema"}.
+func SecretPathsFromSpec(spec map[string]interface{}) SecretPaths {
+	paths := SecretPaths{}
+	if spec == nil {
+		return paths
+	}
+
+	schema, ok := spec["jsonschema"].(map[string]interface{})
+	if !ok {
+		schema = spec
+	}
+
+	var uiSchema map[string]interface{}
+	switch ui := spec["uischema"].(type) {
+	case string:
+		_ = json.Unmarshal([]byte(ui), &uiSchema)
+	case map[string]interface{}:
+		uiSchema = ui
+	}
+
+	collectSecretPaths(schema, uiSchema, "", paths)
+	return paths
+}
+
+func collectSecretPaths(schema, uiSchema map[string]interface{}, prefix string, paths SecretPaths) {
+	if schema == nil {
+		return
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, raw := range properties {
+			property, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			propertyUI, _ := uiSchema[name].(map[string]interface{})
+			path := joinSecretPath(prefix, name)
+			if isSecretSchema(property, propertyUI) {
+				paths[path] = struct{}{}
+			}
+			collectSecretPaths(property, propertyUI, path, paths)
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		itemsUI, _ := uiSchema["items"].(map[string]interface{})
+		collectSecretPaths(items, itemsUI, prefix+"[]", paths)
+	}
+
+	// variants share the parent path, a field is secret if any variant marks it
+	for _, keyword := range []string{"oneOf", "anyOf", "allOf"} {
+		variants, _ := schema[keyword].([]interface{})
+		for _, raw := range variants {
+			if variant, ok := raw.(map[string]interface{}); ok {
+				collectSecretPaths(variant, uiSchema, prefix, paths)
+			}
+		}
+	}
+}
+
+func isSecretSchema(schema, uiSchema map[string]interface{}) bool {
+	if format, _ := schema["format"].(string); format == "password" {
+		return true
+	}
+	for _, flag := range []string{"writeOnly", "secret", "airbyte_secret"} {
+		if set, _ := schema[flag].(bool); set {
+			return true
+		}
+	}
+	widget, _ := uiSchema["ui:widget"].(string)
+	return widget == "password"
+}
+
+// isSecretFieldName reports whether a field name looks like it holds a secret
+func isSecretFieldName(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range secretNameHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p SecretPaths) isSecret(path, name string) bool {
+	if _, ok := p[path]; ok {
+		return true
+	}
+	if _, ok := p[anySecretPath]; ok {
+		return true
+	}
+	return isSecretFieldName(name)
+}
+
+// MaskSecrets replaces every non empty secret string of a config with constants.SecretMask
+func MaskSecrets(config string, paths SecretPaths) (string, error) {
+	if strings.TrimSpace(config) == "" {
+		return config, nil
+	}
+
+	parsed, err := decodeConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	masked := walkSecrets(parsed, "", "", paths, func(value interface{}) interface{} {
+		if s, ok := value.(string); ok && s != "" || isEncryptedField(value) {
+			return constants.SecretMask
+		}
+		return value
+	})
+	return encodeConfig(masked)
+}
+
+// EncryptSecretFields encrypts every non empty secret string of a decrypted config on its own,
+// replacing it with {"$encrypted": "<ciphertext>"}. Masks, references and fields encrypted
+// already are kept. Without an encryption key the config is returned as it is.
+func EncryptSecretFields(config string, paths SecretPaths) (string, error) {
+	if strings.TrimSpace(config) == "" || PrimaryEncryptionKeyID() == "" {
+		return config, nil
+	}
+
+	parsed, err := decodeConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	var encryptErr error
+	encrypted := walkSecrets(parsed, "", "", paths, func(value interface{}) interface{} {
+		s, ok := value.(string)
+		if !ok || s == "" || s == constants.SecretMask || encryptErr != nil {
+			return value
+		}
+		ciphertext, err := encryptField(s)
+		if err != nil {
+			encryptErr = err
+			return value
+		}
+		return map[string]interface{}{encryptedFieldKey: ciphertext}
+	})
+	if encryptErr != nil {
+		return "", fmt.Errorf("failed to encrypt secret field: %s", encryptErr)
+	}
+	return encodeConfig(encrypted)
+}
+
+// DecryptSecretFields replaces every field of a decrypted config encrypted on its own with its
+// value, for the connector
+func DecryptSecretFields(config string) (string, error) {
+	if !strings.Contains(config, encryptedFieldKey) {
+		return config, nil
+	}
+
+	parsed, err := decodeConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	var decryptErr error
+	decrypted := mapEncryptedFields(parsed, func(ciphertext string) interface{} {
+		plaintext, err := Decrypt(fmt.Sprintf("%q", ciphertext))
+		if err != nil && decryptErr == nil {
+			decryptErr = err
+		}
+		return plaintext
+	})
+	if decryptErr != nil {
+		return "", fmt.Errorf("failed to decrypt secret field: %s", decryptErr)
+	}
+	return encodeConfig(decrypted)
+}
+
+// reEncryptSecretFields encrypts the fields of a decrypted config encrypted on their own again
+// with the primary key, when they are not encrypted with it in the configured format yet
+func reEncryptSecretFields(config string) (string, error) {
+	if !strings.Contains(config, encryptedFieldKey) {
+		return config, nil
+	}
+
+	parsed, err := decodeConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	var reEncryptErr error
+	reEncrypted := mapEncryptedFields(parsed, func(ciphertext string) interface{} {
+		field := map[string]interface{}{encryptedFieldKey: ciphertext}
+		quoted := fmt.Sprintf("%q", ciphertext)
+		if needed, err := NeedsReEncryption(quoted); err != nil || !needed || reEncryptErr != nil {
+			if reEncryptErr == nil {
+				reEncryptErr = err
+			}
+			return field
+		}
+		plaintext, err := Decrypt(quoted)
+		if err == nil {
+			field[encryptedFieldKey], err = encryptField(plaintext)
+		}
+		if err != nil {
+			reEncryptErr = err
+		}
+		return field
+	})
+	if reEncryptErr != nil {
+		return "", fmt.Errorf("failed to re-encrypt secret field: %s", reEncryptErr)
+	}
+	return encodeConfig(reEncrypted)
+}
+
+// secretFieldsNeedReEncryption reports whether any field of a decrypted config encrypted on its
+// own is not encrypted with the primary key in the configured format
+func secretFieldsNeedReEncryption(config string) (bool, error) {
+	if !strings.Contains(config, encryptedFieldKey) {
+		return false, nil
+	}
+
+	parsed, err := decodeConfig(config)
+	if err != nil {
+		return false, err
+	}
+
+	needed := false
+	var checkErr error
+	mapEncryptedFields(parsed, func(ciphertext string) interface{} {
+		fieldNeeded, err := NeedsReEncryption(fmt.Sprintf("%q", ciphertext))
+		if err != nil && checkErr == nil {
+			checkErr = err
+		}
+		needed = needed || fieldNeeded
+		return ciphertext
+	})
+	return needed, checkErr
+}
+
+// encryptField encrypts a secret value, returning the ciphertext without its JSON quotes
+func encryptField(value string) (string, error) {
+	quoted, err := Encrypt(value)
+	if err != nil {
+		return "", err
+	}
+	var ciphertext string
+	if err := json.Unmarshal([]byte(quoted), &ciphertext); err != nil {
+		return "", fmt.Errorf("failed to unmarshal JSON string: %s", err)
+	}
+	return ciphertext, nil
+}
+
+// isEncryptedField reports whether a config value is a secret encrypted on its own
+func isEncryptedField(value interface{}) bool {
+	field, ok := value.(map[string]interface{})
+	if !ok || len(field) != 1 {
+		return false
+	}
+	_, ok = field[encryptedFieldKey].(string)
+	return ok
+}
+
+// mapEncryptedFields rebuilds value with fn applied to the ciphertext of every field
+// encrypted on its own
+func mapEncryptedFields(value interface{}, fn func(ciphertext string) interface{}) interface{} {
+	if isEncryptedField(value) {
+		return fn(value.(map[string]interface{})[encryptedFieldKey].(string))
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = mapEncryptedFields(child, fn)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = mapEncryptedFields(child, fn)
+		}
+	}
+	return value
+}
+
+// RestoreMaskedSecrets puts back the stored value of every field that config still holds as
+// constants.SecretMask, so clients can send a masked config back unchanged. Fields are matched
+// by position rather than by spec, so a secret masked earlier is restored even if the spec
+// is not available now.
+func RestoreMaskedSecrets(config, stored string) (string, error) {
+	if !strings.Contains(config, constants.SecretMask) {
+		return config, nil
+	}
+
+	parsed, err := decodeConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	var storedConfig interface{}
+	if strings.TrimSpace(stored) != "" {
+		if storedConfig, err = decodeConfig(stored); err != nil {
+			return "", fmt.Errorf("failed to parse stored config: %s", err)
+		}
+	}
+
+	var missing []string
+	restored := restoreSecrets(parsed, storedConfig, "", &missing)
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no stored value for masked fields: %s", strings.Join(missing, ", "))
+	}
+	return encodeConfig(restored)
+}
+
+// walkSecrets rebuilds value with fn applied to every secret field
+func walkSecrets(value interface{}, path, name string, paths SecretPaths, fn func(value interface{}) interface{}) interface{} {
+	// references point at a secret without holding it and are returned as they are
+	if secretref.IsReference(value) {
+		return value
+	}
+	// fields encrypted on their own are secrets whatever their name
+	if isEncryptedField(value) {
+		return fn(value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = walkSecrets(child, joinSecretPath(path, key), key, paths, fn)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = walkSecrets(child, path+"[]", name, paths, fn)
+		}
+		return v
+	default:
+		if path != "" && paths.isSecret(path, name) {
+			return fn(v)
+		}
+		return v
+	}
+}
+
+func restoreSecrets(value, stored interface{}, path string, missing *[]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		storedMap, _ := stored.(map[string]interface{})
+		for key, child := range v {
+			v[key] = restoreSecrets(child, storedMap[key], joinSecretPath(path, key), missing)
+		}
+		return v
+	case []interface{}:
+		storedList, _ := stored.([]interface{})
+		for i, child := range v {
+			var storedChild interface{}
+			if i < len(storedList) {
+				storedChild = storedList[i]
+			}
+			v[i] = restoreSecrets(child, storedChild, path+"[]", missing)
+		}
+		return v
+	case string:
+		if v != constants.SecretMask {
+			return v
+		}
+		if _, ok := stored.(string); !ok && !isEncryptedField(stored) {
+			*missing = append(*missing, path)
+			return v
+		}
+		return stored
+	default:
+		return v
+	}
+}
+
+func joinSecretPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// decodeConfig parses a JSON config keeping numbers exact
+func decodeConfig(config string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(config))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return parsed, nil
+}
+
+func encodeConfig(config interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(config); err != nil {
+		return "", fmt.Errorf("failed to encode config: %s", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
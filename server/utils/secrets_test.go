@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskAndRestoreSecrets(t *testing.T) {
+	spec := map[string]interface{}{
+		"jsonschema": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"host": map[string]interface{}{"type": "string"},
+				"dsn":  map[string]interface{}{"type": "string", "format": "password"},
+				"brokers": map[string]interface{}{
+					"items": map[string]interface{}{
+						"properties": map[string]interface{}{
+							"address": map[string]interface{}{"type": "string"},
+							"sasl":    map[string]interface{}{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+		"uischema": `{"brokers": {"items": {"sasl": {"ui:widget": "password"}}}}`,
+	}
+	paths := SecretPathsFromSpec(spec)
+	require.Equal(t, SecretPaths{"dsn": {}, "brokers[].sasl": {}}, paths)
+
+	stored := `{"host": "mysql", "port": 3306, "dsn": "user:pw@mysql", "password": "s3cret", "brokers": [{"address": "b1", "sasl": "k1"}, {"address": "b2", "sasl": "k2"}], "ref": {"$secret": "env://OLAKE_REF_TOKEN"}}`
+	masked, err := MaskSecrets(stored, paths)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"host": "mysql", "port": 3306, "dsn": "********", "password": "********", "brokers": [{"address": "b1", "sasl": "********"}, {"address": "b2", "sasl": "********"}], "ref": {"$secret": "env://OLAKE_REF_TOKEN"}}`, masked,
+		"spec secrets and secret-looking names are masked, references are kept")
+
+	t.Run("masks every field without a spec", func(t *testing.T) {
+		masked, err := MaskSecrets(stored, AllSecretPaths())
+		require.NoError(t, err)
+		require.JSONEq(t, `{"host": "********", "port": 3306, "dsn": "********", "password": "********", "brokers": [{"address": "********", "sasl": "********"}, {"address": "********", "sasl": "********"}], "ref": {"$secret": "env://OLAKE_REF_TOKEN"}}`, masked)
+
+		restored, err := RestoreMaskedSecrets(masked, stored)
+		require.NoError(t, err)
+		require.JSONEq(t, stored, restored)
+	})
+
+	t.Run("restores unchanged secrets", func(t *testing.T) {
+		restored, err := RestoreMaskedSecrets(masked, stored)
+		require.NoError(t, err)
+		require.JSONEq(t, stored, restored)
+	})
+
+	t.Run("keeps changed secrets", func(t *testing.T) {
+		update := `{"host": "mysql", "port": 3307, "dsn": "********", "password": "rotated", "brokers": [{"address": "b1", "sasl": "********"}]}`
+		restored, err := RestoreMaskedSecrets(update, stored)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"host": "mysql", "port": 3307, "dsn": "user:pw@mysql", "password": "rotated", "brokers": [{"address": "b1", "sasl": "k1"}]}`, restored)
+	})
+
+	t.Run("fails on masks without a stored value", func(t *testing.T) {
+		_, err := RestoreMaskedSecrets(`{"brokers": [{"sasl": "********"}, {"sasl": "********"}, {"sasl": "********"}]}`, stored)
+		require.ErrorContains(t, err, "brokers[].sasl")
+
+		_, err = RestoreMaskedSecrets(`{"password": "********"}`, "")
+		require.ErrorContains(t, err, "password")
+	})
+}
+
+func TestEncryptSecretFields(t *testing.T) {
+	const config = `{"host": "mysql", "dsn": "user:pw@mysql", "password": "********", "brokers": [{"address": "b1", "sasl": "k1"}], "ref": {"$secret": "env://OLAKE_REF_TOKEN"}}`
+	paths := SecretPaths{"dsn": {}, "brokers[].sasl": {}}
+
+	useKeys(t, "old-key", "", EncryptionFormatV1)
+	encrypted, err := EncryptSecretFields(config, paths)
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "user:pw@mysql")
+	require.NotContains(t, encrypted, "k1")
+	require.Contains(t, encrypted, `"host":"mysql"`)
+	require.Contains(t, encrypted, `"password":"********"`, "masks are left for RestoreMaskedSecrets")
+	require.Contains(t, encrypted, `{"$secret":"env://OLAKE_REF_TOKEN"}`)
+
+	again, err := EncryptSecretFields(encrypted, paths)
+	require.NoError(t, err)
+	require.Equal(t, encrypted, again, "encrypted fields are not encrypted twice")
+
+	t.Run("decrypts for connectors", func(t *testing.T) {
+		decrypted, err := DecryptSecretFields(encrypted)
+		require.NoError(t, err)
+		require.JSONEq(t, config, decrypted)
+	})
+
+	t.Run("masks and restores encrypted fields whatever the spec", func(t *testing.T) {
+		masked, err := MaskSecrets(encrypted, SecretPaths{})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"host": "mysql", "dsn": "********", "password": "********", "brokers": [{"address": "b1", "sasl": "********"}], "ref": {"$secret": "env://OLAKE_REF_TOKEN"}}`, masked)
+
+		restored, err := RestoreMaskedSecrets(`{"host": "mysql", "dsn": "********", "brokers": [{"address": "b1", "sasl": "k2"}]}`, encrypted)
+		require.NoError(t, err)
+		decrypted, err := DecryptSecretFields(restored)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"host": "mysql", "dsn": "user:pw@mysql", "brokers": [{"address": "b1", "sasl": "k2"}]}`, decrypted)
+	})
+
+	t.Run("re-encrypts fields with the primary key", func(t *testing.T) {
+		stored, err := Encrypt(encrypted)
+		require.NoError(t, err)
+
+		useKeys(t, "new-key", "old-key", EncryptionFormatV1)
+		needed, err := SecretFieldsNeedReEncryption(stored)
+		require.NoError(t, err)
+		require.True(t, needed)
+
+		reEncrypted, err := ReEncrypt(stored)
+		require.NoError(t, err)
+		needed, err = SecretFieldsNeedReEncryption(reEncrypted)
+		require.NoError(t, err)
+		require.False(t, needed)
+
+		useKeys(t, "new-key", "", EncryptionFormatV1)
+		decrypted, err := Decrypt(reEncrypted)
+		require.NoError(t, err)
+		decrypted, err = DecryptSecretFields(decrypted)
+		require.NoError(t, err)
+		require.JSONEq(t, config, decrypted)
+	})
+
+	t.Run("keeps configs without an encryption key", func(t *testing.T) {
+		useKeys(t, "", "", EncryptionFormatLegacy)
+		unchanged, err := EncryptSecretFields(config, paths)
+		require.NoError(t, err)
+		require.Equal(t, config, unchanged)
+	})
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// localStorage keeps files on the disk mounted by both the server and the worker
+type localStorage struct {
+	root string
+}
+
+type localObject struct {
+	*os.File
+	info os.FileInfo
+}
+
+func (o *localObject) Size() int64 {
+	return o.info.Size()
+}
+
+func (o *localObject) ModTime() time.Time {
+	return o.info.ModTime()
+}
+
+func newLocalStorage(root string) *localStorage {
+	return &localStorage{root: root}
+}
+
+func (l *localStorage) Location() string {
+	return l.root
+}
+
+func (l *localStorage) fullPath(p string) string {
+	return filepath.Join(l.root, filepath.FromSlash(cleanPath(p)))
+}
+
+func notFound(p string, err error) error {
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", p, ErrNotFound)
+	}
+	return err
+}
+
+func (l *localStorage) Read(_ context.Context, p string) ([]byte, error) {
+	data, err := os.ReadFile(l.fullPath(p))
+	if err != nil {
+		return nil, notFound(p, err)
+	}
+	return data, nil
+}
+
+func (l *localStorage) Write(_ context.Context, p string, data []byte) error {
+	filePath := l.fullPath(p)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %s", filepath.Dir(filePath), err)
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+func (l *localStorage) Open(_ context.Context, p string) (Object, error) {
+	file, err := os.Open(l.fullPath(p))
+	if err != nil {
+		return nil, notFound(p, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &localObject{File: file, info: info}, nil
+}
+
+func (l *localStorage) Exists(_ context.Context, p string) (bool, error) {
+	_, err := os.Stat(l.fullPath(p))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (l *localStorage) ReadDir(_ context.Context, p string) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(l.fullPath(p))
+	if err != nil {
+		return nil, notFound(p, err)
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		entries = append(entries, Entry{Name: entry.Name(), IsDir: entry.IsDir()})
+	}
+	return entries, nil
+}
+
+func (l *localStorage) Walk(_ context.Context, p string) ([]ObjectInfo, error) {
+	root := l.fullPath(p)
+	var objects []ObjectInfo
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		// files and folders deleted while walking (e.g. by the worker or a concurrent cleanup)
+		// are skipped, only a missing root is an error
+		if errors.Is(err, fs.ErrNotExist) && filePath != root {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(l.root, filePath)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, ObjectInfo{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, notFound(p, err)
+	}
+	return objects, nil
+}
+
+func (l *localStorage) Delete(_ context.Context, p string) error {
+	if cleanPath(p) == "" {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+	return os.RemoveAll(l.fullPath(p))
+}
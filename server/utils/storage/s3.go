@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+)
+
+// s3DeleteBatchSize is the maximum number of keys accepted by a single DeleteObjects call
+const s3DeleteBatchSize = 1000
+
+// s3Storage keeps files in an S3 compatible bucket (AWS S3, MinIO, ...)
+type s3Storage struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// s3Object reads a file with ranged GET requests so log pagination does not download whole files
+type s3Object struct {
+	ctx     context.Context
+	storage *s3Storage
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+func (o *s3Object) ReadAt(p []byte, off int64) (int, error) {
+	if off >= o.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	end := min(off+int64(len(p)), o.size) - 1
+	out, err := o.storage.client.GetObject(o.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(o.storage.bucket),
+		Key:    aws.String(o.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, end)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer out.Body.Close()
+
+	n, err := io.ReadFull(out.Body, p[:end-off+1])
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (o *s3Object) Close() error {
+	return nil
+}
+
+func (o *s3Object) Size() int64 {
+	return o.size
+}
+
+func (o *s3Object) ModTime() time.Time {
+	return o.modTime
+}
+
+func newS3Storage(ctx context.Context) (*s3Storage, error) {
+	bucket, _ := web.AppConfig.String(constants.ConfStorageS3Bucket)
+	if strings.TrimSpace(bucket) == "" {
+		return nil, fmt.Errorf("%s is required for the s3 storage backend", constants.ConfStorageS3Bucket)
+	}
+	prefix, _ := web.AppConfig.String(constants.ConfStorageS3Prefix)
+	endpoint, _ := web.AppConfig.String(constants.ConfStorageS3Endpoint)
+	region, _ := web.AppConfig.String(constants.ConfStorageS3Region)
+	accessKey, _ := web.AppConfig.String(constants.ConfStorageS3AccessKey)
+	secretKey, _ := web.AppConfig.String(constants.ConfStorageS3SecretKey)
+	pathStyle := web.AppConfig.DefaultBool(constants.ConfStorageS3PathStyle, false)
+
+	opts := []func(*config.LoadOptions) error{}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	// fall back to the default credential chain (env, shared config, IAM role) when no static keys are set
+	if accessKey != "" && secretKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %s", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = pathStyle
+	})
+
+	return &s3Storage{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+func (s *s3Storage) Location() string {
+	return "s3://" + path.Join(s.bucket, s.prefix)
+}
+
+// key maps a relative path to the object key in the bucket
+func (s *s3Storage) key(p string) string {
+	return path.Join(s.prefix, cleanPath(p))
+}
+
+// dirKey maps a relative folder path to the key prefix of its children
+func (s *s3Storage) dirKey(p string) string {
+	key := s.key(p)
+	if key == "" {
+		return ""
+	}
+	return key + "/"
+}
+
+// relPath maps an object key back to a path relative to the storage root
+func (s *s3Storage) relPath(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, s.prefix+"/")
+}
+
+func isS3NotFound(err error) bool {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return true
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NotFound" || apiErr.ErrorCode() == "NoSuchKey")
+}
+
+func (s *s3Storage) Read(ctx context.Context, p string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(p)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("%s: %w", p, ErrNotFound)
+		}
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+func (s *s3Storage) Write(ctx context.Context, p string, data []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(s.key(p)),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	return err
+}
+
+func (s *s3Storage) Open(ctx context.Context, p string) (Object, error) {
+	key := s.key(p)
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("%s: %w", p, ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return &s3Object{
+		ctx:     ctx,
+		storage: s,
+		key:     key,
+		size:    aws.ToInt64(out.ContentLength),
+		modTime: aws.ToTime(out.LastModified),
+	}, nil
+}
+
+func (s *s3Storage) Exists(ctx context.Context, p string) (bool, error) {
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(p)),
+	})
+	if err == nil {
+		return true, nil
+	}
+	if !isS3NotFound(err) {
+		return false, err
+	}
+
+	// folders only exist as key prefixes
+	out, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		Prefix:  aws.String(s.dirKey(p)),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(out.Contents) > 0, nil
+}
+
+func (s *s3Storage) ReadDir(ctx context.Context, p string) ([]Entry, error) {
+	prefix := s.dirKey(p)
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+
+	var entries []Entry
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(commonPrefix.Prefix), prefix), "/")
+			entries = append(entries, Entry{Name: name, IsDir: true})
+		}
+		for _, object := range page.Contents {
+			entries = append(entries, Entry{Name: strings.TrimPrefix(aws.ToString(object.Key), prefix)})
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: %w", p, ErrNotFound)
+	}
+	return entries, nil
+}
+
+func (s *s3Storage) Walk(ctx context.Context, p string) ([]ObjectInfo, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.dirKey(p)),
+	})
+
+	var objects []ObjectInfo
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Path:    s.relPath(aws.ToString(object.Key)),
+				Size:    aws.ToInt64(object.Size),
+				ModTime: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, p string) error {
+	if cleanPath(p) == "" {
+		return fmt.Errorf("refusing to delete the storage root")
+	}
+
+	// p may be a single file or a folder prefix
+	keys := []types.ObjectIdentifier{{Key: aws.String(s.key(p))}}
+	objects, err := s.Walk(ctx, p)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		keys = append(keys, types.ObjectIdentifier{Key: aws.String(s.key(object.Path))})
+	}
+
+	// quiet deletes only report the keys that failed
+	var failed []string
+	for start := 0; start < len(keys); start += s3DeleteBatchSize {
+		end := min(start+s3DeleteBatchSize, len(keys))
+		out, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{Objects: keys[start:end], Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete objects under %s: %s", p, err)
+		}
+		for _, deleteErr := range out.Errors {
+			failed = append(failed, fmt.Sprintf("%s (%s: %s)", s.relPath(aws.ToString(deleteErr.Key)), aws.ToString(deleteErr.Code), aws.ToString(deleteErr.Message)))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete %d objects under %s: %s", len(failed), p, strings.Join(failed, ", "))
+	}
+	return nil
+}
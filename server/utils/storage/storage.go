@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Storage abstracts where workflow configs, logs and artifacts live. Paths are
+// slash separated and relative to the config directory shared with the worker,
+// e.g. "<sha256(workflowID)>/logs/sync_<ts>/olake.log".
+//
+// Configuration:
+//   - STORAGE_BACKEND=local (default) keeps files under constants.DefaultConfigDir
+//   - STORAGE_BACKEND=s3 keeps files in STORAGE_S3_BUCKET under STORAGE_S3_PREFIX,
+//     set STORAGE_S3_ENDPOINT and STORAGE_S3_PATH_STYLE=true for MinIO
+//
+// The worker must be configured with the same backend. With s3, the worker downloads the
+// configs of a run before starting the connector and uploads its logs, state and outputs to
+// the same keys, since connectors only read and write local files.
+type Storage interface {
+	// Read returns the content of the file at path
+	Read(ctx context.Context, path string) ([]byte, error)
+	// Write stores data at path, creating parent folders as needed
+	Write(ctx context.Context, path string, data []byte) error
+	// Open returns a random access reader over the file at path
+	Open(ctx context.Context, path string) (Object, error)
+	// Exists reports whether a file or a folder exists at path
+	Exists(ctx context.Context, path string) (bool, error)
+	// ReadDir lists the immediate children of the folder at path
+	ReadDir(ctx context.Context, path string) ([]Entry, error)
+	// Walk lists every file under the folder at path, recursively
+	Walk(ctx context.Context, path string) ([]ObjectInfo, error)
+	// Delete removes the file or folder at path along with everything under it
+	Delete(ctx context.Context, path string) error
+	// Location describes where files are kept, the local root or the s3 bucket and prefix
+	Location() string
+}
+
+// Object is an opened file that supports reads at arbitrary offsets
+type Object interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+	ModTime() time.Time
+}
+
+// Entry is an immediate child of a folder
+type Entry struct {
+	Name  string
+	IsDir bool
+}
+
+// ObjectInfo describes a file, Path is relative to the config directory
+type ObjectInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// ErrNotFound is returned when the requested file or folder does not exist
+var ErrNotFound = errors.New("not found")
+
+var instance Storage = newLocalStorage(constants.DefaultConfigDir)
+
+// Backend returns the storage backend selected in the app config
+func Backend() string {
+	backend, _ := web.AppConfig.String(constants.ConfStorageBackend)
+	backend = strings.ToLower(strings.TrimSpace(backend))
+	if backend == "" {
+		return constants.DefaultStorageBackend
+	}
+	return backend
+}
+
+// Init selects the storage backend from the app config
+func Init() error {
+	backend := Backend()
+	switch backend {
+	case "local":
+		instance = newLocalStorage(constants.DefaultConfigDir)
+	case "s3":
+		s3Storage, err := newS3Storage(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to initialize s3 storage: %s", err)
+		}
+		instance = s3Storage
+	default:
+		return fmt.Errorf("unsupported storage backend '%s', supported backends are: local, s3", backend)
+	}
+
+	logger.Infof("Using %s storage backend for workflow files", backend)
+	return nil
+}
+
+// Get returns the configured storage backend
+func Get() Storage {
+	return instance
+}
+
+// cleanPath normalizes a relative path and keeps it from escaping the storage root
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(p, "\\", "/")), "/")
+}
@@ -5,12 +5,11 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"time"
 
-	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
 )
 
 type jobDetails struct {
@@ -90,7 +89,7 @@ func trackSyncEvent(ctx context.Context, jobID int, workflowID, executionEnviron
 
 	properties := prepareCommonProperties(jobID, workflowID, executionEnvironment, details, time.Time{})
 	if eventType == EventSyncCompleted {
-		if err := enrichWithSyncStats(properties, workflowID); err != nil {
+		if err := enrichWithSyncStats(ctx, properties, workflowID); err != nil {
 			return err
 		}
 	}
@@ -101,20 +100,19 @@ func trackSyncEvent(ctx context.Context, jobID int, workflowID, executionEnviron
 	return nil
 }
 
-func enrichWithSyncStats(properties map[string]interface{}, workflowID string) error {
-	syncFolderName := fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID)))
-	mainSyncDir := filepath.Join(constants.DefaultConfigDir, syncFolderName)
+func enrichWithSyncStats(ctx context.Context, properties map[string]interface{}, workflowID string) error {
+	mainSyncDir := fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID)))
 
-	if err := addStatsProperties(properties, mainSyncDir); err != nil {
+	if err := addStatsProperties(ctx, properties, mainSyncDir); err != nil {
 		return err
 	}
 
-	return addStreamsProperties(properties, mainSyncDir)
+	return addStreamsProperties(ctx, properties, mainSyncDir)
 }
 
-func addStatsProperties(properties map[string]interface{}, mainSyncDir string) error {
-	statsPath := filepath.Join(mainSyncDir, "stats.json")
-	statsData, err := os.ReadFile(statsPath)
+func addStatsProperties(ctx context.Context, properties map[string]interface{}, mainSyncDir string) error {
+	statsPath := path.Join(mainSyncDir, "stats.json")
+	statsData, err := storage.Get().Read(ctx, statsPath)
 	if err != nil {
 		return err
 	}
@@ -133,9 +131,9 @@ func addStatsProperties(properties map[string]interface{}, mainSyncDir string) e
 	return nil
 }
 
-func addStreamsProperties(properties map[string]interface{}, mainSyncDir string) error {
-	streamsPath := filepath.Join(mainSyncDir, "streams.json")
-	streamsData, err := os.ReadFile(streamsPath)
+func addStreamsProperties(ctx context.Context, properties map[string]interface{}, mainSyncDir string) error {
+	streamsPath := path.Join(mainSyncDir, "streams.json")
+	streamsData, err := storage.Get().Read(ctx, streamsPath)
 	if err != nil {
 		return fmt.Errorf("failed to read streams.json: %s", err)
 	}
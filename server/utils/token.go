@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip-inc/olake-ui/server/internal/constants"
+	"github.com/datazip-inc/olake-ui/server/utils/logger"
+)
+
+// Signed tokens are "<payload>.<signature>", both base64url. The payload names what the token
+// is for and whom, and when it expires, the signature is an HMAC-SHA256 of the payload.
+
+type tokenPayload struct {
+	Purpose   string `json:"p"`
+	Subject   string `json:"s"`
+	ExpiresAt int64  `json:"e"`
+}
+
+var (
+	tokenKeyOnce sync.Once
+	tokenKey     []byte
+)
+
+// getTokenKey returns the signing key, AUTH_TOKEN_SECRET or else one derived from the encryption
+// key. Without either a random key is used and tokens stop working on restart.
+func getTokenKey() []byte {
+	tokenKeyOnce.Do(func() {
+		secret := web.AppConfig.DefaultString(constants.ConfAuthTokenSecret, "")
+		if secret == "" {
+			if encryptionKey := web.AppConfig.DefaultString(constants.ConfEncryptionKey, ""); encryptionKey != "" {
+				secret = "olake-token:" + encryptionKey
+			}
+		}
+		if secret != "" {
+			sum := sha256.Sum256([]byte(secret))
+			tokenKey = sum[:]
+			return
+		}
+
+		logger.Warnf("%s and the encryption key are not set, signed tokens will not survive a restart", constants.ConfAuthTokenSecret)
+		tokenKey = make([]byte, 32)
+		if _, err := rand.Read(tokenKey); err != nil {
+			logger.Fatalf("failed to generate token signing key: %s", err)
+		}
+	})
+	return tokenKey
+}
+
+// SignToken returns a token for purpose and subject that is valid until expiresAt
+func SignToken(purpose, subject string, expiresAt time.Time) (string, error) {
+	payload, err := json.Marshal(tokenPayload{Purpose: purpose, Subject: subject, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token: %s", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signToken(encoded)), nil
+}
+
+// VerifyToken checks the signature, purpose and expiry of a token and returns its subject
+func VerifyToken(token, purpose string) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", constants.ErrInvalidToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || subtle.ConstantTimeCompare(decodedSignature, signToken(encoded)) != 1 {
+		return "", constants.ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", constants.ErrInvalidToken
+	}
+	var payload tokenPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return "", constants.ErrInvalidToken
+	}
+	if payload.Purpose != purpose || time.Now().Unix() >= payload.ExpiresAt {
+		return "", constants.ErrInvalidToken
+	}
+	return payload.Subject, nil
+}
+
+func signToken(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, getTokenKey())
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
+
+// KeyedHash returns a hex HMAC of data under the token key, for fingerprints of secrets that
+// are stored but must not be reversible by guessing
+func KeyedHash(purpose, data string) string {
+	mac := hmac.New(sha256.New, getTokenKey())
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
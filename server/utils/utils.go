@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/json"
@@ -12,7 +13,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +28,7 @@ import (
 	"github.com/datazip-inc/olake-ui/server/internal/constants"
 	"github.com/datazip-inc/olake-ui/server/internal/models/dto"
 	"github.com/datazip-inc/olake-ui/server/utils/logger"
+	"github.com/datazip-inc/olake-ui/server/utils/storage"
 )
 
 func ToMapOfInterface(structure any) map[string]interface{} {
@@ -295,7 +300,7 @@ func isValidLogLine(line string) bool {
 // Filters out empty lines, invalid JSON, and debug-level logs DURING reading.
 // startOffset is treated as exclusive - we read lines that END BEFORE startOffset.
 // Returns: valid lines (oldest->newest), newOffset (byte position before first returned line), hasMore, error.
-func ReadLinesBackward(f *os.File, startOffset int64, limit int, fileSize int64) ([]string, int64, bool, error) {
+func ReadLinesBackward(f io.ReaderAt, startOffset int64, limit int, fileSize int64) ([]string, int64, bool, error) {
 	if limit <= 0 {
 		return nil, 0, false, fmt.Errorf("limit must be greater than 0")
 	}
@@ -414,7 +419,7 @@ func ReadLinesBackward(f *os.File, startOffset int64, limit int, fileSize int64)
 // Filters out empty lines, invalid JSON, and debug-level logs DURING reading.
 // startOffset is treated as inclusive - we start reading from exactly that position.
 // Returns: valid lines (oldest->newest), newOffset (byte position after last returned line), hasMore, error.
-func ReadLinesForward(f *os.File, startOffset int64, limit int, fileSize int64) ([]string, int64, bool, error) {
+func ReadLinesForward(f io.ReaderAt, startOffset int64, limit int, fileSize int64) ([]string, int64, bool, error) {
 	if limit <= 0 {
 		return nil, 0, false, fmt.Errorf("limit must be greater than 0")
 	}
@@ -427,12 +432,8 @@ func ReadLinesForward(f *os.File, startOffset int64, limit int, fileSize int64)
 		return []string{}, fileSize, false, nil
 	}
 
-	// Seek to the startOffset position in the file before beginning to read lines
-	if _, err := f.Seek(startOffset, io.SeekStart); err != nil {
-		return nil, 0, false, err
-	}
-
-	reader := bufio.NewReader(f)
+	// Read lines from the startOffset position till the end of the file
+	reader := bufio.NewReader(io.NewSectionReader(f, startOffset, fileSize-startOffset))
 
 	lines := make([]string, 0, limit)
 	currentOffset := startOffset
@@ -472,35 +473,50 @@ func ReadLinesForward(f *os.File, startOffset int64, limit int, fileSize int64)
 	return lines, currentOffset, hasMore, nil
 }
 
-// ReadLogs reads logs from the given mainLogDir and returns structured log entries.
+// ReadLogs reads the connector logs of the latest attempt under the given mainLogDir.
+// mainLogDir is relative to the config directory of the storage backend.
 // Direction can be "older" or "newer". If cursor < 0, it tails from the end of the file.
 // Returns a TaskLogsResponse-like struct: oldest->newest logs plus cursors and hasMore flags.
-func ReadLogs(mainLogDir string, cursor int64, limit int, direction string) (*dto.TaskLogsResponse, error) {
+func ReadLogs(ctx context.Context, mainLogDir string, cursor int64, limit int, direction string) (*dto.TaskLogsResponse, error) {
+	return ReadAttemptLogs(ctx, mainLogDir, "", constants.LogTypeSync, cursor, limit, direction)
+}
+
+// ReadAttemptLogs reads the connector log (olake.log) of one logs/sync_* attempt folder under
+// mainLogDir. An empty attempt selects the latest attempt. Cursors are byte offsets in the file.
+func ReadAttemptLogs(ctx context.Context, mainLogDir, attempt, logType string, cursor int64, limit int, direction string) (*dto.TaskLogsResponse, error) {
 	// Check if mainLogDir exists
-	if _, err := os.Stat(mainLogDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("logs directory not found: %s: %s", mainLogDir, err)
+	if exists, err := storage.Get().Exists(ctx, mainLogDir); err != nil || !exists {
+		return nil, fmt.Errorf("logs directory not found: %s: %v", mainLogDir, err)
 	}
 
-	// Resolve and validate logs/sync_* directory
-	logsDir, syncFolderName, err := GetAndValidateSyncDir(mainLogDir)
+	// Resolve and validate every logs/sync_* directory
+	logsDir, attempts, err := GetAndValidateSyncDirs(ctx, mainLogDir)
 	if err != nil {
 		return nil, err
 	}
 
-	logDir := filepath.Join(logsDir, syncFolderName)
-	logPath := filepath.Join(logDir, "olake.log")
+	if logType == "" {
+		logType = constants.LogTypeSync
+	}
 
-	logFile, err := os.Open(logPath)
+	if attempt == "" {
+		attempt = attempts[len(attempts)-1]
+	} else if !slices.Contains(attempts, attempt) {
+		return nil, fmt.Errorf("attempt %s not found in: %s", attempt, logsDir)
+	}
+
+	logPath, err := ResolveAttemptLogPath(logsDir, attempt, logType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read log file: %s: %s", logPath, err)
+		return nil, err
 	}
-	defer logFile.Close()
 
-	stat, err := logFile.Stat()
+	logFile, err := storage.Get().Open(ctx, logPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat log file: %s", err)
+		return nil, fmt.Errorf("failed to read log file: %s: %s", logPath, err)
 	}
-	fileSize := stat.Size()
+	defer logFile.Close()
+
+	fileSize := logFile.Size()
 
 	// Normalize limit
 	if limit <= 0 {
@@ -521,7 +537,11 @@ func ReadLogs(mainLogDir string, cursor int64, limit int, direction string) (*dt
 	// Initial tail: cursor < 0 means "from end of file"
 	isTail := cursor < 0
 
-	response := &dto.TaskLogsResponse{}
+	response := &dto.TaskLogsResponse{
+		Attempt:  attempt,
+		Attempts: attempts,
+		LogType:  logType,
+	}
 
 	// Parse validated lines into response format
 	// Lines are already filtered (no empty, no invalid JSON, no debug) by ReadLines functions
@@ -621,73 +641,111 @@ func RetryWithBackoff(fn func() error, maxRetries int, initialDelay time.Duratio
 	return fmt.Errorf("failed after %d retries: %s", maxRetries, errMsg)
 }
 
-// GetAndValidateLogBaseDir returns the base directory path for log files
-// based on the SHA256 hash of the filePath (workflow ID) and validates it exists
-func GetAndValidateLogBaseDir(filePath string) (string, error) {
+// GetAndValidateLogBaseDir returns the base directory path for log files, relative to
+// the storage root, based on the SHA256 hash of the filePath (workflow ID) and validates it exists
+func GetAndValidateLogBaseDir(ctx context.Context, filePath string) (string, error) {
 	if filePath == "" {
 		return "", fmt.Errorf("file path cannot be empty")
 	}
 
-	syncFolderName := fmt.Sprintf("%x", sha256.Sum256([]byte(filePath)))
-	homeDir := constants.DefaultConfigDir
-	baseDir := filepath.Join(homeDir, syncFolderName)
+	baseDir := fmt.Sprintf("%x", sha256.Sum256([]byte(filePath)))
 
 	// Verify directory exists
-	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("logs directory not found: %s: %s", baseDir, err)
+	if exists, err := storage.Get().Exists(ctx, baseDir); err != nil || !exists {
+		return "", fmt.Errorf("logs directory not found: %s: %v", baseDir, err)
 	}
 
 	return baseDir, nil
 }
 
-// GetAndValidateSyncDir returns the logs directory and sync_* folder name under it
-func GetAndValidateSyncDir(baseDir string) (string, string, error) {
-	logsDir := filepath.Join(baseDir, "logs")
+// GetAndValidateSyncDir returns the logs directory and the latest sync_* folder name under it
+func GetAndValidateSyncDir(ctx context.Context, baseDir string) (string, string, error) {
+	logsDir, attempts, err := GetAndValidateSyncDirs(ctx, baseDir)
+	if err != nil {
+		return "", "", err
+	}
+	return logsDir, attempts[len(attempts)-1], nil
+}
+
+// GetAndValidateSyncDirs returns the logs directory and every sync_* folder name under it,
+// oldest first. A retried run writes one folder per attempt, see SortAttempts for the order.
+func GetAndValidateSyncDirs(ctx context.Context, baseDir string) (string, []string, error) {
+	logsDir := path.Join(baseDir, "logs")
 
-	entries, err := os.ReadDir(logsDir)
+	entries, err := storage.Get().ReadDir(ctx, logsDir)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read logs directory: %s", err)
+		return "", nil, fmt.Errorf("failed to read logs directory: %s", err)
 	}
 	if len(entries) == 0 {
-		return "", "", fmt.Errorf("no sync log folders found in: %s", logsDir)
+		return "", nil, fmt.Errorf("no sync log folders found in: %s", logsDir)
 	}
 
+	var attempts []string
 	for _, entry := range entries {
-		// get the first directory that starts with "sync_"
-		if entry.IsDir() && strings.HasPrefix(entry.Name(), "sync_") {
-			return logsDir, entry.Name(), nil
+		if entry.IsDir && strings.HasPrefix(entry.Name, "sync_") {
+			attempts = append(attempts, entry.Name)
 		}
 	}
+	if len(attempts) == 0 {
+		return "", nil, fmt.Errorf("no sync folder found in: %s", logsDir)
+	}
 
-	return "", "", fmt.Errorf("no sync folder found in: %s", logsDir)
+	SortAttempts(attempts)
+	return logsDir, attempts, nil
 }
 
-// addFileToArchive streams a file into the tar archive
-func AddFileToArchive(tarWriter *tar.Writer, filePath, nameInArchive string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open file %s: %s", filePath, err)
+// SortAttempts orders sync_* attempt folders oldest first by the attempt start time in their
+// name (sync_2006-01-02_15-04-05, UTC). Folders without a parsable start time sort first, by name.
+func SortAttempts(attempts []string) {
+	startedAt := func(attempt string) (time.Time, bool) {
+		t, err := time.Parse(constants.AttemptFolderTimeLayout, strings.TrimPrefix(attempt, "sync_"))
+		return t, err == nil
+	}
+	sort.SliceStable(attempts, func(i, j int) bool {
+		ti, iok := startedAt(attempts[i])
+		tj, jok := startedAt(attempts[j])
+		switch {
+		case iok != jok:
+			return !iok
+		case iok && !ti.Equal(tj):
+			return ti.Before(tj)
+		default:
+			return attempts[i] < attempts[j]
+		}
+	})
+}
+
+// ResolveAttemptLogPath returns the path of the requested log type for an attempt folder.
+// Only connector logs live in the attempt folders, activity logs are read from the workflow
+// history of the run.
+func ResolveAttemptLogPath(logsDir, attempt, logType string) (string, error) {
+	if logType != constants.LogTypeSync {
+		return "", fmt.Errorf("invalid log type '%s' for attempt logs, only %s logs are stored per attempt", logType, constants.LogTypeSync)
 	}
-	defer file.Close()
+	return path.Join(logsDir, attempt, constants.SyncLogFileName), nil
+}
 
-	fileInfo, err := file.Stat()
+// addFileToArchive streams a file from the storage backend into the tar archive
+func AddFileToArchive(ctx context.Context, tarWriter *tar.Writer, filePath, nameInArchive string) error {
+	file, err := storage.Get().Open(ctx, filePath)
 	if err != nil {
-		return fmt.Errorf("failed to stat file %s: %s", filePath, err)
+		return fmt.Errorf("failed to open file %s: %s", filePath, err)
 	}
+	defer file.Close()
 
 	// tar header with file metadata
 	header := &tar.Header{
 		Name:    nameInArchive,
-		Size:    fileInfo.Size(),
-		Mode:    int64(fileInfo.Mode()),
-		ModTime: fileInfo.ModTime(),
+		Size:    file.Size(),
+		Mode:    0644,
+		ModTime: file.ModTime(),
 	}
 
 	if err := tarWriter.WriteHeader(header); err != nil {
 		return fmt.Errorf("failed to write tar header for %s: %s", nameInArchive, err)
 	}
 
-	bytesWritten, err := io.Copy(tarWriter, file)
+	bytesWritten, err := io.Copy(tarWriter, io.NewSectionReader(file, 0, file.Size()))
 	if err != nil {
 		return fmt.Errorf("failed to write file content for %s: %s", nameInArchive, err)
 	}
@@ -697,14 +755,35 @@ func AddFileToArchive(tarWriter *tar.Writer, filePath, nameInArchive string) err
 	return nil
 }
 
+// AddBytesToArchive writes in-memory content into the tar archive as a file
+func AddBytesToArchive(tarWriter *tar.Writer, data []byte, nameInArchive string) error {
+	header := &tar.Header{
+		Name:    nameInArchive,
+		Size:    int64(len(data)),
+		Mode:    0644,
+		ModTime: time.Now(),
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write tar header for %s: %s", nameInArchive, err)
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		return fmt.Errorf("failed to write file content for %s: %s", nameInArchive, err)
+	}
+
+	logger.Debugf("Added %s to archive (%d bytes)", nameInArchive, len(data))
+
+	return nil
+}
+
 // GetLogArchiveFilename generates the filename for the log archive download
-func GetLogArchiveFilename(jobID int, filePath string) (string, error) {
-	baseDir, err := GetAndValidateLogBaseDir(filePath)
+func GetLogArchiveFilename(ctx context.Context, jobID int, filePath string) (string, error) {
+	baseDir, err := GetAndValidateLogBaseDir(ctx, filePath)
 	if err != nil {
 		return "", err
 	}
 
-	_, syncFolderName, err := GetAndValidateSyncDir(baseDir)
+	_, syncFolderName, err := GetAndValidateSyncDir(ctx, baseDir)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortAttempts(t *testing.T) {
+	attempts := []string{
+		"sync_2024-03-10_09-00-00",
+		"sync_2024-03-09_23-59-59",
+		"sync_retry",
+		"sync_2024-03-10_08-59-59",
+		"sync_2024-03-10_09-00-00",
+	}
+	SortAttempts(attempts)
+	require.Equal(t, []string{
+		"sync_retry",
+		"sync_2024-03-09_23-59-59",
+		"sync_2024-03-10_08-59-59",
+		"sync_2024-03-10_09-00-00",
+		"sync_2024-03-10_09-00-00",
+	}, attempts)
+}